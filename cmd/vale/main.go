@@ -1,11 +1,14 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/errata-ai/vale/v2/internal/cli"
 	"github.com/errata-ai/vale/v2/internal/core"
@@ -20,6 +23,28 @@ func validateFlags(cfg *core.Config) error {
 		return core.NewE100(
 			"--config",
 			fmt.Errorf("path '%s' does not exist", cfg.Flags.Path))
+	} else if cfg.Flags.SortBy != "" && !core.StringInSlice(cfg.Flags.SortBy, core.AlertOrders) {
+		return core.NewE100(
+			"--sort-by",
+			fmt.Errorf("'%s' must be one of %v", cfg.Flags.SortBy, core.AlertOrders))
+	} else if cfg.Flags.JSONSchema < 1 || cfg.Flags.JSONSchema > cli.JSONSchema {
+		return core.NewE100(
+			"--json-schema",
+			fmt.Errorf("'%d' must be between 1 and %d", cfg.Flags.JSONSchema, cli.JSONSchema))
+	} else if cfg.Flags.Input != "" && !strings.EqualFold(cfg.Flags.Input, "json") {
+		return core.NewE100(
+			"--input",
+			fmt.Errorf("'%s' must be 'json'", cfg.Flags.Input))
+	} else if _, err := core.ParseExitCodes(cfg.Flags.SeverityExit); err != nil {
+		return core.NewE100("--severity-exit-code", err)
+	} else if cfg.Flags.Sample < 0 {
+		return core.NewE100(
+			"--sample",
+			fmt.Errorf("'%d' must be a positive number", cfg.Flags.Sample))
+	} else if cfg.Flags.MaxProblems < 0 {
+		return core.NewE100(
+			"--max-problems",
+			fmt.Errorf("'%d' must be a positive number", cfg.Flags.MaxProblems))
 	}
 	return nil
 }
@@ -41,19 +66,62 @@ func doLint(args []string, l *lint.Linter, glob string) ([]*core.File, error) {
 	var err error
 
 	length := len(args)
-	if length > 0 {
-		if length == 1 && looksLikeStdin(args[0]) {
+	if strings.EqualFold(l.Manager.Config.Flags.Input, "json") {
+		// Case 4:
+		//
+		// $ cat docs.json | vale --input=json
+		if length > 0 {
+			return linted, core.NewE100(
+				"--input",
+				errors.New("documents are read from stdin; no arguments are allowed"))
+		} else if err = requireConfig(l.Manager.Config); err != nil {
+			return linted, err
+		}
+		docs, err := lint.ReadDocuments(os.Stdin)
+		if err != nil {
+			return linted, err
+		}
+		return l.LintDocuments(docs)
+	} else if core.StringInSlice("-", args) {
+		// Case 5:
+		//
+		// $ cat file.md | vale --ext=.md -
+		//
+		// Unlike case 1, this is never mistaken for a path.
+		if length > 1 {
+			return linted, core.NewE100(
+				"doLint",
+				errors.New("'-' (stdin) can't be combined with other arguments"))
+		}
+		return lintStdin(l)
+	} else if length > 0 {
+		if length == 1 && looksLikeStdin(args[0]) && !lint.IsGlob(args[0]) {
 			// Case 1:
 			//
 			// $ vale "some text in a string"
+			if err = requireConfig(l.Manager.Config); err != nil {
+				return linted, err
+			}
 			linted, err = l.LintString(args[0])
 		} else {
 			// Case 2:
 			//
-			// $ vale file1 dir1 file2
+			// $ vale file1 dir1 file2 'docs/**/*.md'
+			globbed, matched := false, 0
+
 			input := []string{}
 			for _, file := range args {
-				if looksLikeStdin(file) {
+				if lint.IsGlob(file) {
+					// The shell didn't expand the pattern (e.g., it was
+					// quoted or we're on Windows), so we do.
+					matches, err := lint.ExpandGlob(file)
+					if err != nil {
+						return linted, err
+					}
+					globbed, matched = true, matched+len(matches)
+					input = append(input, matches...)
+					continue
+				} else if looksLikeStdin(file) {
 					return linted, core.NewE100(
 						"doLint",
 						fmt.Errorf("argument '%s' does not exist", file),
@@ -62,21 +130,80 @@ func doLint(args []string, l *lint.Linter, glob string) ([]*core.File, error) {
 				input = append(input, file)
 			}
 			linted, err = l.Lint(input, glob)
+			if err == nil && len(linted) == 0 {
+				err = reportEmpty(l.Manager.Config, globbed, matched, l.Skipped())
+			}
 		}
 	} else {
 		// Case 3:
 		//
 		// $ cat file.md | vale
-		stdin, err := ioutil.ReadAll(os.Stdin)
-		if err != nil {
-			return linted, core.NewE100("doLint", err)
-		}
-		linted, err = l.LintString(string(stdin))
+		linted, err = lintStdin(l)
 	}
 
 	return linted, err
 }
 
+// lintStdin lints all of stdin as a single document whose format is given by
+// `--ext`.
+func lintStdin(l *lint.Linter) ([]*core.File, error) {
+	if err := requireConfig(l.Manager.Config); err != nil {
+		return nil, err
+	}
+	stdin, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, core.NewE100("doLint", err)
+	}
+	return l.LintString(string(stdin))
+}
+
+// requireConfig returns an error if we weren't loaded from a configuration
+// file, which only the files we lint may do without: they're also matched
+// against the configuration files in their own directories (see
+// `core.Config.HasConfig`).
+func requireConfig(cfg *core.Config) error {
+	if cfg.HasConfig() {
+		return nil
+	}
+	return core.NewE100(".vale.ini", errors.New(
+		"no configuration file found in the current directory or its parents"))
+}
+
+// reportEmpty explains why no files were linted -- e.g., "0 files linted (3
+// matched glob, 3 skipped: unknown format)" -- returning it as an error if
+// `FailIfEmpty` is set.
+//
+// Otherwise, linting nothing isn't a problem (e.g., a directory with no
+// files that our configuration applies to), so it's only a warning with
+// `--debug`.
+func reportEmpty(cfg *core.Config, globbed bool, matched int, skipped map[string]int) error {
+	details := []string{}
+	if globbed {
+		details = append(details, fmt.Sprintf("%d matched glob", matched))
+	}
+
+	reasons := []string{}
+	for reason := range skipped {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		details = append(details, fmt.Sprintf("%d skipped: %s", skipped[reason], reason))
+	}
+
+	summary := "0 files linted"
+	if len(details) > 0 {
+		summary += " (" + strings.Join(details, ", ") + ")"
+	}
+
+	if cfg.FailIfEmpty {
+		return core.NewE100("doLint", errors.New(summary))
+	} else if cfg.Flags.Debug {
+		cfg.Warnf(summary)
+	}
+	return nil
+}
+
 func handleError(err error) {
 	cli.ShowError(err, cli.Flags.Output, os.Stderr)
 	os.Exit(2)
@@ -84,15 +211,28 @@ func handleError(err error) {
 
 func main() {
 	v := flag.Bool("v", false, "prints current version")
+	flag.BoolVar(v, "version", false,
+		"prints current version (with --output=JSON, along with our configuration)")
 	flag.Parse()
 
+	cli.Version = version
+
 	config, err := core.NewConfig(&cli.Flags)
 	if err != nil {
 		cli.ShowError(err, cli.Flags.Output, os.Stderr)
 	}
 
 	if *v {
-		fmt.Println("vale version " + version)
+		if cli.Flags.Output == "JSON" {
+			// The configuration is optional here: we still report our
+			// version without one.
+			_ = core.From("ini", config)
+			if err = core.PrintJSON(cli.NewRunMeta(config)); err != nil {
+				handleError(err)
+			}
+		} else {
+			fmt.Println("vale version " + version)
+		}
 		os.Exit(0)
 	}
 
@@ -112,8 +252,14 @@ func main() {
 	if argc > 0 {
 		cmd, exists := cli.Actions[args[0]]
 		if exists {
+			if args[0] != "help" && args[0] != "ls-builtin" {
+				if err = requireConfig(config); err != nil {
+					handleError(err)
+				}
+			}
 			if err = cmd(args[1:], config); err != nil {
-				log.Fatal(err)
+				cli.ShowError(err, cli.Flags.Output, os.Stderr)
+				os.Exit(1)
 			}
 			os.Exit(0)
 		}
@@ -129,12 +275,63 @@ func main() {
 		handleError(err)
 	}
 
-	hasErrors, err := cli.PrintAlerts(linted, config)
+	if cli.Flags.Fix {
+		for _, f := range linted {
+			if _, err = f.Fix(); err != nil {
+				handleError(err)
+			}
+		}
+	}
+
+	// Our exit code reflects every alert, not just those we sample.
+	code := exitCode(linted, config)
+	if config.Flags.Sample > 0 {
+		if config.Flags.SampleSeed == 0 {
+			// The seed is included in our output, so a run can be
+			// reproduced.
+			config.Flags.SampleSeed = time.Now().UnixNano()
+		}
+		core.SampleAlerts(linted, config.Flags.Sample, config.Flags.SampleSeed)
+	}
+
+	_, err = cli.PrintAlerts(linted, config)
 	if err != nil {
 		handleError(err)
-	} else if hasErrors && !cli.Flags.NoExit {
-		os.Exit(1)
 	}
 
-	os.Exit(0)
+	// Files that couldn't be linted don't stop the run, but they're still
+	// errors.
+	failures := linter.Failures()
+	for i := range failures {
+		cli.ShowError(&failures[i], cli.Flags.Output, os.Stderr)
+	}
+
+	// The same is true of rules that panicked, which were disabled for the
+	// rest of the run.
+	ruleErrors := linter.RuleErrors()
+	for i := range ruleErrors {
+		cli.ShowError(&ruleErrors[i], cli.Flags.Output, os.Stderr)
+	}
+
+	if len(failures) > 0 || (cli.Flags.Strict && len(ruleErrors) > 0) {
+		os.Exit(2)
+	} else if cli.Flags.NoExit {
+		os.Exit(0)
+	}
+
+	os.Exit(code)
+}
+
+// exitCode returns the highest exit code caused by the alerts in `linted`
+// (see `core.Config.ExitCodes`).
+func exitCode(linted []*core.File, config *core.Config) int {
+	code := 0
+	for _, f := range linted {
+		for _, a := range f.Alerts {
+			if c := config.ExitCodes[a.Severity]; c > code {
+				code = c
+			}
+		}
+	}
+	return code
 }
@@ -0,0 +1,775 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/errata-ai/vale/v2/internal/cli"
+	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/errata-ai/vale/v2/internal/testutil"
+)
+
+// TestMain allows the test binary to act as `vale` itself (see `runVale`).
+func TestMain(m *testing.M) {
+	if os.Getenv("VALE_TEST_MAIN") == "1" {
+		main()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// runVale runs `vale` with the given arguments, returning its stdout and
+// stderr.
+func runVale(t *testing.T, dir string, args ...string) (string, string) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "VALE_TEST_MAIN=1")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			t.Fatal(err)
+		}
+	}
+
+	return stdout.String(), stderr.String()
+}
+
+func TestJSONOutputIsClean(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"broken.ini":        "StylesPath = missing\n[*]\nBasedOnStyles = Test\n",
+		"level.ini":         "StylesPath = styles\nMinAlertLevel = fatal\n[*]\nBasedOnStyles = Test\n",
+		"warn.ini":          "StylesPath = styles\n[*]\nBasedOnStyles = Test\n",
+		"styles/Test/A.yml": "extends: substitution\nmessage: '%s'\nswap:\n  '(foo)': bar\n",
+		"test.md":           "foo bar\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	cases := []struct {
+		args   []string
+		stderr bool
+	}{
+		{[]string{"--config=broken.ini", "test.md"}, true},
+		{[]string{"--config=level.ini", "test.md"}, true},
+		{[]string{"--config=missing.ini", "test.md"}, true},
+		{[]string{"--config=broken.ini", "ls-config"}, true},
+		{[]string{"--config=warn.ini", "test.md"}, true},
+		{[]string{"--config=warn.ini", "--quiet", "test.md"}, false},
+	}
+
+	for _, c := range cases {
+		args := append([]string{"--output=JSON"}, c.args...)
+		stdout, stderr := runVale(t, dir, args...)
+
+		if stdout != "" && !json.Valid([]byte(stdout)) {
+			t.Errorf("%v: expected valid JSON (or nothing) on stdout, got %q", c.args, stdout)
+		}
+		if (stderr != "") != c.stderr {
+			t.Errorf("%v: expected output on stderr = %v, got %q", c.args, c.stderr, stderr)
+		}
+	}
+}
+
+func TestSync(t *testing.T) {
+	dir := t.TempDir()
+
+	// A complete setup, served as a zip file ...
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"Org/.vale.ini":                   "MinAlertLevel = error\n[*]\nBasedOnStyles = Org\nOrg.Rule = warning\n",
+		"Org/styles/Org/Rule.yml":         "extends: existence\nmessage: '%s'\ntokens:\n  - foo\n",
+		"Org/styles/Vocab/Org/accept.txt": "Vale\n",
+	} {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		} else if _, err = f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Org.zip" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	// ... and a single style, given as a local directory.
+	files := map[string]string{
+		"Single/Rule.yml":              "extends: existence\nmessage: '%s'\ntokens:\n  - bar\n",
+		"styles/Vocab/Mine/accept.txt": "Mine\n",
+		".vale.ini": "StylesPath = styles\nMinAlertLevel = suggestion\n" +
+			"Packages = " + server.URL + "/Org.zip, Single\n[*]\nBasedOnStyles = Single\n",
+		"broken.ini": "StylesPath = styles\nPackages = " + server.URL + "/Missing.zip\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	// Syncing twice gives the same result.
+	for i := 0; i < 2; i++ {
+		if stdout, stderr := runVale(t, dir, "sync"); stdout != "Synced 2 packages.\n" {
+			t.Fatalf("%d: expected 2 packages, got %q (%q)", i, stdout, stderr)
+		}
+	}
+
+	for _, name := range []string{"Org/Rule.yml", "Single/Rule.yml", "Vocab/Org/accept.txt", "Vocab/Mine/accept.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, "styles", filepath.FromSlash(name))); err != nil {
+			t.Errorf("expected '%s' to be installed: %s", name, err)
+		}
+	}
+
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, "styles", ".sync-*")); len(leftovers) > 0 {
+		t.Errorf("expected temporary directories to be removed, got %v", leftovers)
+	}
+
+	// Our settings win over the package's, while styles are combined.
+	stdout, stderr := runVale(t, dir, "--output=JSON", "ls-config")
+	cfg := struct {
+		GBaseStyles   []string
+		MinAlertLevel int
+		RuleToLevel   map[string]string
+	}{}
+	if err := json.Unmarshal([]byte(stdout), &cfg); err != nil {
+		t.Fatalf("%s (%q)", err, stderr)
+	}
+	if cfg.MinAlertLevel != 0 || cfg.RuleToLevel["Org.Rule"] != "warning" {
+		t.Errorf("expected our MinAlertLevel and the package's level, got %+v", cfg)
+	} else if len(cfg.GBaseStyles) != 2 {
+		t.Errorf("expected both packages' styles, got %v", cfg.GBaseStyles)
+	}
+
+	// A failed download leaves what's installed alone.
+	if _, stderr = runVale(t, dir, "--config=broken.ini", "sync"); stderr == "" {
+		t.Error("expected an error for a missing package")
+	} else if _, err := os.Stat(filepath.Join(dir, "styles", "Org", "Rule.yml")); err != nil {
+		t.Errorf("expected 'Org' to remain installed: %s", err)
+	}
+}
+
+func TestYAMLConfig(t *testing.T) {
+	ini := strings.Join([]string{
+		"StylesPath = styles",
+		"MinAlertLevel = suggestion",
+		"Vocab = Base",
+		"",
+		"[formats]",
+		"mdx = md",
+		"",
+		"[*]",
+		"BasedOnStyles = Vale, Test",
+		"Vale.Spelling = NO",
+		"Test.A.tokens = [bar, baz]",
+		"",
+		"[*.md]",
+		"Test.A = error",
+		"BlockIgnores = `(?s) *(<!-- #include .*? -->)`",
+		"TokenIgnores = (\\$+[^\\n$]+\\$+), (:[a-z]+:)",
+	}, "\n")
+
+	yml := strings.Join([]string{
+		"StylesPath: styles",
+		"MinAlertLevel: suggestion",
+		"Vocab: [Base]",
+		"formats:",
+		"  mdx: md",
+		"'*':",
+		"  BasedOnStyles: [Vale, Test]",
+		"  Vale.Spelling: NO",
+		"  Test.A.tokens: [bar, baz]",
+		"'*.md':",
+		"  Test.A: error",
+		"  BlockIgnores: ['(?s) *(<!-- #include .*? -->)']",
+		"  TokenIgnores: ['(\\$+[^\\n$]+\\$+)', '(:[a-z]+:)']",
+	}, "\n")
+
+	rendered := map[string]string{}
+	for name, content := range map[string]string{".vale.ini": ini, ".vale.yml": yml} {
+		dir := t.TempDir()
+
+		files := map[string]string{
+			name:                           content,
+			"styles/Test/A.yml":            "extends: existence\nmessage: '%s'\ntokens:\n  - foo\n",
+			"styles/Vocab/Base/accept.txt": "Vale\n",
+		}
+		testutil.WriteFiles(t, dir, files)
+
+		stdout, stderr := runVale(t, dir, "--output=JSON", "ls-config")
+		if stderr != "" {
+			t.Fatalf("%s: %s", name, stderr)
+		}
+
+		// Only the paths differ.
+		stdout = strings.Replace(stdout, filepath.ToSlash(dir), "$DIR", -1)
+		stdout = strings.Replace(stdout, dir, "$DIR", -1)
+		rendered[name] = strings.Replace(stdout, name, "$CONFIG", -1)
+	}
+
+	if rendered[".vale.ini"] != rendered[".vale.yml"] {
+		t.Errorf("expected the same configuration, got:\n%s\nand:\n%s",
+			rendered[".vale.ini"], rendered[".vale.yml"])
+	}
+}
+
+func TestCheckLinks(t *testing.T) {
+	dir := t.TempDir()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ok" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	rule := "extends: existence\nmessage: '%s'\ntokens:\n  - foo\n"
+	files := map[string]string{
+		".vale.ini":                 "StylesPath = styles\n[*]\nBasedOnStyles = Test\n",
+		"styles/Test/Alive.yml":     rule + "link: " + server.URL + "/ok\n",
+		"styles/Test/Dead.yml":      rule + "link: " + server.URL + "/missing\n",
+		"styles/Test/Malformed.yml": rule + "link: docs/rule.html\n",
+		"styles/Test/Missing.yml":   rule,
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	cases := []struct {
+		args   []string
+		rules  []string
+		notice bool
+	}{
+		{[]string{}, []string{"Test.Malformed", "Test.Missing"}, false},
+		{[]string{"--online"}, []string{"Test.Dead", "Test.Malformed", "Test.Missing"}, false},
+		{[]string{"--online", "--offline"}, []string{"Test.Malformed", "Test.Missing"}, true},
+	}
+
+	for _, c := range cases {
+		args := append(append([]string{"--output=JSON"}, c.args...), "check-links")
+		stdout, stderr := runVale(t, dir, args...)
+
+		problems := []struct{ Rule string }{}
+		if err := json.Unmarshal([]byte(stdout), &problems); err != nil {
+			t.Fatalf("%v: %s (%q)", c.args, err, stderr)
+		}
+
+		rules := []string{}
+		for _, p := range problems {
+			rules = append(rules, p.Rule)
+		}
+		if strings.Join(rules, ",") != strings.Join(c.rules, ",") {
+			t.Errorf("%v: expected %v, got %v", c.args, c.rules, rules)
+		}
+		if strings.Contains(stderr, "--offline") != c.notice {
+			t.Errorf("%v: expected a notice = %v, got %q", c.args, c.notice, stderr)
+		}
+	}
+}
+
+func TestSectionVocabs(t *testing.T) {
+	dir := t.TempDir()
+
+	ini := strings.Join([]string{
+		"StylesPath = styles",
+		"Vocab = Base",
+		"[*.md]",
+		"BasedOnStyles = Vale",
+		"[docs/a/*.md]",
+		"Vocab = ProductA",
+		"[docs/b/*.md]",
+		"Vocab = Base, ProductB",
+		"[docs/c/*.md]",
+		"Vocab = Base, ProductB",
+	}, "\n")
+
+	text := "Zorblax uses vale.\n"
+	files := map[string]string{
+		".vale.ini":                        ini,
+		"styles/Vocab/Base/accept.txt":     "Vale\n",
+		"styles/Vocab/ProductA/accept.txt": "Zorblax\n",
+		"styles/Vocab/ProductB/reject.txt": "Zorblax\n",
+		"root.md":                          text,
+		"docs/a/a.md":                      text,
+		"docs/b/b.md":                      text,
+		"docs/c/c.md":                      text,
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	stdout, stderr := runVale(t, dir, "--output=JSON", ".")
+
+	results := map[string][]struct{ Check string }{}
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatalf("%s (%q)", err, stderr)
+	}
+
+	expected := map[string]string{
+		"root.md":     "Vale.Spelling,Vale.Terms",
+		"docs/a/a.md": "",
+		"docs/b/b.md": "Vale.Avoid,Vale.Spelling,Vale.Terms",
+		"docs/c/c.md": "Vale.Avoid,Vale.Spelling,Vale.Terms",
+	}
+	for name, want := range expected {
+		checks := []string{}
+		for _, a := range results[filepath.FromSlash(name)] {
+			checks = append(checks, a.Check)
+		}
+		sort.Strings(checks)
+
+		if got := strings.Join(checks, ","); got != want {
+			t.Errorf("%s: expected '%s', got '%s'", name, want, got)
+		}
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		".vale.ini":         "StylesPath = styles\n[*]\nBasedOnStyles = Test\n",
+		"styles/Test/A.yml": "extends: existence\nmessage: '%s'\ntokens:\n  - foo\n",
+		"test.md":           "foo bar\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	// By default, the output is unchanged ...
+	stdout, _ := runVale(t, dir, "--output=JSON", "test.md")
+
+	var alerts map[string][]core.Alert
+	if err := json.Unmarshal([]byte(stdout), &alerts); err != nil {
+		t.Fatal(err)
+	} else if len(alerts["test.md"]) != 1 {
+		t.Errorf("expected 1 alert, got %v", alerts)
+	}
+
+	// ... but, with `--json-schema=2`, it has a `Meta` header.
+	stdout, _ = runVale(t, dir, "--output=JSON", "--json-schema=2", "test.md")
+
+	var output struct {
+		Meta   cli.RunMeta
+		Alerts map[string][]core.Alert
+	}
+	if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+		t.Fatal(err)
+	} else if len(output.Alerts["test.md"]) != 1 {
+		t.Errorf("expected 1 alert, got %v", output.Alerts)
+	} else if output.Meta.Schema != cli.JSONSchema || output.Meta.Version == "" || output.Meta.Time == "" {
+		t.Errorf("expected a complete Meta header, got %+v", output.Meta)
+	} else if filepath.Base(output.Meta.Config) != ".vale.ini" {
+		t.Errorf("expected Config = .vale.ini, got '%s'", output.Meta.Config)
+	}
+
+	// `--version` reports the same metadata.
+	stdout, _ = runVale(t, dir, "--output=JSON", "--version")
+
+	var meta cli.RunMeta
+	if err := json.Unmarshal([]byte(stdout), &meta); err != nil {
+		t.Fatal(err)
+	} else if meta.Version != output.Meta.Version || meta.Config != output.Meta.Config {
+		t.Errorf("expected %+v, got %+v", output.Meta, meta)
+	}
+}
+
+func TestSample(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		".vale.ini":         "StylesPath = styles\n[*]\nBasedOnStyles = Test\n",
+		"styles/Test/A.yml": "extends: existence\nmessage: '%s'\nlevel: error\ntokens:\n  - foo\n",
+		"test.md":           strings.Repeat("foo bar\n\n", 20),
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	var outputs []string
+	for i := 0; i < 2; i++ {
+		stdout, _ := runVale(t, dir, "--output=JSON", "--sample=3", "--sample-seed=7", "test.md")
+
+		var output struct {
+			Sample cli.SampleMeta
+			Alerts map[string][]core.Alert
+		}
+		if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+			t.Fatal(err)
+		} else if len(output.Alerts["test.md"]) != 3 {
+			t.Errorf("expected 3 alerts, got %v", output.Alerts)
+		}
+
+		s := output.Sample.Rules["Test.A"]
+		if output.Sample.Size != 3 || output.Sample.Seed != 7 || s.Shown != 3 || s.Total != 20 {
+			t.Errorf("expected 3 of 20 alerts shown, got %+v", output.Sample)
+		}
+		outputs = append(outputs, stdout)
+	}
+
+	if outputs[0] != outputs[1] {
+		t.Errorf("expected the same sample from the same seed:\n%s\n%s", outputs[0], outputs[1])
+	}
+
+	// The exit code still reflects every alert.
+	if code := valeExitCode(t, dir, "--sample=1", "test.md"); code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+}
+
+func TestMaxProblems(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		".vale.ini":         "StylesPath = styles\n[*]\nBasedOnStyles = Test\n",
+		"styles/Test/A.yml": "extends: existence\nmessage: '%s'\nlevel: error\ntokens:\n  - foo\n",
+		"a.md":              strings.Repeat("foo bar\n\n", 3),
+		"b.md":              strings.Repeat("foo bar\n\n", 3),
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	// The cap applies across files.
+	stdout, stderr := runVale(t, dir, "--output=line", "--max-problems=4", "a.md", "b.md")
+	if lines := strings.Split(strings.TrimSpace(stdout), "\n"); len(lines) != 4 {
+		t.Errorf("expected 4 alerts, got %q", stdout)
+	} else if !strings.Contains(stderr, "... and 2 more alerts") {
+		t.Errorf("expected a truncation notice, got %q", stderr)
+	}
+
+	stdout, _ = runVale(t, dir, "--output=JSON", "--max-problems=2", "a.md", "b.md")
+
+	var output map[string][]core.Alert
+	if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+		t.Fatal(err)
+	} else if len(output) != 1 || len(output["a.md"]) != 2 {
+		t.Errorf("expected 2 alerts in a.md, got %v", output)
+	}
+
+	stdout, _ = runVale(t, dir, "--max-problems=1", "a.md", "b.md")
+	if !strings.Contains(stdout, "... and 5 more alerts") || !strings.Contains(stdout, "6 errors") {
+		t.Errorf("expected a truncation notice and every error counted, got %q", stdout)
+	}
+
+	// The exit code still reflects every alert.
+	if code := valeExitCode(t, dir, "--max-problems=1", "a.md"); code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+	if code := valeExitCode(t, dir, "--max-problems=-1", "a.md"); code != 2 {
+		t.Errorf("expected exit code 2 for a negative cap, got %d", code)
+	}
+}
+
+// valeExitCode runs `vale` with the given arguments, returning its exit
+// code.
+func valeExitCode(t *testing.T, dir string, args ...string) int {
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "VALE_TEST_MAIN=1")
+
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			t.Fatal(err)
+		}
+	}
+	return cmd.ProcessState.ExitCode()
+}
+
+func TestSeverityExitCode(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		".vale.ini":         "StylesPath = styles\n[*]\nBasedOnStyles = Test\n",
+		"codes.ini":         "StylesPath = styles\nSeverityExitCode = warning=3\n[*]\nBasedOnStyles = Test\n",
+		"styles/Test/E.yml": "extends: existence\nmessage: '%s'\nlevel: error\ntokens:\n  - foo\n",
+		"styles/Test/W.yml": "extends: existence\nmessage: '%s'\nlevel: warning\ntokens:\n  - bar\n",
+		"error.md":          "foo bar\n",
+		"warning.md":        "bar\n",
+		"clean.md":          "baz\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	cases := []struct {
+		args []string
+		code int
+	}{
+		{[]string{"error.md"}, 1},
+		{[]string{"warning.md"}, 0},
+		{[]string{"clean.md"}, 0},
+		{[]string{"--severity-exit-code=warning=2", "warning.md"}, 2},
+		{[]string{"--severity-exit-code=warning=2", "error.md"}, 2},
+		{[]string{"--severity-exit-code=error=4, warning=2", "error.md"}, 4},
+		{[]string{"--severity-exit-code=warning=2", "--no-exit", "error.md"}, 0},
+		{[]string{"--config=codes.ini", "warning.md"}, 3},
+		{[]string{"--config=codes.ini", "--severity-exit-code=error=1", "warning.md"}, 0},
+		{[]string{"--severity-exit-code=fatal=1", "error.md"}, 2},
+	}
+
+	for _, c := range cases {
+		if code := valeExitCode(t, dir, c.args...); code != c.code {
+			t.Errorf("%v: expected exit code %d, got %d", c.args, c.code, code)
+		}
+	}
+}
+
+func TestConfigNearFile(t *testing.T) {
+	dir := t.TempDir()
+
+	// We also look for a configuration file in the user's home directory.
+	home := os.Getenv("HOME")
+	defer os.Setenv("HOME", home)
+	os.Setenv("HOME", dir)
+
+	rule := "extends: existence\nmessage: '%s'\nlevel: error\ntokens:\n  - foo\n"
+	files := map[string]string{
+		"project/.vale.ini":            "StylesPath = styles\n[*]\nBasedOnStyles = Project\n",
+		"project/styles/Project/A.yml": rule,
+		"project/docs/test.md":         "foo bar\n",
+		"other/.vale.ini":              "StylesPath = styles\n[*]\nBasedOnStyles = Other\n",
+		"other/styles/Other/A.yml":     rule,
+		"empty/README.md":              "",
+	}
+	testutil.WriteFiles(t, dir, files)
+	target := filepath.Join(dir, "project", "docs", "test.md")
+
+	// Whether or not the current directory has a configuration file, the
+	// one nearest to the file is used.
+	for _, cwd := range []string{"empty", "other"} {
+		stdout, _ := runVale(t, filepath.Join(dir, cwd), "--output=JSON", target)
+
+		var alerts map[string][]core.Alert
+		if err := json.Unmarshal([]byte(stdout), &alerts); err != nil {
+			t.Fatalf("%s: %s (%q)", cwd, err, stdout)
+		} else if len(alerts[target]) != 1 || alerts[target][0].Check != "Project.A" {
+			t.Errorf("%s: expected 1 alert from 'Project.A', got %v", cwd, alerts)
+		}
+	}
+
+	// Text, on the other hand, needs the current directory's.
+	if code := valeExitCode(t, filepath.Join(dir, "empty"), "foo bar"); code != 2 {
+		t.Errorf("expected exit code 2 without a configuration file, got %d", code)
+	}
+}
+
+func TestRSTFixtures(t *testing.T) {
+	// Without `UseDocutils`, reStructuredText is converted by our own lexer:
+	// its alerts must be those that rst2html gave us.
+	cases := map[string][]string{
+		"formats": {
+			"test.rst:4:34:vale.Annotations:'XXX' left in text",
+			"test.rst:37:45:vale.Annotations:'TODO' left in text",
+			"test.rst:58:1:vale.Annotations:'NOTE' left in text",
+			"test.rst:60:40:vale.Annotations:'TODO' left in text",
+			"test.rst:63:3:vale.Annotations:'TODO' left in text",
+			"test.rst:63:29:vale.Annotations:'XXX' left in text",
+			"test.rst:69:3:vale.Annotations:'FIXME' left in text",
+			"test.rst:75:3:vale.Annotations:'TODO' left in text",
+			"test.rst:75:38:vale.Annotations:'XXX' left in text",
+			"test.rst:81:10:vale.Annotations:'TODO' left in text",
+		},
+		"scopes/attr": {
+			"test.rst:4:10:rules.Alt:alt text should be less than 125 characters.",
+		},
+		"scopes/list": {
+			"test.rst:9:3:rules.List:'TODO' left in text",
+			"test.rst:10:3:rules.List:'TODO' left in text",
+			"test.rst:14:4:rules.List:'XXX' left in text",
+		},
+		"scopes/table": {
+			"test.rst:15:16:rules.Table:'TODO' left in text",
+			"test.rst:17:3:rules.Table:'XXX' left in text",
+		},
+		"comments": {
+			"test.rst:16:19:vale.Redundancy:'ACT test' is redundant",
+			"test.rst:20:19:vale.Redundancy:'ACT test' is redundant",
+			"test.rst:26:20:demo.Ending-Preposition:Don't end a sentence with 'of.'",
+		},
+	}
+
+	for dir, expected := range cases {
+		stdout, stderr := runVale(t, filepath.Join("../../fixtures", dir),
+			"--output=line", "--sort", "--normalize", "--relative", "test.rst")
+
+		got := strings.Split(strings.TrimSpace(stdout), "\n")
+		if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("%s: expected\n%s\ngot\n%s\n%s", dir,
+				strings.Join(expected, "\n"), stdout, stderr)
+		}
+	}
+}
+
+func TestBuiltins(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		".vale.ini": "StylesPath = styles\n[*]\nBasedOnStyles = Vale\n",
+		"test.md":   "This is is a tset.\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+	if err := os.Mkdir(filepath.Join(dir, "styles"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr := runVale(t, dir, "--output=JSON", "ls-builtin")
+
+	var assets []struct {
+		Name string
+		Kind string
+		Size int
+	}
+	if err := json.Unmarshal([]byte(stdout), &assets); err != nil {
+		t.Fatalf("%s (%q, %q)", err, stdout, stderr)
+	}
+	listed := []string{}
+	for _, a := range assets {
+		if a.Size <= 0 {
+			t.Errorf("%s: expected a size, got %d", a.Name, a.Size)
+		}
+		listed = append(listed, a.Kind+":"+a.Name)
+	}
+	expected := []string{
+		"rule:Vale.Repetition", "rule:Vale.Spelling",
+		"rule:api.Descriptions", "rule:api.ParameterStart",
+		"rule:api.SummaryCase", "rule:api.SummaryPunctuation",
+		"dictionary:en_US-web.aff", "dictionary:en_US-web.dic"}
+	if fmt.Sprint(listed) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, listed)
+	}
+
+	// An extracted rule replaces the built-in one, and only that one.
+	runVale(t, dir, "ls-builtin", "--extract", "Vale.Repetition")
+
+	extracted := filepath.Join(dir, "styles", "Vale", "Repetition.yml")
+	b, err := ioutil.ReadFile(extracted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b = bytes.Replace(b, []byte("is repeated!"), []byte("appears twice."), 1)
+	if err = ioutil.WriteFile(extracted, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _ = runVale(t, dir, "--output=line", "test.md")
+	for _, line := range []string{
+		"test.md:1:6:Vale.Repetition:'is' appears twice.",
+		"test.md:1:14:Vale.Spelling:Did you really mean 'tset'?",
+	} {
+		if !strings.Contains(stdout, line) {
+			t.Errorf("expected '%s', got %q", line, stdout)
+		}
+	}
+
+	// We don't overwrite an earlier extraction.
+	if _, stderr = runVale(t, dir, "ls-builtin", "--extract", "Vale.Repetition"); !strings.Contains(stderr, "already exists") {
+		t.Errorf("expected an error, got %q", stderr)
+	}
+
+	runVale(t, dir, "ls-builtin", "--extract", "en_US-web", "--to", "dicts")
+	for _, name := range []string{"en_US-web.dic", "en_US-web.aff"} {
+		if !core.FileExists(filepath.Join(dir, "dicts", name)) {
+			t.Errorf("expected '%s' to be extracted", name)
+		}
+	}
+}
+
+func TestStdinArgument(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		".vale.ini":         "StylesPath = styles\n[*]\nBasedOnStyles = Test\n",
+		"styles/Test/A.yml": "extends: existence\nmessage: '%s'\nscope: heading\ntokens:\n  - foo\n",
+		// A file that has the same name as our input.
+		"# foo": "",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	run := func(args ...string) (string, string) {
+		var stdout, stderr bytes.Buffer
+
+		cmd := exec.Command(os.Args[0], args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "VALE_TEST_MAIN=1")
+		cmd.Stdin = strings.NewReader("# foo\n")
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		if err := cmd.Run(); err != nil {
+			if _, ok := err.(*exec.ExitError); !ok {
+				t.Fatal(err)
+			}
+		}
+		return stdout.String(), stderr.String()
+	}
+
+	// `-` reads stdin, in the format given by `--ext`.
+	stdout, stderr := run("--output=line", "--ext=.md", "-")
+	if strings.TrimSpace(stdout) != "stdin.md:1:3:Test.A:foo" {
+		t.Errorf("expected an alert in stdin.md, got %q (%q)", stdout, stderr)
+	}
+
+	// ... but only on its own.
+	if _, stderr = run("--ext=.md", "-", "# foo"); !strings.Contains(stderr, "can't be combined") {
+		t.Errorf("expected an error, got %q", stderr)
+	}
+}
+
+func TestFailIfEmpty(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		".vale.ini":  "[*.md]\nBasedOnStyles = Vale\n",
+		"docs/a.txt": "foo\n",
+		"docs/b.rst": "foo\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	// Linting nothing is only reported when it's asked for ...
+	if stdout, stderr := runVale(t, dir, "--output=line", "."); stdout != "" || stderr != "" {
+		t.Errorf("expected no output, got %q (%q)", stdout, stderr)
+	}
+
+	// ... and neither our configuration file nor the files excluded by
+	// `--glob` count as skipped.
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--fail-if-empty", "."}, "0 files linted (2 skipped: unknown format)"},
+		{[]string{"--debug", "."}, "0 files linted (2 skipped: unknown format)"},
+		{[]string{"--fail-if-empty", "--glob=*.txt", "."}, "0 files linted (1 skipped: unknown format)"},
+	} {
+		if _, stderr := runVale(t, dir, tc.args...); !strings.Contains(stderr, tc.expected) {
+			t.Errorf("%v: expected %q, got %q", tc.args, tc.expected, stderr)
+		}
+	}
+}
+
+func TestMissingCaptureGroup(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		".vale.ini":         "StylesPath = styles\n[*]\nBasedOnStyles = Test\n",
+		"styles/Test/A.yml": "extends: existence\nmessage: \"'%s' (%{missing})\"\nraw:\n  - '(?P<word>foo)'\n",
+		"test.md":           "foo bar\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	// The rule is still loaded, with the group rendered as an empty string.
+	stdout, stderr := runVale(t, dir, "--output=line", "test.md")
+	if strings.TrimSpace(stdout) != "test.md:1:1:Test.A:'foo' ()" {
+		t.Errorf("expected an alert, got %q", stdout)
+	}
+	if !strings.Contains(stderr, "refers to '%{missing}'") {
+		t.Errorf("expected a warning about '%%{missing}', got %q", stderr)
+	}
+}
@@ -134,3 +134,36 @@ Feature: CLI
       stdin.py:37:5:vale.Annotations:'TODO' left in text
       """
     And the exit status should be 0
+
+  Scenario: Show the tokens of a file's scopes
+    When I run "nlp --scope=paragraph test.md" in "misc/nlp"
+    Then the output should contain:
+      """
+      paragraph.md (line 3)
+            0  PRP    We
+            3  RB     really
+           10  IN     like
+           15  NN     `****`
+           22  CC     and
+           26  NNS    links
+           31  .      .
+      """
+    And the output should not contain "text.heading.h1.md"
+    And the exit status should be 0
+
+  Scenario: Lint with HTML output
+    When I run "--output=HTML ." in "misc/html"
+    Then the output should contain:
+      """
+      <tr><td><a href="#a%26b.md">a&amp;b.md</a></td><td>1</td><td>0</td><td>0</td></tr>
+      """
+    And the output should contain:
+      """
+      <h2 id="a&amp;b.md">a&amp;b.md</h2>
+      """
+    And the output should contain:
+      """
+      <tr><td>3:9</td><td class="severity error">error</td><td>Use &lt;code&gt;foo&lt;/code&gt; &amp; friends sparingly.</td><td><a href="https://example.com/rules?a=1&amp;b=2"><code>Test.Foo</code></a></td></tr>
+      """
+    And the output should not contain "<code>foo</code>"
+    And the exit status should be 1
@@ -5,7 +5,7 @@ Feature: Checks
     Then the output should contain exactly:
       """
       test.md:5:3:demo.SentenceCase:'this isn't in sentence case' should be sentence-cased
-      test.md:11:3:demo.SentenceCase:'This Does Not Comply' should be sentence-cased
+      test.md:11:8:demo.SentenceCase:'This Does Not Comply' should be sentence-cased
       """
 
   Scenario: Repetition
@@ -105,8 +105,10 @@ Feature: Config
       """
     And the exit status should be 0
 
+  # Without `--single-config`, each file would use the repository's own
+  # `.vale.ini` (the nearest one in its parents).
   Scenario: Non-Existent Config
-    When I test "/misc/one/two/three/four"
+    When I run "--single-config ." in "/misc/one/two/three/four"
     Then the output should contain:
       """
       E100 [.vale.ini] Runtime error
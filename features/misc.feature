@@ -66,6 +66,22 @@ Feature: Misc
       test.md:3:96:Spelling.Ignore:'human-friendly' is a typo!
       """
 
+  Scenario: Missing dictionary
+    When I test "misc/dictionary"
+    Then the output should contain:
+      """
+      E201:open missing.dic: no such file or directory
+      """
+    And the exit status should be 2
+
+  Scenario: Missing dictionary (lint-config)
+    When I run "lint-config" in "misc/dictionary"
+    Then the output should contain:
+      """
+      E201:open missing.dic: no such file or directory
+      """
+    And the exit status should be 1
+
   Scenario: i18n
     When I test "i18n"
     Then the output should contain exactly:
@@ -74,6 +90,14 @@ Feature: Misc
       zh.md:7:43:ZH.Simple:Avoid using "根据"
       """
 
+  Scenario: Scope limits
+    When I test "misc/limits"
+    Then the output should contain exactly:
+      """
+      test.md:3:1:vale.Annotations:'TODO' left in text
+      test.md:9:1:vale.Annotations:'TODO' left in text
+      """
+
   Scenario: infostrings
     When I test "misc/infostring"
     Then the output should contain exactly:
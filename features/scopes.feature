@@ -31,6 +31,7 @@ Feature: Scopes
     Then the output should contain exactly:
       """
       test.adoc:3:16:rules.Alt:alt text should be less than 125 characters.
+      test.html:12:15:rules.Title:Titles should be less than 50 characters.
       test.md:3:3:rules.Alt:alt text should be less than 125 characters.
       test.rst:4:10:rules.Alt:alt text should be less than 125 characters.
       """
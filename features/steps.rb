@@ -65,6 +65,11 @@ When(/^I test "(.*)"$/) do |dir|
   step %(I run `#{cmd} .`)
 end
 
+When(/^I run "(.*)" in "(.*)"$/) do |args, dir|
+  step %(I cd to "../../fixtures/#{dir}")
+  step %(I run `#{cmd} #{args}`)
+end
+
 When(/^I inspect "(.*)"$/) do |dir|
   step %(I cd to "../../fixtures/#{dir}")
   step %(I run `#{exe} .`)
@@ -48,3 +48,16 @@ Feature: Styles
       test.rst:24:32:demo.Spelling:Inconsistent spelling of 'colour'
       test.rst:32:1:Limit.Rule:Don't use 'hey'.
       """
+
+  Scenario: Lint against api
+    When I apply style "api"
+    Then the output should contain exactly:
+      """
+      openapi.yml:4:16:api.Descriptions:Write descriptions as complete sentences.
+      openapi.yml:4:56:api.Descriptions:Write descriptions as complete sentences.
+      openapi.yml:9:21:api.SummaryCase:'List All Pets.' should be in sentence case.
+      openapi.yml:9:29:api.SummaryPunctuation:Don't end a summary with a period.
+      openapi.yml:17:24:api.ParameterStart:Don't start a parameter's description with 'The'.
+      openapi.yml:33:20:api.ParameterStart:Don't start a parameter's description with 'The'.
+      """
+    And the exit status should be 0
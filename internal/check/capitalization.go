@@ -3,6 +3,7 @@ package check
 import (
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/errata-ai/vale/v2/internal/core"
 	"github.com/jdkato/prose/transform"
@@ -15,7 +16,7 @@ type Capitalization struct {
 	Definition `mapstructure:",squash"`
 	// `match` (`string`): $title, $sentence, $lower, $upper, or a pattern.
 	Match string
-	Check func(s string, ignore []string, re *regexp.Regexp) bool
+	Check caseCheck
 	// `style` (`string`): AP or Chicago; only applies when match is set to
 	// $title.
 	Style string
@@ -26,6 +27,7 @@ type Capitalization struct {
 	Indicators []string
 
 	exceptRe *regexp.Regexp
+	fix      func(s string) string
 }
 
 // NewCapitalization creates a new `capitalization`-based rule.
@@ -59,22 +61,33 @@ func NewCapitalization(cfg *core.Config, generic baseCheck) (Capitalization, err
 		} else {
 			tc = transform.NewTitleConverter(transform.APStyle)
 		}
-		rule.Check = func(s string, ignore []string, re *regexp.Regexp) bool {
+		rule.Check = func(s string, ignore []string, re *regexp.Regexp) (bool, [][]int) {
 			return title(s, ignore, re, tc)
 		}
+		rule.fix = func(s string) string {
+			return toTitle(s, rule.Exceptions, tc)
+		}
 	} else if rule.Match == "$sentence" {
-		rule.Check = func(s string, ignore []string, re *regexp.Regexp) bool {
+		rule.Check = func(s string, ignore []string, re *regexp.Regexp) (bool, [][]int) {
 			return sentence(s, ignore, rule.Indicators, re)
 		}
+		rule.fix = func(s string) string {
+			return toSentence(s, rule.Exceptions, rule.Indicators)
+		}
 	} else if f, ok := varToFunc[rule.Match]; ok {
 		rule.Check = f
+		rule.fix = func(s string) string {
+			return toCase(s, rule.Exceptions, false, func(i int, word, prev string) string {
+				return varToFix[rule.Match](word)
+			})
+		}
 	} else {
 		re, err := regexp.Compile(rule.Match)
 		if err != nil {
 			return rule, core.NewE201FromPosition(err.Error(), path, 1)
 		}
-		rule.Check = func(s string, ignore []string, r *regexp.Regexp) bool {
-			return re.MatchString(s) || core.StringInSlice(s, ignore)
+		rule.Check = func(s string, ignore []string, r *regexp.Regexp) (bool, [][]int) {
+			return re.MatchString(s) || core.StringInSlice(s, ignore), nil
 		}
 	}
 
@@ -82,10 +95,42 @@ func NewCapitalization(cfg *core.Config, generic baseCheck) (Capitalization, err
 }
 
 // Run checks the capitalization style of the provided text.
+//
+// The alert points at the first offending word (or the whole text, if we
+// can't identify one), but its message still refers to the whole text.
+//
+// If the rule has a `replace` action without any parameters, we compute the
+// corrected text and use it as the action's parameter.
 func (o Capitalization) Run(txt string, f *core.File) []core.Alert {
 	alerts := []core.Alert{}
-	if !o.Check(txt, o.Exceptions, o.exceptRe) {
-		alerts = append(alerts, makeAlert(o.Definition, []int{0, len(txt)}, txt))
+	if ok, violations := o.Check(txt, o.Exceptions, o.exceptRe); !ok {
+		loc := []int{0, len(txt)}
+		if len(violations) > 0 {
+			loc = violations[0]
+		}
+
+		fixed := ""
+		if o.Action.Name == "replace" && len(o.Action.Params) == 0 && o.fix != nil {
+			fixed = o.fix(txt)
+		}
+
+		a := makeAlert(o.Definition, loc, txt)
+		a.Message, a.Description = formatMessages(o.Message, o.Description, txt)
+		if fixed != "" && fixed != txt {
+			// The fix replaces the whole text, while the alert only covers
+			// the first word that doesn't match.
+			a.Action = core.Action{Name: "replace", Params: []string{fixed}}
+			if loc[0] != 0 || loc[1] != len(txt) {
+				a.Action.Target = txt
+				a.Action.Offset = utf8.RuneCountInString(txt[:loc[0]])
+			}
+		}
+		if n := len(violations); n > 1 {
+			a.Description = strings.TrimSpace(fmt.Sprintf(
+				"%s (%d words don't match.)", a.Description, n))
+		}
+
+		alerts = append(alerts, a)
 	}
 	return alerts
 }
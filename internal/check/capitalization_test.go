@@ -0,0 +1,46 @@
+package check
+
+import (
+	"testing"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+func TestCapitalizationFix(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def := baseCheck{
+		"path": "", "message": "'%s' should be in title case", "match": "$title",
+		"exceptions": []string{"API"}}
+
+	rule, err := NewCapitalization(cfg, def)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule.Action = core.Action{Name: "replace"}
+
+	heading := "Introduction to the api"
+	alerts := rule.Run(heading, &core.File{})
+	if len(alerts) != 1 {
+		t.Fatalf("expected 1 alert, got %v", alerts)
+	}
+
+	// The alert only covers the offending word, while its fix replaces the
+	// whole heading.
+	a := alerts[0]
+	if a.Match != "api" {
+		t.Errorf("expected the alert to cover 'api', got '%s'", a.Match)
+	}
+
+	expected := core.Action{
+		Name: "replace", Params: []string{"Introduction to the API"},
+		Target: heading, Offset: 20}
+	if a.Action.Name != expected.Name || len(a.Action.Params) != 1 ||
+		a.Action.Params[0] != expected.Params[0] || a.Action.Target != expected.Target ||
+		a.Action.Offset != expected.Offset {
+		t.Errorf("expected = %+v, got = %+v", expected, a.Action)
+	}
+}
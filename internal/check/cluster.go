@@ -0,0 +1,82 @@
+package check
+
+import (
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+// cluster restricts a rule to the matches that occur close together: at
+// least `count` of them within `chars` characters.
+type cluster struct {
+	count int
+	chars int
+}
+
+// makeCluster reads the `window` key of a rule definition, which is a map of
+// the form `{count: N, chars: W}` -- e.g., `{count: 2, chars: 80}` reports
+// filler words only if there are at least two of them within 80 characters.
+func makeCluster(generic baseCheck, path string) (*cluster, error) {
+	val, ok := generic["window"]
+	if !ok || val == nil {
+		return nil, nil
+	}
+	delete(generic, "window")
+
+	var count, chars interface{}
+	switch v := val.(type) {
+	case map[interface{}]interface{}:
+		count, chars = v["count"], v["chars"]
+	case map[string]interface{}:
+		count, chars = v["count"], v["chars"]
+	}
+
+	n, okN := count.(int)
+	w, okW := chars.(int)
+	if !okN || !okW || n < 2 || w < 1 {
+		return nil, core.NewE201FromTarget(
+			"'window' must be a map of the form {count: N, chars: W}, where N > 1 and W > 0.",
+			"window",
+			path)
+	}
+
+	return &cluster{count: n, chars: w}, nil
+}
+
+// filter returns the alerts, sorted by position, that are part of a cluster:
+// a run of at least `count` of them between the start of the first and the
+// end of the last of which there are no more than `chars` characters.
+//
+// Hidden alerts (see `core.Alert.Hide`) are never part of one.
+func (c *cluster) filter(alerts []core.Alert) []core.Alert {
+	if c == nil {
+		return alerts
+	}
+
+	visible := []core.Alert{}
+	for _, a := range alerts {
+		if !a.Hide {
+			visible = append(visible, a)
+		}
+	}
+
+	keep := make([]bool, len(visible))
+	for i := range visible {
+		j := i
+		for j+1 < len(visible) && visible[j+1].Span[1]-visible[i].Span[0] <= c.chars {
+			j++
+		}
+		if j-i+1 >= c.count {
+			for k := i; k <= j; k++ {
+				keep[k] = true
+			}
+		}
+	}
+
+	clustered := []core.Alert{}
+	for i, a := range visible {
+		if keep[i] {
+			clustered = append(clustered, a)
+		}
+	}
+
+	return clustered
+}
@@ -0,0 +1,68 @@
+package check
+
+import (
+	"testing"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+var clusterTests = []struct {
+	text    string
+	matches []string
+}{
+	{"It is very, very good.", []string{"very", "very"}},
+	{"It is very good. It is really good.", []string{}},
+	{"It is very good, really.", []string{"very", "really"}},
+	// Only those that are part of a cluster are reported.
+	{"Really. " + "It is good. It is good. It is good. It is very, very good.", []string{"very", "very"}},
+}
+
+func TestExistenceWindow(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := core.NewFile("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def := baseCheck{
+		"tokens":     []string{"very", "really"},
+		"ignorecase": true,
+		"window":     map[interface{}]interface{}{"count": 2, "chars": 20},
+	}
+
+	rule, err := NewExistence(cfg, def)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range clusterTests {
+		matches := []string{}
+		for _, a := range rule.Run(tt.text, file) {
+			matches = append(matches, a.Match)
+		}
+		if len(matches) != len(tt.matches) {
+			t.Errorf("'%s': expected %v, got %v", tt.text, tt.matches, matches)
+			continue
+		}
+		for i := range matches {
+			if matches[i] != tt.matches[i] {
+				t.Errorf("'%s': expected %v, got %v", tt.text, tt.matches, matches)
+			}
+		}
+	}
+
+	for _, bad := range []interface{}{
+		"sentence",
+		map[string]interface{}{"count": 1, "chars": 20},
+		map[string]interface{}{"count": 2},
+	} {
+		def := baseCheck{"tokens": []string{"very"}, "window": bad}
+		if _, err = NewExistence(cfg, def); err == nil {
+			t.Errorf("%v: expected an error", bad)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package check
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/errata-ai/vale/v2/internal/core"
 	"github.com/jdkato/regexp"
@@ -47,15 +49,31 @@ func NewConsistency(cfg *core.Config, generic baseCheck) (Consistency, error) {
 		func() bool { return !rule.Nonword },
 		func() string { return "" }, true)
 
+	options := []string{}
+	for v1 := range rule.Either {
+		options = append(options, v1)
+	}
+	sort.Strings(options)
+
 	chkKey := strings.Split(name, ".")[1]
 	count := 0
-	for v1, v2 := range rule.Either {
+	for _, v1 := range options {
+		v2 := rule.Either[v1]
+
 		count += 2
 		subs := []string{
 			fmt.Sprintf("%s%d", chkKey, count), fmt.Sprintf("%s%d", chkKey, count+1)}
 
-		chkRE = fmt.Sprintf("(?P<%s>%s)|(?P<%s>%s)", subs[0], v1, subs[1], v2)
-		chkRE = fmt.Sprintf(regex, chkRE)
+		// The first matching alternative wins, so we try the longer option
+		// first -- otherwise, a pair like `anti: anti-pattern` would never
+		// match its second option.
+		first := fmt.Sprintf("(?P<%s>%s)", subs[0], v1)
+		second := fmt.Sprintf("(?P<%s>%s)", subs[1], v2)
+		if len(v2) > len(v1) {
+			first, second = second, first
+		}
+
+		chkRE = fmt.Sprintf(regex, first+"|"+second)
 
 		re, err := regexp.Compile(chkRE)
 		if err != nil {
@@ -71,30 +89,73 @@ func NewConsistency(cfg *core.Config, generic baseCheck) (Consistency, error) {
 }
 
 // Run looks for inconsistent use of a user-defined regex.
+//
+// The alert is reported on the last use in `txt`, while its description
+// gives the location of the other variant's first use in the file.
 func (o Consistency) Run(txt string, f *core.File) []core.Alert {
 	alerts := []core.Alert{}
 	loc := []int{}
 
 	for _, s := range o.steps {
+		var used string
+
 		matches := s.pattern.FindAllStringSubmatchIndex(txt, -1)
 		for _, submat := range matches {
 			for idx, mat := range submat {
 				if mat != -1 && idx > 0 && idx%2 == 0 {
 					loc = []int{mat, submat[idx+1]}
-					f.Sequences = append(f.Sequences, s.pattern.SubexpNames()[idx/2])
+					used = s.pattern.SubexpNames()[idx/2]
+					f.Sequences = append(f.Sequences, used)
 				}
 			}
 		}
 
 		if matches != nil && core.AllStringsInSlice(s.subs, f.Sequences) {
 			o.Name = o.Extends
-			alerts = append(alerts, makeAlert(o.Definition, loc, txt))
+			a := makeAlert(o.Definition, loc, txt)
+
+			other := s.subs[0]
+			if used == other {
+				other = s.subs[1]
+			}
+			if match, line, col := firstUse(s, other, f); line > 0 {
+				a.Description = strings.TrimSpace(fmt.Sprintf(
+					"%s '%s' was used on line %d, column %d.", a.Description, match, line, col))
+			}
+
+			alerts = append(alerts, a)
 		}
 	}
 
 	return alerts
 }
 
+// firstUse finds the first use, in `f`, of the variant captured by the group
+// `sub` of `s`, returning its text and (1-based) line and column -- or a line
+// of 0 if it can't be found.
+func firstUse(s step, sub string, f *core.File) (string, int, int) {
+	group := -1
+	for i, name := range s.pattern.SubexpNames() {
+		if name == sub {
+			group = i
+		}
+	}
+	if group < 0 {
+		return "", 0, 0
+	}
+
+	for i, line := range f.Lines {
+		for _, submat := range s.pattern.FindAllStringSubmatchIndex(line, -1) {
+			if start := submat[2*group]; start != -1 {
+				col := utf8.RuneCountInString(line[:start]) + 1
+				return line[start:submat[2*group+1]], i + 1, col
+			}
+		}
+	}
+
+	return "", 0, 0
+}
+
 // Fields provides access to the internal rule definition.
 func (o Consistency) Fields() Definition {
 	return o.Definition
@@ -0,0 +1,95 @@
+package check
+
+import (
+	"testing"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+var consistencyTests = []struct {
+	def    baseCheck
+	text   string
+	alerts int
+}{
+	// Punctuation pairs
+	{baseCheck{"nonword": true, "either": map[string]string{`e\.g\.`: `eg\.`}}, "Use e.g. or eg. here.", 1},
+	{baseCheck{"nonword": true, "either": map[string]string{`e\.g\.`: `eg\.`}}, "Use e.g. here, e.g. there.", 0},
+	{baseCheck{"nonword": true, "either": map[string]string{`e\.g\.`: `eg\.`}}, "Use eg. here, eg. there.", 0},
+	{baseCheck{"nonword": true, "either": map[string]string{`e\.g`: `e\.g\.,`}}, "Use e.g., or e.g here.", 1},
+	// Hyphenation pairs
+	{baseCheck{"either": map[string]string{"anti-pattern": "antipattern"}}, "An anti-pattern is an antipattern.", 1},
+	{baseCheck{"either": map[string]string{"anti-pattern": "antipattern"}}, "An anti-pattern is an anti-pattern.", 0},
+	{baseCheck{"either": map[string]string{"anti-pattern": "antipattern"}}, "An antipattern is an antipattern.", 0},
+	{baseCheck{"either": map[string]string{"anti": "anti-pattern"}}, "An anti-pattern isn't anti.", 1},
+	{baseCheck{"either": map[string]string{"anti": "anti-pattern"}}, "An anti-pattern is an anti-pattern.", 0},
+	// Case-insensitive pairs
+	{baseCheck{"ignorecase": true, "either": map[string]string{"advisor": "adviser"}}, "Advisor or ADVISER?", 1},
+	{baseCheck{"ignorecase": true, "either": map[string]string{"advisor": "adviser"}}, "Advisor or advisor?", 0},
+	{baseCheck{"either": map[string]string{"advisor": "adviser"}}, "Advisor or Adviser?", 0},
+}
+
+func TestConsistency(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range consistencyTests {
+		file, err := core.NewFile("", cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tt.def["path"] = ""
+		tt.def["name"] = "Test.Consistency"
+
+		rule, err := NewConsistency(cfg, tt.def)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		alerts := rule.Run(tt.text, file)
+		if len(alerts) != tt.alerts {
+			t.Errorf("%v (%s): expected %d alerts, got %v", tt.def, tt.text, tt.alerts, alerts)
+		}
+	}
+}
+
+func TestConsistencyFirstUse(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		text  string
+		match string
+		desc  string
+	}{
+		{"The colour is red.\nIts color is blue.", "color", "'colour' was used on line 1, column 5."},
+		{"A color.\n\nIts colour is blue, its colour is red.", "colour", "'color' was used on line 1, column 3."},
+	}
+
+	for _, tt := range tests {
+		file, err := core.NewFile(tt.text, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rule, err := NewConsistency(cfg, baseCheck{
+			"path":   "",
+			"name":   "Test.Consistency",
+			"either": map[string]string{"color": "colour"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		alerts := rule.Run(tt.text, file)
+		if len(alerts) != 1 {
+			t.Fatalf("%s: expected 1 alert, got %v", tt.text, alerts)
+		} else if alerts[0].Match != tt.match || alerts[0].Description != tt.desc {
+			t.Errorf("%s: expected '%s' (%s), got '%s' (%s)",
+				tt.text, tt.match, tt.desc, alerts[0].Match, alerts[0].Description)
+		}
+	}
+}
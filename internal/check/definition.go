@@ -22,7 +22,9 @@ type Rule interface {
 type Definition struct {
 	Action      core.Action
 	Description string
+	Escalate    map[int]string // the level of a rule's alerts once it has at least so many in a file
 	Extends     string
+	Instance    string // one of several rules generated from a single definition
 	Level       string
 	Limit       int
 	Link        string
@@ -30,9 +32,36 @@ type Definition struct {
 	Name        string
 	Scope       string
 	Selector    core.Selector
+	Vocab       string // the vocabulary a rule was built for (see `core.Config.SVocabs`)
 }
 
-var defaultStyles = []string{"Vale"}
+var defaultStyles = []string{"Vale", "api"}
+
+// optionalStyles are built-in styles that are only loaded if they're used
+// (e.g., `BasedOnStyles = api`), like a style on `StylesPath`.
+var optionalStyles = []string{"api"}
+
+// IsBuiltin determines if the rule `name` (e.g., `Vale.Spelling`) belongs to
+// one of our built-in styles, including `LanguageTool`.
+func IsBuiltin(name string) bool {
+	style := strings.Split(name, ".")[0]
+	return style == "LanguageTool" || core.StringInSlice(style, defaultStyles)
+}
+
+// optionalRules are built-in rules that are only loaded if they're explicitly
+// enabled (e.g., `Vale.LineLength = YES`).
+var optionalRules = []string{"LineLength"}
+
+// vocabPoints are the extension points whose rules depend on the vocabulary
+// (e.g., by treating accepted terms as exceptions), so each section's
+// vocabulary has its own copy of them (see `Manager.loadSectionVocabs`).
+var vocabPoints = []string{"capitalization", "conditional", "spelling"}
+
+// fileLevel are the extension points that compare matches across their
+// entire scope, so `scope: raw` means the whole file (`raw.file`) rather than
+// each line.
+var fileLevel = []string{"conditional", "consistency", "occurrence"}
+
 var extensionPoints = []string{
 	"capitalization",
 	"conditional",
@@ -44,6 +73,7 @@ var extensionPoints = []string{
 	"readability",
 	"spelling",
 	"sequence",
+	"entity",
 }
 var defaultRules = map[string]map[string]interface{}{
 	"Avoid": {
@@ -66,6 +96,16 @@ var defaultRules = map[string]map[string]interface{}{
 		"swap":       map[string]string{},
 		"path":       "",
 	},
+	"LineLength": {
+		"extends": "existence",
+		"name":    "Vale.LineLength",
+		"level":   "suggestion",
+		"message": "Try to keep lines under 120 characters.",
+		"scope":   "raw",
+		"nonword": true,
+		"tokens":  []string{`^.{121,}`},
+		"path":    "",
+	},
 	"Grammar": {
 		"extends": "lt",
 		"name":    "LanguageTool.Grammar",
@@ -77,6 +117,7 @@ var defaultRules = map[string]map[string]interface{}{
 
 const (
 	ignoreCase      = `(?i)`
+	anyCase         = `(?i:`
 	wordTemplate    = `(?m)\b(?:%s)\b`
 	nonwordTemplate = `(?m)(?:%s)`
 )
@@ -107,8 +148,12 @@ func buildRule(cfg *core.Config, generic baseCheck) (Rule, error) {
 		return NewConsistency(cfg, generic)
 	case "sequence":
 		return NewSequence(cfg, generic)
+	case "entity":
+		return NewEntity(cfg, generic)
 	case "lt":
 		return NewLanguageTool(cfg, generic)
+	case "plugin":
+		return NewPlugin(cfg, generic)
 	default:
 		path := generic["path"].(string)
 		return Existence{}, core.NewE201FromTarget(
@@ -122,6 +167,45 @@ func formatMessages(msg string, desc string, subs ...string) (string, string) {
 	return core.FormatMessage(msg, subs...), core.FormatMessage(desc, subs...)
 }
 
+// reCapture matches a reference to a named group -- e.g., `%{project}` -- in
+// a rule's message or description.
+var reCapture = regexp.MustCompile(`%\{(\w+)\}`)
+
+// checkCaptures determines if the message or description of `chk` refers to
+// any of the named groups in `re`, warning about references to groups that
+// don't exist (which are rendered as an empty string; see `interpolate`).
+func checkCaptures(cfg *core.Config, chk Definition, re *regexp.Regexp, path string) bool {
+	refs := reCapture.FindAllStringSubmatch(chk.Message+chk.Description, -1)
+	for _, ref := range refs {
+		if !core.StringInSlice(ref[1], re.SubexpNames()) {
+			cfg.Warnf("'%s' refers to '%s', but the pattern has no such group.",
+				path, ref[0])
+		}
+	}
+	return len(refs) > 0
+}
+
+// interpolate replaces each `%{name}` in `msg` with the text captured by the
+// named group `name` of `re`, where `submatch` is an element of
+// `re.FindAllStringSubmatchIndex(txt, -1)`.
+//
+// Unknown groups, and groups that didn't participate in the match, are
+// replaced with an empty string.
+func interpolate(msg string, re *regexp.Regexp, txt string, submatch []int) string {
+	names := re.SubexpNames()
+	return reCapture.ReplaceAllStringFunc(msg, func(ref string) string {
+		name := ref[2 : len(ref)-1]
+		for i, n := range names {
+			if n != name || 2*i+1 >= len(submatch) || submatch[2*i] < 0 {
+				continue
+			}
+			// NOTE: The result is later used as a format string.
+			return strings.Replace(txt[submatch[2*i]:submatch[2*i+1]], "%", "%%", -1)
+		}
+		return ""
+	})
+}
+
 func makeAlert(chk Definition, loc []int, txt string) core.Alert {
 	match := txt[loc[0]:loc[1]]
 	a := core.Alert{
@@ -181,6 +265,21 @@ func validateDefinition(generic map[string]interface{}, path string) error {
 		}
 	}
 
+	if limit, ok := generic["limit"]; ok {
+		if n, isInt := limit.(int); !isInt || n < 0 {
+			return core.NewE201FromTarget(
+				"'limit' must be a non-negative integer.",
+				"limit",
+				path)
+		}
+	}
+
+	if escalate, ok := generic["escalate"]; ok {
+		if err := validateEscalate(escalate, path); err != nil {
+			return err
+		}
+	}
+
 	if generic["code"] != nil && generic["code"].(bool) {
 		return core.NewE201FromTarget(
 			"`code` is deprecated; please use `scope: raw` instead.",
@@ -191,6 +290,34 @@ func validateDefinition(generic map[string]interface{}, path string) error {
 	return nil
 }
 
+// validateEscalate checks that `escalate` maps positive numbers of matches
+// to levels -- e.g., `{5: warning, 15: error}`.
+func validateEscalate(escalate interface{}, path string) error {
+	levels, ok := escalate.(map[interface{}]interface{})
+	if !ok {
+		return core.NewE201FromTarget(
+			"'escalate' must map numbers of matches to levels (e.g., {5: warning, 15: error}).",
+			"escalate",
+			path)
+	}
+
+	for n, level := range levels {
+		if count, ok := n.(int); !ok || count < 1 {
+			return core.NewE201FromTarget(
+				fmt.Sprintf("'escalate': '%v' must be a positive number of matches.", n),
+				"escalate",
+				path)
+		} else if l, ok := level.(string); !ok || !core.StringInSlice(l, core.AlertLevels) {
+			return core.NewE201FromTarget(
+				fmt.Sprintf("'escalate': '%v' must be one of %v.", level, core.AlertLevels),
+				"escalate",
+				path)
+		}
+	}
+
+	return nil
+}
+
 func readStructureError(err error, path string) error {
 	r := regexp.MustCompile(`\* '(.+)' (.+)`)
 	if r.MatchString(err.Error()) {
@@ -235,6 +362,20 @@ func makeRegexp(
 	return regex
 }
 
+// vocabException returns the pattern used to except the accepted term `term`
+// (see `core.VocabPattern`) from a rule, which only matches it as written
+// unless it may be written in any case (see `core.VocabIgnoreCase`).
+func vocabException(term string) string {
+	term, nocase := core.VocabIgnoreCase(term)
+	if nocase {
+		pat, _ := core.VocabPattern(term)
+		return anyCase + pat + ")"
+	} else if pat, ok := core.VocabPattern(term); ok {
+		return "(?:" + pat + ")"
+	}
+	return term
+}
+
 func matchToken(expected, observed string, ignorecase bool) bool {
 	p := expected
 	if ignorecase {
@@ -250,7 +391,7 @@ func matchToken(expected, observed string, ignorecase bool) bool {
 
 func updateExceptions(previous []string, current map[string]struct{}) []string {
 	for term := range current {
-		previous = append(previous, term)
+		previous = append(previous, vocabException(term))
 	}
 
 	// NOTE: This is required to ensure that we have greedy alternation.
@@ -0,0 +1,205 @@
+package check
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/jdkato/prose/chunk"
+	"github.com/jdkato/prose/tag"
+	"github.com/jdkato/regexp"
+	"github.com/mitchellh/mapstructure"
+)
+
+// Entity looks for named entities -- runs of proper nouns, such as "Jane Doe"
+// or "Bank of England" -- that satisfy a set of user-defined constraints.
+type Entity struct {
+	Definition `mapstructure:",squash"`
+	// `after` (`array`): A list of tokens that must immediately follow the
+	// entity.
+	After []NLPToken
+	// `before` (`array`): A list of tokens that must immediately precede the
+	// entity.
+	Before []NLPToken
+	// `first` (`bool`): Only consider the first mention of each entity in a
+	// file.
+	First bool
+	// `ignorecase` (`bool`): Makes all matches case-insensitive.
+	Ignorecase bool
+	// `label` (`string`): A regular expression matching the entity's label.
+	// Entities aren't typed, so this is always `ENTITY`.
+	Label string
+	// `start` (`bool`): Only consider entities that begin a sentence.
+	Start bool
+	// `text` (`string`): A regular expression matching the entity's text.
+	Text string
+
+	label  *regexp.Regexp
+	text   *regexp.Regexp
+	tagger *tag.PerceptronTagger
+}
+
+// An entity is a run of proper nouns within a tokenized block of text.
+type entity struct {
+	label string
+	start int // the index of the entity's first token
+	end   int // the index one past the entity's last token
+	span  []int
+}
+
+// namedEntities matches the tags of a named entity (see `chunk.Locate`).
+var namedEntities = chunk.TreebankNamedEntities
+
+// NewEntity creates a new `Rule` that extends `Entity`.
+func NewEntity(cfg *core.Config, generic baseCheck) (Entity, error) {
+	rule := Entity{tagger: cfg.Tagger}
+	path := generic["path"].(string)
+
+	err := mapstructure.Decode(generic, &rule)
+	if err != nil {
+		return rule, readStructureError(err, path)
+	}
+
+	if rule.label, err = regexp.Compile(rule.Label); err != nil {
+		return rule, core.NewE201FromTarget(err.Error(), "label", path)
+	}
+
+	if rule.Text != "" {
+		regex := rule.Text
+		if rule.Ignorecase {
+			regex = ignoreCase + regex
+		}
+		if rule.text, err = regexp.Compile(regex); err != nil {
+			return rule, core.NewE201FromTarget(err.Error(), "text", path)
+		}
+	}
+
+	for _, tokens := range [][]NLPToken{rule.Before, rule.After} {
+		if err = compileTokens(cfg, tokens, rule.Ignorecase, path); err != nil {
+			return rule, err
+		}
+	}
+
+	rule.Definition.Scope = "summary"
+	return rule, nil
+}
+
+// Fields provides access to the rule definition.
+func (e Entity) Fields() Definition {
+	return e.Definition
+}
+
+// Pattern is the internal regex pattern used by this rule.
+func (e Entity) Pattern() string {
+	return ""
+}
+
+// Run looks for entities that satisfy the rule's constraints.
+func (e Entity) Run(txt string, f *core.File) []core.Alert {
+	alerts := []core.Alert{}
+
+	// Every entity contains at least one capital letter, so there's no need to
+	// tag text that doesn't.
+	if strings.IndexFunc(txt, unicode.IsUpper) < 0 {
+		return alerts
+	}
+
+	words := core.TextToTokens(txt, true, e.tagger)
+	for _, ent := range findEntities(words, txt) {
+		match := txt[ent.span[0]:ent.span[1]]
+		if !e.label.MatchString(ent.label) {
+			continue
+		} else if e.text != nil && !e.text.MatchString(match) {
+			continue
+		} else if e.Start && !startsSentence(words, ent.start) {
+			continue
+		} else if !e.constrained(words, ent) {
+			continue
+		}
+
+		if e.First {
+			seen := fmt.Sprintf("%s:%s", e.Name, match)
+			if core.StringInSlice(seen, f.Sequences) {
+				continue
+			}
+			f.Sequences = append(f.Sequences, seen)
+		}
+
+		alerts = append(alerts, makeAlert(e.Definition, ent.span, txt))
+	}
+
+	return alerts
+}
+
+// constrained determines if the tokens surrounding `ent` match the rule's
+// `before` and `after` tokens.
+func (e Entity) constrained(words []tag.Token, ent entity) bool {
+	for i, token := range e.Before {
+		idx := ent.start - len(e.Before) + i
+		if !tokenAt(token, words, idx) {
+			return false
+		}
+	}
+	for i, token := range e.After {
+		if !tokenAt(token, words, ent.end+i) {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenAt determines if `words[idx]` matches `token`.
+//
+// A missing token (i.e., one past either end of the text) only matches a
+// negated token.
+func tokenAt(token NLPToken, words []tag.Token, idx int) bool {
+	if idx < 0 || idx >= len(words) {
+		return token.Negate
+	}
+	return tokensMatch(token, words[idx])
+}
+
+// findEntities finds the named entities in `words` (see `chunk.Locate`).
+func findEntities(words []tag.Token, txt string) []entity {
+	entities := []entity{}
+
+	// NOTE: `chunk.Locate` modifies its pattern (see `regexp.Longest`), so
+	// each call needs its own copy.
+	offsets := tokenOffsets(words, txt)
+	for _, loc := range chunk.Locate(words, namedEntities.Copy()) {
+		i, j := loc[0], loc[1]
+		if offsets[i] < 0 || offsets[j-1] < 0 {
+			continue
+		}
+		entities = append(entities, entity{
+			label: "ENTITY",
+			start: i,
+			end:   j,
+			span:  []int{offsets[i], offsets[j-1] + len(words[j-1].Text)}})
+	}
+
+	return entities
+}
+
+// tokenOffsets finds the byte offset of each token in `txt`, or -1 if the
+// tokenizer altered the token (e.g., by converting quotes).
+func tokenOffsets(words []tag.Token, txt string) []int {
+	offsets := make([]int, len(words))
+
+	cursor := 0
+	for i, tok := range words {
+		offset := strings.Index(txt[cursor:], tok.Text)
+		if offset >= 0 {
+			offset += cursor
+			cursor = offset + len(tok.Text)
+		}
+		offsets[i] = offset
+	}
+
+	return offsets
+}
+
+func startsSentence(words []tag.Token, idx int) bool {
+	return idx == 0 || core.StringInSlice(words[idx-1].Text, []string{".", "!", "?"})
+}
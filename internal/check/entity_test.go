@@ -0,0 +1,99 @@
+package check
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+const entityText = "Acme Corp. makes things. We like Acme Corp. and Dr. Jane Doe."
+
+var entityTests = []struct {
+	def     baseCheck
+	matches []string
+}{
+	{baseCheck{"label": "ENTITY"}, []string{"Acme Corp.", "Acme Corp.", "Dr. Jane Doe"}},
+	// Entities aren't typed.
+	{baseCheck{"label": "PERSON"}, []string{}},
+	{baseCheck{"text": "Acme", "first": true}, []string{"Acme Corp."}},
+	{baseCheck{"text": "Acme", "start": true}, []string{"Acme Corp."}},
+	{
+		baseCheck{
+			"after": []interface{}{map[string]interface{}{"pattern": "makes"}},
+		},
+		[]string{"Acme Corp."},
+	},
+	{
+		baseCheck{
+			"before": []interface{}{map[string]interface{}{"tag": "IN"}},
+		},
+		[]string{"Acme Corp."},
+	},
+}
+
+func TestEntity(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range entityTests {
+		file, err := core.NewFile("", cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tt.def["path"] = ""
+		rule, err := NewEntity(cfg, tt.def)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		alerts := rule.Run(entityText, file)
+		if len(alerts) != len(tt.matches) {
+			t.Errorf("%v: expected %v, got %v", tt.def, tt.matches, alerts)
+			continue
+		}
+		for i, a := range alerts {
+			if a.Match != tt.matches[i] {
+				t.Errorf("%v: expected '%s', got '%s'", tt.def, tt.matches[i], a.Match)
+			}
+		}
+	}
+}
+
+func TestEntityNoCapitals(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := core.NewFile("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rule, err := NewEntity(cfg, baseCheck{"path": ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if alerts := rule.Run("there's nothing to see here.", file); len(alerts) != 0 {
+		t.Errorf("expected no alerts, got %v", alerts)
+	}
+}
+
+func TestFindEntities(t *testing.T) {
+	txt := "We met Jane Doe at the Bank of England in 2020."
+
+	found := []string{}
+	for _, ent := range findEntities(core.TextToTokens(txt, true, nil), txt) {
+		found = append(found, txt[ent.span[0]:ent.span[1]])
+	}
+
+	expected := []string{"Jane Doe", "Bank of England"}
+	if strings.Join(found, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %v, got %v", expected, found)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/jdkato/prose/tag"
 	"github.com/jdkato/regexp"
 	"github.com/mitchellh/mapstructure"
 )
@@ -15,29 +16,51 @@ type Existence struct {
 	// `append` (`bool`): Adds `raw` to the end of `tokens`, assuming both are
 	// defined.
 	Append bool
+	// `exceptions` (`array`): An array of patterns; a match that one of them
+	// matches in full (e.g., `API` for a rule that flags all-caps words) is
+	// ignored. They follow the rule's `ignorecase`.
+	Exceptions []string
 	// `ignorecase` (`bool`): Makes all matches case-insensitive.
 	IgnoreCase bool
 	// `nonword` (`bool`): Removes the default word boundaries (`\b`).
 	Nonword bool
+	// `pos` (`string`): A regular expression matching tokens to parts of
+	// speech.
+	POS string
 	// `raw` (`array`): A list of tokens to be concatenated into a pattern.
 	Raw []string
 	// `tokens` (`array`): A list of tokens to be transformed into a
 	// non-capturing group.
 	Tokens []string
 
-	pattern *regexp.Regexp
+	pattern  *regexp.Regexp
+	exceptRe *regexp.Regexp
+	within   *window
+	cluster  *cluster
+	captures bool
+	tagger   *tag.PerceptronTagger
 }
 
 // NewExistence creates a new `Rule` that extends `Existence`.
 func NewExistence(cfg *core.Config, generic baseCheck) (Existence, error) {
-	rule := Existence{}
+	rule := Existence{tagger: cfg.Tagger}
 
 	path := ""
-	if p, ok := generic["path"].(string); !ok {
+	if p, ok := generic["path"].(string); ok {
 		path = p
 	}
 
-	err := mapstructure.Decode(generic, &rule)
+	within, err := makeWindow(generic, path)
+	if err != nil {
+		return rule, err
+	}
+	rule.within = within
+
+	if rule.cluster, err = makeCluster(generic, path); err != nil {
+		return rule, err
+	}
+
+	err = mapstructure.Decode(generic, &rule)
 	if err != nil {
 		return rule, readStructureError(err, path)
 	}
@@ -55,6 +78,17 @@ func NewExistence(cfg *core.Config, generic baseCheck) (Existence, error) {
 		return rule, core.NewE201FromPosition(err.Error(), path, 1)
 	}
 	rule.pattern = re
+	rule.captures = checkCaptures(cfg, rule.Definition, re, path)
+
+	if len(rule.Exceptions) > 0 {
+		regex = `^(?:` + strings.Join(rule.Exceptions, "|") + `)$`
+		if rule.IgnoreCase {
+			regex = `(?i)` + regex
+		}
+		if rule.exceptRe, err = regexp.Compile(regex); err != nil {
+			return rule, core.NewE201FromPosition(err.Error(), path, 1)
+		}
+	}
 
 	return rule, nil
 }
@@ -67,12 +101,38 @@ func NewExistence(cfg *core.Config, generic baseCheck) (Existence, error) {
 func (e Existence) Run(text string, file *core.File) []core.Alert {
 	alerts := []core.Alert{}
 
-	locs := e.pattern.FindAllStringIndex(text, -1)
-	for _, loc := range locs {
-		alerts = append(alerts, makeAlert(e.Definition, loc, text))
+	extent := e.within.extent(text)
+	for _, loc := range e.matches(text[:extent]) {
+		if isMatch(e.exceptRe, text[loc[0]:loc[1]]) {
+			continue
+		}
+		a := makeAlert(e.Definition, loc[:2], text)
+		if e.captures {
+			a.Message, a.Description = formatMessages(
+				interpolate(e.Message, e.pattern, text, loc),
+				interpolate(e.Description, e.pattern, text, loc),
+				a.Match)
+		}
+		if e.POS != "" {
+			// If we're given a POS pattern, check that it matches.
+			//
+			// If it doesn't match, the alert doesn't get added to a File
+			// (i.e., `hide` == true).
+			a.Hide = core.CheckPOS(loc, e.POS, text, e.tagger)
+		}
+		alerts = append(alerts, a)
 	}
 
-	return alerts
+	return e.cluster.filter(alerts)
+}
+
+// matches finds all of the rule's matches in `text`, including the location
+// of any submatches if the rule's message needs them.
+func (e Existence) matches(text string) [][]int {
+	if e.captures {
+		return e.pattern.FindAllStringSubmatchIndex(text, -1)
+	}
+	return e.pattern.FindAllStringIndex(text, -1)
 }
 
 // Fields provides access to the internal rule definition.
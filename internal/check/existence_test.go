@@ -1,6 +1,7 @@
 package check
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/errata-ai/vale/v2/internal/core"
@@ -31,3 +32,242 @@ func TestExistence(t *testing.T) {
 	}
 
 }
+
+func TestExistencePOS(t *testing.T) {
+	def := baseCheck{"tokens": []string{"impact"}, "pos": "impact/VB"}
+
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rule, err := NewExistence(cfg, def)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := core.NewFile("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for text, hidden := range map[string]bool{
+		"This will impact the schedule.":   false,
+		"The impact was significant here.": true,
+	} {
+		alerts := rule.Run(text, file)
+		if len(alerts) != 1 {
+			t.Fatalf("expected one alert, not %v", alerts)
+		} else if alerts[0].Hide != hidden {
+			t.Errorf("%q: expected Hide = %v, got %v", text, hidden, alerts[0].Hide)
+		}
+	}
+}
+
+func TestExistenceExceptions(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := core.NewFile("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := "The API uses HTTPS, unlike the NASA and APIS ones."
+
+	rule, err := NewExistence(cfg, baseCheck{
+		"raw":        []string{`\b[A-Z]{2,}\b`},
+		"exceptions": []string{"API", "HTTPS?"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matches := []string{}
+	for _, a := range rule.Run(text, file) {
+		matches = append(matches, a.Match)
+	}
+	if strings.Join(matches, ",") != "NASA,APIS" {
+		t.Errorf("expected [NASA APIS], got %v", matches)
+	}
+
+	// The exceptions follow the rule's `ignorecase`.
+	rule, err = NewExistence(cfg, baseCheck{
+		"tokens":     []string{"api", "nasa"},
+		"exceptions": []string{"API"},
+		"ignorecase": true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if alerts := rule.Run("An api, an Api, and NASA.", file); len(alerts) != 1 || alerts[0].Match != "NASA" {
+		t.Errorf("expected only 'NASA', got %v", alerts)
+	}
+
+	// Without any exceptions, every match is reported.
+	rule, err = NewExistence(cfg, baseCheck{"raw": []string{`\b[A-Z]{2,}\b`}})
+	if err != nil {
+		t.Fatal(err)
+	} else if alerts := rule.Run(text, file); len(alerts) != 4 {
+		t.Errorf("expected 4 alerts, got %v", alerts)
+	}
+}
+
+func TestExistenceCaptures(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := core.NewFile("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def := baseCheck{
+		"path":    "",
+		"message": "Remove '%s' (%{project}, %{number}%{suffix})",
+		"raw":     []string{`(?P<project>[A-Z]{2,5})-(?P<number>\d+)(?P<suffix>[a-z])?`},
+	}
+
+	rule, err := NewExistence(cfg, def)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alerts := rule.Run("Fixed in DOCS-12 and API-7b.", file)
+	expected := []string{
+		"Remove 'DOCS-12' (DOCS, 12)",
+		"Remove 'API-7b' (API, 7b)",
+	}
+
+	if len(alerts) != len(expected) {
+		t.Fatalf("expected %d alerts, got %v", len(expected), alerts)
+	}
+	for i, a := range alerts {
+		if a.Message != expected[i] {
+			t.Errorf("expected = %q, got = %q", expected[i], a.Message)
+		}
+	}
+}
+
+func TestCapturesMissingGroup(t *testing.T) {
+	// The missing group is only a warning (see `TestMissingCaptureGroup`).
+	cfg, err := core.NewConfig(&core.CLIFlags{Quiet: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := core.NewFile("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def := baseCheck{
+		"path":    "",
+		"message": "Remove '%s' (%{project}, %{missing})",
+		"raw":     []string{`(?P<project>[A-Z]{2,5})-\d+`},
+	}
+
+	rule, err := NewExistence(cfg, def)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alerts := rule.Run("Fixed in DOCS-12.", file)
+	if len(alerts) != 1 || alerts[0].Message != "Remove 'DOCS-12' (DOCS, )" {
+		t.Errorf("expected the missing group to be empty, got %v", alerts)
+	}
+}
+
+func TestSubstitutionCaptures(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := core.NewFile("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def := baseCheck{
+		"path":    "",
+		"message": "Use '%s' instead of '%s' (%{ver}).",
+		"swap": map[string]string{
+			`Python (?P<ver>\d)`: "Python",
+			"utilize":            "use",
+		},
+	}
+
+	rule, err := NewSubstitution(cfg, def)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alerts := rule.Run("We utilize Python 3.", file)
+	expected := map[string]bool{
+		"Use 'use' instead of 'utilize' ().":      true,
+		"Use 'Python' instead of 'Python 3' (3).": true,
+	}
+
+	if len(alerts) != len(expected) {
+		t.Fatalf("expected %d alerts, got %v", len(expected), alerts)
+	}
+	for _, a := range alerts {
+		if !expected[a.Message] {
+			t.Errorf("unexpected message %q", a.Message)
+		}
+	}
+}
+
+func TestSubstitutionPOS(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := core.NewFile("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def := baseCheck{
+		"path":    "",
+		"message": "Use '%s' instead of '%s'.",
+		"swap": map[string]string{
+			"that":    "which",
+			"utilize": "use",
+		},
+		"swappos": map[string]string{
+			"that": "that/WDT",
+		},
+	}
+
+	rule, err := NewSubstitution(cfg, def)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for text, hidden := range map[string]map[string]bool{
+		"This is the car that won the race.": {"that": false},
+		"I think that we should utilize it.": {"that": true, "utilize": false},
+	} {
+		alerts := rule.Run(text, file)
+		if len(alerts) != len(hidden) {
+			t.Fatalf("%q: expected %d alerts, got %v", text, len(hidden), alerts)
+		}
+		for _, a := range alerts {
+			if a.Hide != hidden[a.Match] {
+				t.Errorf("%q: expected Hide = %v for '%s', got %v",
+					text, hidden[a.Match], a.Match, a.Hide)
+			}
+		}
+	}
+
+	def["swappos"] = map[string]string{"which": "which/WDT"}
+	if _, err = NewSubstitution(cfg, def); err == nil {
+		t.Error("expected an error for a 'swappos' entry not in 'swap'")
+	}
+}
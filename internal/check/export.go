@@ -0,0 +1,153 @@
+package check
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+	"gopkg.in/yaml.v2"
+)
+
+// frozenRules is a single document holding every rule (and the vocabulary)
+// that a Manager has loaded.
+//
+// See `Manager.Export` and `--rules`.
+type frozenRules struct {
+	Vocab struct {
+		Accept []string `yaml:"accept,omitempty"`
+		Reject []string `yaml:"reject,omitempty"`
+	} `yaml:"vocab"`
+	Rules map[string]map[string]interface{} `yaml:"rules"`
+}
+
+// Export creates a YAML document containing the effective definition of
+// every loaded rule -- including any level assigned by our configuration (see
+// `applyLevel`) -- with each rule preceded by a comment noting where it was
+// loaded from.
+//
+// The result can be passed to `--rules` to lint without access to the
+// original `StylesPath`.
+func (mgr *Manager) Export() ([]byte, error) {
+	var buf bytes.Buffer
+
+	frozen := frozenRules{}
+	frozen.Vocab.Accept = sortedKeys(mgr.Config.AcceptedTokens)
+	frozen.Vocab.Reject = sortedKeys(mgr.Config.RejectedTokens)
+
+	buf.WriteString(fmt.Sprintf(
+		"# Generated by `vale export-rules` on %s.\n",
+		time.Now().Format("2006-01-02")))
+
+	b, err := yaml.Marshal(map[string]interface{}{"vocab": frozen.Vocab})
+	if err != nil {
+		return nil, core.NewE100("Export", err)
+	}
+	buf.Write(b)
+	buf.WriteString("rules:\n")
+
+	names := []string{}
+	for name, def := range mgr.definitions {
+		if vocab, ok := def["vocab"].(string); ok && vocab != core.GlobalVocab {
+			// A frozen rule set only includes the global vocabulary (and
+			// the rules built for it).
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		def := map[string]interface{}{}
+		for k, v := range mgr.definitions[name] {
+			if k != "name" && k != "path" && k != "vocab" {
+				def[k] = v
+			}
+		}
+
+		b, err = yaml.Marshal(map[string]interface{}{name: def})
+		if err != nil {
+			return nil, core.NewE100("Export", err)
+		}
+
+		buf.WriteString(fmt.Sprintf(
+			"  # %s (source: %s)\n", name, mgr.provenance(name)))
+		for _, line := range strings.SplitAfter(string(b), "\n") {
+			if line != "" {
+				buf.WriteString("  " + line)
+			}
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// provenance describes where the rule stored as `key` was loaded from.
+func (mgr *Manager) provenance(key string) string {
+	def := mgr.definitions[key]
+	if path, ok := def["path"].(string); ok && path != "" {
+		return path
+	} else if name := def["name"]; name == "Vale.Terms" || name == "Vale.Avoid" {
+		return "vocabulary"
+	}
+	return "built-in"
+}
+
+// loadFrozenRules loads every rule (and the vocabulary) from a document
+// created by `Manager.Export`.
+func (mgr *Manager) loadFrozenRules(path string) error {
+	var frozen frozenRules
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return core.NewE100("--rules", err)
+	} else if err = yaml.Unmarshal(b, &frozen); err != nil {
+		return core.NewE201FromPosition(err.Error(), path, 1)
+	}
+
+	for _, term := range frozen.Vocab.Accept {
+		mgr.Config.AcceptedTokens[term] = struct{}{}
+	}
+	for _, term := range frozen.Vocab.Reject {
+		mgr.Config.RejectedTokens[term] = struct{}{}
+	}
+
+	for key, generic := range frozen.Rules {
+		if point, ok := generic["extends"].(string); !ok || point == "" {
+			return core.NewE201FromTarget(
+				"Missing the required 'extends' key.", key, path)
+		}
+
+		name := key
+		if instance, ok := generic["instance"].(string); ok && instance != "" {
+			name = strings.TrimSuffix(key, "-"+instance)
+		}
+
+		generic["name"] = name
+		generic["path"] = path
+		if scope, ok := generic["scope"].(string); ok {
+			mgr.scopes[strings.Split(scope, ".")[0]] = struct{}{}
+		}
+
+		rule, err := mgr.buildRule(generic)
+		if err != nil {
+			return err
+		} else if err = mgr.AddRule(key, rule); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,244 @@
+package check
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/gobwas/glob"
+)
+
+// filterFields are the rule properties that a `--filter` expression may
+// refer to (e.g., `.extends`).
+var filterFields = []string{"name", "style", "extends", "level", "scope"}
+
+// A ruleFilter determines if a rule, given by its `filterFields`, should
+// run.
+type ruleFilter func(fields map[string]string) bool
+
+// filterRules removes any rules that don't match the `--filter` expression
+// `expr`, such as `.extends == "spelling" or .name == "Vale.*"`.
+//
+// An expression compares a field against a value, which is a glob pattern
+// for `==` and `!=`; levels may also be compared using `<`, `<=`, `>`, and
+// `>=`. Comparisons may be combined using `and`, `or`, `not`, and
+// parentheses.
+func (mgr *Manager) filterRules(expr string) error {
+	filter, err := parseFilter(expr)
+	if err != nil {
+		return core.NewE100("--filter", err)
+	}
+
+	for key, rule := range mgr.rules {
+		name, info := key, rule.Fields()
+		if info.Instance != "" || info.Vocab != "" {
+			// This is one of several rules generated from a single
+			// definition (e.g., `Vale.Terms`), so we use the definition's
+			// name.
+			name = info.Name
+		}
+
+		fields := map[string]string{
+			"name":    name,
+			"style":   strings.Split(name, ".")[0],
+			"extends": info.Extends,
+			"level":   info.Level,
+			"scope":   info.Scope,
+		}
+		if !filter(fields) {
+			delete(mgr.rules, key)
+		}
+	}
+	mgr.updateScopes()
+
+	return nil
+}
+
+// parseFilter compiles the expression `expr` into a `ruleFilter`.
+func parseFilter(expr string) (ruleFilter, error) {
+	tokens, err := tokenizeFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := filterParser{tokens: tokens}
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	} else if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected '%s'", p.tokens[p.pos])
+	}
+
+	return filter, nil
+}
+
+// filterParser is a recursive-descent parser for `--filter` expressions.
+type filterParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *filterParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *filterParser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+func (p *filterParser) parseOr() (ruleFilter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == "or" || p.peek() == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(fields map[string]string) bool { return l(fields) || right(fields) }
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (ruleFilter, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == "and" || p.peek() == "&&" {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(fields map[string]string) bool { return l(fields) && right(fields) }
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseNot() (ruleFilter, error) {
+	switch p.peek() {
+	case "not", "!":
+		p.next()
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(fields map[string]string) bool { return !inner(fields) }, nil
+	case "(":
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		} else if tok := p.next(); tok != ")" {
+			return nil, fmt.Errorf("expected ')', found '%s'", tok)
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (ruleFilter, error) {
+	field := p.next()
+	if !strings.HasPrefix(field, ".") || !core.StringInSlice(field[1:], filterFields) {
+		return nil, fmt.Errorf("expected one of %v, found '%s'", filterFields, field)
+	}
+	field = field[1:]
+
+	op := p.next()
+	if !core.StringInSlice(op, []string{"==", "!=", "<", "<=", ">", ">="}) {
+		return nil, fmt.Errorf("expected a comparison after '.%s', found '%s'", field, op)
+	}
+
+	value := p.next()
+	if value == "" {
+		return nil, fmt.Errorf("expected a value after '.%s %s'", field, op)
+	}
+	value = strings.Trim(value, `"'`)
+
+	if op == "==" || op == "!=" {
+		g, err := glob.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' isn't a valid pattern (%s)", value, err)
+		}
+		return func(fields map[string]string) bool {
+			return g.Match(fields[field]) == (op == "==")
+		}, nil
+	}
+
+	want, ok := core.LevelToInt[value]
+	if field != "level" || !ok {
+		return nil, fmt.Errorf("'%s' can only compare a level, such as 'warning'", op)
+	}
+	return func(fields map[string]string) bool {
+		have := core.LevelToInt[fields[field]]
+		switch op {
+		case "<":
+			return have < want
+		case "<=":
+			return have <= want
+		case ">":
+			return have > want
+		}
+		return have >= want
+	}, nil
+}
+
+// tokenizeFilter splits `expr` into its tokens: parentheses, operators,
+// quoted strings, and words (e.g., `.name` or `and`).
+func tokenizeFilter(expr string) ([]string, error) {
+	var tokens []string
+
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, string(r))
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated string starting at '%s'", string(runes[i:]))
+			}
+			tokens = append(tokens, string(runes[i:end+1]))
+			i = end + 1
+		case strings.ContainsRune("=!<>&|", r):
+			end := i + 1
+			for end < len(runes) && strings.ContainsRune("=&|", runes[end]) {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) &&
+				!strings.ContainsRune(`()"'=!<>&|`, runes[end]) {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
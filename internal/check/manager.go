@@ -6,10 +6,14 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/errata-ai/vale/v2/internal/core"
 	"github.com/errata-ai/vale/v2/internal/rule"
+	"gopkg.in/yaml.v2"
 )
 
 // Manager controls the loading and validating of the check extension points.
@@ -19,6 +23,14 @@ type Manager struct {
 	scopes map[string]struct{}
 	rules  map[string]Rule
 	styles []string
+
+	// required holds the distinct scopes of our rules (see
+	// `RequiredScopes`).
+	required []string
+
+	// definitions holds the effective definition of each rule, as it was
+	// passed to `buildRule`.
+	definitions map[string]baseCheck
 }
 
 // NewManager creates a new Manager and loads the rule definitions (that is,
@@ -31,13 +43,32 @@ func NewManager(config *core.Config) (*Manager, error) {
 
 		rules:  make(map[string]Rule),
 		scopes: make(map[string]struct{}),
+
+		definitions: make(map[string]baseCheck),
+	}
+
+	if config.Flags.Rules != "" {
+		// We've been given a frozen rule set, so there's nothing else to
+		// load.
+		if err := mgr.loadFrozenRules(config.Flags.Rules); err != nil {
+			return &mgr, err
+		}
+		return &mgr, mgr.applyFilter()
 	}
 
-	err := mgr.loadDefaultRules()
+	err := mgr.loadDefaultRules(config.BuiltIn)
 	if err != nil {
 		return &mgr, err
 	}
 
+	if err = mgr.loadPlugins(); err != nil {
+		return &mgr, err
+	}
+
+	if err = mgr.loadInlineRules(); err != nil {
+		return &mgr, err
+	}
+
 	// Load our styles ...
 	err = mgr.loadStyles(mgr.Config.Styles)
 	if err != nil {
@@ -51,29 +82,197 @@ func NewManager(config *core.Config) (*Manager, error) {
 			continue
 		}
 		parts := strings.Split(chk, ".")
-		if !mgr.hasStyle(parts[0]) {
+		if _, found := mgr.rules[chk]; found {
+			// It's a plugin or an inline rule.
+			continue
+		} else if parts[1] == "*" {
+			// A wildcard (e.g., `Style.* = YES`) applies to the whole style.
+			if err = mgr.loadStyles([]string{parts[0]}); err != nil {
+				return &mgr, err
+			}
+			continue
+		} else if !mgr.hasStyle(parts[0]) {
 			// If this rule isn't part of an already-loaded style, we load it
 			// individually.
 			fName := parts[1] + ".yml"
 			path = filepath.Join(mgr.Config.StylesPath, parts[0], fName)
+			for _, p := range mgr.Config.Paths {
+				// A chain of configurations may have more than one
+				// `StylesPath` (see `core.LoadChain`).
+				if candidate := filepath.Join(p, parts[0], fName); core.FileExists(candidate) {
+					path = candidate
+					break
+				}
+			}
 			if err = mgr.addRuleFromSource(fName, path); err != nil {
 				return &mgr, err
 			}
 		}
 	}
 
-	return &mgr, err
+	if err = mgr.loadSectionVocabs(); err != nil {
+		return &mgr, err
+	}
+
+	// Now that we know every rule in each style, we can expand any
+	// wildcards.
+	mgr.expandWildcards(mgr.Config.GChecks)
+	for _, checks := range mgr.Config.SChecks {
+		mgr.expandWildcards(checks)
+	}
+
+	return &mgr, mgr.applyFilter()
+}
+
+// applyFilter limits our rules to those selected by `--filter`, if given.
+func (mgr *Manager) applyFilter() error {
+	if mgr.Config.Flags.Filter == "" {
+		return nil
+	}
+	return mgr.filterRules(mgr.Config.Flags.Filter)
+}
+
+// expandWildcards replaces each `Style.*` key in `checks` with a key for
+// every loaded rule in `Style` -- unless that rule has its own key, which
+// takes precedence.
+func (mgr *Manager) expandWildcards(checks map[string]bool) {
+	wildcards := []string{}
+	for key := range checks {
+		if strings.HasSuffix(key, ".*") {
+			wildcards = append(wildcards, key)
+		}
+	}
+
+	for _, key := range wildcards {
+		style := strings.TrimSuffix(key, ".*")
+		for name, rule := range mgr.rules {
+			if def := rule.Fields().Name; def != "" {
+				// e.g., `Vale.Terms` rather than `Vale.Terms-A`.
+				name = def
+			}
+			parts := strings.Split(name, ".")
+			if len(parts) < 2 || parts[0] != style {
+				continue
+			}
+
+			name = parts[0] + "." + parts[1]
+			if _, found := checks[name]; !found {
+				checks[name] = checks[key]
+			}
+		}
+		delete(checks, key)
+	}
+}
+
+// levelFor returns the level assigned to the rule `name`, either directly
+// (`Style.Rule = error`) or by a wildcard (`Style.* = error`).
+func (mgr *Manager) levelFor(name string) (string, bool) {
+	if level, ok := mgr.Config.RuleToLevel[name]; ok {
+		return level, true
+	}
+	style := strings.Split(name, ".")[0]
+	level, ok := mgr.Config.RuleToLevel[style+".*"]
+	return level, ok
+}
+
+// applyLevel assigns `def` the level that our configuration gives its rule,
+// if any (see `levelFor`).
+func (mgr *Manager) applyLevel(def baseCheck) {
+	if level, ok := mgr.levelFor(def["name"].(string)); ok {
+		def["level"] = level
+	}
+}
+
+// applyParams merges any parameters assigned to the rule `name` in our
+// configuration (`Style.Rule.param = value`) into its definition, returning
+// their names.
+func (mgr *Manager) applyParams(name string, generic baseCheck) []string {
+	names := []string{}
+	for param, value := range mgr.Config.RuleParams[name] {
+		generic[param] = value
+		names = append(names, param)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkParams reports (with `--debug`) any of `params` that `rule` doesn't
+// have a field for, since they'd otherwise be silently ignored.
+func (mgr *Manager) checkParams(name string, rule Rule, params []string) {
+	if !mgr.Config.Flags.Debug || len(params) == 0 {
+		return
+	}
+
+	known := map[string]bool{}
+	fieldNames(reflect.TypeOf(rule), known)
+	for _, param := range params {
+		if !known[strings.ToLower(param)] {
+			fmt.Fprintf(os.Stderr, "%s: ignored unknown parameter '%s'\n", name, param)
+		}
+	}
+}
+
+// fieldNames records the (lowercased) names of `t`'s fields, including those
+// of any embedded structs (e.g., `Definition`).
+func fieldNames(t reflect.Type, names map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fieldNames(field.Type, names)
+		} else if field.PkgPath == "" {
+			names[strings.ToLower(field.Name)] = true
+		}
+	}
 }
 
 // AddRule adds the given rule to the manager.
 func (mgr *Manager) AddRule(name string, rule Rule) error {
 	if _, found := mgr.rules[name]; !found {
 		mgr.rules[name] = rule
+		mgr.addScope(rule.Fields().Scope)
 		return nil
 	}
 	return fmt.Errorf("the rule '%s' has already been added", name)
 }
 
+// RequiredScopes returns the scopes (e.g., `heading` or `text`) targeted by
+// at least one of our rules, in sorted order.
+func (mgr *Manager) RequiredScopes() []string {
+	return mgr.required
+}
+
+// NeedsScope determines if any of our rules would run on a block with the
+// selector `sel` (e.g., `text.table.cell.md`) -- that is, if the block needs
+// to be extracted at all.
+func (mgr *Manager) NeedsScope(sel string) bool {
+	s := core.Selector{Value: sel}
+	for _, scope := range mgr.required {
+		if s.ContainsString(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// addScope adds `scope` to `RequiredScopes`, if it's not already there.
+func (mgr *Manager) addScope(scope string) {
+	i := sort.SearchStrings(mgr.required, scope)
+	if i < len(mgr.required) && mgr.required[i] == scope {
+		return
+	}
+	mgr.required = append(mgr.required, "")
+	copy(mgr.required[i+1:], mgr.required[i:])
+	mgr.required[i] = scope
+}
+
+// updateScopes recomputes `RequiredScopes` after rules have been removed.
+func (mgr *Manager) updateScopes() {
+	mgr.required = nil
+	for _, rule := range mgr.rules {
+		mgr.addScope(rule.Fields().Scope)
+	}
+}
+
 // AddRuleFromFile adds the given rule to the manager.
 func (mgr *Manager) AddRuleFromFile(name, path string) error {
 	content, err := ioutil.ReadFile(path)
@@ -94,6 +293,28 @@ func (mgr *Manager) HasScope(scope string) bool {
 	return found
 }
 
+// Preload forces any lazily-loaded rule resources (such as spelling
+// dictionaries) to be built now, returning the first error encountered.
+//
+// This is useful for commands that need to surface rule errors immediately
+// rather than on first use.
+func (mgr *Manager) Preload() error {
+	names := []string{}
+	for name := range mgr.rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if s, ok := mgr.rules[name].(Spelling); ok {
+			if _, err := s.Load(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (mgr *Manager) addStyle(path string) error {
 	return filepath.Walk(path,
 		func(fp string, fi os.FileInfo, err error) error {
@@ -106,7 +327,7 @@ func (mgr *Manager) addStyle(path string) error {
 
 func (mgr *Manager) addRuleFromSource(name, path string) error {
 	if strings.HasSuffix(name, ".yml") {
-		f, err := ioutil.ReadFile(path)
+		f, err := core.ReadFileRetry(path)
 		if err != nil {
 			return core.NewE201FromPosition(err.Error(), path, 1)
 		}
@@ -133,34 +354,105 @@ func (mgr *Manager) addCheck(file []byte, chkName, path string) error {
 	generic["name"] = chkName
 	generic["path"] = path
 
-	if level, ok := mgr.Config.RuleToLevel[chkName]; ok {
+	if level, ok := mgr.levelFor(chkName); ok {
 		generic["level"] = level
 	} else if _, ok := generic["level"]; !ok {
 		generic["level"] = "warning"
 	}
 	if scope, ok := generic["scope"]; scope == nil || !ok {
 		generic["scope"] = "text"
+		if within, ok := generic["within"]; ok && within != nil {
+			// A window is always measured in the raw file (see `makeWindow`).
+			generic["scope"] = "raw.file"
+		}
+	}
+
+	scope := generic["scope"].(string)
+	if extends, ok := generic["extends"].(string); ok && scope == "raw" {
+		if core.StringInSlice(extends, fileLevel) {
+			generic["scope"] = "raw.file"
+		}
 	}
 
-	rule, err := buildRule(mgr.Config, generic)
+	params := mgr.applyParams(chkName, generic)
+
+	rule, err := mgr.buildRule(generic)
 	if err != nil {
 		return err
 	}
+	mgr.checkParams(chkName, rule, params)
 
-	base := strings.Split(generic["scope"].(string), ".")[0]
+	base := strings.Split(scope, ".")[0]
 	mgr.scopes[base] = struct{}{}
 
 	return mgr.AddRule(chkName, rule)
 }
 
-func (mgr *Manager) loadDefaultRules() error {
+// buildRule creates a rule from `generic`, recording its definition.
+func (mgr *Manager) buildRule(generic baseCheck) (Rule, error) {
+	return mgr.buildRuleFor(mgr.Config, generic)
+}
+
+// buildRuleFor creates a rule from `generic` using the vocabulary of `cfg`
+// (see `core.Config.ForVocab`), recording its definition.
+//
+// If any section has its own vocabulary, a rule that depends on the global
+// one is marked as such, so that it only runs on the files it applies to.
+func (mgr *Manager) buildRuleFor(cfg *core.Config, generic baseCheck) (Rule, error) {
+	extends, _ := generic["extends"].(string)
+	if _, ok := generic["vocab"]; !ok && len(cfg.SVocabs) > 0 && core.StringInSlice(extends, vocabPoints) {
+		generic["vocab"] = core.GlobalVocab
+	}
+
+	// NOTE: Our constructors may modify their definitions, so we store a
+	// copy.
+	def := baseCheck{}
+	for k, v := range generic {
+		def[k] = v
+	}
+
+	rule, err := buildRule(cfg, generic)
+	if err == nil {
+		mgr.definitions[ruleKey(def)] = def
+	}
+
+	return rule, err
+}
+
+// ruleKey is the name under which the rule defined by `generic` is stored:
+// its own name or, if it's an instance of a larger rule, its name suffixed
+// by the instance -- e.g., `Vale.Terms-A`.
+//
+// A copy of a rule built for a section's vocabulary is further suffixed by
+// the vocabulary -- e.g., `Vale.Terms-A@ProductA`.
+func ruleKey(generic baseCheck) string {
+	name := generic["name"].(string)
+	if instance, ok := generic["instance"].(string); ok && instance != "" {
+		name += "-" + instance
+	}
+	if vocab, ok := generic["vocab"].(string); ok && vocab != "" && vocab != core.GlobalVocab {
+		name += "@" + vocab
+	}
+	return name
+}
+
+// loadDefaultRules loads our vocabulary-based rules and, if `builtin` is
+// true, our built-in styles (see `core.Config.BuiltIn`).
+//
+// NOTE: The built-in rules are still known to `core` (see `init`), so
+// configurations that mention them remain valid either way.
+func (mgr *Manager) loadDefaultRules(builtin bool) error {
 	for _, style := range defaultStyles {
-		if core.StringInSlice(style, mgr.styles) {
+		if !builtin {
+			break
+		} else if core.StringInSlice(style, mgr.styles) {
 			// The user has a style on their `StylesPath` with the same
 			// name as a built-in style.
 			//
 			// TODO: Should this be considered an error?
 			continue
+		} else if core.StringInSlice(style, optionalStyles) && !mgr.usesStyle(style) {
+			continue
 		}
 
 		rules, err := rule.AssetDir(filepath.Join("rule", style))
@@ -169,6 +461,15 @@ func (mgr *Manager) loadDefaultRules() error {
 		}
 
 		for _, name := range rules {
+			if shadow := mgr.shadowOf(style, name); shadow != "" {
+				// A rule on our `StylesPath` with the same name (e.g., one
+				// extracted by `vale ls-builtin`) takes the built-in's place.
+				if err = mgr.addRuleFromSource(name, shadow); err != nil {
+					return err
+				}
+				continue
+			}
+
 			b, err := rule.Asset(filepath.Join("rule", style, name))
 			if err != nil {
 				return err
@@ -184,8 +485,73 @@ func (mgr *Manager) loadDefaultRules() error {
 	}
 
 	// TODO: where should this go?
-	mgr.loadVocabRules()
+	return mgr.loadVocabRules()
+}
+
+// loadPlugins loads the external rules assigned in the `[plugins]` section
+// of our configuration.
+func (mgr *Manager) loadPlugins() error {
+	for name, exe := range mgr.Config.Plugins {
+		generic := baseCheck{
+			"extends": "plugin",
+			"name":    name,
+			"exe":     exe,
+			"level":   "warning",
+			"scope":   "text",
+			"path":    "",
+		}
+		mgr.applyLevel(generic)
+
+		rule, err := mgr.buildRule(generic)
+		if err != nil {
+			return err
+		}
 
+		mgr.scopes["text"] = struct{}{}
+		if err = mgr.AddRule(name, rule); err != nil {
+			return core.NewE100("loadPlugins", err)
+		}
+	}
+	return nil
+}
+
+// inlineLists are the fields of a definition that hold lists, which an inline
+// rule (see `loadInlineRules`) may give as comma-separated values.
+var inlineLists = []string{
+	"dictionaries", "except", "exceptions", "filters", "ignore", "indicators",
+	"metrics", "raw", "tokens"}
+
+// loadInlineRules loads the rules defined in our configuration file (see
+// `core.Config.InlineRules`) as the style `core.InlineStyle`.
+func (mgr *Manager) loadInlineRules() error {
+	for _, name := range mgr.Config.InlineRuleNames() {
+		short := strings.TrimPrefix(name, core.InlineStyle+".")
+
+		def := make(map[string]interface{})
+		for k, v := range mgr.Config.InlineRules[short] {
+			if s, ok := v.(string); ok && core.StringInSlice(k, inlineLists) {
+				items := []string{}
+				for _, item := range strings.Split(s, ",") {
+					if item = strings.TrimSpace(item); item != "" {
+						items = append(items, item)
+					}
+				}
+				v = items
+			}
+			def[k] = v
+		}
+
+		b, err := yaml.Marshal(def)
+		if err != nil {
+			return core.NewE100("loadInlineRules", err)
+		} else if err = mgr.addCheck(b, name, mgr.Config.InlineSource(short)); err != nil {
+			return err
+		}
+	}
+
+	if len(mgr.Config.InlineRules) > 0 {
+		mgr.styles = append(mgr.styles, core.InlineStyle)
+	}
 	return nil
 }
 
@@ -215,7 +581,7 @@ func (mgr *Manager) loadStyles(styles []string) error {
 	}
 
 	for _, s := range need {
-		if !core.StringInSlice(s, found) {
+		if !core.StringInSlice(s, found) && !mgr.Config.HasPlugin(s) {
 			return core.NewE100(
 				"loadStyles",
 				errors.New("style '"+s+"' does not exist on StylesPath"))
@@ -226,31 +592,301 @@ func (mgr *Manager) loadStyles(styles []string) error {
 	return nil
 }
 
-func (mgr *Manager) loadVocabRules() {
-	if len(mgr.Config.AcceptedTokens) > 0 {
-		vocab := defaultRules["Terms"]
-		for term := range mgr.Config.AcceptedTokens {
-			if core.IsPhrase(term) {
-				vocab["swap"].(map[string]string)[strings.ToLower(term)] = term
-			}
-		}
-		rule, _ := buildRule(mgr.Config, vocab)
-		mgr.rules["Vale.Terms"] = rule
+// maxTermsPerRule is the largest number of accepted terms we'll put into a
+// single `Vale.Terms` instance.
+const maxTermsPerRule = 250
+
+func (mgr *Manager) loadVocabRules() error {
+	vocab := ""
+	if len(mgr.Config.SVocabs) > 0 {
+		vocab = core.GlobalVocab
+	}
+	if err := mgr.addTermRules(mgr.Config, vocab); err != nil {
+		return err
 	}
 
-	if len(mgr.Config.RejectedTokens) > 0 {
-		avoid := defaultRules["Avoid"]
-		for term := range mgr.Config.RejectedTokens {
-			avoid["tokens"] = append(avoid["tokens"].([]string), term)
+	for _, name := range optionalRules {
+		def := copyRule(defaultRules[name])
+		if !core.StringInSlice(def["name"].(string), mgr.Config.Checks) {
+			continue
 		}
-		rule, _ := buildRule(mgr.Config, avoid)
-		mgr.rules["Vale.Avoid"] = rule
+		mgr.applyLevel(def)
+		params := mgr.applyParams(def["name"].(string), def)
+		rule, err := mgr.buildRule(def)
+		if err != nil {
+			mgr.Config.Warnf("%s", err)
+			continue
+		}
+		mgr.checkParams(def["name"].(string), rule, params)
+		mgr.rules[def["name"].(string)] = rule
 	}
 
 	if mgr.Config.LTPath != "" {
-		rule, _ := buildRule(mgr.Config, defaultRules["Grammar"])
+		def := copyRule(defaultRules["Grammar"])
+		mgr.applyLevel(def)
+
+		rule, err := mgr.buildRule(def)
+		if err != nil {
+			return err
+		}
 		mgr.rules["LanguageTool.Grammar"] = rule
 	}
+
+	return nil
+}
+
+// addTermRules adds the `Vale.Terms` and `Vale.Avoid` rules for the
+// vocabulary of `cfg`, marking them as belonging to `vocab` (if it isn't
+// empty).
+func (mgr *Manager) addTermRules(cfg *core.Config, vocab string) error {
+	add := func(def baseCheck) error {
+		if vocab != "" {
+			def["vocab"] = vocab
+		}
+		rule, err := mgr.buildRuleFor(cfg, def)
+		if err != nil {
+			return err
+		}
+		mgr.rules[ruleKey(def)] = rule
+		return nil
+	}
+
+	if len(cfg.AcceptedTokens) > 0 {
+		for instance, swap := range termBuckets(cfg.AcceptedTokens) {
+			terms := copyRule(defaultRules["Terms"])
+			terms["swap"] = swap
+			terms["instance"] = instance
+			mgr.applyLevel(terms)
+			if err := add(terms); err != nil {
+				return err
+			}
+		}
+
+		if swap := termPatterns(cfg.AcceptedTokens); len(swap) > 0 {
+			// A pattern is matched regardless of case, but only accepted
+			// as written.
+			terms := copyRule(defaultRules["Terms"])
+			terms["swap"] = swap
+			terms["instance"] = "Patterns"
+			terms["ignorecase"] = false
+			mgr.applyLevel(terms)
+			if err := add(terms); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(cfg.RejectedTokens) > 0 {
+		for i, group := range rejectGroups(cfg) {
+			avoid := copyRule(defaultRules["Avoid"])
+			avoid["tokens"] = group.tokens
+			// A level given in the vocabulary takes precedence.
+			mgr.applyLevel(avoid)
+			if group.Level != "" {
+				avoid["level"] = group.Level
+			}
+			if group.Message != "" {
+				avoid["message"] = group.Message
+			}
+			if group.VocabRejection != (core.VocabRejection{}) {
+				// Each distinct level and message is its own instance.
+				avoid["instance"] = strconv.Itoa(i)
+			}
+			if err := add(avoid); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// loadSectionVocabs loads, for each section's vocabulary (see
+// `core.Config.SVocabs`), its own `Vale.Terms` and `Vale.Avoid` rules and a
+// copy of each rule that depends on the vocabulary (see `vocabPoints`).
+//
+// These only run on the files whose section gives that vocabulary, while the
+// global ones only run on the rest (see `lint.Linter.shouldRun`).
+func (mgr *Manager) loadSectionVocabs() error {
+	shared := []string{}
+	for key, rule := range mgr.rules {
+		if rule.Fields().Vocab == core.GlobalVocab && mgr.definitions[key] != nil &&
+			core.StringInSlice(rule.Fields().Extends, vocabPoints) {
+			shared = append(shared, key)
+		}
+	}
+	sort.Strings(shared)
+
+	for vocab := range mgr.Config.SVocabs {
+		cfg := mgr.Config.ForVocab(vocab)
+		if err := mgr.addTermRules(cfg, vocab); err != nil {
+			return err
+		}
+
+		for _, key := range shared {
+			def := copyRule(mgr.definitions[key])
+			def["vocab"] = vocab
+
+			rule, err := mgr.buildRuleFor(cfg, def)
+			if err != nil {
+				return err
+			}
+			mgr.rules[ruleKey(def)] = rule
+		}
+	}
+
+	return nil
+}
+
+// termBuckets splits the phrases in `accepted` into `swap` maps, keyed by
+// instance: one per initial letter (or `_` for anything else), further split
+// into chunks of at most `maxTermsPerRule` -- e.g., `A`, `B`, `B2`, ....
+//
+// Terms are assigned to buckets in sorted order, so the result is the same
+// for the same vocabulary.
+func termBuckets(accepted map[string]struct{}) map[string]map[string]string {
+	terms := []string{}
+	for term := range accepted {
+		// A term that may be written in any case has nothing to enforce.
+		if _, nocase := core.VocabIgnoreCase(term); !nocase && core.IsPhrase(term) {
+			terms = append(terms, term)
+		}
+	}
+	sort.Strings(terms)
+
+	buckets := map[string]map[string]string{}
+	counts := map[string]int{}
+	for _, term := range terms {
+		key := strings.ToLower(term)
+
+		letter := "_"
+		if r := []rune(key)[0]; r >= 'a' && r <= 'z' {
+			letter = strings.ToUpper(string(r))
+		}
+
+		chunk := counts[letter] / maxTermsPerRule
+		counts[letter]++
+
+		instance := letter
+		if chunk > 0 {
+			instance += strconv.Itoa(chunk + 1)
+		}
+
+		if _, ok := buckets[instance]; !ok {
+			buckets[instance] = map[string]string{}
+		}
+		buckets[instance][key] = term
+	}
+
+	return buckets
+}
+
+// termPatterns returns the `swap` map of the `Vale.Terms` instance for the
+// accepted terms that are patterns (see `core.VocabPattern`): each is found
+// case-insensitively, and then expected to match as written.
+func termPatterns(accepted map[string]struct{}) map[string]string {
+	swap := map[string]string{}
+	for term := range accepted {
+		if _, nocase := core.VocabIgnoreCase(term); nocase {
+			continue
+		} else if pat, ok := core.VocabPattern(term); ok {
+			swap["(?i:"+pat+")"] = pat
+		}
+	}
+	return swap
+}
+
+// A rejectGroup is the tokens of the rejected terms that share a level and
+// message.
+type rejectGroup struct {
+	core.VocabRejection
+	tokens []string
+}
+
+// rejectGroups groups the rejected terms of `cfg` by their level and message
+// (see `core.VocabRejection`).
+//
+// The terms without either come first, as the plain `Vale.Avoid` rule; the
+// rest are sorted by level and then message, so the result is the same for
+// the same vocabulary.
+func rejectGroups(cfg *core.Config) []rejectGroup {
+	byDetails := map[core.VocabRejection][]string{}
+	for term := range cfg.RejectedTokens {
+		details := cfg.Rejections[term]
+
+		term, nocase := core.VocabIgnoreCase(term)
+		pat, _ := core.VocabPattern(term)
+		if nocase {
+			pat = anyCase + pat + ")"
+		}
+		byDetails[details] = append(byDetails[details], pat)
+	}
+
+	groups := []rejectGroup{}
+	for details, tokens := range byDetails {
+		sort.Strings(tokens)
+		groups = append(groups, rejectGroup{VocabRejection: details, tokens: tokens})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i].VocabRejection, groups[j].VocabRejection
+		if a.Level != b.Level {
+			return a.Level < b.Level
+		}
+		return a.Message < b.Message
+	})
+
+	return groups
+}
+
+// copyRule returns a copy of one of our `defaultRules`, so that we never
+// modify the originals.
+func copyRule(generic baseCheck) baseCheck {
+	rule := baseCheck{}
+	for k, v := range generic {
+		rule[k] = v
+	}
+	return rule
+}
+
+func init() {
+	// Our built-in rules don't exist on `StylesPath`, so `core` needs to know
+	// about them in order to validate configurations.
+	names := []string{}
+	for _, style := range defaultStyles {
+		rules, _ := rule.AssetDir(filepath.Join("rule", style))
+		for _, name := range rules {
+			names = append(names, style+"."+strings.Split(name, ".")[0])
+		}
+	}
+	for _, def := range defaultRules {
+		names = append(names, def["name"].(string))
+	}
+	core.RegisterBuiltinRules(names)
+}
+
+// shadowOf returns the path of the file on our `StylesPath` that replaces the
+// built-in rule `name` (e.g., `Repetition.yml`) of `style`, if there is one.
+func (mgr *Manager) shadowOf(style, name string) string {
+	for _, p := range mgr.Config.Paths {
+		if candidate := filepath.Join(p, style, name); core.FileExists(candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// usesStyle determines if our configuration refers to `style`, either in a
+// `BasedOnStyles` or through one of its rules (e.g., `api.SummaryCase = YES`).
+func (mgr *Manager) usesStyle(style string) bool {
+	if core.StringInSlice(style, mgr.Config.Styles) {
+		return true
+	}
+	for _, chk := range mgr.Config.Checks {
+		if strings.HasPrefix(chk, style+".") {
+			return true
+		}
+	}
+	return false
 }
 
 func (mgr *Manager) hasStyle(name string) bool {
@@ -1,7 +1,16 @@
 package check
 
 import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/errata-ai/vale/v2/internal/testutil"
+	"gopkg.in/yaml.v2"
 )
 
 var checktests = []struct {
@@ -54,3 +63,398 @@ func TestFormatMessage(t *testing.T) {
 		}
 	}
 }
+
+func TestTermBuckets(t *testing.T) {
+	accepted := map[string]struct{}{"Vale": {}, "vale lint": {}, "API": {}, "Éclair": {}}
+	for i := 0; i < maxTermsPerRule+1; i++ {
+		accepted[fmt.Sprintf("Buck%c%c", 'a'+i/26, 'a'+i%26)] = struct{}{}
+	}
+
+	buckets := termBuckets(accepted)
+	for _, instance := range []string{"A", "B", "B2", "V", "_"} {
+		if _, ok := buckets[instance]; !ok {
+			t.Errorf("expected an instance '%s', got %d instances", instance, len(buckets))
+		}
+	}
+
+	if len(buckets["B"]) != maxTermsPerRule || len(buckets["B2"]) != 1 {
+		t.Errorf("expected B = %d, B2 = 1; got B = %d, B2 = %d",
+			maxTermsPerRule, len(buckets["B"]), len(buckets["B2"]))
+	} else if _, ok := buckets["B2"]["buckjq"]; !ok {
+		t.Errorf("expected the last term to be in B2, got %v", buckets["B2"])
+	}
+}
+
+func TestTermsInstances(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.AcceptedTokens = map[string]struct{}{"Vale": {}, "GitHub": {}}
+
+	mgr, err := NewManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := mgr.Rules()["Vale.Terms"]; ok {
+		t.Fatal("expected 'Vale.Terms' to be split into instances")
+	}
+
+	for _, key := range []string{"Vale.Terms-G", "Vale.Terms-V"} {
+		rule, ok := mgr.Rules()[key]
+		if !ok {
+			t.Fatalf("expected a rule '%s'", key)
+		}
+
+		alerts := rule.Run("We use vale on github.", &core.File{})
+		if len(alerts) != 1 || alerts[0].Check != "Vale.Terms" {
+			t.Errorf("%s: expected one 'Vale.Terms' alert, got %v", key, alerts)
+		}
+	}
+}
+
+func TestTermsError(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.AcceptedTokens = map[string]struct{}{"Vale(": {}}
+
+	if _, err = NewManager(cfg); err == nil {
+		t.Fatal("expected an error for an invalid vocabulary term")
+	}
+}
+
+func TestTermPatterns(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.AcceptedTokens = map[string]struct{}{"Vale": {}, `/Config\w*/`: {}}
+
+	mgr, err := NewManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rule, ok := mgr.Rules()["Vale.Terms-Patterns"]
+	if !ok {
+		t.Fatal("expected a rule 'Vale.Terms-Patterns'")
+	} else if _, ok = mgr.Rules()["Vale.Terms-_"]; ok {
+		t.Error("expected patterns to be excluded from the other instances")
+	}
+
+	alerts := rule.Run("Both ConfigMap and configmap, but not Vale.", &core.File{})
+	if len(alerts) != 1 || alerts[0].Match != "configmap" {
+		t.Errorf("expected one alert for 'configmap', got %v", alerts)
+	}
+}
+
+func TestTermCase(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.AcceptedTokens = map[string]struct{}{"macOS": {}, "!Ansible": {}}
+
+	mgr, err := NewManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := mgr.Rules()["Vale.Terms-A"]; ok {
+		t.Error("expected 'Ansible' to be excluded from Vale.Terms")
+	}
+
+	rule, ok := mgr.Rules()["Vale.Terms-M"]
+	if !ok {
+		t.Fatal("expected a rule 'Vale.Terms-M'")
+	}
+	alerts := rule.Run("Both macOS and MacOS use ansible.", &core.File{})
+	if len(alerts) != 1 || alerts[0].Match != "MacOS" {
+		t.Errorf("expected one alert for 'MacOS', got %v", alerts)
+	}
+
+	exceptions := []string{vocabException("macOS"), vocabException("!Ansible")}
+	for s, expected := range map[string]string{
+		"MacOS and ANSIBLE": "macOS and ANSIBLE",
+		"Ansible on MACOS":  "Ansible on macOS",
+	} {
+		if got := toSentence(s, exceptions, nil); got != expected {
+			t.Errorf("toSentence(%q): expected %q, got %q", s, expected, got)
+		}
+	}
+}
+
+func TestAvoidInstances(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.RejectedTokens = map[string]struct{}{"foo": {}, "whitelist": {}, "utilize": {}}
+	cfg.Rejections = map[string]core.VocabRejection{
+		"whitelist": {Level: "suggestion", Message: "Use 'allowlist' instead of '%s'."},
+		"utilize":   {Level: "warning"},
+	}
+
+	mgr, err := NewManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]struct{ level, message string }{
+		"Vale.Avoid":   {"error", "Avoid using 'foo'."},
+		"Vale.Avoid-1": {"suggestion", "Use 'allowlist' instead of 'whitelist'."},
+		"Vale.Avoid-2": {"warning", "Avoid using 'utilize'."},
+	}
+	for key, e := range expected {
+		rule, ok := mgr.Rules()[key]
+		if !ok {
+			t.Fatalf("expected a rule '%s'", key)
+		}
+
+		alerts := rule.Run("We utilize foo as a whitelist.", &core.File{})
+		if len(alerts) != 1 {
+			t.Fatalf("%s: expected one alert, got %v", key, alerts)
+		}
+
+		a := alerts[0]
+		if a.Check != "Vale.Avoid" || a.Severity != e.level || a.Message != e.message {
+			t.Errorf("%s: expected %v, got %v", key, e, a)
+		}
+	}
+}
+
+func TestWildcardChecks(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Checks = []string{"Vale.*", "Vale.Spelling"}
+	cfg.GChecks = map[string]bool{"Vale.*": true, "Vale.Spelling": false}
+	cfg.SChecks = map[string]map[string]bool{"*.md": {"Vale.*": false}}
+	cfg.RuleToLevel = map[string]string{"Vale.*": "suggestion", "Vale.Spelling": "error"}
+
+	mgr, err := NewManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]bool{"Vale.Repetition": true, "Vale.Spelling": false}
+	for name, on := range expected {
+		if cfg.GChecks[name] != on {
+			t.Errorf("%s: expected global = %v, got %v", name, on, cfg.GChecks[name])
+		} else if on, found := cfg.SChecks["*.md"][name]; !found || on {
+			t.Errorf("%s: expected '*.md' = false, got %v", name, on)
+		}
+	}
+	if _, found := cfg.GChecks["Vale.*"]; found {
+		t.Error("expected 'Vale.*' to be expanded")
+	}
+
+	levels := map[string]string{"Vale.Repetition": "suggestion", "Vale.Spelling": "error"}
+	for name, level := range levels {
+		if got := mgr.Rules()[name].Fields().Level; got != level {
+			t.Errorf("%s: expected level '%s', got '%s'", name, level, got)
+		}
+	}
+}
+
+func TestRuleParams(t *testing.T) {
+	dir := t.TempDir()
+	testutil.WriteFiles(t, dir, map[string]string{
+		"styles/Test/Rule.yml": "extends: existence\nmessage: \"Avoid '%s'.\"\ntokens:\n  - foo\n",
+		".vale.ini": strings.Join([]string{
+			"StylesPath = styles",
+			"[*]",
+			"BasedOnStyles = Test",
+			"Test.Rule.tokens = [bar, baz]",
+			"Test.Rule.ignorecase = true",
+			"Test.Rule.level = error",
+		}, "\n"),
+	})
+
+	ini := filepath.Join(dir, ".vale.ini")
+
+	cfg, err := core.NewConfig(&core.CLIFlags{Path: ini, InExt: ".txt"})
+	if err != nil {
+		t.Fatal(err)
+	} else if err = core.From("ini", cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr, err := NewManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	existence, ok := mgr.Rules()["Test.Rule"].(Existence)
+	if !ok {
+		t.Fatal("expected 'Test.Rule' to be loaded")
+	} else if fmt.Sprint(existence.Tokens) != "[bar baz]" {
+		t.Errorf("expected tokens = [bar baz], got %v", existence.Tokens)
+	} else if !existence.IgnoreCase || existence.Level != "error" {
+		t.Errorf("expected ignorecase and 'error', got %v, %s", existence.IgnoreCase, existence.Level)
+	}
+
+	if _, found := cfg.GChecks["Test.Rule.tokens"]; found {
+		t.Error("expected parameters not to be treated as checks")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	dir := t.TempDir()
+	testutil.WriteFiles(t, dir, map[string]string{
+		"styles/Test/Avoid.yml":   "extends: existence\nmessage: '%s'\nlevel: error\ntokens:\n  - foo\n",
+		"styles/Test/Swap.yml":    "extends: substitution\nmessage: '%s'\nswap:\n  foo: bar\n",
+		"styles/Test/Heading.yml": "extends: capitalization\nmessage: '%s'\nlevel: suggestion\nscope: heading\nmatch: $title\n",
+	})
+
+	cases := []struct {
+		filter   string
+		expected []string
+	}{
+		{`.extends == "existence"`, []string{"Test.Avoid"}},
+		{`.name == 'Test.*' and .level >= warning`, []string{"Test.Avoid", "Test.Swap"}},
+		{`.style == Test && !(.extends == existence || .scope == heading)`, []string{"Test.Swap"}},
+		{`.name != "Vale.*" and .level < warning`, []string{"Test.Heading"}},
+	}
+
+	for _, c := range cases {
+		cfg, err := core.NewConfig(&core.CLIFlags{Filter: c.filter})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.StylesPath = filepath.Join(dir, "styles")
+		cfg.Paths = []string{cfg.StylesPath}
+		cfg.GBaseStyles = []string{"Test"}
+		cfg.Styles = cfg.GBaseStyles
+
+		mgr, err := NewManager(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		names := []string{}
+		for name := range mgr.Rules() {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		if fmt.Sprint(names) != fmt.Sprint(c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.filter, c.expected, names)
+		}
+	}
+
+	for _, bad := range []string{`.extends`, `.nope == x`, `.name > x`, `(.level == error`, `.name == "x`} {
+		if _, err := parseFilter(bad); err == nil {
+			t.Errorf("expected '%s' to be an error", bad)
+		}
+	}
+}
+
+func TestBuiltIn(t *testing.T) {
+	dir := t.TempDir()
+	testutil.WriteFiles(t, dir, map[string]string{"styles/Vocab/Test/accept.txt": "Vale\n"})
+
+	cases := []struct {
+		lines   []string
+		flag    bool
+		builtin bool
+	}{
+		{[]string{"StylesPath = styles", "Vocab = Test"}, false, true},
+		{[]string{"StylesPath = styles", "Vocab = Test", "BuiltIn = false"}, false, false},
+		{[]string{"StylesPath = styles", "Vocab = Test", "[*]", "Vale = NO"}, false, false},
+		{[]string{"StylesPath = styles", "Vocab = Test", "[*]", "Vale = YES"}, true, false},
+	}
+
+	for _, tc := range cases {
+		ini := filepath.Join(dir, ".vale.ini")
+		if err := ioutil.WriteFile(ini, []byte(strings.Join(tc.lines, "\n")), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := core.NewConfig(&core.CLIFlags{Path: ini, InExt: ".txt", NoGlobal: tc.flag})
+		if err != nil {
+			t.Fatal(err)
+		} else if err = core.From("ini", cfg); err != nil {
+			t.Fatal(err)
+		} else if cfg.BuiltIn != tc.builtin {
+			t.Errorf("%v: expected BuiltIn = %v, got %v", tc.lines, tc.builtin, cfg.BuiltIn)
+		}
+
+		mgr, err := NewManager(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The rules made from our vocabulary are loaded either way.
+		terms := false
+		for name := range mgr.Rules() {
+			terms = terms || strings.HasPrefix(name, "Vale.Terms")
+		}
+		if !terms {
+			t.Errorf("%v: expected 'Vale.Terms' to be loaded", tc.lines)
+		}
+		if _, found := mgr.Rules()["Vale.Spelling"]; found != tc.builtin {
+			t.Errorf("%v: expected 'Vale.Spelling' loaded = %v", tc.lines, tc.builtin)
+		}
+	}
+}
+
+func TestOptionalStyles(t *testing.T) {
+	dir := t.TempDir()
+	testutil.WriteFiles(t, dir, map[string]string{"styles/.keep": ""})
+
+	cases := map[string]int{
+		"":                                 0,
+		"[*.yml]\nBasedOnStyles = api":     4,
+		"[*.yml]\napi.SummaryCase = YES":   4,
+		"[*.yml]\nBasedOnStyles = Vale":    0,
+		"[*]\nBasedOnStyles = Vale, api\n": 4,
+	}
+
+	for lines, expected := range cases {
+		ini := filepath.Join(dir, ".vale.ini")
+		if err := ioutil.WriteFile(ini, []byte("StylesPath = styles\n"+lines), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := core.NewConfig(&core.CLIFlags{Path: ini, InExt: ".txt"})
+		if err != nil {
+			t.Fatal(err)
+		} else if err = core.From("ini", cfg); err != nil {
+			t.Fatal(err)
+		}
+
+		mgr, err := NewManager(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The `api` style is only loaded if it's used.
+		loaded := 0
+		for name := range mgr.Rules() {
+			if strings.HasPrefix(name, "api.") {
+				loaded++
+			}
+		}
+		if loaded != expected {
+			t.Errorf("%q: expected %d 'api' rules, got %d", lines, expected, loaded)
+		}
+	}
+}
+
+func TestLimitValidation(t *testing.T) {
+	for limit, valid := range map[string]bool{"0": true, "3": true, "-1": false, "many": false} {
+		def := map[string]interface{}{}
+		rule := "extends: existence\nmessage: '%s'\nlimit: " + limit + "\ntokens:\n  - foo\n"
+		if err := yaml.Unmarshal([]byte(rule), &def); err != nil {
+			t.Fatal(err)
+		}
+		if err := validateDefinition(def, "Limit.yml"); (err == nil) != valid {
+			t.Errorf("limit: %s: expected valid = %v, got %v", limit, valid, err)
+		}
+	}
+}
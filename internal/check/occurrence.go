@@ -1,7 +1,12 @@
 package check
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/jdkato/prose/summarize"
 	"github.com/jdkato/regexp"
 	"github.com/mitchellh/mapstructure"
 )
@@ -9,6 +14,16 @@ import (
 // Occurrence counts the number of times Token appears.
 type Occurrence struct {
 	Definition `mapstructure:",squash"`
+	// `distinct` (`bool`): Counts the distinct matches of `token` (compared
+	// case-insensitively with `ignorecase`) rather than all of them. The
+	// number of distinct matches and a comma-separated list of them are
+	// available to the rule's message as the first and second `%s`.
+	Distinct bool
+	// `distinct_group` (`string`): The named group of `token` whose captured
+	// text is counted by `distinct`, rather than the whole match -- e.g.,
+	// `\.\. (?P<kind>\w+)::` counts the kinds of directives. Matches in
+	// which the group doesn't participate aren't counted.
+	DistinctGroup string `mapstructure:"distinct_group"`
 	// `ignorecase` (`bool`): Makes all matches case-insensitive.
 	Ignorecase bool
 	// `max` (`int`): The maximum amount of times `token` may appear in a given
@@ -17,10 +32,17 @@ type Occurrence struct {
 	// `min` (`int`): The minimum amount of times `token` has to appear in a
 	// given scope.
 	Min int
+	// `ratio` (`float`): The maximum percentage (0 - 100) of `relativeTo`
+	// units that may contain `token`. When set, `max` and `min` are ignored.
+	Ratio float64
+	// `relativeTo` (`string`): The basis for `ratio` -- `words` (the
+	// default), `sentences`, or `paragraphs`.
+	RelativeTo string
 	// `token` (`string`): The token of interest.
 	Token string
 
 	pattern *regexp.Regexp
+	within  *window
 }
 
 // NewOccurrence creates a new `occurrence`-based rule.
@@ -28,7 +50,13 @@ func NewOccurrence(cfg *core.Config, generic baseCheck) (Occurrence, error) {
 	rule := Occurrence{}
 	path := generic["path"].(string)
 
-	err := mapstructure.Decode(generic, &rule)
+	within, err := makeWindow(generic, path)
+	if err != nil {
+		return rule, err
+	}
+	rule.within = within
+
+	err = mapstructure.Decode(generic, &rule)
 	if err != nil {
 		return rule, readStructureError(err, path)
 	}
@@ -45,22 +73,60 @@ func NewOccurrence(cfg *core.Config, generic baseCheck) (Occurrence, error) {
 	}
 
 	rule.pattern = re
+	if rule.Ratio < 0 || rule.Ratio > 100 {
+		return rule, core.NewE201FromTarget(
+			"'ratio' must be between 0 and 100.", "ratio", path)
+	} else if rule.Distinct && rule.Ratio > 0 {
+		return rule, core.NewE201FromTarget(
+			"'distinct' can't be used with 'ratio'.", "distinct", path)
+	} else if rule.DistinctGroup != "" && !rule.Distinct {
+		return rule, core.NewE201FromTarget(
+			"'distinct_group' requires 'distinct: true'.", "distinct_group", path)
+	} else if rule.DistinctGroup != "" && !core.StringInSlice(rule.DistinctGroup, re.SubexpNames()) {
+		return rule, core.NewE201FromTarget(
+			fmt.Sprintf("'token' has no group named '%s'.", rule.DistinctGroup),
+			"distinct_group",
+			path)
+	} else if !core.StringInSlice(rule.RelativeTo, relativeTo) {
+		return rule, core.NewE201FromTarget(
+			fmt.Sprintf("'relativeTo' must be one of %v.", relativeTo[1:]),
+			"relativeTo",
+			path)
+	}
+
 	return rule, nil
 }
 
+var relativeTo = []string{"", "words", "sentences", "paragraphs"}
+
 // Run checks the number of occurrences of a user-defined regex against a
 // certain threshold.
 func (o Occurrence) Run(txt string, f *core.File) []core.Alert {
 	alerts := []core.Alert{}
 
-	locs := o.pattern.FindAllStringIndex(txt, -1)
+	extent := o.within.extent(txt)
+
+	locs := o.pattern.FindAllStringIndex(txt[:extent], -1)
 	occurrences := len(locs)
-	if occurrences > o.Max || occurrences < o.Min {
-		// NOTE: We take only the first match (`locs[0]`) instead of the whole
-		// scope (`txt`) to avoid having to fall back to string matching.
-		//
-		// See (core/util.go#initialPosition).
-		a := makeAlert(o.Definition, locs[0], txt)
+	if o.Ratio > 0 {
+		return o.runRatio(txt, extent, locs)
+	} else if o.Distinct {
+		return o.runDistinct(txt, extent)
+	} else if occurrences > o.Max || occurrences < o.Min {
+		var a core.Alert
+		if occurrences > 0 {
+			// NOTE: We take only the first match (`locs[0]`) instead of the
+			// whole scope (`txt`) to avoid having to fall back to string
+			// matching.
+			//
+			// See (core/util.go#initialPosition).
+			a = makeAlert(o.Definition, locs[0], txt)
+		} else {
+			// There's nothing to point at (e.g., a required token is
+			// missing), so we anchor the alert to the start of the scope.
+			a = core.Alert{Check: o.Name, Severity: o.Level,
+				Span: []int{1, 1}, Link: o.Link, Action: o.Action}
+		}
 		a.Message = o.Message
 		a.Description = o.Description
 		alerts = append(alerts, a)
@@ -69,6 +135,101 @@ func (o Occurrence) Run(txt string, f *core.File) []core.Alert {
 	return alerts
 }
 
+// runDistinct checks the number of distinct matches of a user-defined regex
+// against a certain threshold.
+//
+// The number of distinct matches and a list of them, in the order in which
+// they first appear, are available to the rule's message as `%s`.
+//
+// Too many distinct matches are reported at the first occurrence of the one
+// that exceeds `max`; too few, at the first match (or, if there isn't one,
+// the start of the scope).
+func (o Occurrence) runDistinct(txt string, extent int) []core.Alert {
+	alerts := []core.Alert{}
+
+	group := 0
+	for i, name := range o.pattern.SubexpNames() {
+		if o.DistinctGroup != "" && name == o.DistinctGroup {
+			group = i
+		}
+	}
+
+	seen := map[string]bool{}
+	matches := []string{}
+	firsts := [][]int{}
+	for _, submatch := range o.pattern.FindAllStringSubmatchIndex(txt[:extent], -1) {
+		if submatch[2*group] < 0 {
+			continue
+		}
+		match := txt[submatch[2*group]:submatch[2*group+1]]
+
+		key := match
+		if o.Ignorecase {
+			key = strings.ToLower(match)
+		}
+
+		if !seen[key] {
+			seen[key] = true
+			matches = append(matches, match)
+			firsts = append(firsts, submatch[:2])
+		}
+	}
+
+	distinct := len(matches)
+	if distinct > o.Max || distinct < o.Min {
+		var a core.Alert
+		if distinct > o.Max {
+			a = makeAlert(o.Definition, firsts[o.Max], txt)
+		} else if distinct > 0 {
+			a = makeAlert(o.Definition, firsts[0], txt)
+		} else {
+			a = core.Alert{Check: o.Name, Severity: o.Level,
+				Span: []int{1, 1}, Link: o.Link, Action: o.Action}
+		}
+		a.Message, a.Description = formatMessages(o.Message, o.Description,
+			strconv.Itoa(distinct), strings.Join(matches, ", "))
+		alerts = append(alerts, a)
+	}
+
+	return alerts
+}
+
+// runRatio checks the number of occurrences of a user-defined regex, as a
+// percentage of the words, sentences, or paragraphs in `txt`, against a
+// threshold.
+//
+// The computed percentage is available to the rule's message as `%s`.
+func (o Occurrence) runRatio(txt string, extent int, locs [][]int) []core.Alert {
+	alerts := []core.Alert{}
+	if len(locs) == 0 {
+		return alerts
+	}
+
+	doc := summarize.NewDocument(txt[:extent])
+
+	basis := doc.NumWords
+	switch o.RelativeTo {
+	case "sentences":
+		basis = doc.NumSentences
+	case "paragraphs":
+		basis = doc.NumParagraphs
+	}
+
+	if basis == 0 {
+		return alerts
+	}
+
+	percent := float64(len(locs)) / basis * 100
+	if percent > o.Ratio {
+		a := makeAlert(o.Definition, locs[0], txt)
+		a.Message, a.Description = formatMessages(
+			o.Message, o.Description, fmt.Sprintf("%.1f%%", percent))
+		alerts = append(alerts, a)
+	}
+
+	return alerts
+}
+
 // Fields provides access to the internal rule definition.
 func (o Occurrence) Fields() Definition {
 	return o.Definition
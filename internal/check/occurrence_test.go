@@ -0,0 +1,170 @@
+package check
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+var ratioTests = []struct {
+	ratio      float64
+	relativeTo string
+	text       string
+	message    string
+}{
+	{10, "", "Very good. Very bad. It is fine now.", "25.0%"},
+	{50, "", "Very good. Very bad. It is fine now.", ""},
+	{50, "sentences", "Very good. Very bad. It is fine now.", "66.7%"},
+	{10, "sentences", "It is fine now.", ""},
+}
+
+func TestOccurrenceRatio(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := core.NewFile("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range ratioTests {
+		def := baseCheck{
+			"path":       "",
+			"token":      `\b[Vv]ery\b`,
+			"message":    "%s",
+			"ratio":      tt.ratio,
+			"relativeTo": tt.relativeTo,
+		}
+
+		rule, err := NewOccurrence(cfg, def)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		alerts := rule.Run(tt.text, file)
+		if tt.message == "" && len(alerts) != 0 {
+			t.Errorf("%v: expected no alerts, got %v", def, alerts)
+		} else if tt.message != "" && (len(alerts) != 1 || alerts[0].Message != tt.message) {
+			t.Errorf("%v: expected '%s', got %v", def, tt.message, alerts)
+		}
+	}
+}
+
+func TestOccurrenceRelativeTo(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def := baseCheck{"path": "", "token": "a", "ratio": 10, "relativeTo": "pages"}
+	if _, err = NewOccurrence(cfg, def); err == nil {
+		t.Error("expected an error for an invalid 'relativeTo'")
+	}
+}
+
+var distinctTests = []struct {
+	ignorecase bool
+	text       string
+	message    string
+}{
+	{false, "The API and the SDK use the API.", ""},
+	{false, "The API, the SDK, and the CLI use the API.", "3: API, SDK, CLI"},
+	{false, "The API, the Api, and the api.", "3: API, Api, api"},
+	{true, "The API, the Api, and the api.", ""},
+}
+
+func TestOccurrenceDistinct(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := core.NewFile("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range distinctTests {
+		def := baseCheck{
+			"path":       "",
+			"token":      `\b(?:API|Api|api|SDK|CLI)\b`,
+			"message":    "%s: %s",
+			"max":        2,
+			"distinct":   true,
+			"ignorecase": tt.ignorecase,
+		}
+
+		rule, err := NewOccurrence(cfg, def)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		alerts := rule.Run(tt.text, file)
+		if tt.message == "" && len(alerts) != 0 {
+			t.Errorf("%v: expected no alerts, got %v", def, alerts)
+		} else if tt.message != "" && (len(alerts) != 1 || alerts[0].Message != tt.message) {
+			t.Errorf("%v: expected '%s', got %v", def, tt.message, alerts)
+		}
+	}
+
+	def := baseCheck{"path": "", "token": "a", "ratio": 10, "distinct": true}
+	if _, err = NewOccurrence(cfg, def); err == nil {
+		t.Error("expected an error for 'distinct' with 'ratio'")
+	}
+}
+
+func TestOccurrenceDistinctGroup(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := core.NewFile("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def := baseCheck{
+		"path":           "",
+		"token":          `\.\. (?P<kind>\w+)::`,
+		"message":        "%s kinds: %s",
+		"max":            2,
+		"distinct":       true,
+		"distinct_group": "kind",
+	}
+
+	rule, err := NewOccurrence(cfg, def)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The same kind repeated is counted once ...
+	text := strings.Repeat(".. note:: A.\n", 5) + strings.Repeat(".. tip:: B.\n", 5)
+	if alerts := rule.Run(text, file); len(alerts) != 0 {
+		t.Errorf("expected no alerts, got %v", alerts)
+	}
+
+	// ... and the alert points at the first occurrence of the kind that
+	// exceeds `max`.
+	text += ".. note:: C.\n.. warning:: D.\n.. danger:: E.\n"
+	alerts := rule.Run(text, file)
+	if len(alerts) != 1 {
+		t.Fatalf("expected 1 alert, got %v", alerts)
+	} else if alerts[0].Message != "4 kinds: note, tip, warning, danger" {
+		t.Errorf("unexpected message '%s'", alerts[0].Message)
+	} else if alerts[0].Match != ".. warning::" {
+		t.Errorf("expected the alert at '.. warning::', got '%s'", alerts[0].Match)
+	}
+
+	for _, bad := range []baseCheck{
+		{"path": "", "token": `(?P<kind>\w+)`, "distinct_group": "kind"},
+		{"path": "", "token": `(?P<kind>\w+)`, "distinct": true, "distinct_group": "type"},
+	} {
+		if _, err = NewOccurrence(cfg, bad); err == nil {
+			t.Errorf("%v: expected an error", bad)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package check
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/mitchellh/mapstructure"
+)
+
+// Plugin runs an external executable, assigned in the `[plugins]` section of
+// a configuration file (e.g., `MyStyle.MyRule = path/to/exe`), against each
+// scope.
+//
+// The executable is given the scope's text on stdin, along with the
+// environment variables `VALE_FILE` (the path of the file being linted) and
+// `VALE_RULE` (e.g., `MyStyle.MyRule`). It must then print a JSON array of
+// alerts to stdout:
+//
+//	[{"Message": "...", "Span": [0, 3], "Match": "foo", "Severity": "error"}]
+//
+// where `Span` holds the byte offsets ([start, end)) of the match within the
+// text. `Match` defaults to the text within `Span`, while `Severity`,
+// `Description`, `Link`, and `Action` are optional.
+//
+// An executable that exits with a non-zero status, takes longer than
+// `ProcessTimeout` seconds, or prints invalid output is reported and isn't
+// run again.
+type Plugin struct {
+	Definition `mapstructure:",squash"`
+	// `exe` (`string`): The executable to run.
+	Exe string
+
+	timeout time.Duration
+	state   *pluginState
+	config  *core.Config
+}
+
+type pluginState struct {
+	sync.Once
+	failed int32
+}
+
+// NewPlugin creates a new `Rule` that runs an external executable.
+func NewPlugin(cfg *core.Config, generic baseCheck) (Plugin, error) {
+	rule := Plugin{}
+	path := generic["path"].(string)
+
+	err := mapstructure.Decode(generic, &rule)
+	if err != nil {
+		return rule, readStructureError(err, path)
+	}
+
+	rule.timeout = time.Duration(cfg.Timeout) * time.Second
+	rule.state = &pluginState{}
+	rule.config = cfg
+
+	return rule, nil
+}
+
+// Run sends `txt` to the plugin, returning the alerts that it reports.
+func (p Plugin) Run(txt string, f *core.File) []core.Alert {
+	var stdout, stderr bytes.Buffer
+
+	if atomic.LoadInt32(&p.state.failed) == 1 {
+		return []core.Alert{}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, p.Exe)
+	cmd.Env = append(os.Environ(), "VALE_FILE="+f.Path, "VALE_RULE="+p.Name)
+	cmd.Stdin = strings.NewReader(txt)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			p.fail("timed out after %s", p.timeout)
+		} else {
+			p.fail("%s %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return []core.Alert{}
+	}
+
+	reported := []core.Alert{}
+	if err := json.Unmarshal(stdout.Bytes(), &reported); err != nil {
+		p.fail("invalid output: %s", err)
+		return []core.Alert{}
+	}
+
+	alerts := []core.Alert{}
+	for _, a := range reported {
+		if len(a.Span) != 2 || a.Span[0] < 0 || a.Span[0] > a.Span[1] || a.Span[1] > len(txt) {
+			p.fail("invalid span %v", a.Span)
+			return []core.Alert{}
+		} else if a.Message == "" {
+			p.fail("missing message")
+			return []core.Alert{}
+		} else if a.Severity != "" && !core.StringInSlice(a.Severity, core.AlertLevels) {
+			p.fail("'Severity' must be one of %v", core.AlertLevels)
+			return []core.Alert{}
+		}
+
+		if a.Match == "" {
+			a.Match = txt[a.Span[0]:a.Span[1]]
+		}
+		a.Check = p.Name
+
+		alerts = append(alerts, a)
+	}
+
+	return alerts
+}
+
+// fail disables the plugin, reporting the first problem with it.
+func (p Plugin) fail(format string, a ...interface{}) {
+	atomic.StoreInt32(&p.state.failed, 1)
+	p.state.Do(func() {
+		args := append([]interface{}{p.Name, p.Exe}, a...)
+		p.config.Warnf("plugin '%s' (%s): "+format, args...)
+	})
+}
+
+// Fields provides access to the internal rule definition.
+func (p Plugin) Fields() Definition {
+	return p.Definition
+}
+
+// Pattern is the internal regex pattern used by this rule.
+func (p Plugin) Pattern() string {
+	return ""
+}
@@ -0,0 +1,76 @@
+package check
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+var pluginTests = []struct {
+	output string
+	alerts []core.Alert
+}{
+	{`[]`, []core.Alert{}},
+	{
+		`[{"Message": "Avoid 'foo'.", "Span": [5, 8], "Severity": "error"}]`,
+		[]core.Alert{{Check: "A.Plugin", Message: "Avoid 'foo'.", Span: []int{5, 8}, Match: "foo", Severity: "error"}},
+	},
+	{`[{"Message": "Avoid 'foo'.", "Span": [5, 80]}]`, []core.Alert{}},
+	{`not JSON`, []core.Alert{}},
+}
+
+func TestPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	dir, err := ioutil.TempDir("", "vale")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg, err := core.NewConfig(&core.CLIFlags{Quiet: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := core.NewFile("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tt := range pluginTests {
+		exe := filepath.Join(dir, "plugin.sh")
+		script := "#!/bin/sh\ncat > /dev/null\necho '" + tt.output + "'\n"
+		if err = ioutil.WriteFile(exe, []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		rule, err := NewPlugin(cfg, baseCheck{"name": "A.Plugin", "exe": exe, "path": ""})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		alerts := rule.Run("Some foo text.", file)
+		if len(alerts) != len(tt.alerts) {
+			t.Fatalf("%d: expected %v, got %v", i, tt.alerts, alerts)
+		}
+		for j, a := range alerts {
+			e := tt.alerts[j]
+			if a.Check != e.Check || a.Match != e.Match || a.Severity != e.Severity || a.Span[0] != e.Span[0] {
+				t.Errorf("%d: expected %v, got %v", i, e, a)
+			}
+		}
+
+		// Invalid output disables the plugin.
+		disabled := len(tt.alerts) == 0 && tt.output != `[]`
+		if (rule.state.failed == 1) != disabled {
+			t.Errorf("%d: expected disabled = %v", i, disabled)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package check
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/errata-ai/vale/v2/internal/core"
 	"github.com/jdkato/prose/summarize"
@@ -16,6 +18,10 @@ type Readability struct {
 	Metrics []string
 	// `grade` (`float`): The highest acceptable score.
 	Grade float64
+	// `weights` (`map`): The weight of each metric in the average score
+	// (e.g., `{Flesch-Kincaid: 2}`); any metric that isn't listed has a
+	// weight of 1. By default, the metrics are weighted equally.
+	Weights map[string]float64
 }
 
 // NewReadability creates a new `readability`-based rule.
@@ -28,12 +34,35 @@ func NewReadability(cfg *core.Config, generic baseCheck) (Readability, error) {
 		return rule, readStructureError(err, path)
 	}
 
-	if core.AllStringsInSlice(rule.Metrics, readabilityMetrics) {
-		// NOTE: This is the only extension point that doesn't support scoping.
-		// The reason for this is that we need to split on sentences to
-		// calculate readability, which means that specifying a scope smaller
-		// than a paragraph or including non-block level content (i.e.,
-		// headings, list items or table cells) doesn't make sense.
+	metrics := []string{}
+	for metric := range rule.Weights {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+
+	for _, metric := range metrics {
+		if !core.StringInSlice(metric, rule.Metrics) {
+			return rule, core.NewE201FromTarget(
+				fmt.Sprintf("'%s' isn't one of the rule's 'metrics'.", metric),
+				"weights",
+				path)
+		} else if rule.Weights[metric] <= 0 {
+			return rule, core.NewE201FromTarget(
+				fmt.Sprintf("The weight of '%s' must be a positive number.", metric),
+				"weights",
+				path)
+		}
+	}
+
+	if core.AllStringsInSlice(rule.Metrics, readabilityMetrics) && rule.Scope != "paragraph" {
+		// NOTE: This is the only extension point that doesn't fully support
+		// scoping. The reason for this is that we need to split on sentences
+		// to calculate readability, which means that specifying a scope
+		// smaller than a paragraph or including non-block level content
+		// (i.e., headings, list items or table cells) doesn't make sense.
+		//
+		// So, we either score the whole document or, with `scope:
+		// paragraph`, each paragraph individually.
 		rule.Definition.Scope = "summary"
 	}
 
@@ -42,30 +71,37 @@ func NewReadability(cfg *core.Config, generic baseCheck) (Readability, error) {
 
 // Run calculates the readability level of the given text.
 func (o Readability) Run(txt string, f *core.File) []core.Alert {
-	var grade float64
+	var grade, total float64
 	alerts := []core.Alert{}
 
 	doc := summarize.NewDocument(txt)
-	if core.StringInSlice("SMOG", o.Metrics) {
-		grade += doc.SMOG()
-	}
-	if core.StringInSlice("Gunning Fog", o.Metrics) {
-		grade += doc.GunningFog()
-	}
-	if core.StringInSlice("Coleman-Liau", o.Metrics) {
-		grade += doc.ColemanLiau()
-	}
-	if core.StringInSlice("Flesch-Kincaid", o.Metrics) {
-		grade += doc.FleschKincaid()
-	}
-	if core.StringInSlice("Automated Readability", o.Metrics) {
-		grade += doc.AutomatedReadability()
+	score := func(metric string, calc func() float64) {
+		if core.StringInSlice(metric, o.Metrics) {
+			w := o.weight(metric)
+			grade += w * calc()
+			total += w
+		}
 	}
+	score("SMOG", doc.SMOG)
+	score("Gunning Fog", doc.GunningFog)
+	score("Coleman-Liau", doc.ColemanLiau)
+	score("Flesch-Kincaid", doc.FleschKincaid)
+	score("Automated Readability", doc.AutomatedReadability)
 
-	grade = grade / float64(len(o.Metrics))
+	if len(o.Weights) > 0 {
+		grade = grade / total
+	} else {
+		grade = grade / float64(len(o.Metrics))
+	}
 	if grade > o.Grade {
 		a := core.Alert{Check: o.Name, Severity: o.Level,
 			Span: []int{1, 1}, Link: o.Link}
+		if o.Scope == "paragraph" {
+			// We point to the paragraph's first line.
+			first := strings.TrimSpace(strings.SplitN(strings.TrimSpace(txt), "\n", 2)[0])
+			start := strings.Index(txt, first)
+			a.Span, a.Match = []int{start, start + len(first)}, first
+		}
 		a.Message, a.Description = formatMessages(o.Message, o.Description,
 			fmt.Sprintf("%.2f", grade))
 		alerts = append(alerts, a)
@@ -74,6 +110,15 @@ func (o Readability) Run(txt string, f *core.File) []core.Alert {
 	return alerts
 }
 
+// weight returns the weight of `metric` in our average score (see
+// `Weights`).
+func (o Readability) weight(metric string) float64 {
+	if w, ok := o.Weights[metric]; ok {
+		return w
+	}
+	return 1
+}
+
 // Fields provides access to the internal rule definition.
 func (o Readability) Fields() Definition {
 	return o.Definition
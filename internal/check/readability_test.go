@@ -0,0 +1,126 @@
+package check
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/jdkato/prose/summarize"
+)
+
+func TestReadabilityParagraph(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := core.NewFile("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for scope, expected := range map[string]string{"text": "summary", "paragraph": "paragraph"} {
+		def := baseCheck{
+			"path":    "",
+			"name":    "Test.Grade",
+			"message": "Grade %s is too high.",
+			"scope":   scope,
+			"metrics": []string{"Flesch-Kincaid"},
+			"grade":   8,
+		}
+
+		rule, err := NewReadability(cfg, def)
+		if err != nil {
+			t.Fatal(err)
+		} else if rule.Scope != expected {
+			t.Errorf("expected scope '%s', got '%s'", expected, rule.Scope)
+		}
+	}
+
+	rule, err := NewReadability(cfg, baseCheck{
+		"path":    "",
+		"name":    "Test.Grade",
+		"message": "Grade %s is too high.",
+		"scope":   "paragraph",
+		"metrics": []string{"Flesch-Kincaid"},
+		"grade":   8,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	simple := "The cat sat on the mat. It was a good cat."
+	if alerts := rule.Run(simple, file); len(alerts) != 0 {
+		t.Errorf("expected no alerts, got %v", alerts)
+	}
+
+	complex := "Notwithstanding the aforementioned considerations, institutional\n" +
+		"stakeholders systematically underestimated the organizational ramifications\n" +
+		"of implementing comprehensive interdisciplinary methodologies.\n"
+	alerts := rule.Run(complex, file)
+	if len(alerts) != 1 {
+		t.Fatalf("expected 1 alert, got %v", alerts)
+	} else if alerts[0].Match != "Notwithstanding the aforementioned considerations, institutional" {
+		t.Errorf("expected the paragraph's first line, got '%s'", alerts[0].Match)
+	}
+}
+
+func TestReadabilityWeights(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := core.NewFile("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newRule := func(weights map[interface{}]interface{}) (Readability, error) {
+		def := baseCheck{
+			"path":    "",
+			"name":    "Test.Grade",
+			"message": "Grade %s is too high.",
+			"metrics": []string{"Flesch-Kincaid", "Coleman-Liau"},
+			"grade":   0,
+		}
+		if weights != nil {
+			def["weights"] = weights
+		}
+		return NewReadability(cfg, def)
+	}
+
+	text := "The committee reviewed the proposal carefully. It approved the budget."
+	doc := summarize.NewDocument(text)
+	fk, cl := doc.FleschKincaid(), doc.ColemanLiau()
+
+	for _, tt := range []struct {
+		weights  map[interface{}]interface{}
+		expected float64
+	}{
+		{nil, (fk + cl) / 2},
+		{map[interface{}]interface{}{"Flesch-Kincaid": 3}, (3*fk + cl) / 4},
+		{map[interface{}]interface{}{"Flesch-Kincaid": 1, "Coleman-Liau": 0.5}, (fk + 0.5*cl) / 1.5},
+	} {
+		rule, err := newRule(tt.weights)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		alerts := rule.Run(text, file)
+		expected := fmt.Sprintf("Grade %.2f is too high.", tt.expected)
+		if len(alerts) != 1 || alerts[0].Message != expected {
+			t.Errorf("%v: expected '%s', got %v", tt.weights, expected, alerts)
+		}
+	}
+
+	for _, weights := range []map[interface{}]interface{}{
+		{"SMOG": 2},
+		{"Flesch-Kincaid": 0},
+		{"Coleman-Liau": -1},
+	} {
+		if _, err := newRule(weights); err == nil {
+			t.Errorf("%v: expected an error", weights)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/jdkato/prose/tag"
 	"github.com/jdkato/regexp"
 	"github.com/mitchellh/mapstructure"
 )
@@ -16,16 +17,21 @@ type Repetition struct {
 	Ignorecase bool
 	// `alpha` (`bool`): Limits all matches to alphanumeric tokens.
 	Alpha bool
+	// `lemma` (`bool`): Compares the lemmas of tokens (e.g., "go" and
+	// "going") rather than the tokens themselves, which requires tagging
+	// each scope.
+	Lemma bool
 	// `tokens` (`array`): A list of tokens to be transformed into a
 	// non-capturing group.
 	Tokens []string
 
 	pattern *regexp.Regexp
+	tagger  *tag.PerceptronTagger
 }
 
 // NewRepetition creates a new `repetition`-based rule.
 func NewRepetition(cfg *core.Config, generic baseCheck) (Repetition, error) {
-	rule := Repetition{}
+	rule := Repetition{tagger: cfg.Tagger}
 	path := generic["path"].(string)
 
 	err := mapstructure.Decode(generic, &rule)
@@ -56,9 +62,17 @@ func (o Repetition) Run(txt string, f *core.File) []core.Alert {
 	var hit bool
 	var ploc []int
 	var count int
+	var lemmas, plemmas []string
+
+	locs := o.pattern.FindAllStringIndex(txt, -1)
+
+	var tags map[int]tag.Token
+	if o.Lemma && len(locs) > 1 {
+		tags = tagsByOffset(txt, o.tagger)
+	}
 
 	alerts := []core.Alert{}
-	for _, loc := range o.pattern.FindAllStringIndex(txt, -1) {
+	for _, loc := range locs {
 		curr = strings.TrimSpace(txt[loc[0]:loc[1]])
 		if o.Ignorecase {
 			hit = strings.ToLower(curr) == strings.ToLower(prev) && curr != ""
@@ -66,6 +80,12 @@ func (o Repetition) Run(txt string, f *core.File) []core.Alert {
 			hit = curr == prev && curr != ""
 		}
 
+		if o.Lemma {
+			lemmas = o.lemmas(curr, loc[0], tags)
+			hit = hit || (curr != "" && prev != "" && core.SameLemma(lemmas, plemmas))
+			plemmas = lemmas
+		}
+
 		hit = hit && (!o.Alpha || core.IsLetter(curr))
 		if hit {
 			count++
@@ -88,6 +108,35 @@ func (o Repetition) Run(txt string, f *core.File) []core.Alert {
 	return alerts
 }
 
+// lemmas returns the candidate lemmas (see `core.Lemmas`) of the match `curr`,
+// which starts at `offset`.
+func (o Repetition) lemmas(curr string, offset int, tags map[int]tag.Token) []string {
+	word, pos := curr, ""
+	if tok, found := tags[offset]; found {
+		// The match may include, e.g., punctuation that isn't part of the
+		// word.
+		word, pos = tok.Text, tok.Tag
+	}
+	if o.Ignorecase {
+		word = strings.ToLower(word)
+	}
+	return core.Lemmas(word, pos)
+}
+
+// tagsByOffset tags the words of `txt` with `model`, keyed by their byte
+// offset.
+func tagsByOffset(txt string, model *tag.PerceptronTagger) map[int]tag.Token {
+	words := core.TextToTokens(txt, true, model)
+
+	tags := make(map[int]tag.Token, len(words))
+	for i, offset := range tokenOffsets(words, txt) {
+		if offset >= 0 {
+			tags[offset] = words[i]
+		}
+	}
+	return tags
+}
+
 // Fields provides access to the internal rule definition.
 func (o Repetition) Fields() Definition {
 	return o.Definition
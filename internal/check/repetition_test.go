@@ -0,0 +1,70 @@
+package check
+
+import (
+	"testing"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+var lemmaTests = []struct {
+	text    string
+	lemma   bool
+	matches []string
+}{
+	{"We go going there.", true, []string{"go going"}},
+	{"We go going there.", false, []string{}},
+	{"Two cats cat owners.", true, []string{"cats cat"}},
+	{"The the dog.", true, []string{"The the"}},
+	{"It was done.", true, []string{}},
+	{"Stop stopping now.", true, []string{"Stop stopping"}},
+}
+
+func TestRepetitionLemma(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := core.NewFile("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range lemmaTests {
+		def := baseCheck{
+			"path":       "",
+			"tokens":     []string{`[^\s.]+`},
+			"alpha":      true,
+			"ignorecase": true,
+			"lemma":      tt.lemma,
+		}
+
+		rule, err := NewRepetition(cfg, def)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		alerts := rule.Run(tt.text, file)
+		if len(alerts) != len(tt.matches) {
+			t.Fatalf("%q: expected %v, got %d alerts", tt.text, tt.matches, len(alerts))
+		}
+		for i, a := range alerts {
+			if a.Match != tt.matches[i] {
+				t.Errorf("%q: expected '%s', got '%s'", tt.text, tt.matches[i], a.Match)
+			}
+		}
+	}
+}
+
+func TestLemmas(t *testing.T) {
+	for _, tt := range []struct{ a, aTag, b, bTag string }{
+		{"go", "VB", "went", "VBD"},
+		{"study", "VB", "studied", "VBD"},
+		{"make", "VB", "making", "VBG"},
+		{"party", "NN", "parties", "NNS"},
+	} {
+		if !core.SameLemma(core.Lemmas(tt.a, tt.aTag), core.Lemmas(tt.b, tt.bTag)) {
+			t.Errorf("expected '%s' and '%s' to share a lemma", tt.a, tt.b)
+		}
+	}
+}
@@ -16,9 +16,28 @@ type NLPToken struct {
 	Negate  bool
 	Tag     string
 	Skip    int
+	// `within` (`int`): The maximum number of arbitrary tokens that may
+	// separate this token from the one before it.
+	Within int
+	// `except` (`array`): Words that this token doesn't match, even if its
+	// `pattern` or `tag` does (e.g., `just` for `tag: RB`). They're compared
+	// regardless of case if the rule's `ignorecase` is set.
+	Except []string
+
+	re         *regexp.Regexp
+	except     map[string]bool
+	ignorecase bool
+	optional   bool
+}
 
-	re       *regexp.Regexp
-	optional bool
+// excepts determines if `text` is one of the token's `except` words.
+func (t NLPToken) excepts(text string) bool {
+	if len(t.except) == 0 {
+		return false
+	} else if t.ignorecase {
+		text = strings.ToLower(text)
+	}
+	return t.except[text]
 }
 
 // Sequence looks for a user-defined sequence of tokens.
@@ -29,11 +48,12 @@ type Sequence struct {
 
 	needsTagging bool
 	history      []int
+	tagger       *tag.PerceptronTagger
 }
 
 // NewSequence creates a new rule from the provided `baseCheck`.
 func NewSequence(cfg *core.Config, generic baseCheck) (Sequence, error) {
-	rule := Sequence{}
+	rule := Sequence{tagger: cfg.Tagger}
 	path := generic["path"].(string)
 
 	makeTokens(&rule, generic, cfg)
@@ -43,30 +63,22 @@ func NewSequence(cfg *core.Config, generic baseCheck) (Sequence, error) {
 		return rule, readStructureError(err, path)
 	}
 
-	for i, token := range rule.Tokens {
+	for _, token := range rule.Tokens {
 		if !rule.needsTagging && token.Tag != "" {
 			rule.needsTagging = true
 		}
+	}
 
-		if token.Pattern != "" {
-			regex := makeRegexp(
-				cfg.WordTemplate,
-				rule.Ignorecase,
-				func() bool { return true },
-				func() string { return "" },
-				false)
-			regex = fmt.Sprintf(regex, token.Pattern)
-
-			re, err := regexp.Compile(regex)
-			if err != nil {
-				return rule, core.NewE201FromPosition(err.Error(), path, 1)
-			}
-			rule.Tokens[i].re = re
-		}
-
+	err = compileTokens(cfg, rule.Tokens, rule.Ignorecase, path)
+	if err != nil {
+		return rule, err
 	}
 
-	rule.Definition.Scope = "summary"
+	if rule.Scope != "paragraph" {
+		// Sequences may span sentences, so they're either run against the
+		// whole document or, with `scope: paragraph`, each paragraph.
+		rule.Definition.Scope = "summary"
+	}
 	return rule, nil
 }
 
@@ -80,6 +92,42 @@ func (s Sequence) Pattern() string {
 	return ""
 }
 
+// compileTokens compiles the `pattern` and `except` list of each token in
+// `tokens`.
+func compileTokens(cfg *core.Config, tokens []NLPToken, ignorecase bool, path string) error {
+	for i, token := range tokens {
+		if len(token.Except) > 0 {
+			tokens[i].except = make(map[string]bool)
+			tokens[i].ignorecase = ignorecase
+			for _, word := range token.Except {
+				if ignorecase {
+					word = strings.ToLower(word)
+				}
+				tokens[i].except[word] = true
+			}
+		}
+
+		if token.Pattern == "" {
+			continue
+		}
+
+		regex := makeRegexp(
+			cfg.WordTemplate,
+			ignorecase,
+			func() bool { return true },
+			func() string { return "" },
+			false)
+		regex = fmt.Sprintf(regex, token.Pattern)
+
+		re, err := regexp.Compile(regex)
+		if err != nil {
+			return core.NewE201FromPosition(err.Error(), path, 1)
+		}
+		tokens[i].re = re
+	}
+	return nil
+}
+
 func makeTokens(s *Sequence, generic baseCheck, cfg *core.Config) error {
 	for _, token := range generic["tokens"].([]interface{}) {
 		tok := NLPToken{}
@@ -112,59 +160,95 @@ func tokensMatch(token NLPToken, word tag.Token) bool {
 		return false
 	}
 
-	return true
+	return !token.excepts(word.Text)
 }
 
-func sequenceMatches(idx int, chk Sequence, target, src string) ([]string, int) {
-	toks := chk.Tokens
-	text := []string{}
+// seek looks for a word matching `token` in the direction of `step` (-1 or 1)
+// from `words[pos]`, allowing up to `window` intervening words. It returns the
+// index of the matching word or -1.
+func seek(token NLPToken, words []tag.Token, pos, step, window int) int {
+	for n := 1; n <= window+1; n++ {
+		i := pos + step*n
+		if i < 0 || i >= len(words) {
+			break
+		} else if tokensMatch(token, words[i]) {
+			return i
+		}
+	}
+	return -1
+}
 
+// sequenceMatches looks for the sequence `chk.Tokens` around the word
+// `target`, which is matched by the token at `idx`.
+//
+// It returns the text of each word in the sequence, the index of `target`,
+// and the indices of the sequence's first and last words.
+func sequenceMatches(idx int, chk Sequence, target string, words []tag.Token) ([]string, int, []int) {
+	toks := chk.Tokens
 	sizeT := len(toks)
-	words := core.TextToTokens(src, chk.needsTagging)
-	index := 0
 
 	for jdx, tok := range words {
-		if tok.Text == target && !core.IntInSlice(jdx, chk.history) {
-			index = jdx
-			// We've found our context.
-			if idx > 0 {
-				// Check the left-end of the sequence:
-				for i := 1; idx-i >= 0; i++ {
-					word := words[jdx-i]
-					text = append([]string{word.Text}, text...)
-
-					mat := tokensMatch(toks[idx-i], word)
-					opt := toks[idx-i].optional
-					if !mat && !opt {
-						return []string{}, index
-					} else if mat && opt {
-						break
-					}
+		if tok.Text != target || core.IntInSlice(jdx, chk.history) {
+			continue
+		}
+
+		// We've found our context.
+		text := []string{tok.Text}
+		bounds := []int{jdx, jdx}
+
+		// Check the left-end of the sequence:
+		pos := jdx
+		for i := idx - 1; i >= 0; i-- {
+			next := pos - 1
+			if !toks[i].optional {
+				// `within` is the gap between a token and its predecessor.
+				next = seek(toks[i], words, pos, -1, toks[i+1].Within)
+				if next < 0 {
+					return []string{}, jdx, nil
 				}
+			} else if next < 0 {
+				break
 			}
-			if idx < sizeT {
-				// Check the right-end of the sequence
-				for i := 1; idx+i < sizeT; i++ {
-					if i == 1 {
-						text = append(text, words[index].Text)
-					}
-					word := words[jdx+i]
-					text = append(text, word.Text)
-
-					mat := tokensMatch(toks[idx+i], word)
-					opt := toks[idx+i].optional
-					if !mat && !opt {
-						return []string{}, index
-					} else if mat && opt {
-						break
-					}
+
+			for j := pos - 1; j >= next; j-- {
+				text = append([]string{words[j].Text}, text...)
+			}
+			pos = next
+
+			if toks[i].optional && tokensMatch(toks[i], words[next]) {
+				break
+			}
+		}
+		bounds[0] = pos
+
+		// Check the right-end of the sequence
+		pos = jdx
+		for i := idx + 1; i < sizeT; i++ {
+			next := pos + 1
+			if !toks[i].optional {
+				next = seek(toks[i], words, pos, 1, toks[i].Within)
+				if next < 0 {
+					return []string{}, jdx, nil
 				}
+			} else if next >= len(words) {
+				break
+			}
+
+			for j := pos + 1; j <= next; j++ {
+				text = append(text, words[j].Text)
+			}
+			pos = next
+
+			if toks[i].optional && tokensMatch(toks[i], words[next]) {
+				break
 			}
-			break
 		}
+		bounds[1] = pos
+
+		return text, jdx, bounds
 	}
 
-	return text, index
+	return []string{}, 0, nil
 }
 
 func stepsToString(steps []string) string {
@@ -185,20 +269,43 @@ func (s Sequence) Run(txt string, f *core.File) []core.Alert {
 
 	for idx, tok := range s.Tokens {
 		if !tok.Negate && tok.Pattern != "" {
-			for _, loc := range tok.re.FindAllStringIndex(txt, -1) {
+			locs := tok.re.FindAllStringIndex(txt, -1)
+			if len(locs) == 0 {
+				// There's no anchor, so we don't need to tag anything.
+				break
+			}
+
+			words := core.TextToTokens(txt, s.needsTagging, s.tagger)
+			offsets := tokenOffsets(words, txt)
+			for _, loc := range locs {
 				target := txt[loc[0]:loc[1]]
+				if tok.excepts(target) {
+					// The anchor itself is an exception, so there's no
+					// sequence here.
+					continue
+				}
 				// These are all possible violations in `txt`:
-				steps, index := sequenceMatches(idx, s, target, txt)
+				steps, index, bounds := sequenceMatches(idx, s, target, words)
 				s.history = append(s.history, index)
 
 				if len(steps) > 0 {
 					seq := stepsToString(steps)
-					idx := strings.Index(txt, seq)
+
+					span := []int{-1, -1}
+					first, last := offsets[bounds[0]], offsets[bounds[1]]
+					if first >= 0 && last >= 0 {
+						// The span covers everything from the first to the
+						// last word of the sequence, including any words
+						// skipped over by `within`.
+						span = []int{first, last + len(words[bounds[1]].Text)}
+						seq = txt[span[0]:span[1]]
+					} else if idx := strings.Index(txt, seq); idx >= 0 {
+						span = []int{idx, idx + len(seq)}
+					}
 
 					a := core.Alert{
 						Check: s.Name, Severity: s.Level, Link: s.Link,
-						Span: []int{idx, idx + len(seq)}, Hide: false,
-						Match: seq, Action: s.Action}
+						Span: span, Hide: false, Match: seq, Action: s.Action}
 
 					a.Message, a.Description = formatMessages(s.Message,
 						s.Description, steps...)
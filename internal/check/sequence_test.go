@@ -0,0 +1,167 @@
+package check
+
+import (
+	"testing"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+var sequenceTests = []struct {
+	tokens  []interface{}
+	text    string
+	matches []string
+}{
+	{
+		[]interface{}{
+			map[string]interface{}{"pattern": "install"},
+			map[string]interface{}{"pattern": "package", "within": 2},
+		},
+		"You install the npm package now.",
+		[]string{"install the npm package"},
+	},
+	{
+		[]interface{}{
+			map[string]interface{}{"pattern": "install"},
+			map[string]interface{}{"pattern": "package", "within": 1},
+		},
+		"You install the npm package now.",
+		[]string{},
+	},
+	{
+		[]interface{}{
+			map[string]interface{}{"pattern": "install"},
+			map[string]interface{}{"pattern": "package"},
+		},
+		"You install package now.",
+		[]string{"install package"},
+	},
+	{
+		[]interface{}{
+			map[string]interface{}{"tag": "PRP"},
+			map[string]interface{}{"pattern": "install"},
+		},
+		"You install it.",
+		[]string{"You install"},
+	},
+	{
+		[]interface{}{
+			map[string]interface{}{"tag": "PRP"},
+			map[string]interface{}{"pattern": "note", "within": 3},
+		},
+		"If you can, note this.",
+		[]string{"you can, note"},
+	},
+}
+
+func TestSequenceWithin(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := core.NewFile("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range sequenceTests {
+		def := baseCheck{"path": "", "tokens": tt.tokens}
+
+		rule, err := NewSequence(cfg, def)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		alerts := rule.Run(tt.text, file)
+		if len(alerts) != len(tt.matches) {
+			t.Errorf("%s: expected %v, got %v", tt.text, tt.matches, alerts)
+			continue
+		}
+		for i, a := range alerts {
+			if a.Match != tt.matches[i] {
+				t.Errorf("%s: expected '%s', got '%s'", tt.text, tt.matches[i], a.Match)
+			}
+		}
+	}
+}
+
+func TestSequenceParagraph(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := []interface{}{
+		map[string]interface{}{"tag": "PRP"},
+		map[string]interface{}{"pattern": "install"},
+	}
+
+	for scope, expected := range map[string]string{"": "summary", "text": "summary", "paragraph": "paragraph"} {
+		def := baseCheck{"path": "", "tokens": tokens}
+		if scope != "" {
+			def["scope"] = scope
+		}
+
+		rule, err := NewSequence(cfg, def)
+		if err != nil {
+			t.Fatal(err)
+		} else if rule.Scope != expected {
+			t.Errorf("%s: expected scope '%s', got '%s'", scope, expected, rule.Scope)
+		}
+	}
+}
+
+func TestSequenceExcept(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := core.NewFile("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	adverb := []interface{}{
+		map[string]interface{}{"tag": "RB", "except": []interface{}{"just"}},
+		map[string]interface{}{"pattern": "said"},
+	}
+	anchor := []interface{}{
+		map[string]interface{}{"pattern": `\w+ly`, "except": []interface{}{"ONLY"}},
+		map[string]interface{}{"pattern": "works"},
+	}
+
+	cases := []struct {
+		tokens     []interface{}
+		ignorecase bool
+		text       string
+		matches    []string
+	}{
+		// An exception on a context token:
+		{adverb, false, "She just said it. He loudly said no.", []string{"loudly said"}},
+		// ... and on the anchor:
+		{anchor, true, "It only works. It mostly works.", []string{"mostly works"}},
+		{anchor, true, "Only works.", []string{}},
+		{anchor, false, "Only works.", []string{"Only works"}},
+	}
+
+	for _, tt := range cases {
+		def := baseCheck{"path": "", "tokens": tt.tokens, "ignorecase": tt.ignorecase}
+
+		rule, err := NewSequence(cfg, def)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		alerts := rule.Run(tt.text, file)
+		if len(alerts) != len(tt.matches) {
+			t.Errorf("%s: expected %v, got %v", tt.text, tt.matches, alerts)
+			continue
+		}
+		for i, a := range alerts {
+			if a.Match != tt.matches[i] {
+				t.Errorf("%s: expected '%s', got '%s'", tt.text, tt.matches[i], a.Match)
+			}
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/errata-ai/vale/v2/internal/core"
 	"github.com/errata-ai/vale/v2/pkg/spell"
@@ -43,7 +44,11 @@ type Spelling struct {
 	// files consisting of one word per line to ignore.
 	Ignore     []string
 	Exceptions []string
-	Threshold  int
+	// `threshold` (`int`): The maximum edit distance between an unknown word
+	// and its nearest dictionary word for it to be considered a misspelling;
+	// more distant words are assumed to be novel terms (e.g., jargon) and
+	// aren't flagged. By default, every unknown word is flagged.
+	Threshold int
 
 	// `dicpath` overrides the environments `DICPATH` setting.
 	Dicpath string
@@ -52,7 +57,30 @@ type Spelling struct {
 	Dictionaries []string
 
 	exceptRe *regexp.Regexp
-	gs       *spell.Checker
+	model    *speller
+}
+
+// A speller is a lazily-built spell-checking model.
+//
+// Building a model (parsing a Hunspell dictionary and its word lists) is
+// expensive, so we wait until a rule actually needs it and then share the
+// result between all rules that reference the same files.
+type speller struct {
+	once sync.Once
+	gs   *spell.Checker
+	err  error
+
+	build func() (*spell.Checker, error)
+}
+
+// spellers caches `speller`s by their dictionary and word-list paths.
+var spellers = sync.Map{}
+
+func (s *speller) load() (*spell.Checker, error) {
+	s.once.Do(func() {
+		s.gs, s.err = s.build()
+	})
+	return s.gs, s.err
 }
 
 func addFilters(s *Spelling, generic baseCheck, cfg *core.Config) error {
@@ -86,57 +114,87 @@ func addExceptions(s *Spelling, generic baseCheck, cfg *core.Config) error {
 	}
 
 	for term := range cfg.AcceptedTokens {
-		s.Exceptions = append(s.Exceptions, term)
-		s.exceptRe = regexp.MustCompile(
-			ignoreCase + strings.Join(s.Exceptions, "|"))
+		s.Exceptions = append(s.Exceptions, vocabException(term))
+	}
+
+	if len(s.Exceptions) > 0 {
+		// NOTE: A term that's only accepted as written is still excepted in
+		// any case: `Vale.Terms` reports its other cases, so flagging them
+		// here would report the same word twice.
+		re, err := regexp.Compile(ignoreCase + strings.Join(s.Exceptions, "|"))
+		if err != nil {
+			return core.NewE201FromPosition(err.Error(), generic["path"].(string), 1)
+		}
+		s.exceptRe = re
 	}
 
 	return nil
 }
 
 // NewSpelling creates a new `spelling`-based rule.
+//
+// The rule's dictionaries aren't loaded until it's first run (see `Load`).
 func NewSpelling(cfg *core.Config, generic baseCheck) (Spelling, error) {
-	var model *spell.Checker
-
 	rule := Spelling{}
 	path := generic["path"].(string)
 	name := generic["name"].(string)
 
 	addFilters(&rule, generic, cfg)
-	addExceptions(&rule, generic, cfg)
+	if err := addExceptions(&rule, generic, cfg); err != nil {
+		return rule, err
+	}
 
 	err := mapstructure.Decode(generic, &rule)
 	if err != nil {
 		return rule, readStructureError(err, path)
 	}
 
-	model, err = makeSpeller(&rule, cfg)
-	if err != nil {
-		return rule, core.NewE201FromPosition(err.Error(), path, 1)
-	}
-
+	vocabs, ignores := []string{}, []string{}
 	for _, ignore := range rule.Ignore {
-		vocab := filepath.Join(cfg.StylesPath, ignore)
-		if name == "Vale.Spelling" && cfg.Project != "" {
+		if name == "Vale.Spelling" && len(cfg.Projects) > 0 {
 			// Special case: Project support
-			vocab = filepath.Join(
-				cfg.StylesPath,
-				"Vocab",
-				cfg.Project,
-				ignore)
-		}
-		exists := model.AddWordListFile(vocab)
-		if exists != nil {
-			vocab, _ = filepath.Abs(ignore)
-			exists = model.AddWordListFile(vocab)
-			// TODO: check error?
+			for _, project := range cfg.Projects {
+				vocabs = append(vocabs, filepath.Join(
+					cfg.StylesPath,
+					"Vocab",
+					project,
+					ignore))
+				ignores = append(ignores, ignore)
+			}
+			continue
 		}
+		vocabs = append(vocabs, filepath.Join(cfg.StylesPath, ignore))
+		ignores = append(ignores, ignore)
 	}
 
 	if !rule.Custom {
 		rule.Filters = append(rule.Filters, defaultFilters...)
 	}
-	rule.gs = model
+
+	key := strings.Join([]string{
+		core.FindAsset(cfg, rule.Aff),
+		core.FindAsset(cfg, rule.Dic),
+		rule.Dicpath,
+		strings.Join(rule.Dictionaries, ","),
+		strings.Join(vocabs, ",")}, "|")
+
+	model, _ := spellers.LoadOrStore(key, &speller{
+		build: func() (*spell.Checker, error) {
+			gs, err := makeSpeller(&rule, cfg)
+			if err != nil {
+				return gs, core.NewE201FromPosition(err.Error(), path, 1)
+			}
+			for i, vocab := range vocabs {
+				if gs.AddWordListFile(vocab) != nil {
+					vocab, _ = filepath.Abs(ignores[i])
+					_ = gs.AddWordListFile(vocab)
+					// TODO: check error?
+				}
+			}
+			return gs, nil
+		},
+	})
+	rule.model = model.(*speller)
 
 	return rule, nil
 }
@@ -145,11 +203,18 @@ func NewSpelling(cfg *core.Config, generic baseCheck) (Spelling, error) {
 func (s Spelling) Run(txt string, f *core.File) []core.Alert {
 	alerts := []core.Alert{}
 
+	gs, err := s.Load()
+	if err != nil {
+		// NOTE: The linter reports this error instead of running the rule
+		// (see `lint.Linter.load`).
+		return alerts
+	}
+
 	// This ensures that we respect `.aff` entries like `ICONV ’ '`,
 	// allowing us to avoid false positives.
 	//
 	// See https://github.com/errata-ai/vale/v2/issues/148.
-	txt = s.gs.Convert(txt)
+	txt = gs.Convert(txt)
 
 OUTER:
 	for _, word := range core.WordTokenizer.Tokenize(txt) {
@@ -159,7 +224,7 @@ OUTER:
 			}
 		}
 
-		if !s.gs.Spell(word) && !isMatch(s.exceptRe, word) {
+		if !gs.Spell(word) && !isMatch(s.exceptRe, word) && s.isTypo(gs, word) {
 			offset := strings.Index(txt, word)
 			loc := []int{offset, offset + len(word)}
 
@@ -176,6 +241,17 @@ OUTER:
 	return alerts
 }
 
+// isTypo determines if the unknown `word` is close enough to a dictionary
+// word to be a misspelling of it (see `Threshold`).
+func (s Spelling) isTypo(gs *spell.Checker, word string) bool {
+	return s.Threshold <= 0 || gs.Distance(word, s.Threshold) >= 0
+}
+
+// Load builds (or retrieves from the cache) the rule's spell-checking model.
+func (s Spelling) Load() (*spell.Checker, error) {
+	return s.model.load()
+}
+
 // Fields provides access to the internal rule definition.
 func (s Spelling) Fields() Definition {
 	return s.Definition
@@ -0,0 +1,91 @@
+package check
+
+import (
+	"testing"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+func TestSpellingThreshold(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for threshold, expected := range map[int][]string{
+		0: {"recieve", "Kubeflowz"},
+		1: {"recieve"},
+	} {
+		def := baseCheck{
+			"name": "Test.Spelling", "path": "", "message": "'%s'", "threshold": threshold}
+
+		rule, err := NewSpelling(cfg, def)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		matches := []string{}
+		for _, a := range rule.Run("We recieve Kubeflowz events.", &core.File{}) {
+			matches = append(matches, a.Match)
+		}
+
+		if len(matches) != len(expected) {
+			t.Errorf("%d: expected %v, got %v", threshold, expected, matches)
+			continue
+		}
+		for i, match := range matches {
+			if match != expected[i] {
+				t.Errorf("%d: expected %v, got %v", threshold, expected, matches)
+			}
+		}
+	}
+}
+
+func TestSpellingLoadError(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def := baseCheck{
+		"name": "Test.Missing", "path": "", "message": "'%s'",
+		"dictionaries": []interface{}{"missing"}}
+
+	// The dictionary isn't read until the rule is first used ...
+	rule, err := NewSpelling(cfg, def)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ... at which point its error is returned, rather than raised by `Run`.
+	if _, err = rule.Load(); err == nil {
+		t.Fatal("expected an error for a missing dictionary")
+	}
+	if alerts := rule.Run("We recieve events.", &core.File{}); len(alerts) != 0 {
+		t.Errorf("expected no alerts, got %v", alerts)
+	}
+}
+
+func TestSpellingCaseVariants(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.AcceptedTokens = map[string]struct{}{"Documentarians": {}}
+
+	def := baseCheck{"name": "Test.Spelling", "path": "", "message": "'%s'"}
+	rule, err := NewSpelling(cfg, def)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// `Vale.Terms` reports the lowercase variant, so we don't.
+	text := "Documentarians and documentarians and documentarianz."
+	matches := []string{}
+	for _, a := range rule.Run(text, &core.File{}) {
+		matches = append(matches, a.Match)
+	}
+	if len(matches) != 1 || matches[0] != "documentarianz" {
+		t.Errorf("expected [documentarianz], got %v", matches)
+	}
+}
@@ -2,9 +2,11 @@ package check
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/jdkato/prose/tag"
 	"github.com/jdkato/regexp"
 	"github.com/mitchellh/mapstructure"
 )
@@ -21,14 +23,20 @@ type Substitution struct {
 	// `pos` (`string`): A regular expression matching tokens to parts of
 	// speech.
 	POS string
-
-	pattern *regexp.Regexp
-	repl    []string
+	// `swappos` (`map`): A sequence of `observed: pos` pairs, which override
+	// `pos` for individual entries of `swap`.
+	SwapPOS map[string]string
+
+	pattern  *regexp.Regexp
+	repl     map[int]string
+	tags     map[int]string
+	captures bool
+	tagger   *tag.PerceptronTagger
 }
 
 // NewSubstitution creates a new `substitution`-based rule.
 func NewSubstitution(cfg *core.Config, generic baseCheck) (Substitution, error) {
-	rule := Substitution{}
+	rule := Substitution{tagger: cfg.Tagger}
 	path := generic["path"].(string)
 
 	err := mapstructure.Decode(generic, &rule)
@@ -37,17 +45,44 @@ func NewSubstitution(cfg *core.Config, generic baseCheck) (Substitution, error)
 	}
 	tokens := ""
 
+	for observed := range rule.SwapPOS {
+		if _, found := rule.Swap[observed]; !found {
+			return rule, core.NewE201FromTarget(
+				fmt.Sprintf("'%s' is not an entry in 'swap'.", observed),
+				observed,
+				path)
+		}
+	}
+
 	regex := makeRegexp(
 		cfg.WordTemplate,
 		rule.Ignorecase,
 		func() bool { return !rule.Nonword },
 		func() string { return "" }, true)
 
-	replacements := []string{}
-	for regexstr, replacement := range rule.Swap {
-		opens := strings.Count(regexstr, "(")
-		if opens != strings.Count(regexstr, "?:") &&
-			opens != strings.Count(regexstr, `\(`) {
+	// NOTE: We sort the keys to ensure that the resulting pattern is stable
+	// and that, given a choice, we prefer the longest match.
+	keys := []string{}
+	for regexstr := range rule.Swap {
+		keys = append(keys, regexstr)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	group := 0
+	replacements := map[int]string{}
+	tags := map[int]string{}
+	for _, regexstr := range keys {
+		replacement := rule.Swap[regexstr]
+		// Named groups (used for message interpolation) are allowed since we
+		// can account for them below.
+		named := strings.Count(regexstr, "(?P<")
+
+		if problem := swapProblem(regexstr); problem != "" {
 			// We rely on manually-added capture groups to associate a match
 			// with its replacement -- e.g.,
 			//
@@ -60,10 +95,17 @@ func NewSubstitution(cfg *core.Config, generic baseCheck) (Substitution, error)
 			//
 			// TODO: Should we change this? Perhaps by creating a map of regex
 			// to replacements?
+			cfg.Warnf("'%s' skips the swap entry '%s': it %s.", path, regexstr, problem)
 			continue
 		}
 		tokens += `(` + regexstr + `)|`
-		replacements = append(replacements, replacement)
+
+		group++
+		replacements[group] = replacement
+		if pos, found := rule.SwapPOS[regexstr]; found {
+			tags[group] = pos
+		}
+		group += named
 	}
 	regex = fmt.Sprintf(regex, strings.TrimRight(tokens, "|"))
 
@@ -74,15 +116,31 @@ func NewSubstitution(cfg *core.Config, generic baseCheck) (Substitution, error)
 
 	rule.pattern = re
 	rule.repl = replacements
+	rule.tags = tags
+	rule.captures = checkCaptures(cfg, rule.Definition, re, path)
 	return rule, nil
 }
 
+// swapProblem explains why the `swap` key `regexstr` can't be used, or
+// returns an empty string if it can.
+func swapProblem(regexstr string) string {
+	re, err := regexp.Compile(regexstr)
+	if err != nil {
+		return fmt.Sprintf("isn't a valid pattern (%s)", err)
+	}
+	for _, name := range re.SubexpNames()[1:] {
+		if name == "" {
+			return "contains a capturing group; use '(?:...)'"
+		}
+	}
+	return ""
+}
+
 // Run executes the the `substitution`-based rule.
 //
 // The rule looks for one pattern and then suggests a replacement.
 func (s Substitution) Run(txt string, f *core.File) []core.Alert {
 	alerts := []core.Alert{}
-	pos := false
 
 	// Leave early if we can to avoid calling `FindAllStringSubmatchIndex`
 	// unnecessarily.
@@ -93,18 +151,23 @@ func (s Substitution) Run(txt string, f *core.File) []core.Alert {
 	for _, submat := range s.pattern.FindAllStringSubmatchIndex(txt, -1) {
 		for idx, mat := range submat {
 			if mat != -1 && idx > 0 && idx%2 == 0 {
-				loc := []int{mat, submat[idx+1]}
 				// Based on the current capture group (`idx`), we can determine
-				// the associated replacement string by using the `repl` slice:
-				expected := s.repl[(idx/2)-1]
+				// the associated replacement string by using the `repl` map:
+				expected, found := s.repl[idx/2]
+				if !found {
+					// This is a named group within one of our patterns.
+					continue
+				}
+				loc := []int{mat, submat[idx+1]}
 				observed := strings.TrimSpace(txt[loc[0]:loc[1]])
 				if !matchToken(expected, observed, s.Ignorecase) {
-					if s.POS != "" {
+					pos := false
+					if tag := s.posFor(idx / 2); tag != "" {
 						// If we're given a POS pattern, check that it matches.
 						//
 						// If it doesn't match, the alert doesn't get added to
 						// a File (i.e., `hide` == true).
-						pos = core.CheckPOS(loc, s.POS, txt)
+						pos = core.CheckPOS(loc, tag, txt, s.tagger)
 					}
 					action := s.Fields().Action
 					if action.Name == "replace" && len(action.Params) == 0 {
@@ -120,8 +183,14 @@ func (s Substitution) Run(txt string, f *core.File) []core.Alert {
 						Link: s.Link, Hide: pos, Match: observed,
 						Action: s.Action}
 
-					a.Message, a.Description = formatMessages(s.Message,
-						s.Description, expected, observed)
+					msg, desc := s.Message, s.Description
+					if s.captures {
+						msg = interpolate(msg, s.pattern, txt, submat)
+						desc = interpolate(desc, s.pattern, txt, submat)
+					}
+
+					a.Message, a.Description = formatMessages(msg, desc,
+						expected, observed)
 
 					alerts = append(alerts, a)
 				}
@@ -132,6 +201,15 @@ func (s Substitution) Run(txt string, f *core.File) []core.Alert {
 	return alerts
 }
 
+// posFor returns the POS pattern for the entry matched by capture group
+// `group`: its own (from `swappos`), if any, or the rule's.
+func (s Substitution) posFor(group int) string {
+	if tag, found := s.tags[group]; found {
+		return tag
+	}
+	return s.POS
+}
+
 // Fields provides access to the internal rule definition.
 func (s Substitution) Fields() Definition {
 	return s.Definition
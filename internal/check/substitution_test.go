@@ -0,0 +1,51 @@
+package check
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+var swapTests = []struct {
+	key     string
+	problem string
+}{
+	{`utilize`, ""},
+	{`(?:e-mail|email)`, ""},
+	{`(?P<verb>use) of`, ""},
+	{`foo \(bar\)`, ""},
+	{`(?:a)\(b\)`, ""},
+	{`(e-mail|email)`, "capturing group"},
+	{`(?:a)(b)`, "capturing group"},
+	{`foo(`, "valid pattern"},
+}
+
+func TestSwapProblem(t *testing.T) {
+	for _, tt := range swapTests {
+		problem := swapProblem(tt.key)
+		if (tt.problem == "") != (problem == "") || !strings.Contains(problem, tt.problem) {
+			t.Errorf("%s: expected %q, got %q", tt.key, tt.problem, problem)
+		}
+	}
+}
+
+func TestSubstitutionSkips(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{Quiet: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	swap := map[string]interface{}{}
+	for _, tt := range swapTests {
+		swap[tt.key] = "replacement"
+	}
+
+	rule, err := NewSubstitution(cfg, baseCheck{
+		"name": "A.Swap", "path": "", "message": "Use '%s'.", "swap": swap})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(rule.repl) != 5 {
+		t.Errorf("expected 5 usable entries, got %v", rule.repl)
+	}
+}
@@ -23,23 +23,45 @@ func makeExceptions(ignore []string) *regexp.Regexp {
 	return regexp.MustCompile(s + `[\p{N}\p{L}*]+[^\s]*`)
 }
 
-func lower(s string, ignore []string, re *regexp.Regexp) bool {
-	return s == strings.ToLower(s) || core.StringInSlice(s, ignore)
+// A caseCheck reports whether or not `s` has the expected capitalization,
+// along with the locations of the individual words that don't.
+type caseCheck func(s string, ignore []string, re *regexp.Regexp) (bool, [][]int)
+
+func lower(s string, ignore []string, re *regexp.Regexp) (bool, [][]int) {
+	ok := s == strings.ToLower(s) || core.StringInSlice(s, ignore)
+	return ok, caseViolations(s, ignore, strings.ToLower)
 }
 
-func upper(s string, ignore []string, re *regexp.Regexp) bool {
-	return s == strings.ToUpper(s) || core.StringInSlice(s, ignore)
+func upper(s string, ignore []string, re *regexp.Regexp) (bool, [][]int) {
+	ok := s == strings.ToUpper(s) || core.StringInSlice(s, ignore)
+	return ok, caseViolations(s, ignore, strings.ToUpper)
 }
 
-func title(s string, ignore []string, except *regexp.Regexp, tc *transform.TitleConverter) bool {
+// caseViolations finds the words in `s` that aren't equal to their
+// `convert`ed form.
+func caseViolations(s string, ignore []string, convert func(string) string) [][]int {
+	violations := [][]int{}
+	for _, loc := range makeExceptions(ignore).FindAllStringIndex(s, -1) {
+		word := s[loc[0]:loc[1]]
+		if word != convert(word) && !core.StringInSlice(word, ignore) {
+			violations = append(violations, loc)
+		}
+	}
+	return violations
+}
+
+func title(s string, ignore []string, except *regexp.Regexp, tc *transform.TitleConverter) (bool, [][]int) {
 	count := 0.0
 	words := 0.0
 
+	violations := [][]int{}
+
 	re := makeExceptions(ignore)
 	expected := re.FindAllString(tc.Title(s), -1)
 
 	extent := len(expected)
-	for i, word := range re.FindAllString(s, -1) {
+	for i, loc := range re.FindAllStringIndex(s, -1) {
+		word := s[loc[0]:loc[1]]
 		if i >= extent {
 			// TODO: Look into this more.
 			//
@@ -56,11 +78,13 @@ func title(s string, ignore []string, except *regexp.Regexp, tc *transform.Title
 			count++
 		} else if word == strings.ToUpper(word) {
 			count++
+		} else {
+			violations = append(violations, loc)
 		}
 		words++
 	}
 
-	return (count / words) > 0.8
+	return (count / words) > 0.8, violations
 }
 
 func hasAnySuffix(s string, suffixes []string) bool {
@@ -72,17 +96,22 @@ func hasAnySuffix(s string, suffixes []string) bool {
 	return false
 }
 
-func sentence(s string, ignore []string, indicators []string, except *regexp.Regexp) bool {
+func sentence(s string, ignore []string, indicators []string, except *regexp.Regexp) (bool, [][]int) {
 	count := 0.0
 	words := 0.0
 
+	violations := [][]int{}
+
 	re := makeExceptions(ignore)
 
-	tokens := re.FindAllString(strings.TrimRight(s, "?!.:"), -1)
-	for i, w := range tokens {
+	trimmed := strings.TrimRight(s, "?!.:")
+	locs := re.FindAllStringIndex(trimmed, -1)
+	for i, loc := range locs {
+		w := trimmed[loc[0]:loc[1]]
+
 		prev := ""
 		if i-1 >= 0 {
-			prev = tokens[i-1]
+			prev = trimmed[locs[i-1][0]:locs[i-1][1]]
 		}
 
 		if strings.Contains(w, "-") {
@@ -99,17 +128,87 @@ func sentence(s string, ignore []string, indicators []string, except *regexp.Reg
 		if w == strings.ToUpper(w) || hasAnySuffix(prev, indicators) || isMatch(except, w) {
 			count++
 		} else if i == 0 && w != strings.Title(strings.ToLower(w)) {
-			return false
+			return false, [][]int{loc}
 		} else if i == 0 || w == strings.ToLower(w) {
 			count++
+		} else {
+			violations = append(violations, loc)
 		}
 		words++
 	}
 
-	return (count / words) > 0.8
+	return (count / words) > 0.8, violations
+}
+
+// toCase rewrites each word of `s` using `convert`, which receives the word's
+// index and its preceding word.
+//
+// Exceptions are always written in the form given in `ignore`, and (if
+// `acronyms` is true) all-uppercase words are left as-is.
+func toCase(s string, ignore []string, acronyms bool, convert func(i int, word, prev string) string) string {
+	var buf strings.Builder
+
+	prev, cursor := "", 0
+	for i, loc := range makeExceptions(ignore).FindAllStringIndex(s, -1) {
+		word := s[loc[0]:loc[1]]
+		buf.WriteString(s[cursor:loc[0]])
+		if exception := findException(word, ignore); exception != "" {
+			buf.WriteString(exception)
+		} else if acronyms && len(word) > 1 && word == strings.ToUpper(word) {
+			buf.WriteString(word)
+		} else {
+			buf.WriteString(convert(i, word, prev))
+		}
+		prev, cursor = word, loc[1]
+	}
+	buf.WriteString(s[cursor:])
+
+	return buf.String()
+}
+
+// findException returns the form of `word` given in `ignore`, if any. An
+// exception that may be written in any case (see `vocabException`) leaves
+// `word` as it is.
+func findException(word string, ignore []string) string {
+	for _, exception := range ignore {
+		if strings.EqualFold(word, exception) {
+			return exception
+		} else if term := strings.TrimPrefix(exception, anyCase); term != exception {
+			if strings.EqualFold(word, strings.TrimSuffix(term, ")")) {
+				return word
+			}
+		}
+	}
+	return ""
+}
+
+func toTitle(s string, ignore []string, tc *transform.TitleConverter) string {
+	expected := makeExceptions(ignore).FindAllString(tc.Title(s), -1)
+	return toCase(s, ignore, true, func(i int, word, prev string) string {
+		if i < len(expected) && strings.EqualFold(word, expected[i]) {
+			return expected[i]
+		}
+		return word
+	})
+}
+
+func toSentence(s string, ignore, indicators []string) string {
+	return toCase(s, ignore, true, func(i int, word, prev string) string {
+		if i == 0 {
+			return strings.Title(strings.ToLower(word))
+		} else if hasAnySuffix(prev, indicators) {
+			return word
+		}
+		return strings.ToLower(word)
+	})
+}
+
+var varToFix = map[string]func(string) string{
+	"$lower": strings.ToLower,
+	"$upper": strings.ToUpper,
 }
 
-var varToFunc = map[string]func(string, []string, *regexp.Regexp) bool{
+var varToFunc = map[string]caseCheck{
 	"$lower": lower,
 	"$upper": upper,
 }
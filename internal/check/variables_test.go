@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/jdkato/prose/transform"
 	"github.com/jdkato/regexp"
 )
 
@@ -56,9 +57,57 @@ func TestSentence(t *testing.T) {
 			r = regexp.MustCompile(regex)
 		}
 
-		s := sentence(h.heading, h.exceptions, h.indicators, r)
+		s, _ := sentence(h.heading, h.exceptions, h.indicators, r)
 		if s != h.match {
 			t.Errorf("expected = %v, got = %v (%s)", h.match, s, h.heading)
 		}
 	}
 }
+
+func TestSentenceViolations(t *testing.T) {
+	heading := "Creating a New Connection"
+
+	ok, violations := sentence(heading, nil, nil, nil)
+	if ok {
+		t.Fatalf("expected '%s' to fail", heading)
+	} else if len(violations) != 2 {
+		t.Fatalf("expected 2 violations, got %v", violations)
+	}
+
+	loc := violations[0]
+	if word := heading[loc[0]:loc[1]]; word != "New" {
+		t.Errorf("expected the first violation to be 'New', got '%s'", word)
+	}
+}
+
+func TestToCase(t *testing.T) {
+	tc := transform.NewTitleConverter(transform.APStyle)
+
+	cases := []struct {
+		fix      func(string) string
+		heading  string
+		expected string
+	}{
+		{
+			func(s string) string { return toTitle(s, []string{"API"}, tc) },
+			"introduction to the api",
+			"Introduction to the API",
+		},
+		{
+			func(s string) string { return toSentence(s, []string{"Vale"}, []string{":"}) },
+			"Using VALE With The CLI: Getting Started",
+			"Using Vale with the CLI: Getting started",
+		},
+		{
+			func(s string) string { return toSentence(s, []string{"Vale"}, nil) },
+			"configuring vale",
+			"Configuring Vale",
+		},
+	}
+
+	for _, c := range cases {
+		if fixed := c.fix(c.heading); fixed != c.expected {
+			t.Errorf("expected = %q, got = %q", c.expected, fixed)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package check
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+// window restricts a rule to the beginning of a file -- either its first
+// `lines` lines or its first paragraph.
+type window struct {
+	lines     int
+	paragraph bool
+}
+
+// makeWindow reads the `within` key of a rule definition, which may be either
+// `first_paragraph` or a map of the form `{lines: N}`.
+//
+// Since a window is defined in terms of the source file, rules that use one
+// are always run against the `raw.file` scope: any other scope is an error.
+func makeWindow(generic baseCheck, path string) (*window, error) {
+	val, ok := generic["within"]
+	if !ok || val == nil {
+		return nil, nil
+	}
+	delete(generic, "within")
+
+	if scope, ok := generic["scope"].(string); ok && scope != "raw" && scope != "raw.file" {
+		return nil, core.NewE201FromTarget(fmt.Sprintf(
+			"'within' applies to the raw file, so it can't be used with the '%s' scope.", scope),
+			"scope", path)
+	}
+
+	msg := "'within' must be 'first_paragraph' or a map of the form {lines: N}."
+	switch v := val.(type) {
+	case string:
+		if v == "first_paragraph" {
+			generic["scope"] = "raw.file"
+			return &window{paragraph: true}, nil
+		}
+	case map[interface{}]interface{}:
+		if n, ok := v["lines"].(int); ok && n > 0 {
+			generic["scope"] = "raw.file"
+			return &window{lines: n}, nil
+		}
+	case map[string]interface{}:
+		if n, ok := v["lines"].(int); ok && n > 0 {
+			generic["scope"] = "raw.file"
+			return &window{lines: n}, nil
+		}
+	}
+
+	return nil, core.NewE201FromTarget(msg, "within", path)
+}
+
+// extent returns the byte offset in `txt` at which the window ends.
+//
+// Files that are shorter than the window are included in their entirety.
+func (w *window) extent(txt string) int {
+	if w == nil {
+		return len(txt)
+	}
+
+	offset := 0
+	inParagraph := false
+	for i, line := range strings.SplitAfter(txt, "\n") {
+		blank := strings.TrimSpace(line) == ""
+		if w.paragraph && blank && inParagraph {
+			return offset
+		} else if !w.paragraph && i == w.lines {
+			return offset
+		}
+		inParagraph = inParagraph || !blank
+		offset += len(line)
+	}
+
+	return len(txt)
+}
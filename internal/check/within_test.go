@@ -0,0 +1,108 @@
+package check
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+var withinTests = []struct {
+	within interface{}
+	text   string
+	alerts int
+}{
+	{map[string]interface{}{"lines": 2}, "TODO\nTODO\nTODO\n", 2},
+	{map[string]interface{}{"lines": 10}, "TODO\nTODO\n", 2},
+	{map[string]interface{}{"lines": 1}, "", 0},
+	{"first_paragraph", "\n\nTODO\nTODO\n\nTODO\n", 2},
+	{"first_paragraph", "TODO", 1},
+}
+
+func TestExistenceWithin(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := core.NewFile("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range withinTests {
+		def := baseCheck{"tokens": []string{"TODO"}, "within": tt.within}
+
+		rule, err := NewExistence(cfg, def)
+		if err != nil {
+			t.Fatal(err)
+		} else if rule.Scope != "raw.file" {
+			t.Errorf("expected scope 'raw.file', got '%s'", rule.Scope)
+		}
+
+		alerts := rule.Run(tt.text, file)
+		if len(alerts) != tt.alerts {
+			t.Errorf("%v: expected %d alerts, got %d", tt.within, tt.alerts, len(alerts))
+		}
+	}
+}
+
+func TestOccurrenceWithin(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := core.NewFile("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def := baseCheck{
+		"path":   "",
+		"token":  "Vale",
+		"min":    1,
+		"max":    10,
+		"within": "first_paragraph",
+	}
+
+	rule, err := NewOccurrence(cfg, def)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The token is present, but not in the first paragraph.
+	alerts := rule.Run("Intro.\n\nVale is great.\n", file)
+	if len(alerts) != 1 {
+		t.Fatalf("expected one alert, got %v", alerts)
+	} else if alerts[0].Span[0] != 1 || alerts[0].Match != "" {
+		t.Errorf("expected the alert to be anchored at the start, got %v", alerts[0])
+	}
+
+	// A file that's shorter than the window.
+	if alerts = rule.Run("Vale", file); len(alerts) != 0 {
+		t.Errorf("expected no alerts, got %v", alerts)
+	}
+}
+
+func TestWithinScope(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for scope, valid := range map[string]bool{"raw": true, "raw.file": true, "heading": false, "text": false} {
+		def := baseCheck{"tokens": []string{"TODO"}, "within": "first_paragraph", "scope": scope}
+
+		rule, err := NewExistence(cfg, def)
+		if !valid {
+			if err == nil || !strings.Contains(err.Error(), "'"+scope+"' scope") {
+				t.Errorf("%s: expected a scope conflict, got %v", scope, err)
+			}
+		} else if err != nil {
+			t.Errorf("%s: unexpected error: %v", scope, err)
+		} else if rule.Scope != "raw.file" {
+			t.Errorf("%s: expected scope 'raw.file', got '%s'", scope, rule.Scope)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/errata-ai/vale/v2/internal/rule"
+	"github.com/errata-ai/vale/v2/pkg/spell"
+	"github.com/olekukonko/tablewriter"
+)
+
+// A builtinAsset is a rule or dictionary file that's embedded in our binary.
+type builtinAsset struct {
+	Name  string // e.g., `Vale.Spelling` or `en_US-web.dic`
+	Kind  string // `rule` or `dictionary`
+	Size  int
+	asset string // the name given to `Asset`
+	load  func(string) ([]byte, error)
+}
+
+// defaultDictionary is the name of our built-in Hunspell dictionary, which
+// `--extract` copies as a pair of `.dic` and `.aff` files.
+const defaultDictionary = "en_US-web"
+
+// builtinAssets returns every embedded asset, sorted by kind and name.
+func builtinAssets() ([]builtinAsset, error) {
+	assets := []builtinAsset{}
+	for _, src := range []struct {
+		kind  string
+		names []string
+		load  func(string) ([]byte, error)
+	}{
+		{"rule", rule.AssetNames(), rule.Asset},
+		{"dictionary", spell.AssetNames(), spell.Asset},
+	} {
+		for _, asset := range src.names {
+			b, err := src.load(asset)
+			if err != nil {
+				return assets, err
+			}
+
+			name := path.Base(asset)
+			if src.kind == "rule" {
+				// `rule/Vale/Spelling.yml` -> `Vale.Spelling`.
+				name = path.Base(path.Dir(asset)) + "." + strings.TrimSuffix(name, ".yml")
+			}
+			assets = append(assets, builtinAsset{
+				Name: name, Kind: src.kind, Size: len(b), asset: asset, load: src.load})
+		}
+	}
+
+	sort.SliceStable(assets, func(i, j int) bool {
+		if assets[i].Kind != assets[j].Kind {
+			return assets[i].Kind == "rule"
+		}
+		return assets[i].Name < assets[j].Name
+	})
+
+	return assets, nil
+}
+
+// listBuiltins lists the rules and dictionaries embedded in our binary or,
+// with `--extract`, copies one of them into `--to` (by default, our
+// `StylesPath`) for customization.
+//
+// An extracted rule (e.g., `Vale.Repetition`) is written to
+// `Vale/Repetition.yml`, where it takes the place of the built-in one; the
+// dictionary (`en_US-web`) is written as its `.dic` and `.aff` files, to be
+// referenced by a `spelling` rule's `dic` and `aff`.
+//
+// $ vale ls-builtin --extract Vale.Repetition
+func listBuiltins(args []string, cfg *core.Config) error {
+	var extract, to string
+
+	fs := flag.NewFlagSet("ls-builtin", flag.ContinueOnError)
+	fs.StringVar(&extract, "extract", "", "the rule (Style.Rule) or dictionary to extract")
+	fs.StringVar(&to, "to", "", "the directory to extract into (defaults to StylesPath)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() > 0 {
+		return core.NewE100("ls-builtin", fmt.Errorf("unexpected argument '%s'", fs.Arg(0)))
+	}
+
+	assets, err := builtinAssets()
+	if err != nil {
+		return err
+	} else if extract != "" {
+		return extractBuiltin(assets, extract, to, cfg)
+	}
+
+	if Flags.Output == "JSON" {
+		return core.PrintJSON(assets)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Kind", "Size"})
+	table.SetAutoWrapText(false)
+	for _, a := range assets {
+		table.Append([]string{a.Name, a.Kind, strconv.Itoa(a.Size)})
+	}
+	table.Render()
+
+	return nil
+}
+
+// extractBuiltin writes the built-in rule or dictionary `name` into `dir`,
+// refusing to overwrite an existing file.
+func extractBuiltin(assets []builtinAsset, name, dir string, cfg *core.Config) error {
+	if dir == "" {
+		dir = cfg.StylesPath
+	}
+	if dir == "" {
+		return core.NewE100("ls-builtin", errors.New(
+			"no StylesPath to extract into; use --to"))
+	}
+
+	targets := map[string]string{}
+	for _, a := range assets {
+		if a.Kind == "rule" && a.Name == name {
+			parts := strings.SplitN(a.Name, ".", 2)
+			targets[a.asset] = filepath.Join(dir, parts[0], parts[1]+".yml")
+		} else if a.Kind == "dictionary" && strings.TrimSuffix(a.Name, path.Ext(a.Name)) == name {
+			targets[a.asset] = filepath.Join(dir, a.Name)
+		}
+	}
+
+	if len(targets) == 0 {
+		return core.NewE100("ls-builtin", fmt.Errorf(
+			"'%s' isn't a built-in rule (Style.Rule) or dictionary ('%s')", name, defaultDictionary))
+	}
+	for _, target := range targets {
+		if core.FileExists(target) {
+			return core.NewE100("ls-builtin", fmt.Errorf("'%s' already exists", target))
+		}
+	}
+
+	for _, a := range assets {
+		target, ok := targets[a.asset]
+		if !ok {
+			continue
+		}
+
+		b, err := a.load(a.asset)
+		if err != nil {
+			return err
+		} else if a.Kind == "rule" {
+			b = append([]byte(fmt.Sprintf(
+				"# Extracted from the built-in '%s', which this file replaces.\n", a.Name)), b...)
+		}
+
+		if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		} else if err = core.WriteFileAtomic(target, b, 0644); err != nil {
+			return err
+		}
+		fmt.Println(target)
+	}
+
+	return nil
+}
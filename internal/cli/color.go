@@ -3,24 +3,47 @@ package cli
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/errata-ai/vale/v2/internal/core"
 	"github.com/logrusorgru/aurora/v3"
+	"github.com/mattn/go-runewidth"
 	"github.com/olekukonko/tablewriter"
 )
 
-// PrintVerboseAlerts prints Alerts in verbose format.
-func PrintVerboseAlerts(linted []*core.File, wrap bool) bool {
-	var errors, warnings, suggestions int
+// PrintVerboseAlerts prints Alerts in verbose format, along with `context`
+// lines of source before and after each one and, if `explain` is true, a
+// footer that explains any alerts that weren't shown (see `printRunReport`).
+//
+// Only the first `max` alerts are printed, if it's positive (see
+// `--max-problems`), followed by a count of the rest. Its totals include
+// these, as well as the alerts dropped by `--sample`.
+func PrintVerboseAlerts(linted []*core.File, wrap bool, context int, explain bool, max int) bool {
+	var errors, warnings, suggestions, escalated, sampled int
 	var e, w, s int
 	var symbol string
 
+	shown := alertCap{max: max}
 	for _, f := range linted {
-		e, w, s = printVerboseAlert(f, wrap)
+		e, w, s = printVerboseAlert(f, wrap, context, &shown)
 		errors += e
 		warnings += w
 		suggestions += s
+		for _, n := range f.Escalated {
+			escalated += n
+		}
+		for _, levels := range f.Sampled {
+			errors += levels["error"]
+			warnings += levels["warning"]
+			suggestions += levels["suggestion"]
+			sampled += levels["error"] + levels["warning"] + levels["suggestion"]
+		}
+	}
+
+	if shown.hidden > 0 {
+		fmt.Printf("\n%s\n", truncationNote(shown.hidden, max))
 	}
 
 	etotal := fmt.Sprintf("%d %s", errors, pluralize("error", errors))
@@ -33,28 +56,117 @@ func PrintVerboseAlerts(linted []*core.File, wrap bool) bool {
 		symbol = "\u2714"
 	}
 
+	// Alerts raised by their rules' `escalate` thresholds are noted, since
+	// their levels differ from those of the rules themselves.
+	note := ""
+	if escalated > 0 {
+		note = fmt.Sprintf(" (%d escalated by match count)", escalated)
+	}
+	if sampled > 0 {
+		note += fmt.Sprintf(" (%d not shown by --sample)", sampled)
+	}
+
 	n := len(linted)
 	if n == 1 && strings.HasPrefix(linted[0].Path, "stdin") {
-		fmt.Printf("%s %s, %s and %s in %s.\n", symbol,
+		fmt.Printf("%s %s, %s and %s in %s%s.\n", symbol,
 			aurora.Green(etotal), aurora.Yellow(wtotal),
-			aurora.Blue(stotal), "stdin")
+			aurora.Blue(stotal), "stdin", note)
 	} else {
-		fmt.Printf("%s %s, %s and %s in %d %s.\n", symbol,
+		fmt.Printf("%s %s, %s and %s in %d %s%s.\n", symbol,
 			aurora.Red(etotal), aurora.Yellow(wtotal),
-			aurora.Blue(stotal), n, pluralize("file", n))
+			aurora.Blue(stotal), n, pluralize("file", n), note)
+	}
+
+	if explain {
+		printRunReport(core.ExplainRun(linted))
 	}
 
 	return errors != 0
 }
 
+// printRunReport lists the rules that were limited, were escalated, had
+// their levels changed, or were turned off by comments during this run.
+func printRunReport(report core.RunReport) {
+	fmt.Printf("\n%s\n", aurora.Bold("Run details:"))
+	if report.Empty() {
+		fmt.Println("  No limits, escalations, level overrides, or comments applied.")
+		return
+	}
+
+	if len(report.Limited) > 0 {
+		fmt.Println("  Limited (alerts hidden by a rule's 'limit'):")
+		for _, rule := range sortedKeys(report.Limited) {
+			n := report.Limited[rule]
+			fmt.Printf("    %s: %d %s hidden\n", rule, n, pluralize("alert", n))
+		}
+	}
+
+	if len(report.Escalated) > 0 {
+		fmt.Println("  Escalated (alerts raised by a rule's 'escalate'):")
+		for _, rule := range sortedKeys(report.Escalated) {
+			n := report.Escalated[rule]
+			fmt.Printf("    %s: %d %s raised\n", rule, n, pluralize("alert", n))
+		}
+	}
+
+	if len(report.Overridden) > 0 {
+		fmt.Println("  Levels changed by configuration:")
+		for _, o := range report.Overridden {
+			fmt.Printf("    %s = %s (%s)\n", o.Rule, o.Level, o.Source)
+		}
+	}
+
+	if len(report.Disabled) > 0 {
+		fmt.Println("  Turned off by comments:")
+		rules := []string{}
+		for rule := range report.Disabled {
+			rules = append(rules, rule)
+		}
+		sort.Strings(rules)
+		for _, rule := range rules {
+			name := rule
+			if rule == "off" {
+				name = "all rules ('vale off')"
+			}
+			fmt.Printf("    %s: %s\n", name, strings.Join(report.Disabled[rule], ", "))
+		}
+	}
+}
+
+// sortedKeys returns the keys of `m` in sorted order.
+func sortedKeys(m map[string]int) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // printVerboseAlert includes an alert's line, column, level, and message.
-func printVerboseAlert(f *core.File, wrap bool) (int, int, int) {
+//
+// It returns the number of f's errors, warnings, and suggestions, including
+// those that `shown` didn't allow to be printed.
+func printVerboseAlert(f *core.File, wrap bool, context int, shown *alertCap) (int, int, int) {
 	var loc, level string
 	var errors, warnings, notifications int
 
-	alerts := f.SortedAlerts()
+	alerts := []core.Alert{}
+	for _, a := range f.SortedAlerts() {
+		switch a.Severity {
+		case "suggestion":
+			notifications++
+		case "warning":
+			warnings++
+		default:
+			errors++
+		}
+		if shown.allow() {
+			alerts = append(alerts, a)
+		}
+	}
 	if len(alerts) == 0 {
-		return 0, 0, 0
+		return errors, warnings, notifications
 	}
 
 	table := tablewriter.NewWriter(os.Stdout)
@@ -67,17 +179,104 @@ func printVerboseAlert(f *core.File, wrap bool) (int, int, int) {
 	for _, a := range alerts {
 		if a.Severity == "suggestion" {
 			level = aurora.Blue(a.Severity).String()
-			notifications++
 		} else if a.Severity == "warning" {
 			level = aurora.Yellow(a.Severity).String()
-			warnings++
 		} else {
 			level = aurora.Red(a.Severity).String()
-			errors++
 		}
 		loc = fmt.Sprintf("%d:%d", a.Line, a.Span[0])
 		table.Append([]string{loc, level, a.Message, a.Check})
+		if context > 0 {
+			// Each alert is followed by its source, so we can't align
+			// them all in a single table.
+			table.Render()
+			table.ClearRows()
+			printContext(f, a, context)
+		}
+	}
+	if context == 0 {
+		table.Render()
 	}
-	table.Render()
 	return errors, warnings, notifications
 }
+
+// printContext prints the line of `a` along with up to `n` lines before and
+// after it, highlighting (and underlining) its span.
+func printContext(f *core.File, a core.Alert, n int) {
+	lines := f.Lines
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		// The file ends with a newline.
+		lines = lines[:len(lines)-1]
+	}
+	if a.Line < 1 || a.Line > len(lines) {
+		return
+	}
+
+	first, last := a.Line-n, a.Line+n
+	if first < 1 {
+		first = 1
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+	width := len(strconv.Itoa(last))
+
+	for i := first; i <= last; i++ {
+		text := strings.TrimRight(lines[i-1], "\r\n")
+		if i != a.Line {
+			fmt.Printf("  %s %s\n", aurora.Faint(fmt.Sprintf("%*d |", width, i)), text)
+			continue
+		}
+
+		before, match, after := splitSpan(text, a.Span)
+		fmt.Printf("  %s %s%s%s\n",
+			aurora.Faint(fmt.Sprintf("%*d |", width, i)),
+			before, aurora.Bold(aurora.Red(match)), after)
+		fmt.Printf("  %s %s%s\n",
+			aurora.Faint(fmt.Sprintf("%*s |", width, "")),
+			padding(before), aurora.Red(carets(match)))
+	}
+	fmt.Println()
+}
+
+// splitSpan splits `line` into the text before, within, and after `span`,
+// whose (1-based, inclusive) bounds are given in runes.
+func splitSpan(line string, span []int) (string, string, string) {
+	runes := []rune(line)
+
+	start, end := span[0]-1, span[1]
+	if start < 0 {
+		start = 0
+	}
+	if end > len(runes) {
+		end = len(runes)
+	}
+	if start > end {
+		start = end
+	}
+
+	return string(runes[:start]), string(runes[start:end]), string(runes[end:])
+}
+
+// padding returns whitespace that occupies the same number of columns as
+// `text`, accounting for tabs and wide (e.g., CJK) characters.
+func padding(text string) string {
+	var sb strings.Builder
+	for _, r := range text {
+		if r == '\t' {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteString(strings.Repeat(" ", runewidth.RuneWidth(r)))
+		}
+	}
+	return sb.String()
+}
+
+// carets returns an underline for `text`, accounting for wide characters.
+func carets(text string) string {
+	width := runewidth.StringWidth(text)
+	if width < 1 {
+		width = 1
+	}
+	return strings.Repeat("^", width)
+}
@@ -1,37 +1,167 @@
 package cli
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/errata-ai/vale/v2/internal/lint"
+	"github.com/olekukonko/tablewriter"
 )
 
 var commandInfo = map[string]string{
-	"ls-config": "Print the current configuration to stdout and exit.",
+	"ls-config":    "Print the current configuration (and each setting's source) and exit.",
+	"nlp":          "Print the tagged tokens of each of a file's scopes.",
+	"lint-config":  "Check the current configuration for unknown settings, styles, and rules.",
+	"export-rules": "Write every loaded rule to a single YAML file (see --rules).",
+	"sync":         "Download and install the packages listed in Packages.",
+	"check-links":  "Report rules with missing, malformed, or (with --online) dead links.",
+	"ls-builtin":   "List the built-in rules and dictionaries, or --extract one to customize it.",
 }
 
 // Actions are the available CLI commands.
 var Actions = map[string]func(args []string, cfg *core.Config) error{
-	"ls-config": printConfig,
-	"dc":        printConfig,
-	"help":      printUsage,
+	"ls-config":    printConfig,
+	"dc":           printConfig,
+	"nlp":          printNLP,
+	"export-rules": exportRules,
+	"lint-config":  lintConfig,
+	"sync":         syncPackages,
+	"check-links":  checkLinks,
+	"ls-builtin":   listBuiltins,
+	"help":         printUsage,
 }
 
+// resolvedConfig is the output of `ls-config --output=JSON`.
+type resolvedConfig struct {
+	*core.Config
+
+	Vocab struct {
+		Accept []string
+		Reject []string
+	}
+}
+
+// printConfig prints the resolved configuration along with where each
+// setting came from: as a table or, with `--output=JSON`, as JSON.
+//
+// $ vale ls-config
 func printConfig(args []string, cfg *core.Config) error {
 	cfg, err := core.NewConfig(&Flags)
 	if err != nil {
-		ShowError(err, Flags.Output, os.Stderr)
+		return err
+	} else if err = core.From("ini", cfg); err != nil {
+		// NOTE: We don't print a partial configuration, since stdout is
+		// reserved for valid output.
+		return err
+	}
+
+	if Flags.Output == "JSON" {
+		resolved := resolvedConfig{Config: cfg}
+		resolved.Vocab.Accept = sortedTokens(cfg.AcceptedTokens)
+		resolved.Vocab.Reject = sortedTokens(cfg.RejectedTokens)
+
+		cfg.StylesPath = filepath.ToSlash(cfg.StylesPath)
+		return core.PrintJSON(resolved)
+	}
+
+	names := []string{}
+	for name := range cfg.Settings {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		// Core settings come before those in sections.
+		a, b := strings.HasPrefix(names[i], "["), strings.HasPrefix(names[j], "[")
+		if a != b {
+			return b
+		}
+		return names[i] < names[j]
+	})
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Setting", "Value", "Source"})
+	table.SetAutoWrapText(false)
+	for _, name := range names {
+		setting := cfg.Settings[name]
+		table.Append([]string{name, setting.Value, setting.Source})
+	}
+	table.Render()
+
+	return nil
+}
+
+func sortedTokens(tokens map[string]struct{}) []string {
+	list := []string{}
+	for token := range tokens {
+		list = append(list, token)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// lintConfig strictly validates the current configuration (see
+// `core.Config.Validate`), printing each problem found.
+//
+// $ vale lint-config
+func lintConfig(args []string, cfg *core.Config) error {
+	problems := cfg.Validate()
+	if len(problems) == 0 {
+		// We also build our rules' dictionaries, which are otherwise only
+		// loaded once a file needs them.
+		linter, err := lint.NewLinter(cfg)
+		if err == nil {
+			err = linter.Manager.Preload()
+		}
+		if err != nil {
+			problems = append(problems, err)
+		}
+	}
+	if Flags.Output == "JSON" {
+		data := []interface{}{}
+		for _, problem := range problems {
+			data = append(data, errorData(problem))
+		}
+		if err := core.PrintJSON(data); err != nil {
+			return err
+		}
+	} else {
+		for _, problem := range problems {
+			ShowError(problem, Flags.Output, os.Stdout)
+		}
+	}
+
+	if len(problems) > 0 {
+		return core.NewE100("lint-config", fmt.Errorf(
+			"found %d %s", len(problems), pluralize("problem", len(problems))))
+	}
+	return nil
+}
+
+// exportRules writes the effective definition of every loaded rule to a
+// single file, which may later be passed to `--rules`.
+//
+// $ vale export-rules frozen.yml
+func exportRules(args []string, cfg *core.Config) error {
+	if len(args) != 1 {
+		return core.NewE100("export-rules", errors.New("expected exactly one file"))
+	}
+
+	linter, err := lint.NewLinter(cfg)
+	if err != nil {
+		return err
 	}
 
-	err = core.From("ini", cfg)
+	b, err := linter.Manager.Export()
 	if err != nil {
-		ShowError(err, Flags.Output, os.Stderr)
+		return err
 	}
 
-	fmt.Println(cfg.String())
-	return err
+	return core.WriteFileAtomic(args[0], b, 0644)
 }
 
 func printUsage(args []string, cfg *core.Config) error {
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"fmt"
+	"os"
 	"sort"
 
 	"github.com/errata-ai/vale/v2/internal/core"
@@ -11,14 +13,59 @@ func PrintAlerts(linted []*core.File, config *core.Config) (bool, error) {
 	if config.Flags.Sorted {
 		sort.Sort(core.ByName(linted))
 	}
+	if config.Flags.Output != "CLI" && config.Flags.Output != "JSON" {
+		defer noteSampled(linted, config)
+	}
+	if config.Flags.Output != "CLI" {
+		defer noteTruncated(linted, config)
+	}
+
+	max := config.Flags.MaxProblems
 	switch config.Flags.Output {
 	case "JSON":
-		return PrintJSONAlerts(linted), nil
+		return PrintJSONAlerts(linted, config), nil
 	case "line":
-		return PrintLineAlerts(linted, config.Flags.Relative), nil
+		return PrintLineAlerts(linted, config.Flags.Relative, max), nil
+	case "HTML":
+		return PrintHTMLAlerts(linted, max)
 	case "CLI":
-		return PrintVerboseAlerts(linted, config.Flags.Wrap), nil
+		hasErrors := PrintVerboseAlerts(
+			linted, config.Flags.Wrap, config.Flags.Context, config.Flags.ExplainRun, max)
+		printSamples(os.Stdout, linted, config)
+		noteSkipped(linted, config)
+		return hasErrors, nil
 	default:
-		return PrintCustomAlerts(linted, config.Flags.Output)
+		return PrintCustomAlerts(linted, config.Flags.Output, max)
+	}
+}
+
+// noteTruncated tells the user (on stderr) how many alerts weren't printed
+// (see `--max-problems`), for the formats that have nowhere else to say so.
+func noteTruncated(linted []*core.File, config *core.Config) {
+	max := config.Flags.MaxProblems
+	if hidden := truncated(linted, max); hidden > 0 && !config.Flags.Quiet {
+		fmt.Fprintln(os.Stderr, truncationNote(hidden, max))
+	}
+}
+
+// noteSampled tells the user (on stderr) which rules' alerts were sampled,
+// for the formats that have nowhere else to say so.
+func noteSampled(linted []*core.File, config *core.Config) {
+	if !config.Flags.Quiet {
+		printSamples(os.Stderr, linted, config)
+	}
+}
+
+// noteSkipped tells the user (on stderr) how many scopes weren't linted.
+func noteSkipped(linted []*core.File, config *core.Config) {
+	skipped := 0
+	for _, f := range linted {
+		skipped += f.Skipped
+	}
+
+	if skipped > 0 && !config.Flags.Quiet {
+		fmt.Fprintf(os.Stderr,
+			"%d %s skipped (too large or not prose); use --debug for details.\n",
+			skipped, pluralize("scope", skipped))
 	}
 }
@@ -22,7 +22,10 @@ type Data struct {
 }
 
 // PrintCustomAlerts formats the given alerts using a user-defined template.
-func PrintCustomAlerts(linted []*core.File, path string) (bool, error) {
+//
+// Only the first `max` alerts are given to it, if it's positive (see
+// `--max-problems`).
+func PrintCustomAlerts(linted []*core.File, path string, max int) (bool, error) {
 	var alertCount int
 
 	b, err := ioutil.ReadFile(path)
@@ -37,19 +40,23 @@ func PrintCustomAlerts(linted []*core.File, path string) (bool, error) {
 	}
 
 	formatted := []ProcessedFile{}
+	shown := alertCap{max: max}
 	for _, f := range linted {
-		if len(f.Alerts) == 0 {
-			continue
-		}
+		alerts := []core.Alert{}
 		for _, a := range f.SortedAlerts() {
 			if a.Severity == "error" {
 				alertCount++
-				break
 			}
+			if shown.allow() {
+				alerts = append(alerts, a)
+			}
+		}
+		if len(alerts) == 0 {
+			continue
 		}
 		formatted = append(formatted, ProcessedFile{
 			Path:   f.Path,
-			Alerts: f.Alerts,
+			Alerts: alerts,
 		})
 	}
 
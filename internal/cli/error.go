@@ -57,6 +57,33 @@ func parseError(err error) (valeError, error) {
 	return parsed, nil
 }
 
+// errorData is the JSON representation of the given error.
+func errorData(err error) interface{} {
+	parsed, failed := parseError(err)
+	if failed != nil {
+		return struct {
+			Code string
+			Text string
+		}{
+			Text: core.StripANSI(err.Error()),
+			Code: "E100",
+		}
+	}
+	return struct {
+		Line int
+		Path string
+		Text string
+		Code string
+		Span int
+	}{
+		Line: parsed.line,
+		Path: parsed.path,
+		Text: parsed.text,
+		Code: parsed.code,
+		Span: parsed.span,
+	}
+}
+
 // ShowError displays the given error in the user-specified format.
 func ShowError(err error, style string, out io.Writer) {
 	parsed, failed := parseError(err)
@@ -64,33 +91,7 @@ func ShowError(err error, style string, out io.Writer) {
 	logger.SetOutput(out)
 	switch style {
 	case "JSON":
-		var data interface{}
-
-		if failed != nil {
-			data = struct {
-				Code string
-				Text string
-			}{
-				Text: core.StripANSI(err.Error()),
-				Code: "E100",
-			}
-		} else {
-			data = struct {
-				Line int
-				Path string
-				Text string
-				Code string
-				Span int
-			}{
-				Line: parsed.line,
-				Path: parsed.path,
-				Text: parsed.text,
-				Code: parsed.code,
-				Span: parsed.span,
-			}
-		}
-
-		logger.Println(getJSON(data))
+		logger.Println(getJSON(errorData(err)))
 	case "line":
 		var data string
 
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"flag"
+	"os"
 
 	"github.com/errata-ai/vale/v2/internal/core"
 	"github.com/mholt/archiver/v3"
@@ -10,31 +11,91 @@ import (
 // Flags are the user-defined CLI flags.
 var Flags core.CLIFlags
 
-var zip archiver.Unarchiver
+// zip unpacks the packages installed by `vale sync`.
+var zip archiver.Unarchiver = archiver.NewZip()
 
 func init() {
 	flag.StringVar(&Flags.Sources, "sources", "", "config files to load")
 	flag.StringVar(&Flags.Glob, "glob", "*",
 		`A glob pattern (e.g., --glob='*.{md,txt}).'`)
+	flag.StringVar(&Flags.Ignore, "ignore", "",
+		`Comma-separated glob patterns of files to skip (e.g., --ignore='CHANGELOG.md,vendor/**').`)
 	flag.StringVar(&Flags.Path, "config", "",
-		`A file path (e.g., --config='some/file/path/.vale.ini').`)
+		`A config file, or a directory to search for one (e.g., --config='some/file/path/.vale.ini').`)
 	flag.StringVar(&Flags.AlertLevel, "minAlertLevel", "",
 		`Lowest alert level to display (e.g., --minAlertLevel=error).`)
-	flag.StringVar(&Flags.Output, "output", "CLI",
-		`Output style ("line", "JSON", or a template file).`)
+	flag.StringVar(&Flags.Output, "output", envOr("VALE_OUTPUT", "CLI"),
+		`Output style ("line", "JSON", "HTML", or a template file).`)
 	flag.StringVar(&Flags.InExt, "ext", ".txt",
 		`Extension to associate with stdin (e.g., --ext=.md).`)
+	flag.StringVar(&Flags.Input, "input", "",
+		`Format of stdin: "json" reads an array of {path, ext, content} documents (e.g., --input=json).`)
+	flag.StringVar(&Flags.Diff, "diff", "",
+		`Only report alerts on lines changed since a Git ref, or by a patch file (e.g., --diff=origin/main).`)
+	flag.StringVar(&Flags.SortBy, "sort-by", "position",
+		`Order of each file's alerts ("position", "severity", or "check").`)
+	flag.StringVar(&Flags.Filter, "filter", "",
+		`Only run the rules matching an expression (e.g., --filter='.extends == "spelling"').`)
+	flag.StringVar(&Flags.Rules, "rules", "",
+		`A frozen rule set to use instead of StylesPath (e.g., --rules=frozen.yml).`)
+
+	flag.IntVar(&Flags.Context, "context", 0,
+		"Lines of source to show before and after each alert (e.g., --context=2).")
+	flag.IntVar(&Flags.Sample, "sample", 0,
+		"Only show this many randomly chosen alerts per rule, while still counting them all (e.g., --sample=100).")
+	flag.IntVar(&Flags.MaxProblems, "max-problems", 0,
+		"Only print this many alerts in total, followed by a count of the rest; the exit code still reflects them all (e.g., --max-problems=500).")
+	flag.Int64Var(&Flags.SampleSeed, "sample-seed", 0,
+		"The seed used to choose the alerts shown by --sample, to reproduce a run (by default, a random one).")
+	flag.IntVar(&Flags.JSONSchema, "json-schema", 1,
+		"Version of the JSON output's structure (2 adds a Meta header with our version and configuration).")
+
+	flag.BoolVar(&Flags.FailIfEmpty, "fail-if-empty", false,
+		"Exit with an error if no files were linted (e.g., a glob matched nothing).")
+
+	flag.BoolVar(&Flags.ExplainRun, "explain-run", false,
+		"List the limits, level overrides, and comments that changed which alerts were shown.")
+
+	flag.BoolVar(&Flags.Online, "online", false,
+		"Also request each rule's link in check-links (see --offline).")
+	flag.BoolVar(&Flags.Offline, "offline", false,
+		"Don't access the network, even if --online is set.")
 
 	flag.BoolVar(&Flags.Wrap, "no-wrap", false, "Don't wrap CLI output.")
 	flag.BoolVar(&Flags.NoExit, "no-exit", false,
 		"Don't return a nonzero exit code on errors.")
+	flag.StringVar(&Flags.SeverityExit, "severity-exit-code", "",
+		`The exit code caused by each alert level (e.g., --severity-exit-code="error=1,warning=3"); by default, only errors cause one (1).`)
+	flag.BoolVar(&Flags.Strict, "strict", false,
+		"Exit with code 2 if a rule fails (by default, it's reported and disabled).")
+	flag.BoolVar(&Flags.NoGlobal, "no-default-rules", false,
+		"Don't load the built-in styles (e.g., Vale); the rules made from each Vocab are still loaded.")
+	flag.BoolVar(&Flags.NoGlobal, "no-global", false,
+		"An alias of --no-default-rules.")
 	flag.BoolVar(&Flags.Local, "mode-compat", false,
 		"prioritize local Vale configurations")
 	flag.BoolVar(&Flags.Sorted, "sort", false,
 		"sort files by their name in output")
 	flag.BoolVar(&Flags.Normalize, "normalize", false,
 		"replace each path separator with a slash ('/')")
+	flag.BoolVar(&Flags.Fix, "fix", false,
+		"Apply fixes that have a single suggestion.")
 	flag.BoolVar(&Flags.Simple, "ignore-syntax", false,
 		"Lint all files line-by-line.")
+	flag.BoolVar(&Flags.SingleConfig, "single-config", false,
+		"Use one configuration for all files, rather than each file's nearest (implied by --config).")
+	flag.BoolVar(&Flags.Quiet, "quiet", false,
+		"Don't print warnings or notes to stderr (errors are still printed).")
 	flag.BoolVar(&Flags.Relative, "relative", false, "return relative paths")
+	flag.BoolVar(&Flags.Debug, "debug", false,
+		"Print debugging information to stderr.")
+}
+
+// envOr returns the value of the environment variable `key` or, if it's
+// unset, `fallback`.
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
 }
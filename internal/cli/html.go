@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"html/template"
+	"os"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+// htmlFile is a file's alerts, as shown in an HTML report.
+type htmlFile struct {
+	Path   string
+	Alerts []core.Alert
+	Counts map[string]int
+}
+
+// htmlReport holds the information exposed to the HTML report template.
+type htmlReport struct {
+	Files       []htmlFile
+	Totals      map[string]int
+	LintedTotal int
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Vale report</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 60em; color: #24292e; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { text-align: left; padding: .4em .6em; border-bottom: 1px solid #e1e4e8; vertical-align: top; }
+th { background: #f6f8fa; }
+code { font-size: 90%; }
+.error { color: #cb2431; }
+.warning { color: #b08800; }
+.suggestion { color: #0366d6; }
+.severity { font-weight: bold; white-space: nowrap; }
+</style>
+</head>
+<body>
+<h1>Vale report</h1>
+<h2>Summary</h2>
+<table>
+<tr><th>File</th><th class="error">Errors</th><th class="warning">Warnings</th><th class="suggestion">Suggestions</th></tr>
+{{- range .Files }}
+<tr><td><a href="#{{ .Path }}">{{ .Path }}</a></td><td>{{ index .Counts "error" }}</td><td>{{ index .Counts "warning" }}</td><td>{{ index .Counts "suggestion" }}</td></tr>
+{{- end }}
+<tr><th>{{ .LintedTotal }} file(s) linted</th><th>{{ index .Totals "error" }}</th><th>{{ index .Totals "warning" }}</th><th>{{ index .Totals "suggestion" }}</th></tr>
+</table>
+{{- range .Files }}
+<h2 id="{{ .Path }}">{{ .Path }}</h2>
+<table>
+<tr><th>Line</th><th>Severity</th><th>Message</th><th>Rule</th></tr>
+{{- range .Alerts }}
+<tr><td>{{ .Line }}:{{ index .Span 0 }}</td><td class="severity {{ .Severity }}">{{ .Severity }}</td><td>{{ .Message }}</td><td>{{ if .Link }}<a href="{{ .Link }}"><code>{{ .Check }}</code></a>{{ else }}<code>{{ .Check }}</code>{{ end }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+</body>
+</html>
+`))
+
+// PrintHTMLAlerts prints a self-contained HTML report of the given alerts,
+// grouped by file.
+//
+// Only the first `max` alerts are listed, if it's positive (see
+// `--max-problems`), but the totals include every alert.
+func PrintHTMLAlerts(linted []*core.File, max int) (bool, error) {
+	report := htmlReport{
+		Totals:      map[string]int{"error": 0, "warning": 0, "suggestion": 0},
+		LintedTotal: len(linted),
+	}
+
+	shown := alertCap{max: max}
+	for _, f := range linted {
+		file := htmlFile{
+			Path:   f.Path,
+			Counts: map[string]int{"error": 0, "warning": 0, "suggestion": 0},
+		}
+		for _, a := range f.SortedAlerts() {
+			report.Totals[a.Severity]++
+			if shown.allow() {
+				file.Alerts = append(file.Alerts, a)
+				file.Counts[a.Severity]++
+			}
+		}
+		if len(file.Alerts) > 0 {
+			report.Files = append(report.Files, file)
+		}
+	}
+
+	return report.Totals["error"] != 0, htmlTemplate.Execute(os.Stdout, report)
+}
@@ -21,6 +21,7 @@ var intro = fmt.Sprintf(`vale - A command-line linter for prose.
 %s:	%s
 	%s
 	%s
+	%s
 
 Vale is a syntax-aware linter for prose built with speed and extensibility in
 mind. It supports Markdown, AsciiDoc, reStructuredText, HTML, and more.
@@ -40,6 +41,7 @@ See %s for more setup information.`,
 	aurora.Faint("vale [options] [input...]"),
 	aurora.Faint("vale myfile.md myfile1.md mydir1"),
 	aurora.Faint("vale --output=JSON [input...]"),
+	aurora.Faint("cat myfile.md | vale --ext=.md -"),
 
 	aurora.Underline("https://github.com/errata-ai/styles"),
 
@@ -55,6 +57,18 @@ var info = fmt.Sprintf(`%s
 	intro,
 	aurora.Faint("vale --help"))
 
+// envInfo describes the environment variables we read, in the order they're
+// listed by `--help`.
+var envInfo = [][]string{
+	{"VALE_CONFIG", "The content of a configuration file (if --config isn't given)."},
+	{"VALE_CONFIG_PATH", "The path of a configuration file (if neither --config nor VALE_CONFIG is given)."},
+	{"VALE_STYLES_PATH", "Overrides the StylesPath setting."},
+	{"VALE_MIN_ALERT_LEVEL", "Overrides the MinAlertLevel setting."},
+	{"VALE_OUTPUT", "The default value of --output."},
+	{"GOMEMLIMIT", "A soft memory limit (e.g., 512MiB). Near it, files are linted one at a time, with a\n" +
+		"warning; the exit status is unaffected."},
+}
+
 var hidden = []string{
 	"mode-compat",
 	"mode-rev-compat",
@@ -95,6 +109,11 @@ func init() {
 			table.Append([]string{cmd, use})
 		}
 		table.Render()
+		table.ClearRows()
+
+		fmt.Println(aurora.Bold("Environment:"))
+		table.AppendBulk(envInfo)
+		table.Render()
 
 		os.Exit(0)
 	}
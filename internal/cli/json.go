@@ -2,22 +2,89 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/errata-ai/vale/v2/internal/core"
 )
 
+// Version is our version, which is set by `main` (see `main.version`).
+var Version = "master"
+
+// JSONSchema is the latest version of the structure of our JSON output (see
+// `--json-schema`):
+//
+//  1. a map of each file's path to its alerts; or
+//  2. that map (as `Alerts`), along with a `Meta` header (see `RunMeta`).
+const JSONSchema = 2
+
+// RunMeta describes the run that produced our JSON output: which version of
+// Vale was used, with which configuration, and when.
+type RunMeta struct {
+	Schema     int
+	Version    string
+	Config     string `json:",omitempty"`
+	StylesPath string `json:",omitempty"`
+	Time       string
+}
+
+// NewRunMeta returns the metadata for a run using `cfg`.
+func NewRunMeta(cfg *core.Config) RunMeta {
+	meta := RunMeta{
+		Schema:     JSONSchema,
+		Version:    Version,
+		StylesPath: cfg.StylesPath,
+		Time:       time.Now().UTC().Format(time.RFC3339),
+	}
+
+	if setting := cfg.Settings["ConfigPath"]; setting.Source == core.InlineConfig {
+		meta.Config = setting.Source
+	} else if core.FileExists(cfg.Flags.Path) {
+		meta.Config = cfg.Flags.Path
+	}
+
+	return meta
+}
+
 // PrintJSONAlerts prints Alerts in map[file.path][]Alert form.
-func PrintJSONAlerts(linted []*core.File) bool {
+//
+// If `explain` is true (see `--explain-run`), that map is instead given as
+// `Alerts`, alongside a `Run` summary (see `core.RunReport`). This is opt-in
+// since it changes the output's structure, as is the `Meta` header added by
+// `--json-schema=2` (see `RunMeta`) and the `Sample` added by `--sample` (see
+// `SampleMeta`).
+//
+// Only the first `--max-problems` alerts are included, if it's set.
+func PrintJSONAlerts(linted []*core.File, config *core.Config) bool {
 	alertCount := 0
 	formatted := map[string][]core.Alert{}
+	shown := alertCap{max: config.Flags.MaxProblems}
 	for _, f := range linted {
 		for _, a := range f.SortedAlerts() {
 			if a.Severity == "error" {
 				alertCount++
 			}
-			formatted[f.Path] = append(formatted[f.Path], a)
+			if shown.allow() {
+				formatted[f.Path] = append(formatted[f.Path], a)
+			}
+		}
+	}
+
+	explain := config.Flags.ExplainRun
+	if explain || config.Flags.JSONSchema > 1 || config.Flags.Sample > 0 {
+		data := map[string]interface{}{"Alerts": formatted}
+		if explain {
+			data["Run"] = core.ExplainRun(linted)
+		}
+		if config.Flags.JSONSchema > 1 {
+			data["Meta"] = NewRunMeta(config)
 		}
+		if config.Flags.Sample > 0 {
+			data["Sample"] = NewSampleMeta(linted, config)
+		}
+		fmt.Println(getJSON(data))
+	} else {
+		fmt.Println(getJSON(formatted))
 	}
-	fmt.Println(getJSON(formatted))
+
 	return alertCount != 0
 }
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/errata-ai/vale/v2/internal/check"
+	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/errata-ai/vale/v2/internal/lint"
+	"github.com/olekukonko/tablewriter"
+)
+
+// linkWorkers is the number of links we request at once.
+const linkWorkers = 8
+
+// linkClient is used to request links, each of which is given at most
+// `linkClient.Timeout` to respond.
+var linkClient = &http.Client{Timeout: 10 * time.Second}
+
+// A linkProblem is a rule whose `link` is missing, malformed, or dead.
+type linkProblem struct {
+	Rule    string
+	Link    string
+	Problem string
+}
+
+// checkLinks reports the rules whose `link` is missing or malformed and,
+// with `--online`, those whose link doesn't resolve.
+//
+// Rules from the built-in styles (e.g., `Vale.Spelling`) aren't checked.
+//
+// $ vale --online check-links
+func checkLinks(args []string, cfg *core.Config) error {
+	linter, err := lint.NewLinter(cfg)
+	if err != nil {
+		return err
+	}
+
+	problems := []linkProblem{}
+	toRequest := map[string][]string{}
+
+	for name, rule := range linter.Manager.Rules() {
+		if check.IsBuiltin(name) {
+			continue
+		} else if vocab := rule.Fields().Vocab; vocab != "" && vocab != core.GlobalVocab {
+			// It's a copy of another rule, built for a section's
+			// vocabulary.
+			continue
+		}
+		link := rule.Fields().Link
+		if problem := validateLink(link); problem != "" {
+			problems = append(problems, linkProblem{Rule: name, Link: link, Problem: problem})
+		} else {
+			toRequest[link] = append(toRequest[link], name)
+		}
+	}
+
+	if Flags.Online && Flags.Offline {
+		cfg.Warnf("--offline is set; skipping the requests for %d %s.",
+			len(toRequest), pluralize("link", len(toRequest)))
+	} else if Flags.Online {
+		for link, problem := range requestLinks(toRequest) {
+			for _, name := range toRequest[link] {
+				problems = append(problems, linkProblem{Rule: name, Link: link, Problem: problem})
+			}
+		}
+	}
+
+	sort.Slice(problems, func(i, j int) bool {
+		return problems[i].Rule < problems[j].Rule
+	})
+
+	if Flags.Output == "JSON" {
+		if err = core.PrintJSON(problems); err != nil {
+			return err
+		}
+	} else if len(problems) > 0 {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Rule", "Link", "Problem"})
+		table.SetAutoWrapText(false)
+		for _, p := range problems {
+			table.Append([]string{p.Rule, p.Link, p.Problem})
+		}
+		table.Render()
+	}
+
+	if len(problems) > 0 {
+		return core.NewE100("check-links", fmt.Errorf(
+			"found %d %s", len(problems), pluralize("problem", len(problems))))
+	}
+	return nil
+}
+
+// validateLink returns the problem with `link`'s syntax, if any: it must be
+// an absolute HTTP(S) URL.
+func validateLink(link string) string {
+	if link == "" {
+		return "missing link"
+	}
+
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "malformed link (expected an absolute HTTP(S) URL)"
+	}
+
+	return ""
+}
+
+// requestLinks requests each of `links` (at most `linkWorkers` at a time),
+// returning the problem with each link that doesn't resolve.
+func requestLinks(links map[string][]string) map[string]string {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	dead := map[string]string{}
+	sem := make(chan struct{}, linkWorkers)
+
+	for link := range links {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(link string) {
+			defer func() { <-sem; wg.Done() }()
+			if problem := requestLink(link); problem != "" {
+				mu.Lock()
+				dead[link] = problem
+				mu.Unlock()
+			}
+		}(link)
+	}
+	wg.Wait()
+
+	return dead
+}
+
+// requestLink returns the problem with `link`, if it doesn't resolve.
+//
+// We make a HEAD request, falling back to GET for servers that don't
+// support it.
+func requestLink(link string) string {
+	resp, err := linkClient.Head(link)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed ||
+		resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		resp, err = linkClient.Get(link)
+	}
+
+	if err != nil {
+		return fmt.Sprintf("unreachable link (%s)", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Sprintf("dead link (%s)", resp.Status)
+	}
+	return ""
+}
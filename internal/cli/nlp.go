@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/errata-ai/vale/v2/internal/lint"
+	"github.com/jdkato/prose/tag"
+)
+
+// nlpToken is a tagged token, as seen by `sequence`-based rules.
+type nlpToken struct {
+	Text   string
+	Tag    string
+	Offset int // the token's byte offset within its scope (or -1)
+}
+
+// nlpScope is a single block of text extracted from a file.
+type nlpScope struct {
+	Scope  string
+	Line   int // the scope's (1-based) starting line, if known
+	Text   string
+	Tokens []nlpToken
+}
+
+// printNLP shows how Vale tokenizes and tags each scope of a file after all
+// markup processing has been performed.
+//
+// $ vale nlp README.md --scope=paragraph
+func printNLP(args []string, cfg *core.Config) error {
+	var scope string
+
+	fs := flag.NewFlagSet("nlp", flag.ContinueOnError)
+	fs.StringVar(&scope, "scope", "", "only show blocks in this scope")
+
+	paths := []string{}
+	for len(args) > 0 {
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		args = fs.Args()
+		if len(args) > 0 {
+			paths = append(paths, args[0])
+			args = args[1:]
+		}
+	}
+
+	if len(paths) != 1 {
+		return core.NewE100("nlp", errors.New("expected exactly one file"))
+	}
+
+	linter, err := lint.NewLinter(cfg)
+	if err != nil {
+		return err
+	}
+
+	blocks, err := linter.Scopes(paths[0])
+	if err != nil {
+		return err
+	}
+
+	scopes := []nlpScope{}
+	for _, blk := range blocks {
+		if scope != "" && !blk.Scope.ContainsString(scope) {
+			continue
+		} else if scope == "" && blk.Scope.Has("raw") {
+			continue
+		} else if strings.TrimSpace(blk.Text) == "" {
+			continue
+		}
+		scopes = append(scopes, nlpScope{
+			Scope:  blk.Scope.Value,
+			Line:   blk.Line + 1,
+			Text:   blk.Text,
+			Tokens: toNLPTokens(blk.Text, cfg.Tagger),
+		})
+	}
+
+	if Flags.Output == "JSON" {
+		fmt.Println(getJSON(scopes))
+		return nil
+	}
+
+	for _, s := range scopes {
+		fmt.Printf("%s (line %d)\n", s.Scope, s.Line)
+		for _, tok := range s.Tokens {
+			fmt.Printf("  %5d  %-6s %s\n", tok.Offset, tok.Tag, tok.Text)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func toNLPTokens(text string, model *tag.PerceptronTagger) []nlpToken {
+	tokens := []nlpToken{}
+
+	cursor := 0
+	for _, tok := range core.TextToTokens(text, true, model) {
+		offset := strings.Index(text[cursor:], tok.Text)
+		if offset >= 0 {
+			offset += cursor
+			cursor = offset + len(tok.Text)
+		}
+		tokens = append(tokens, nlpToken{
+			Text:   tok.Text,
+			Tag:    tok.Tag,
+			Offset: offset,
+		})
+	}
+
+	return tokens
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+// SampleMeta describes how the alerts in our JSON output were sampled (see
+// `--sample`): `Rules` gives the true totals of each rule that was.
+type SampleMeta struct {
+	Size  int
+	Seed  int64
+	Rules map[string]core.RuleSample
+}
+
+// NewSampleMeta returns the sampling of `linted` by `cfg`.
+func NewSampleMeta(linted []*core.File, cfg *core.Config) SampleMeta {
+	return SampleMeta{
+		Size:  cfg.Flags.Sample,
+		Seed:  cfg.Flags.SampleSeed,
+		Rules: core.Samples(linted),
+	}
+}
+
+// printSamples lists the rules whose alerts were sampled (see `--sample`),
+// along with their true totals.
+func printSamples(w io.Writer, linted []*core.File, cfg *core.Config) {
+	meta := NewSampleMeta(linted, cfg)
+	if len(meta.Rules) == 0 {
+		return
+	}
+
+	rules := []string{}
+	for rule := range meta.Rules {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	fmt.Fprintf(w, "\nSampled rules (--sample=%d --sample-seed=%d):\n", meta.Size, meta.Seed)
+	for _, rule := range rules {
+		s := meta.Rules[rule]
+
+		levels := []string{}
+		for _, level := range []string{"error", "warning", "suggestion"} {
+			if n := s.Levels[level]; n > 0 {
+				levels = append(levels, fmt.Sprintf("%d %s", n, pluralize(level, n)))
+			}
+		}
+
+		fmt.Fprintf(w, "  %s: %d of %d %s shown (%s)\n", rule, s.Shown, s.Total,
+			pluralize("alert", s.Total), strings.Join(levels, ", "))
+	}
+}
@@ -0,0 +1,296 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+// LibraryURL is the index of known packages, which we use to find a package
+// given by name (e.g., `Packages = Google`).
+var LibraryURL = "https://raw.githubusercontent.com/errata-ai/styles/master/library.json"
+
+// A Style is an entry in the package library (see `LibraryURL`).
+type Style struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Homepage    string `json:"homepage"`
+	URL         string `json:"url"`
+}
+
+var httpClient = &http.Client{Timeout: 2 * time.Minute}
+
+// GetLibrary returns the packages listed in the library at `url`.
+func GetLibrary(url string) ([]Style, error) {
+	styles := []Style{}
+
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return styles, core.NewE100("GetLibrary", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return styles, core.NewE100("GetLibrary", fmt.Errorf("%s: %s", url, resp.Status))
+	} else if err = json.NewDecoder(resp.Body).Decode(&styles); err != nil {
+		return styles, core.NewE100("GetLibrary", err)
+	}
+
+	return styles, nil
+}
+
+// syncPackages installs each of our `Packages` -- a local zip file or
+// directory, a URL to a zip file, or the name of a package in the library --
+// into `StylesPath`.
+//
+// A package is either a single style (e.g., `MyStyle/Rule.yml`) or a
+// complete setup: a `styles` directory (whose `Vocab` entries are added to
+// ours) and, optionally, a `.vale.ini` to merge with ours (see
+// `core.PackageConfigs`).
+//
+// Each package is unpacked into a temporary directory before replacing what
+// was previously installed, so syncing is idempotent and a failed download
+// never corrupts an existing `StylesPath`.
+//
+// $ vale sync
+func syncPackages(args []string, cfg *core.Config) error {
+	if cfg.StylesPath == "" || !core.IsDir(cfg.StylesPath) {
+		return core.NewE100("sync", errors.New("a valid StylesPath is required"))
+	} else if core.IsRemote(cfg.Settings["StylesPath"].Value) {
+		return core.NewE100("sync", errors.New("a remote StylesPath can't have packages"))
+	}
+
+	configs, err := ioutil.TempDir(cfg.StylesPath, ".sync-")
+	if err != nil {
+		return core.NewE100("sync", err)
+	}
+	defer os.RemoveAll(configs)
+
+	for i, pkg := range cfg.Packages {
+		if err = installPackage(i, strings.TrimSpace(pkg), cfg, configs); err != nil {
+			return err
+		}
+	}
+
+	// We replace the bundled configurations as a whole, so those of
+	// packages that have since been removed don't linger.
+	err = core.ReplaceDir(configs, filepath.Join(cfg.StylesPath, core.PackageConfigs))
+	if err != nil {
+		return err
+	}
+
+	n := len(cfg.Packages)
+	fmt.Printf("Synced %d %s.\n", n, pluralize("package", n))
+	return nil
+}
+
+// installPackage installs the `index`th package, `pkg`, into `StylesPath`,
+// copying its configuration (if any) into `configs`.
+func installPackage(index int, pkg string, cfg *core.Config, configs string) error {
+	tmp, err := ioutil.TempDir(cfg.StylesPath, ".sync-")
+	if err != nil {
+		return core.NewE100("sync", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	name := strings.TrimSuffix(filepath.Base(filepath.FromSlash(pkg)), ".zip")
+	if err = fetchPackage(pkg, cfg, tmp); err != nil {
+		return err
+	}
+
+	root := packageRoot(tmp)
+	if root != tmp {
+		name = filepath.Base(root)
+	}
+
+	styles := filepath.Join(root, "styles")
+	if !core.IsDir(styles) {
+		// The package is a single style.
+		return core.ReplaceDir(root, filepath.Join(cfg.StylesPath, name))
+	}
+
+	entries, err := ioutil.ReadDir(styles)
+	if err != nil {
+		return core.NewE100("sync", err)
+	}
+	for _, entry := range entries {
+		src := filepath.Join(styles, entry.Name())
+		if !entry.IsDir() {
+			continue
+		} else if entry.Name() == "Vocab" {
+			// Vocabularies are shared, so we only replace the package's own.
+			err = installEach(src, filepath.Join(cfg.StylesPath, "Vocab"))
+		} else {
+			err = core.ReplaceDir(src, filepath.Join(cfg.StylesPath, entry.Name()))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if ini := filepath.Join(root, ".vale.ini"); core.FileExists(ini) {
+		dst := filepath.Join(configs, fmt.Sprintf("%02d-%s.ini", index, name))
+		return copyFile(ini, dst)
+	}
+
+	return nil
+}
+
+// fetchPackage puts the content of the package `pkg` into `dir`.
+func fetchPackage(pkg string, cfg *core.Config, dir string) error {
+	if strings.HasPrefix(pkg, "http://") || strings.HasPrefix(pkg, "https://") {
+		return downloadPackage(pkg, dir)
+	}
+
+	local := filepath.FromSlash(pkg)
+	if !filepath.IsAbs(local) && cfg.Flags.Path != "" {
+		// Like other paths, it's relative to the configuration file.
+		local = filepath.Join(filepath.Dir(cfg.Flags.Path), local)
+	}
+
+	if core.IsDir(local) {
+		return copyDir(local, filepath.Join(dir, filepath.Base(local)))
+	} else if core.FileExists(local) {
+		return unzip(local, dir)
+	} else if strings.ContainsAny(pkg, `/\.`) {
+		return core.NewE100("sync", fmt.Errorf("package '%s' does not exist", pkg))
+	}
+
+	library, err := GetLibrary(LibraryURL)
+	if err != nil {
+		return err
+	}
+	for _, style := range library {
+		if style.Name == pkg {
+			return downloadPackage(style.URL, dir)
+		}
+	}
+
+	return core.NewE100("sync", fmt.Errorf("'%s' is not in the library", pkg))
+}
+
+// downloadPackage downloads the zip file at `url` and unpacks it into `dir`.
+func downloadPackage(url, dir string) error {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return core.NewE100("sync", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return core.NewE100("sync", fmt.Errorf("%s: %s", url, resp.Status))
+	}
+
+	archive, err := ioutil.TempFile(dir, ".download-*.zip")
+	if err != nil {
+		return core.NewE100("sync", err)
+	}
+	defer os.Remove(archive.Name())
+
+	_, err = io.Copy(archive, resp.Body)
+	if cerr := archive.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return core.NewE100("sync", err)
+	}
+
+	return unzip(archive.Name(), dir)
+}
+
+// packageRoot returns the directory that holds a package's content: `dir`
+// or, if it holds nothing else, its only subdirectory.
+func packageRoot(dir string) string {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return dir
+	}
+
+	content := []os.FileInfo{}
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), ".") && entry.Name() != "__MACOSX" {
+			content = append(content, entry)
+		}
+	}
+
+	if len(content) == 1 && content[0].IsDir() && content[0].Name() != "styles" {
+		return filepath.Join(dir, content[0].Name())
+	}
+	return dir
+}
+
+// installEach replaces each directory in `dst` with its counterpart in
+// `src`.
+func installEach(src, dst string) error {
+	if err := os.MkdirAll(dst, os.ModePerm); err != nil {
+		return core.NewE100("sync", err)
+	}
+
+	entries, err := ioutil.ReadDir(src)
+	if err != nil {
+		return core.NewE100("sync", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		err = core.ReplaceDir(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unzip extracts the zip file `archive` into `dir`.
+func unzip(archive, dir string) error {
+	if err := zip.Unarchive(archive, dir); err != nil {
+		return core.NewE100("sync", fmt.Errorf("%s: %s", archive, err))
+	}
+	return nil
+}
+
+// copyDir copies the directory `src` to `dst`.
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(fp string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return core.NewE100("sync", err)
+		}
+
+		rel, err := filepath.Rel(src, fp)
+		if err != nil {
+			return core.NewE100("sync", err)
+		}
+
+		target := filepath.Join(dst, rel)
+		if fi.IsDir() {
+			if err = os.MkdirAll(target, os.ModePerm); err != nil {
+				return core.NewE100("sync", err)
+			}
+			return nil
+		}
+
+		return copyFile(fp, target)
+	})
+}
+
+// copyFile copies the file `src` to `dst`.
+func copyFile(src, dst string) error {
+	b, err := ioutil.ReadFile(src)
+	if err != nil {
+		return core.NewE100("sync", err)
+	} else if err = ioutil.WriteFile(dst, b, 0644); err != nil {
+		return core.NewE100("sync", err)
+	}
+	return nil
+}
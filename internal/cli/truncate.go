@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+// An alertCap limits the number of alerts we print across all files (see
+// `--max-problems`); a `max` of 0 means there's no limit.
+type alertCap struct {
+	max    int
+	shown  int
+	hidden int
+}
+
+// allow determines if another alert may be printed, counting it either way.
+func (c *alertCap) allow() bool {
+	if c.max > 0 && c.shown >= c.max {
+		c.hidden++
+		return false
+	}
+	c.shown++
+	return true
+}
+
+// truncated returns the number of alerts in `linted` beyond the first `max`.
+func truncated(linted []*core.File, max int) int {
+	total := 0
+	for _, f := range linted {
+		total += len(f.Alerts)
+	}
+	if max > 0 && total > max {
+		return total - max
+	}
+	return 0
+}
+
+// truncationNote is the line that follows our output when `hidden` alerts
+// weren't printed.
+func truncationNote(hidden, max int) string {
+	return fmt.Sprintf("... and %d more %s (--max-problems=%d)",
+		hidden, pluralize("alert", hidden), max)
+}
@@ -2,59 +2,122 @@ package core
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/gobwas/glob"
+	"github.com/jdkato/prose/tag"
+	"github.com/jdkato/regexp"
 )
 
 // CLIFlags holds the values that are defined at rumtime by the user.
 //
 // For example, `vale --minAlertLevel=error`.
 type CLIFlags struct {
-	AlertLevel string
-	Glob       string
-	InExt      string
-	Local      bool
-	NoExit     bool
-	Normalize  bool
-	Output     string
-	Path       string
-	Relative   bool
-	Remote     bool
-	Simple     bool
-	Sorted     bool
-	Sources    string
-	Wrap       bool
+	AlertLevel   string
+	Context      int
+	Debug        bool
+	Diff         string
+	ExplainRun   bool
+	FailIfEmpty  bool
+	Filter       string
+	Fix          bool
+	Glob         string
+	Ignore       string
+	InExt        string
+	Input        string
+	JSONSchema   int
+	Local        bool
+	MaxProblems  int
+	NoExit       bool
+	NoGlobal     bool
+	Normalize    bool
+	Offline      bool
+	Online       bool
+	Output       string
+	Path         string
+	Quiet        bool
+	Relative     bool
+	Remote       bool
+	Rules        string
+	Sample       int
+	SampleSeed   int64
+	Simple       bool
+	SeverityExit string
+	SingleConfig bool
+	SortBy       string
+	Sorted       bool
+	Sources      string
+	Strict       bool
+	Wrap         bool
+
+	// resolved records where `Path` came from, if it wasn't given as is
+	// (see `loadINI`), so that it survives reloading the configuration.
+	resolved Setting
 }
 
 // Config holds the the configuration values from both the CLI and `.vale.ini`.
 type Config struct {
 	// General configuration
-	BlockIgnores   map[string][]string        // A list of blocks to ignore
-	Checks         []string                   // All checks to load
-	Formats        map[string]string          // A map of unknown -> known formats
-	GBaseStyles    []string                   // Global base style
-	GChecks        map[string]bool            // Global checks
-	IgnoredClasses []string                   // A list of HTML classes to ignore
-	IgnoredScopes  []string                   // A list of HTML tags to ignore
-	MinAlertLevel  int                        // Lowest alert level to display
-	Project        string                     // The active project
-	RuleToLevel    map[string]string          // Single-rule level changes
-	SBaseStyles    map[string][]string        // Syntax-specific base styles
-	SChecks        map[string]map[string]bool // Syntax-specific checks
-	SkippedScopes  []string                   // A list of HTML blocks to ignore
-	Stylesheets    map[string]string          // XSLT stylesheet
-	StylesPath     string                     // Directory with Rule.yml files
-	TokenIgnores   map[string][]string        // A list of tokens to ignore
-	WordTemplate   string                     // The template used in YAML -> regexp list conversions
+	BlockIgnores   map[string][]string               // A list of blocks to ignore
+	BuiltIn        bool                              // Load the built-in styles (e.g., `Vale`)?
+	Checks         []string                          // All checks to load
+	Commands       map[string]string                 // Syntax-specific commands to convert files to HTML
+	ExitCodes      map[string]int                    // The exit code caused by each alert level
+	FailIfEmpty    bool                              // Is linting no files an error?
+	Formats        map[string]string                 // A map of unknown -> known formats
+	GBaseStyles    []string                          // Global base style
+	GChecks        map[string]bool                   // Global checks
+	IgnoreFiles    []string                          // Glob patterns of files to skip
+	IgnoredClasses []string                          // A list of HTML classes to ignore
+	IgnoredScopes  []string                          // A list of HTML tags to ignore
+	InlineRules    map[string]map[string]interface{} // Rules defined in `.vale.ini` (see `InlineStyle`)
+	LintedAttrs    []string                          // The HTML attributes to lint as `text.attr.NAME`
+	LintKeys       []string                          // The YAML keys whose string values are linted
+	MaxScopeBytes  int                               // The size of the largest scope we'll lint (0 for no limit)
+	MergeDups      bool                              // Merge alerts that suggest the same fix?
+	MaxNonProse    float64                           // The highest non-alphabetic ratio of a scope we'll lint (0 for no limit)
+	MinAlertLevel  int                               // Lowest alert level to display
+	Packages       []string                          // Packages to install with `vale sync`
+	Plugins        map[string]string                 // External rules (Style.Rule -> executable)
+	Projects       []string                          // The active projects, in the order they're loaded
+	RuleParams     map[string]map[string]interface{} // Single-rule parameter changes
+	RuleToLevel    map[string]string                 // Single-rule level changes
+	SBaseStyles    map[string][]string               // Syntax-specific base styles
+	SChecks        map[string]map[string]bool        // Syntax-specific checks
+	SIgnoredScopes map[string][]string               // Syntax-specific inline tags to ignore
+	SMinAlertLevel map[string]int                    // Syntax-specific lowest alert levels to display
+	SProjects      map[string][]string               // Syntax-specific projects (see `SVocabs`)
+	SSkippedScopes map[string][]string               // Syntax-specific blocks to ignore
+	SkippedScopes  []string                          // A list of HTML blocks to ignore
+	Stylesheets    map[string]string                 // XSLT stylesheet
+	StylesPath     string                            // Directory with Rule.yml files
+	TaggerModel    string                            // A custom part-of-speech tagging model
+	TokenIgnores   map[string][]string               // A list of tokens to ignore
+	UseDocutils    bool                              // Convert reStructuredText with rst2html?
+	WordTemplate   string                            // The template used in YAML -> regexp list conversions
+
+	// Settings holds the raw value of each setting -- e.g., `StylesPath` or,
+	// for those in a section, `[*.md] BasedOnStyles` -- and where it came
+	// from.
+	Settings map[string]Setting `json:",omitempty"`
 
 	AcceptedTokens map[string]struct{} `json:"-"` // Project-specific vocabulary (okay)
 	RejectedTokens map[string]struct{} `json:"-"` // Project-specific vocabulary (avoid)
 
+	// Rejections holds the level and message of each rejected term that was
+	// given its own (see `VocabRejection`).
+	Rejections map[string]VocabRejection `json:"-"`
+
+	// SVocabs holds the vocabulary of each distinct list of projects in
+	// `SProjects` (other than the global one), keyed by `VocabKey`.
+	SVocabs map[string]*Vocab `json:"-"`
+
 	DictionaryPath string // Location to search for dictionaries.
 
 	Built string // A path to a pre-built file (e.g., an HTML file made from a Markdown file)
@@ -72,6 +135,36 @@ type Config struct {
 
 	// Command-line configuration
 	Flags *CLIFlags `json:"-"`
+
+	// Tagger is the model read from `TaggerModel`, or nil if we're using our
+	// default model (see `Tag`).
+	Tagger *tag.PerceptronTagger `json:"-"`
+
+	// Explicit is true if the configuration file was given by the user
+	// (e.g., `--config`) rather than found by searching.
+	Explicit bool `json:"-"`
+
+	// chain holds the configuration files we were loaded from, if there was
+	// more than one (see `LoadChain`).
+	chain []string
+
+	// missing is true if we searched for a configuration file and didn't
+	// find one (see `HasConfig`).
+	missing bool
+
+	// userStyles is the `StylesPath` of the user's personal configuration,
+	// if any (see `loadUserConfig`).
+	userStyles string
+}
+
+// A Setting is the raw value of a configuration setting, along with where it
+// came from.
+type Setting struct {
+	Value string
+	// Source is "default", the path of a configuration file, a CLI flag
+	// (e.g., `--minAlertLevel`), or an environment variable (e.g.,
+	// `$VALE_STYLES_PATH`).
+	Source string
 }
 
 // NewConfig initializes a Config with its default values.
@@ -80,15 +173,29 @@ func NewConfig(flags *CLIFlags) (*Config, error) {
 
 	cfg.AcceptedTokens = make(map[string]struct{})
 	cfg.BlockIgnores = make(map[string][]string)
+	cfg.BuiltIn = true
 	cfg.Flags = flags
 	cfg.Formats = make(map[string]string)
+	cfg.Settings = make(map[string]Setting)
 	cfg.GChecks = make(map[string]bool)
+	cfg.InlineRules = make(map[string]map[string]interface{})
 	cfg.LTPath = "http://localhost:8081/v2/check"
+	cfg.LintedAttrs = []string{"alt", "title", "aria-label", "placeholder"}
+	cfg.Commands = make(map[string]string)
+	cfg.ExitCodes, _ = ParseExitCodes("")
 	cfg.MinAlertLevel = 1
+	cfg.Plugins = make(map[string]string)
 	cfg.RejectedTokens = make(map[string]struct{})
+	cfg.Rejections = make(map[string]VocabRejection)
+	cfg.RuleParams = make(map[string]map[string]interface{})
 	cfg.RuleToLevel = make(map[string]string)
 	cfg.SBaseStyles = make(map[string][]string)
 	cfg.SChecks = make(map[string]map[string]bool)
+	cfg.SIgnoredScopes = make(map[string][]string)
+	cfg.SMinAlertLevel = make(map[string]int)
+	cfg.SProjects = make(map[string][]string)
+	cfg.SSkippedScopes = make(map[string][]string)
+	cfg.SVocabs = make(map[string]*Vocab)
 	cfg.SecToPat = make(map[string]glob.Glob)
 	cfg.Stylesheets = make(map[string]string)
 	cfg.Timeout = 2
@@ -98,29 +205,189 @@ func NewConfig(flags *CLIFlags) (*Config, error) {
 	return &cfg, nil
 }
 
+// HasConfig determines if we found a configuration file to load (see
+// `loadINI`).
+//
+// If we didn't, we still lint the files that have a configuration file of
+// their own (see `ConfigChain`), but nothing else.
+func (c *Config) HasConfig() bool {
+	return !c.missing
+}
+
+// Warnf prints a warning to stderr, unless `--quiet` is set.
+//
+// NOTE: Diagnostics never go to stdout, which is reserved for the selected
+// output format (e.g., `--output=JSON`).
+func (c *Config) Warnf(format string, a ...interface{}) {
+	if c.Flags == nil || !c.Flags.Quiet {
+		fmt.Fprintf(os.Stderr, "WARNING: "+format+"\n", a...)
+	}
+}
+
 // AddWordListFile adds vocab terms from a provided file.
 func (c *Config) AddWordListFile(name string, accept bool) error {
-	fd, err := os.Open(name)
-	defer fd.Close()
+	b, err := ReadFileRetry(name)
 	if err != nil {
 		return err
 	}
-	return c.addWordList(fd, accept)
+	return c.addWordList(bytes.NewReader(b), accept, name)
+}
+
+// VocabPattern returns the regular expression of the vocabulary entry `term`,
+// if it's written between slashes (e.g., `/Kubernetes 1\.\d+/`), and true;
+// otherwise, it returns `term` and false.
+func VocabPattern(term string) (string, bool) {
+	if len(term) > 2 && strings.HasPrefix(term, "/") && strings.HasSuffix(term, "/") {
+		return term[1 : len(term)-1], true
+	}
+	return term, false
+}
+
+// VocabIgnoreCase returns the vocabulary entry `term` without its leading `!`
+// (e.g., `!email`), which marks a term that may be written in any case, and
+// true; otherwise, it returns `term` and false.
+//
+// Other terms are case-sensitive: accepting `macOS` means that `MacOS` is
+// flagged.
+func VocabIgnoreCase(term string) (string, bool) {
+	if len(term) > 1 && strings.HasPrefix(term, "!") {
+		return term[1:], true
+	}
+	return term, false
+}
+
+// GlobalVocab is the `VocabKey` of the vocabulary given outside of any
+// section, which applies to files whose section doesn't give its own.
+const GlobalVocab = "*"
+
+// VocabKey identifies the vocabulary made up of `projects`, in order.
+func VocabKey(projects []string) string {
+	return strings.Join(projects, ",")
+}
+
+// A Vocab is the vocabulary of a section's projects (see `SProjects`), which
+// takes the place of the global one for the files that the section matches.
+type Vocab struct {
+	Projects       []string
+	AcceptedTokens map[string]struct{}
+	RejectedTokens map[string]struct{}
+	Rejections     map[string]VocabRejection
+}
+
+// ForVocab returns a copy of `c` whose vocabulary is the one identified by
+// `key` (see `VocabKey`), for building the rules that depend on it; `c`
+// itself is returned for `GlobalVocab`.
+func (c *Config) ForVocab(key string) *Config {
+	vocab, ok := c.SVocabs[key]
+	if !ok {
+		return c
+	}
+
+	copied := *c
+	copied.Projects = vocab.Projects
+	copied.AcceptedTokens = vocab.AcceptedTokens
+	copied.RejectedTokens = vocab.RejectedTokens
+	copied.Rejections = vocab.Rejections
+
+	return &copied
+}
+
+// vocabFor returns the `VocabKey` of the vocabulary that applies to `fp`:
+// that of the most specific matching section with its own projects or, if
+// there isn't one, `GlobalVocab`.
+//
+// It's empty if no section has its own projects.
+func (c *Config) vocabFor(fp string) string {
+	if len(c.SVocabs) == 0 {
+		return ""
+	} else if sec, found := c.sectionFor(fp, sectionsOf(c.SProjects)); found {
+		if key := VocabKey(c.SProjects[sec]); c.SVocabs[key] != nil {
+			return key
+		}
+	}
+	return GlobalVocab
+}
+
+// A VocabRejection is the level and message of a rejected term, which are
+// given after it in a `reject.txt` file, separated by tabs:
+//
+//	term<TAB>level<TAB>message
+//
+// Either may be empty, in which case `Vale.Avoid`'s own is used.
+type VocabRejection struct {
+	Level   string
+	Message string
+}
+
+// parseRejection splits a line of a `reject.txt` file into its term and, if
+// it has one, its `VocabRejection`.
+func parseRejection(line string) (string, VocabRejection, bool) {
+	fields := strings.SplitN(line, "\t", 3)
+
+	details := VocabRejection{}
+	if len(fields) > 1 {
+		details.Level = strings.TrimSpace(fields[1])
+	}
+	if len(fields) > 2 {
+		details.Message = strings.TrimSpace(fields[2])
+	}
+
+	return strings.TrimSpace(fields[0]), details, details != VocabRejection{}
 }
 
-func (c *Config) addWordList(r io.Reader, accept bool) error {
+// addWordList adds the vocabulary terms in `r`, which was read from `source`.
+//
+// An invalid pattern (see `VocabPattern`) is skipped with a warning, as is an
+// invalid level of a rejected term (see `VocabRejection`).
+func (c *Config) addWordList(r io.Reader, accept bool, source string) error {
+	line := 0
+
 	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
+		line++
+
 		word := strings.TrimSpace(scanner.Text())
+
+		details, custom := VocabRejection{}, false
+		if !accept {
+			word, details, custom = parseRejection(scanner.Text())
+		}
+
 		if len(word) == 0 || word == "#" {
 			continue
-		} else if accept {
-			if _, ok := c.AcceptedTokens[word]; !ok {
-				c.AcceptedTokens[word] = struct{}{}
+		}
+
+		if details.Level != "" && !StringInSlice(details.Level, AlertLevels) {
+			c.Warnf("%s:%d: '%s' isn't a valid level (expected one of %s); ignoring it.",
+				source, line, details.Level, ToSentence(AlertLevels, "or"))
+			details.Level = ""
+			custom = details.Message != ""
+		}
+
+		bare, _ := VocabIgnoreCase(word)
+		if pat, ok := VocabPattern(bare); ok {
+			if _, err := regexp.Compile(pat); err != nil {
+				c.Warnf("%s:%d: '%s' isn't a valid pattern (%s); skipping it.",
+					source, line, word, err)
+				continue
 			}
+		}
+
+		// NOTE: A later list takes precedence over an earlier one, so
+		// accepting a term un-rejects it (and vice versa), in either case.
+		for _, m := range []map[string]struct{}{c.AcceptedTokens, c.RejectedTokens} {
+			delete(m, bare)
+			delete(m, "!"+bare)
+		}
+		delete(c.Rejections, bare)
+		delete(c.Rejections, "!"+bare)
+
+		if accept {
+			c.AcceptedTokens[word] = struct{}{}
 		} else {
-			if _, ok := c.RejectedTokens[word]; !ok {
-				c.RejectedTokens[word] = struct{}{}
+			c.RejectedTokens[word] = struct{}{}
+			if custom {
+				c.Rejections[word] = details
 			}
 		}
 	}
@@ -135,3 +402,82 @@ func (c *Config) String() string {
 	b, _ := json.MarshalIndent(c, "", "  ")
 	return string(b)
 }
+
+// sectionFor returns the most specific of `sections` whose glob matches `fp`.
+//
+// A section is more specific than another if its glob has more literal (that
+// is, non-wildcard) characters -- e.g., `docs/*.md` beats `*.md`, which beats
+// `*.{md,adoc}`. Ties are broken by comparing the globs themselves, so the
+// result never depends on the order of `sections`.
+func (c *Config) sectionFor(fp string, sections []string) (string, bool) {
+	best, found := "", false
+	for _, sec := range sections {
+		pat, ok := c.SecToPat[sec]
+		if !ok || !pat.Match(fp) {
+			continue
+		}
+		if !found || moreSpecific(sec, best) {
+			best, found = sec, true
+		}
+	}
+	return best, found
+}
+
+// minAlertLevelFor returns the lowest alert level to display for `fp`: that
+// of the most specific matching section or, if there isn't one (or the level
+// was given by `--minAlertLevel`), the global one.
+func (c *Config) minAlertLevelFor(fp string) int {
+	if StringInSlice(c.Flags.AlertLevel, AlertLevels) {
+		return c.MinAlertLevel
+	}
+
+	sections := []string{}
+	for sec := range c.SMinAlertLevel {
+		sections = append(sections, sec)
+	}
+	if sec, found := c.sectionFor(fp, sections); found {
+		return c.SMinAlertLevel[sec]
+	}
+	return c.MinAlertLevel
+}
+
+// ignoresFor returns the patterns in `ignores` that apply to `fp`: those of
+// the most specific matching section or, if there isn't one, the global ones.
+func (c *Config) ignoresFor(fp string, ignores map[string][]string) []string {
+	if sec, found := c.sectionFor(fp, sectionsOf(ignores)); found {
+		return ignores[sec]
+	}
+	return ignores["*"]
+}
+
+func moreSpecific(a, b string) bool {
+	if la, lb := literalLen(a), literalLen(b); la != lb {
+		return la > lb
+	}
+	return a < b
+}
+
+// literalLen counts the characters of `pattern` that aren't wildcards or part
+// of a `{...}` or `[...]` group.
+func literalLen(pattern string) int {
+	count, depth := 0, 0
+	for _, r := range pattern {
+		switch {
+		case r == '{' || r == '[':
+			depth++
+		case r == '}' || r == ']':
+			depth--
+		case depth == 0 && r != '*' && r != '?':
+			count++
+		}
+	}
+	return count
+}
+
+func sectionsOf(m map[string][]string) []string {
+	sections := []string{}
+	for sec := range m {
+		sections = append(sections, sec)
+	}
+	return sections
+}
@@ -2,13 +2,18 @@ package core
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"unicode/utf8"
 
+	"github.com/errata-ai/vale/v2/pkg/pdf"
 	"github.com/gobwas/glob"
 	"github.com/jdkato/prose/tag"
 	"github.com/jdkato/prose/tokenize"
@@ -59,25 +64,44 @@ func NewLinedBlock(ctx, txt, sel string, line int) Block {
 
 // A File represents a linted text file.
 type File struct {
-	Alerts     []Alert           // all alerts associated with this file
-	BaseStyles []string          // base style assigned in .vale
-	Checks     map[string]bool   // syntax-specific checks assigned in .vale
-	ChkToCtx   map[string]string // maps a temporary context to a particular check
-	Comments   map[string]bool   // comment control statements
-	Content    string            // the raw file contents
-	Format     string            // 'code', 'markup' or 'prose'
-	Lines      []string          // the File's Content split into lines
-	NormedExt  string            // the normalized extension (see util/format.go)
-	Path       string            // the full path
-	Transform  string            // XLST transform
-	RealExt    string            // actual file extension
-	Sequences  []string          // tracks various info (e.g., defined abbreviations)
-	Summary    bytes.Buffer      // holds content to be included in summarization checks
-
-	history  map[string]int
-	limits   map[string]int
-	isGlobal bool
-	simple   bool
+	Alerts        []Alert           // all alerts associated with this file
+	BaseStyles    []string          // base style assigned in .vale
+	BlockIgnores  []string          // block-level patterns to ignore
+	Checks        map[string]bool   // syntax-specific checks assigned in .vale
+	ChkToCtx      map[string]string // maps a temporary context to a particular check
+	Command       string            // command (with arguments) to convert the file to HTML
+	Comments      map[string]int    // open 'off' comments per rule ("off" for all rules)
+	Content       string            // the raw file contents
+	Disabled      []string          // rules turned off by comments ("off" for all rules)
+	Escalated     map[string]int    // alerts raised by each rule's `escalate` thresholds
+	Format        string            // 'code', 'markup' or 'prose'
+	IgnoredScopes []string          // inline tags to ignore
+	Limited       map[string]int    // alerts not reported due to each rule's limit
+	Lines         []string          // the File's Content split into lines
+	MinAlertLevel int               // the lowest alert level to display
+	NormedExt     string            // the normalized extension (see util/format.go)
+	Path          string            // the full path
+	Transform     string            // XSLT stylesheet or command to convert the file to HTML
+	RealExt       string            // actual file extension
+	Sequences     []string          // tracks various info (e.g., defined abbreviations)
+	Skipped       int               // the number of scopes too large or non-prose to lint
+	SkippedScopes []string          // block-level tags to ignore
+	Summary       bytes.Buffer      // holds content to be included in summarization checks
+	TokenIgnores  []string          // inline patterns to ignore
+	Vocab         string            // the key of the vocabulary that applies (see `Config.SVocabs`)
+
+	// Sampled counts the alerts dropped by `--sample`, per rule and level
+	// (see `SampleAlerts`).
+	Sampled map[string]map[string]int
+
+	history   map[string][][]int // the spans of our alerts (see `isDuplicate`)
+	limits    map[string]int
+	overrides []LevelOverride
+	sortBy    string
+	isGlobal  bool
+	simple    bool
+	stdin     bool
+	debug     bool
 }
 
 // An Action represents a possible solution to an Alert.
@@ -86,6 +110,13 @@ type File struct {
 type Action struct {
 	Name   string   // the name of the action -- e.g, 'replace'
 	Params []string // a slice of parameters for the given action
+
+	// Target is the text that a `replace` action's parameter replaces, if it
+	// isn't the alert's `Match` -- e.g., a capitalization fix rewrites the
+	// whole heading, while its alert only covers the first offending word.
+	// It begins `Offset` characters before the alert's span.
+	Target string `json:",omitempty"`
+	Offset int    `json:",omitempty"`
 }
 
 // An Alert represents a potential error in prose.
@@ -100,6 +131,10 @@ type Alert struct {
 	Span        []int  // the [begin, end] location within a line
 	Match       string // the actual matched text
 
+	// MergedFrom lists the rules that made this same alert, if they were
+	// merged (see `File.MergeDuplicates`).
+	MergedFrom []string `json:",omitempty"`
+
 	Hide  bool `json:"-"` // should we hide this alert?
 	Limit int  `json:"-"` // the max times to report
 }
@@ -112,23 +147,43 @@ type Plugin struct {
 }
 
 // A Selector represents a named section of text.
+//
+// The markup formats use, among others, `text.heading.h1` (through `h6`),
+// `text.table.header`, `text.table.cell`, `text.list`, `text.blockquote`,
+// `text.attr.alt` (an image's alternate text), `text.link` (a link's
+// visible text), and `text.url` (a link's target); code uses
+// `text.comment.line` and `text.comment.block`.
 type Selector struct {
 	Value string // e.g., text.comment.line.py
 }
 
+// explicitSections are the sections that a rule must name to run on a block
+// that has them: e.g., a `text` rule doesn't run on `text.link`, since the
+// link's text is also linted as part of its paragraph, or on `text.url`.
+var explicitSections = []string{"link", "url"}
+
 // Sections splits a Selector into its parts -- e.g., text.comment.line.py ->
 // []string{"text", "comment", "line", "py"}.
 func (s Selector) Sections() []string { return strings.Split(s.Value, ".") }
 
-// Contains determines if all if sel's sections are in s.
+// Contains determines if all if sel's sections are in s (and, if s has one
+// of `explicitSections`, sel does too).
 func (s Selector) Contains(sel Selector) bool {
-	return AllStringsInSlice(sel.Sections(), s.Sections())
+	if !AllStringsInSlice(sel.Sections(), s.Sections()) {
+		return false
+	}
+	for _, section := range explicitSections {
+		if s.Has(section) && !sel.Has(section) {
+			return false
+		}
+	}
+	return true
 }
 
-// ContainsString determines if all if sel's sections are in s.
+// ContainsString determines if all if sel's sections are in s (see
+// `Contains`).
 func (s Selector) ContainsString(scope string) bool {
-	sel := Selector{Value: scope}
-	return AllStringsInSlice(sel.Sections(), s.Sections())
+	return s.Contains(Selector{Value: scope})
 }
 
 // Equal determines if sel == s.
@@ -149,10 +204,45 @@ func (a ByPosition) Less(i, j int) bool {
 
 	if ai.Line != aj.Line {
 		return ai.Line < aj.Line
+	} else if ai.Span[0] != aj.Span[0] {
+		return ai.Span[0] < aj.Span[0]
+	}
+	// Alerts at the same position are ordered by rule, so that the output
+	// is the same from run to run.
+	return ai.Check < aj.Check
+}
+
+// BySeverity sorts Alerts by level (errors first) and then by position.
+type BySeverity []Alert
+
+func (a BySeverity) Len() int      { return len(a) }
+func (a BySeverity) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a BySeverity) Less(i, j int) bool {
+	ai, aj := LevelToInt[a[i].Severity], LevelToInt[a[j].Severity]
+
+	if ai != aj {
+		return ai > aj
 	}
-	return ai.Span[0] < aj.Span[0]
+	return ByPosition(a).Less(i, j)
 }
 
+// ByCheck sorts Alerts by the name of their check and then by position.
+type ByCheck []Alert
+
+func (a ByCheck) Len() int      { return len(a) }
+func (a ByCheck) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a ByCheck) Less(i, j int) bool {
+	ai, aj := a[i], a[j]
+
+	if ai.Check != aj.Check {
+		return ai.Check < aj.Check
+	}
+	return ByPosition(a).Less(i, j)
+}
+
+// AlertOrders are the possible values of `--sort-by`.
+var AlertOrders = []string{"position", "severity", "check"}
+
 // ByName sorts Files by their path.
 type ByName []*File
 
@@ -164,23 +254,56 @@ func (a ByName) Less(i, j int) bool {
 }
 
 // NewFile initilizes a File.
+//
+// If `src` isn't the path of a file, it's the file's content, which has the
+// extension given by `--ext` (see `NewFileFromBytes`).
 func NewFile(src string, config *Config) (*File, error) {
-	var format, ext string
-	var fbytes []byte
-
-	if FileExists(src) {
-		fbytes, _ = ioutil.ReadFile(src)
-		if config.Flags.InExt != ".txt" {
-			ext, format = FormatFromExt(config.Flags.InExt, config.Formats)
-		} else {
-			ext, format = FormatFromExt(src, config.Formats)
-		}
-	} else {
+	if !FileExists(src) {
+		return NewFileFromBytes([]byte(src), config.Flags.InExt, config)
+	}
+
+	// NOTE: This is the only time we read the file, so all of our positions
+	// refer to this content -- even if it changes on disk while we're
+	// linting.
+	fbytes, err := ioutil.ReadFile(LongPath(src))
+	if err != nil {
+		return &File{}, NewE100(src, err)
+	}
+
+	ext, format := FormatFromExt(src, config.Formats)
+	if config.Flags.InExt != ".txt" {
 		ext, format = FormatFromExt(config.Flags.InExt, config.Formats)
-		fbytes = []byte(src)
-		src = "stdin" + config.Flags.InExt
 	}
 
+	return newFile(src, fbytes, ext, format, false, config)
+}
+
+// NewFileFromBytes initializes a File from `content`, which has the extension
+// `ext` (e.g., `.md`), without accessing the filesystem.
+//
+// Its path is `stdin` followed by `ext` -- e.g., `stdin.md` -- which is what
+// the configuration's sections are matched against.
+func NewFileFromBytes(content []byte, ext string, config *Config) (*File, error) {
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return NewNamedFile("stdin"+ext, content, ext, config)
+}
+
+// NewNamedFile initializes a File named `path` from `content`, which has the
+// extension `ext` (e.g., `.md`), without accessing the filesystem.
+//
+// `path` is only used to report the file's alerts and to match the
+// configuration's sections; it needn't exist.
+func NewNamedFile(path string, content []byte, ext string, config *Config) (*File, error) {
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	normed, format := FormatFromExt(ext, config.Formats)
+	return newFile(path, content, normed, format, true, config)
+}
+
+func newFile(src string, fbytes []byte, ext, format string, stdin bool, config *Config) (*File, error) {
 	fp := src
 	old := filepath.Ext(fp)
 	if normed, found := config.Formats[strings.Trim(old, ".")]; found {
@@ -188,19 +311,27 @@ func NewFile(src string, config *Config) (*File, error) {
 	}
 
 	baseStyles := config.GBaseStyles
-	for sec, styles := range config.SBaseStyles {
-		if pat, found := config.SecToPat[sec]; found && pat.Match(fp) {
-			baseStyles = styles
-			break
-		}
+	if sec, found := config.sectionFor(fp, sectionsOf(config.SBaseStyles)); found {
+		baseStyles = config.SBaseStyles[sec]
 	}
 
 	checks := make(map[string]bool)
-	for sec, smap := range config.SChecks {
-		if pat, found := config.SecToPat[sec]; found && pat.Match(fp) {
-			checks = smap
-			break
-		}
+	sections := []string{}
+	for sec := range config.SChecks {
+		sections = append(sections, sec)
+	}
+	if sec, found := config.sectionFor(fp, sections); found {
+		checks = config.SChecks[sec]
+	}
+
+	ignoredScopes := config.IgnoredScopes
+	if sec, found := config.sectionFor(fp, sectionsOf(config.SIgnoredScopes)); found {
+		ignoredScopes = config.SIgnoredScopes[sec]
+	}
+
+	skippedScopes := config.SkippedScopes
+	if sec, found := config.sectionFor(fp, sectionsOf(config.SSkippedScopes)); found {
+		skippedScopes = config.SSkippedScopes[sec]
 	}
 
 	transform := ""
@@ -214,31 +345,73 @@ func NewFile(src string, config *Config) (*File, error) {
 		}
 	}
 
+	command := ""
+	for sec, c := range config.Commands {
+		pat, err := glob.Compile(sec)
+		if err != nil {
+			return &File{}, NewE100(src, err)
+		} else if pat.Match(src) {
+			command = c
+			break
+		}
+	}
+
+	if ext == ".pdf" {
+		// We lint a PDF's text, with each page on its own line: an alert's
+		// line is the page it's on.
+		pages, err := pdf.Pages(fbytes)
+		if err != nil {
+			return &File{}, NewE100(src, err)
+		}
+		fbytes = []byte(strings.Join(pages, "\n"))
+	}
+
 	content := Sanitize(string(fbytes))
 	lines := strings.SplitAfter(content, "\n")
 	file := File{
 		Path: src, NormedExt: ext, Format: format, RealExt: filepath.Ext(src),
 		BaseStyles: baseStyles, Checks: checks, Lines: lines, Content: content,
-		Comments: make(map[string]bool), history: make(map[string]int),
-		simple: config.Flags.Simple, Transform: transform,
-		limits: make(map[string]int),
+		Comments: make(map[string]int), history: make(map[string][][]int),
+		simple: config.Flags.Simple, Transform: transform, Command: command, sortBy: config.Flags.SortBy,
+		limits: make(map[string]int), stdin: stdin, debug: config.Flags.Debug,
+		Limited: make(map[string]int), overrides: config.levelOverrides(),
+		Escalated:     make(map[string]int),
+		IgnoredScopes: ignoredScopes, SkippedScopes: skippedScopes,
+		MinAlertLevel: config.minAlertLevelFor(fp),
+		TokenIgnores:  config.ignoresFor(fp, config.TokenIgnores),
+		BlockIgnores:  config.ignoresFor(fp, config.BlockIgnores),
+		Vocab:         config.vocabFor(fp),
 	}
 
 	return &file, nil
 }
 
-// SortedAlerts returns all of f's alerts sorted by line and column.
+// SortedAlerts returns all of f's alerts sorted by line and column or, if
+// requested (see `--sort-by`), by severity or check.
 func (f *File) SortedAlerts() []Alert {
-	sort.Sort(ByPosition(f.Alerts))
+	switch f.sortBy {
+	case "severity":
+		sort.Sort(BySeverity(f.Alerts))
+	case "check":
+		sort.Sort(ByCheck(f.Alerts))
+	default:
+		sort.Sort(ByPosition(f.Alerts))
+	}
 	return f.Alerts
 }
 
 // FindLoc calculates the line and span of an Alert.
 func (f *File) FindLoc(ctx, s string, pad, count int, a Alert) (int, []int) {
+	return f.findLoc(ctx, s, pad, count, a, false)
+}
+
+// findLoc is `FindLoc` for an alert that may be in a table's cell (see
+// `initialPosition`).
+func (f *File) findLoc(ctx, s string, pad, count int, a Alert, cell bool) (int, []int) {
 	var length int
 	var lines []string
 
-	pos, substring := initialPosition(ctx, s, a)
+	pos, substring := initialPosition(ctx, s, a, cell)
 	if pos < 0 {
 		// Shouldn't happen ...
 		return pos, []int{0, 0}
@@ -286,7 +459,7 @@ func (f *File) assignLoc(ctx string, blk Block, pad int, a Alert) (int, []int) {
 	for idx, l := range strings.SplitAfter(ctx, "\n") {
 		if idx == blk.Line {
 			length := utf8.RuneCountInString(l)
-			pos, substring := initialPosition(l, blk.Text, a)
+			pos, substring := initialPosition(l, blk.Text, a, blk.Scope.Has("table"))
 
 			loc[0] = pos + pad
 			loc[1] = pos + utf8.RuneCountInString(substring) - 1
@@ -302,6 +475,21 @@ func (f *File) assignLoc(ctx string, blk Block, pad int, a Alert) (int, []int) {
 	return blk.Line + 1, a.Span
 }
 
+// runeSpan converts the byte offsets `span` (e.g., from a regular expression)
+// into the 1-based, inclusive column range of the runes they cover in `txt`.
+func runeSpan(txt string, span []int) []int {
+	start, end := span[0], span[1]
+	if start < 0 || end > len(txt) || start > end {
+		return []int{-1, -1}
+	}
+	first := utf8.RuneCountInString(txt[:start]) + 1
+	last := utf8.RuneCountInString(txt[:end])
+	if last < first {
+		last = first
+	}
+	return []int{first, last}
+}
+
 // AddAlert calculates the in-text location of an Alert and adds it to a File.
 func (f *File) AddAlert(a Alert, blk Block, lines, pad int, lookup bool) {
 	ctx := blk.Context
@@ -309,69 +497,113 @@ func (f *File) AddAlert(a Alert, blk Block, lines, pad int, lookup bool) {
 		ctx = old
 	}
 
-	if !lookup {
+	if blk.Line >= 0 && blk.Scope.Has("raw") {
+		// A raw line is the source itself, so there's nothing to look up.
+		a.Line, a.Span = blk.Line+1, runeSpan(blk.Text, a.Span)
+	} else if !lookup {
 		a.Line, a.Span = f.assignLoc(ctx, blk, pad, a)
 	}
 	if (!lookup && a.Span[0] < 0) || lookup {
-		a.Line, a.Span = f.FindLoc(ctx, blk.Text, pad, lines, a)
+		a.Line, a.Span = f.findLoc(ctx, blk.Text, pad, lines, a, blk.Scope.Has("table"))
 	}
 
 	if a.Span[0] > 0 {
 		f.ChkToCtx[a.Check], _ = Substitute(ctx, a.Match, '#')
-		if !a.Hide {
-			// Ensure that we're not double-reporting an Alert:
-			entry := strings.Join([]string{
-				strconv.Itoa(a.Line),
-				strconv.Itoa(a.Span[0]),
-				a.Check}, "-")
-
-			if _, found := f.history[entry]; !found {
-				// Check rule-assigned limits for reporting:
-				count, found := f.limits[a.Check]
-				if (!found || a.Limit == 0) || count < a.Limit {
-					f.Alerts = append(f.Alerts, a)
-
-					f.history[entry] = 1
-					if a.Limit > 0 {
-						f.limits[a.Check]++
-					}
+		if !a.Hide && !f.isDuplicate(a) {
+			entry := historyKey(a)
+			f.history[entry] = append(f.history[entry], []int{a.Span[0], a.Span[1]})
+
+			// Check rule-assigned limits for reporting:
+			count, found := f.limits[a.Check]
+			if (!found || a.Limit == 0) || count < a.Limit {
+				f.Alerts = append(f.Alerts, a)
+				if a.Limit > 0 {
+					f.limits[a.Check]++
 				}
+			} else {
+				f.Limited[a.Check]++
 			}
 		}
 	}
 }
 
+// historyKey identifies the alerts that `isDuplicate` compares `a` to: those
+// of the same rule, for the same text, on the same line.
+func historyKey(a Alert) string {
+	return strings.Join([]string{strconv.Itoa(a.Line), a.Check, a.Match}, "-")
+}
+
+// isDuplicate determines if `a` has already been reported.
+//
+// The same finding may be reported more than once -- e.g., by instances of
+// a rule with different scopes (say, `paragraph` and `summary`) -- and its
+// column may differ slightly between them, since each scope is located
+// separately. So, rather than requiring the same column, we consider `a` a
+// duplicate of any alert of the same rule and text, on the same line, whose
+// span overlaps its own.
+func (f *File) isDuplicate(a Alert) bool {
+	for _, span := range f.history[historyKey(a)] {
+		if a.Span[0] <= span[1] && span[0] <= a.Span[1] {
+			return true
+		}
+	}
+	return false
+}
+
 var commentControlRE = regexp.MustCompile(`^vale (.+\..+) = (YES|NO)$`)
 
 // UpdateComments sets a new status based on comment.
+//
+// Comments nest: each `vale off` (or `vale Rule = NO`) must be balanced by
+// its own `vale on` (or `vale Rule = YES`), and rule-specific comments are
+// tracked independently of `vale off` -- so, for example, a rule turned off
+// within a `vale off` region remains off after the region ends.
 func (f *File) UpdateComments(comment string) {
 	if comment == "vale off" {
-		f.Comments["off"] = true
+		f.Comments["off"]++
+		f.noteDisabled("off")
 	} else if comment == "vale on" {
-		f.Comments["off"] = false
+		f.reenable("off", comment)
 	} else if commentControlRE.MatchString(comment) {
 		check := commentControlRE.FindStringSubmatch(comment)
 		if len(check) == 3 {
-			f.Comments[check[1]] = check[2] == "NO"
+			if check[2] == "NO" {
+				f.Comments[check[1]]++
+				f.noteDisabled(check[1])
+			} else {
+				f.reenable(check[1], comment)
+			}
 		}
 	}
 }
 
+// reenable closes the innermost 'off' comment for `check`, if there is one.
+func (f *File) reenable(check, comment string) {
+	if f.Comments[check] > 0 {
+		f.Comments[check]--
+	} else if f.debug {
+		fmt.Fprintf(os.Stderr,
+			"%s: redundant '%s' comment (it isn't turned off)\n", f.Path, comment)
+	}
+}
+
+// noteDisabled records that `check` was turned off by a comment.
+func (f *File) noteDisabled(check string) {
+	if !StringInSlice(check, f.Disabled) {
+		f.Disabled = append(f.Disabled, check)
+	}
+}
+
 // QueryComments checks if there has been an in-text comment for this check.
 func (f *File) QueryComments(check string) bool {
-	if !f.Comments["off"] {
-		if status, ok := f.Comments[check]; ok {
-			return status
-		}
-	}
-	return f.Comments["off"]
+	return f.Comments["off"] > 0 || f.Comments[check] > 0
 }
 
 // ResetComments resets the state of all checks back to active.
 func (f *File) ResetComments() {
 	for check := range f.Comments {
 		if check != "off" {
-			f.Comments[check] = false
+			f.Comments[check] = 0
 		}
 	}
 }
@@ -388,3 +620,40 @@ var SentenceTokenizer = tokenize.NewPunktSentenceTokenizer()
 // We wait to initialize it until we need it since it's slow (~1s) and we may
 // not need it.
 var Tagger *tag.PerceptronTagger
+
+var taggerOnce sync.Once
+
+// taggedCache holds the most recently tagged text.
+//
+// NLP-based rules (e.g., `sequence` and `entity`) are all run against the
+// same `summary` blocks, so this saves us from tagging the same text more
+// than once.
+var taggedCache = struct {
+	sync.Mutex
+	text   string
+	model  *tag.PerceptronTagger
+	tokens []tag.Token
+}{}
+
+// cachesOff is 1 once `DisableCaches` has been called.
+var cachesOff int32
+
+// DisableCaches empties our in-memory caches (of tagged text and of the
+// patterns used to locate alerts) and stops them from being filled for the
+// rest of the run -- e.g., when we're close to a memory limit.
+func DisableCaches() {
+	atomic.StoreInt32(&cachesOff, 1)
+
+	cache.Range(func(k, _ interface{}) bool {
+		cache.Delete(k)
+		return true
+	})
+
+	taggedCache.Lock()
+	taggedCache.text, taggedCache.model, taggedCache.tokens = "", nil, nil
+	taggedCache.Unlock()
+}
+
+func cachesEnabled() bool {
+	return atomic.LoadInt32(&cachesOff) == 0
+}
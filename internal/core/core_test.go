@@ -1,7 +1,11 @@
 package core
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/gobwas/glob"
 )
 
 func TestSelectors(t *testing.T) {
@@ -23,4 +27,245 @@ func TestSelectors(t *testing.T) {
 			t.Errorf("expected `true`, got `false`")
 		}
 	}
+
+	// A link's text and URL only match the scopes that name them.
+	for _, tt := range []struct {
+		block, scope string
+		contains     bool
+	}{
+		{"text.link", "text", false},
+		{"text.link", "link", true},
+		{"text.link", "text.link", true},
+		{"text.url", "text", false},
+		{"text.url", "text.url", true},
+		{"text.url", "text.link", false},
+	} {
+		if got := (Selector{Value: tt.block}).ContainsString(tt.scope); got != tt.contains {
+			t.Errorf("%s contains %s: expected %v, got %v", tt.block, tt.scope, tt.contains, got)
+		}
+	}
+}
+
+func TestSectionFor(t *testing.T) {
+	cfg, err := NewConfig(&CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, sec := range []string{"*.{md,adoc}", "*.md", "docs/*.md", "*.adoc"} {
+		cfg.SecToPat[sec] = glob.MustCompile(sec)
+		cfg.TokenIgnores[sec] = []string{sec}
+	}
+	cfg.TokenIgnores["*"] = []string{"*"}
+
+	expected := map[string]string{
+		"a.md":        "*.md",
+		"docs/a.md":   "docs/*.md",
+		"a.adoc":      "*.adoc",
+		"a.txt":       "*",
+		"docs/a.adoc": "*.adoc",
+	}
+
+	for fp, sec := range expected {
+		// Repeat to ensure that map ordering doesn't matter.
+		for i := 0; i < 10; i++ {
+			if got := cfg.ignoresFor(fp, cfg.TokenIgnores); len(got) != 1 || got[0] != sec {
+				t.Fatalf("%s: expected = %v, got = %v", fp, sec, got)
+			}
+		}
+	}
+}
+
+var commentTests = []struct {
+	name  string
+	lines []string
+	// active holds, for each non-comment line, the rules that are on.
+	active []string
+}{
+	{"none", []string{"text"}, []string{"A.x B.y"}},
+	{"off/on", []string{"vale off", "text", "vale on", "text"}, []string{"", "A.x B.y"}},
+	{"rule off/on",
+		[]string{"vale A.x = NO", "text", "vale A.x = YES", "text"},
+		[]string{"B.y", "A.x B.y"}},
+	{"nested off",
+		[]string{"vale off", "vale off", "vale on", "text", "vale on", "text"},
+		[]string{"", "A.x B.y"}},
+	{"rule off twice, on once",
+		[]string{"vale A.x = NO", "vale A.x = NO", "vale A.x = YES", "text", "vale A.x = YES", "text"},
+		[]string{"B.y", "A.x B.y"}},
+	{"rule off inside off",
+		[]string{"vale off", "vale A.x = NO", "text", "vale on", "text", "vale A.x = YES", "text"},
+		[]string{"", "B.y", "A.x B.y"}},
+	{"rule on inside off",
+		[]string{"vale A.x = NO", "vale off", "vale A.x = YES", "text", "vale on", "text"},
+		[]string{"", "A.x B.y"}},
+	{"redundant on",
+		[]string{"vale on", "text", "vale off", "text", "vale on", "text"},
+		[]string{"A.x B.y", "", "A.x B.y"}},
+	{"redundant rule on",
+		[]string{"vale A.x = YES", "vale A.x = NO", "text", "vale A.x = YES", "text"},
+		[]string{"B.y", "A.x B.y"}},
+	{"interleaved rules",
+		[]string{"vale A.x = NO", "vale B.y = NO", "text", "vale A.x = YES", "text", "vale B.y = YES", "text"},
+		[]string{"", "A.x", "A.x B.y"}},
+	{"overlapping regions",
+		[]string{"vale off", "vale B.y = NO", "vale on", "text", "vale off", "vale B.y = YES", "text", "vale on", "text"},
+		[]string{"A.x", "", "A.x B.y"}},
+	{"unknown directive",
+		[]string{"vale maybe", "text", "vale A.x = MAYBE", "text"},
+		[]string{"A.x B.y", "A.x B.y"}},
+}
+
+func TestComments(t *testing.T) {
+	rules := []string{"A.x", "B.y"}
+	for _, tt := range commentTests {
+		f := File{Comments: make(map[string]int)}
+
+		observed := []string{}
+		for _, line := range tt.lines {
+			if line != "text" {
+				f.UpdateComments(line)
+				continue
+			}
+			active := []string{}
+			for _, rule := range rules {
+				if !f.QueryComments(rule) {
+					active = append(active, rule)
+				}
+			}
+			observed = append(observed, strings.Join(active, " "))
+		}
+
+		if strings.Join(observed, "|") != strings.Join(tt.active, "|") {
+			t.Errorf("%s: expected = %q, got = %q", tt.name, tt.active, observed)
+		}
+	}
+}
+
+func TestExplainRun(t *testing.T) {
+	cfg, err := NewConfig(&CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Settings["[*] A.x"] = Setting{Value: "error", Source: "a.ini"}
+	cfg.Settings["[*] B.y"] = Setting{Value: "NO", Source: "a.ini"}
+	cfg.Settings["[*.md] B.y.level"] = Setting{Value: "suggestion", Source: "b.ini"}
+
+	f1 := File{Path: "1.md", Comments: make(map[string]int), overrides: cfg.levelOverrides()}
+	f1.UpdateComments("vale A.x = NO")
+	f1.Limited = map[string]int{"B.y": 2}
+
+	f2 := File{Path: "2.md", Comments: make(map[string]int), overrides: cfg.levelOverrides()}
+	f2.UpdateComments("vale off")
+	f2.UpdateComments("vale A.x = NO")
+	f2.Limited = map[string]int{"B.y": 1}
+
+	report := ExplainRun([]*File{&f2, &f1})
+	if report.Limited["B.y"] != 3 {
+		t.Errorf("expected 3 limited alerts, got %d", report.Limited["B.y"])
+	}
+	if got := strings.Join(report.Disabled["A.x"], " "); got != "1.md 2.md" {
+		t.Errorf("expected 'A.x' off in 1.md and 2.md, got %q", got)
+	} else if got = strings.Join(report.Disabled["off"], " "); got != "2.md" {
+		t.Errorf("expected 'vale off' in 2.md, got %q", got)
+	}
+
+	expected := []LevelOverride{{"A.x", "error", "a.ini"}, {"B.y", "suggestion", "b.ini"}}
+	if len(report.Overridden) != len(expected) {
+		t.Fatalf("expected = %v, got = %v", expected, report.Overridden)
+	}
+	for i, o := range expected {
+		if report.Overridden[i] != o {
+			t.Errorf("expected = %v, got = %v", o, report.Overridden[i])
+		}
+	}
+}
+
+func TestSortedAlerts(t *testing.T) {
+	alerts := []Alert{
+		{Check: "B.b", Severity: "suggestion", Line: 1, Span: []int{5, 6}},
+		{Check: "A.a", Severity: "warning", Line: 2, Span: []int{1, 2}},
+		{Check: "B.b", Severity: "error", Line: 3, Span: []int{1, 2}},
+		{Check: "A.a", Severity: "error", Line: 1, Span: []int{1, 2}},
+	}
+
+	for order, expected := range map[string][]int{
+		"position": {3, 0, 1, 2},
+		"severity": {3, 2, 1, 0},
+		"check":    {3, 1, 0, 2},
+	} {
+		f := File{sortBy: order, Alerts: append([]Alert{}, alerts...)}
+		for i, a := range f.SortedAlerts() {
+			e := alerts[expected[i]]
+			if a.Check != e.Check || a.Line != e.Line || a.Severity != e.Severity {
+				t.Errorf("%s, %d: expected = %v, got = %v", order, i, e, a)
+			}
+		}
+	}
+}
+
+func TestDuplicateAlerts(t *testing.T) {
+	f := File{history: map[string][][]int{
+		historyKey(Alert{Check: "A.a", Match: "foo", Line: 1}): {{5, 7}}}}
+
+	for _, tt := range []struct {
+		a   Alert
+		dup bool
+	}{
+		{Alert{Check: "A.a", Match: "foo", Line: 1, Span: []int{5, 7}}, true},
+		{Alert{Check: "A.a", Match: "foo", Line: 1, Span: []int{6, 8}}, true},
+		{Alert{Check: "A.a", Match: "foo", Line: 1, Span: []int{9, 11}}, false},
+		{Alert{Check: "A.a", Match: "foo", Line: 2, Span: []int{5, 7}}, false},
+		{Alert{Check: "A.a", Match: "foo bar", Line: 1, Span: []int{5, 11}}, false},
+		{Alert{Check: "B.b", Match: "foo", Line: 1, Span: []int{5, 7}}, false},
+	} {
+		if dup := f.isDuplicate(tt.a); dup != tt.dup {
+			t.Errorf("%v: expected duplicate = %v", tt.a, tt.dup)
+		}
+	}
+}
+
+func TestSampleAlerts(t *testing.T) {
+	newFiles := func() []*File {
+		files := []*File{{Path: "2.md"}, {Path: "1.md"}}
+		for i := 0; i < 30; i++ {
+			level := "error"
+			if i%3 == 0 {
+				level = "warning"
+			}
+			f := files[i%2]
+			f.Alerts = append(f.Alerts, Alert{Check: "A.x", Severity: level, Line: i + 1, Span: []int{1, 3}})
+		}
+		files[0].Alerts = append(files[0].Alerts, Alert{Check: "B.y", Severity: "suggestion", Line: 1, Span: []int{5, 7}})
+		return files
+	}
+
+	kept := func(files []*File) string {
+		lines := []string{}
+		for _, f := range files {
+			for _, a := range f.Alerts {
+				lines = append(lines, fmt.Sprintf("%s:%d", f.Path, a.Line))
+			}
+		}
+		return strings.Join(lines, " ")
+	}
+
+	first := newFiles()
+	SampleAlerts(first, 5, 42)
+
+	samples := Samples(first)
+	if len(samples) != 1 {
+		t.Fatalf("expected only A.x to be sampled, got %v", samples)
+	}
+	s := samples["A.x"]
+	if s.Shown != 5 || s.Total != 30 || s.Levels["error"] != 20 || s.Levels["warning"] != 10 {
+		t.Errorf("expected 5 of 30 alerts (20 errors, 10 warnings), got %+v", s)
+	}
+
+	// The same seed always keeps the same alerts.
+	second := newFiles()
+	SampleAlerts(second, 5, 42)
+	if kept(first) != kept(second) {
+		t.Errorf("expected %q, got %q", kept(first), kept(second))
+	}
 }
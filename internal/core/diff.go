@@ -0,0 +1,147 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jdkato/regexp"
+)
+
+// ChangedLines maps the (absolute) path of each file in a diff to the ranges
+// of lines that were added or modified in it.
+type ChangedLines map[string][][]int
+
+// Contains determines if line `n` of the file `fp` was changed.
+func (c ChangedLines) Contains(fp string, n int) bool {
+	abs, err := filepath.Abs(fp)
+	if err != nil {
+		return false
+	}
+	for _, r := range c[abs] {
+		if InRange(n, r) {
+			return true
+		}
+	}
+	return false
+}
+
+var reHunk = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// ParseDiff reads a unified diff (e.g., from `git diff --unified=0`),
+// returning the lines changed in each file.
+//
+// Paths are relative to `root`. Deleted files (`+++ /dev/null`) are skipped,
+// while renamed and added files are recorded under their new names.
+func ParseDiff(r io.Reader, root string) (ChangedLines, error) {
+	changes := ChangedLines{}
+
+	current := ""
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "+++ ") {
+			current = diffPath(strings.TrimPrefix(line, "+++ "), root)
+		} else if m := reHunk.FindStringSubmatch(line); m != nil && current != "" {
+			start, _ := strconv.Atoi(m[1])
+
+			count := 1
+			if m[2] != "" {
+				count, _ = strconv.Atoi(m[2])
+			}
+
+			if count > 0 {
+				// A count of 0 means that lines were only removed.
+				changes[current] = append(
+					changes[current], []int{start, start + count - 1})
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return changes, err
+	}
+	return changes, nil
+}
+
+// diffPath converts a `+++` path into an absolute path (or an empty string
+// for deleted files).
+func diffPath(entry, root string) string {
+	entry = strings.TrimRight(strings.SplitN(entry, "\t", 2)[0], " ")
+	if strings.HasPrefix(entry, `"`) {
+		// Git quotes paths with unusual characters.
+		if unquoted, err := strconv.Unquote(entry); err == nil {
+			entry = unquoted
+		}
+	}
+
+	if entry == "/dev/null" {
+		return ""
+	}
+	entry = strings.TrimPrefix(entry, "b/")
+
+	return filepath.Join(root, filepath.FromSlash(entry))
+}
+
+// GitDiff returns the lines changed since the Git reference `ref` (e.g.,
+// `origin/main`), including any uncommitted changes -- or, if `ref` is a
+// file, the lines changed by the patch it contains.
+func GitDiff(ref string) (ChangedLines, error) {
+	root, err := gitRoot()
+	if FileExists(ref) && !IsDir(ref) {
+		if err != nil {
+			// We're not in a repository, so we assume that the patch's paths
+			// are relative to the current directory.
+			if root, err = os.Getwd(); err != nil {
+				return nil, NewE100("--diff", err)
+			}
+		}
+
+		f, err := os.Open(ref)
+		if err != nil {
+			return nil, NewE100("--diff", err)
+		}
+		defer f.Close()
+
+		return ParseDiff(f, root)
+	} else if err != nil {
+		return nil, err
+	}
+
+	var out, stderr bytes.Buffer
+
+	cmd := exec.Command(
+		"git", "diff", "--unified=0", "--no-color", "--find-renames", ref, "--")
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+
+	if err = cmd.Run(); err != nil {
+		return nil, NewE100("--diff", fmt.Errorf(
+			"git diff %s: %s", ref, strings.TrimSpace(stderr.String())))
+	}
+
+	return ParseDiff(&out, root)
+}
+
+// gitRoot returns the top-level directory of the current Git repository.
+func gitRoot() (string, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", NewE100("--diff", fmt.Errorf(
+			"not a Git repository: %s", strings.TrimSpace(stderr.String())))
+	}
+
+	return filepath.FromSlash(strings.TrimSpace(string(out))), nil
+}
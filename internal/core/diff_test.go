@@ -0,0 +1,87 @@
+package core
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testDiff = `diff --git a/docs/a.md b/docs/a.md
+index 3b18e51..a2e1f9c 100644
+--- a/docs/a.md
++++ b/docs/a.md
+@@ -3 +3 @@ Title
+-old
++new
+@@ -10,0 +11,3 @@ More
++one
++two
++three
+@@ -20,2 +22,0 @@ Gone
+-removed
+-removed
+diff --git a/old.md b/new.md
+similarity index 90%
+rename from old.md
+rename to new.md
+--- a/old.md
++++ b/new.md
+@@ -1 +1 @@
+-before
++after
+diff --git a/added.md b/added.md
+new file mode 100644
+--- /dev/null
++++ b/added.md
+@@ -0,0 +1,2 @@
++hello
++world
+diff --git a/deleted.md b/deleted.md
+deleted file mode 100644
+--- a/deleted.md
++++ /dev/null
+@@ -1,2 +0,0 @@
+-bye
+-bye
+`
+
+func TestParseDiff(t *testing.T) {
+	root, err := filepath.Abs("repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := ParseDiff(strings.NewReader(testDiff), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		file    string
+		line    int
+		changed bool
+	}{
+		{"docs/a.md", 3, true},
+		{"docs/a.md", 4, false},
+		{"docs/a.md", 10, false},
+		{"docs/a.md", 11, true},
+		{"docs/a.md", 13, true},
+		{"docs/a.md", 14, false},
+		{"docs/a.md", 22, false},
+		{"new.md", 1, true},
+		{"old.md", 1, false},
+		{"added.md", 2, true},
+		{"deleted.md", 1, false},
+	}
+
+	for _, c := range cases {
+		fp := filepath.Join(root, filepath.FromSlash(c.file))
+		if got := changes.Contains(fp, c.line); got != c.changed {
+			t.Errorf("%s:%d: expected changed = %v, got %v", c.file, c.line, c.changed, got)
+		}
+	}
+
+	if len(changes) != 3 {
+		t.Errorf("expected 3 files, got %v", changes)
+	}
+}
@@ -0,0 +1,58 @@
+package core
+
+// Escalate raises the level of the alerts of each rule in `thresholds`, which
+// maps a rule (e.g., `Style.Passive`) to the number of matches at which its
+// alerts become each level -- e.g., `{5: warning, 15: error}`.
+//
+// A rule's matches are counted once the whole file has been linted,
+// including those hidden by its `limit`, and its alerts are only ever raised.
+// Those still below the file's `MinAlertLevel` are then removed (see
+// `Linter.shouldRun`, which runs such rules anyway).
+func (f *File) Escalate(thresholds map[string]map[int]string) {
+	if len(thresholds) == 0 {
+		return
+	}
+
+	counts := map[string]int{}
+	for _, a := range f.Alerts {
+		counts[a.Check]++
+	}
+	for rule, n := range f.Limited {
+		counts[rule] += n
+	}
+
+	alerts := []Alert{}
+	for _, a := range f.Alerts {
+		levels, ok := thresholds[a.Check]
+		if !ok {
+			alerts = append(alerts, a)
+			continue
+		}
+
+		escalated := false
+		if level := escalation(levels, counts[a.Check]); level != "" &&
+			LevelToInt[level] > LevelToInt[a.Severity] {
+			a.Severity, escalated = level, true
+		}
+
+		if LevelToInt[a.Severity] >= f.MinAlertLevel {
+			alerts = append(alerts, a)
+			if escalated {
+				f.Escalated[a.Check]++
+			}
+		}
+	}
+	f.Alerts = alerts
+}
+
+// escalation returns the level given by the highest of the thresholds in
+// `levels` that `count` reaches, if any.
+func escalation(levels map[int]string, count int) string {
+	highest, level := 0, ""
+	for n, l := range levels {
+		if count >= n && n > highest {
+			highest, level = n, l
+		}
+	}
+	return level
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestEscalate(t *testing.T) {
+	thresholds := map[string]map[int]string{
+		"A.a": {5: "warning", 15: "error"},
+	}
+
+	for _, tt := range []struct {
+		count     int
+		limited   int
+		min       int
+		level     string
+		escalated int
+	}{
+		{4, 0, 0, "suggestion", 0},
+		{5, 0, 0, "warning", 5},
+		{14, 0, 0, "warning", 14},
+		{15, 0, 0, "error", 15},
+		// Alerts hidden by a rule's limit still count towards it.
+		{3, 2, 0, "warning", 3},
+		// Those that aren't escalated past the minimum level are removed.
+		{4, 0, 1, "", 0},
+		{5, 0, 1, "warning", 5},
+	} {
+		f := File{
+			Limited:       map[string]int{"A.a": tt.limited},
+			Escalated:     map[string]int{},
+			MinAlertLevel: tt.min,
+		}
+		for i := 0; i < tt.count; i++ {
+			f.Alerts = append(f.Alerts, Alert{Check: "A.a", Severity: "suggestion"})
+		}
+		f.Alerts = append(f.Alerts, Alert{Check: "B.b", Severity: "warning"})
+
+		f.Escalate(thresholds)
+
+		n := 0
+		for _, a := range f.Alerts {
+			if a.Check == "B.b" {
+				if a.Severity != "warning" {
+					t.Errorf("%+v: expected B.b to be unchanged, got '%s'", tt, a.Severity)
+				}
+				continue
+			}
+			n++
+			if a.Severity != tt.level {
+				t.Errorf("%+v: expected '%s', got '%s'", tt, tt.level, a.Severity)
+			}
+		}
+
+		if tt.level == "" && n != 0 {
+			t.Errorf("%+v: expected no alerts, got %d", tt, n)
+		} else if tt.level != "" && n != tt.count {
+			t.Errorf("%+v: expected %d alerts, got %d", tt, tt.count, n)
+		}
+		if f.Escalated["A.a"] != tt.escalated {
+			t.Errorf("%+v: expected %d escalated, got %d", tt, tt.escalated, f.Escalated["A.a"])
+		}
+	}
+
+	// An alert is never lowered.
+	f := File{Limited: map[string]int{}, Escalated: map[string]int{},
+		Alerts: []Alert{{Check: "A.a", Severity: "error"}}}
+	f.Escalate(map[string]map[int]string{"A.a": {1: "warning"}})
+	if f.Alerts[0].Severity != "error" || f.Escalated["A.a"] != 0 {
+		t.Errorf("expected an unchanged error, got %+v", f.Alerts[0])
+	}
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultExitCodes maps each alert level to the exit code it causes, unless
+// `SeverityExitCode` (or `--severity-exit-code`) says otherwise: only errors
+// fail a run.
+var DefaultExitCodes = map[string]int{"suggestion": 0, "warning": 0, "error": 1}
+
+// ParseExitCodes parses a mapping of alert levels to exit codes -- e.g.,
+// `error=1, warning=2` -- on top of `DefaultExitCodes`.
+//
+// NOTE: We also exit with code 2 if a file couldn't be linted, so a distinct
+// code for warnings is only distinct if the run otherwise succeeded.
+func ParseExitCodes(value string) (map[string]int, error) {
+	codes := make(map[string]int)
+	for level, code := range DefaultExitCodes {
+		codes[level] = code
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		level := strings.TrimSpace(parts[0])
+		if _, found := LevelToInt[level]; !found || len(parts) != 2 {
+			return nil, fmt.Errorf(
+				"'%s' must be of the form 'level=code', where level is 'suggestion', 'warning', or 'error'", pair)
+		}
+
+		code, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || code < 0 || code > 125 {
+			return nil, fmt.Errorf("'%s' must have an exit code between 0 and 125", pair)
+		}
+		codes[level] = code
+	}
+
+	return codes, nil
+}
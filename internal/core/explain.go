@@ -0,0 +1,105 @@
+package core
+
+import (
+	"sort"
+	"strings"
+)
+
+// LevelOverride records a rule's level being changed by a setting -- e.g.,
+// `Vale.Spelling = error` or `Vale.* = suggestion`.
+type LevelOverride struct {
+	Rule   string // the rule (or `Style.*`) whose level was changed
+	Level  string // the new level
+	Source string // where the setting came from (see `Setting`)
+}
+
+// RunReport describes what changed the alerts shown for a run, beyond the
+// rules themselves (see `--explain-run`).
+type RunReport struct {
+	// Limited maps each rule that reached its `limit` to the number of
+	// alerts that weren't shown as a result.
+	Limited map[string]int
+	// Escalated maps each rule whose alerts were raised by its `escalate`
+	// thresholds to the number of alerts that were.
+	Escalated map[string]int
+	// Overridden lists the rule levels changed by our configuration.
+	Overridden []LevelOverride
+	// Disabled maps each rule turned off by an in-document comment (or
+	// "off", for `vale off`) to the files it was turned off in.
+	Disabled map[string][]string
+}
+
+// Empty determines if nothing changed the run's alerts.
+func (r RunReport) Empty() bool {
+	return len(r.Limited) == 0 && len(r.Escalated) == 0 && len(r.Overridden) == 0 &&
+		len(r.Disabled) == 0
+}
+
+// ExplainRun builds a `RunReport` for the files in `linted`.
+func ExplainRun(linted []*File) RunReport {
+	report := RunReport{
+		Limited:    make(map[string]int),
+		Escalated:  make(map[string]int),
+		Overridden: []LevelOverride{},
+		Disabled:   make(map[string][]string),
+	}
+
+	seen := map[LevelOverride]bool{}
+	for _, f := range linted {
+		for rule, n := range f.Limited {
+			report.Limited[rule] += n
+		}
+		for rule, n := range f.Escalated {
+			report.Escalated[rule] += n
+		}
+		for _, rule := range f.Disabled {
+			report.Disabled[rule] = append(report.Disabled[rule], f.Path)
+		}
+		for _, o := range f.overrides {
+			// Files that share a configuration share its overrides.
+			if !seen[o] {
+				seen[o] = true
+				report.Overridden = append(report.Overridden, o)
+			}
+		}
+	}
+
+	sort.Slice(report.Overridden, func(i, j int) bool {
+		a, b := report.Overridden[i], report.Overridden[j]
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Source < b.Source
+	})
+	for rule := range report.Disabled {
+		sort.Strings(report.Disabled[rule])
+	}
+
+	return report
+}
+
+// levelOverrides lists the rule levels changed by `c`'s settings: either
+// `Style.Rule = level` or `Style.Rule.level = level` (see `RuleParams`).
+func (c *Config) levelOverrides() []LevelOverride {
+	overrides := []LevelOverride{}
+	for name, setting := range c.Settings {
+		key := name
+		if strings.HasPrefix(name, "[") {
+			key = strings.SplitN(name, "] ", 2)[1]
+		}
+
+		parts := strings.Split(key, ".")
+		if len(parts) == 3 && parts[2] == "level" {
+			parts = parts[:2]
+		}
+
+		if len(parts) == 2 && StringInSlice(setting.Value, AlertLevels) {
+			overrides = append(overrides, LevelOverride{
+				Rule:   strings.Join(parts, "."),
+				Level:  setting.Value,
+				Source: setting.Source,
+			})
+		}
+	}
+	return overrides
+}
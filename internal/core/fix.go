@@ -0,0 +1,108 @@
+package core
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Fixable determines if `a` can be fixed automatically -- i.e., it has a
+// `replace` action with exactly one suggestion.
+func Fixable(a Alert) bool {
+	return a.Action.Name == "replace" && len(a.Action.Params) == 1
+}
+
+// Fix applies the suggestion of each of f's fixable alerts to the file on
+// disk. The fixed alerts are removed from f, leaving only those that still
+// need to be addressed.
+//
+// Alerts are skipped if they overlap with another fix or if the text at their
+// location no longer matches (e.g., because the file changed after linting).
+func (f *File) Fix() (int, error) {
+	if f.stdin {
+		return 0, nil
+	}
+
+	fixable := []Alert{}
+	for _, a := range f.Alerts {
+		if Fixable(a) {
+			fixable = append(fixable, a)
+		}
+	}
+
+	if len(fixable) == 0 {
+		return 0, nil
+	}
+
+	fi, err := os.Stat(f.Path)
+	if err != nil {
+		return 0, NewE100("Fix", err)
+	}
+
+	b, err := ReadFileRetry(f.Path)
+	if err != nil {
+		return 0, NewE100("Fix", err)
+	}
+	lines := strings.SplitAfter(string(b), "\n")
+
+	// We work backwards through each line so that a fix doesn't change the
+	// location of those that precede it.
+	sort.SliceStable(fixable, func(i, j int) bool {
+		if fixable[i].Line != fixable[j].Line {
+			return fixable[i].Line < fixable[j].Line
+		}
+		return fixable[i].Span[0] > fixable[j].Span[0]
+	})
+
+	fixed := map[string]bool{}
+	limit := map[int]int{}
+	for _, a := range fixable {
+		idx := a.Line - 1
+		if idx < 0 || idx >= len(lines) {
+			continue
+		}
+
+		runes := []rune(lines[idx])
+		start, target := a.Span[0]-1, a.Match
+		if a.Action.Target != "" {
+			start, target = start-a.Action.Offset, a.Action.Target
+		}
+		end := start + utf8.RuneCountInString(target)
+
+		if bound, found := limit[idx]; found && end > bound {
+			continue
+		} else if start < 0 || end > len(runes) || string(runes[start:end]) != target {
+			continue
+		}
+
+		lines[idx] = string(runes[:start]) + a.Action.Params[0] + string(runes[end:])
+		limit[idx] = start
+		fixed[fixKey(a)] = true
+	}
+
+	if len(fixed) == 0 {
+		return 0, nil
+	}
+
+	err = WriteFileAtomic(f.Path, []byte(strings.Join(lines, "")), fi.Mode())
+	if err != nil {
+		return 0, err
+	}
+
+	remaining := []Alert{}
+	for _, a := range f.Alerts {
+		if !Fixable(a) || !fixed[fixKey(a)] {
+			remaining = append(remaining, a)
+		}
+	}
+	f.Alerts = remaining
+
+	return len(fixed), nil
+}
+
+func fixKey(a Alert) string {
+	return strings.Join([]string{
+		strconv.Itoa(a.Line), strconv.Itoa(a.Span[0]), a.Check}, "-")
+}
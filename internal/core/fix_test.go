@@ -0,0 +1,57 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestFix(t *testing.T) {
+	f, err := ioutil.TempFile("", "fix*.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	content := "# introduction\n\nUse utilize and utilize.\n\n## Using the api\n"
+	if _, err = f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	replace := func(s string) Action {
+		return Action{Name: "replace", Params: []string{s}}
+	}
+
+	file := File{Path: f.Name(), Alerts: []Alert{
+		{Check: "A", Line: 1, Span: []int{3, 14}, Match: "introduction", Action: replace("Introduction")},
+		{Check: "B", Line: 3, Span: []int{5, 11}, Match: "utilize", Action: replace("use")},
+		{Check: "B", Line: 3, Span: []int{17, 23}, Match: "utilize", Action: replace("use")},
+		{Check: "C", Line: 3, Span: []int{1, 3}, Match: "Use", Action: Action{Name: "replace", Params: []string{"a", "b"}}},
+		{Check: "D", Line: 3, Span: []int{1, 3}, Match: "Nope", Action: replace("x")},
+		// The fix may replace more than the alert covers.
+		{Check: "E", Line: 5, Span: []int{14, 16}, Match: "api", Action: Action{
+			Name: "replace", Params: []string{"Using the API"}, Target: "Using the api", Offset: 10}},
+	}}
+
+	n, err := file.Fix()
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 4 {
+		t.Errorf("expected 4 fixes, got %d", n)
+	}
+
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# Introduction\n\nUse use and use.\n\n## Using the API\n"
+	if string(b) != expected {
+		t.Errorf("expected = %q, got = %q", expected, string(b))
+	}
+
+	if len(file.Alerts) != 2 {
+		t.Errorf("expected 2 remaining alerts, got %v", file.Alerts)
+	}
+}
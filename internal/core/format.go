@@ -3,6 +3,7 @@ package core
 import (
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/jdkato/regexp"
 )
@@ -74,7 +75,11 @@ var FormatByExtension = map[string][]string{
 	`\.(?:js)$`:                                   {".c", "code"},
 	`\.(?:lua)$`:                                  {".lua", "code"},
 	`\.(?:md|mdown|markdown|markdn)$`:             {".md", "markup"},
+	`\.(?:mdx)$`:                                  {".mdx", "markup"},
+	`\.(?:org)$`:                                  {".org", "markup"},
+	`\.(?:pdf)$`:                                  {".pdf", "text"},
 	`\.(?:php)$`:                                  {".php", "code"},
+	`\.(?:po|pot)$`:                               {".po", "markup"},
 	`\.(?:pl|pm|pod)$`:                            {".r", "code"},
 	`\.(?:r|R)$`:                                  {".r", "code"},
 	`\.(?:rs)$`:                                   {".rs", "code"},
@@ -85,9 +90,48 @@ var FormatByExtension = map[string][]string{
 	`\.(?:scala|sbt)$`:                            {".c", "code"},
 	`\.(?:hs)$`:                                   {".hs", "code"},
 	`\.(?:xml)$`:                                  {".xml", "markup"},
+	`\.(?:ya?ml)$`:                                {".yml", "markup"},
 	`\.(?:dita)$`:                                 {".dita", "markup"},
 }
 
+// A FormatBlock is a section of a file, as extracted by a `FormatFunc`.
+type FormatBlock struct {
+	Scope string // The block's selector, without an extension (e.g., `text.heading.h1`)
+	Text  string // The block's text content
+	Start int    // The first line (1-based) of the block in its source
+	End   int    // The last line (inclusive) of the block in its source
+}
+
+// A FormatFunc extracts the blocks to be linted from a file's content.
+type FormatFunc func(content string) ([]FormatBlock, error)
+
+// customFormats holds the formats added by `RegisterFormat`, keyed by
+// extension.
+var customFormats = struct {
+	sync.RWMutex
+	funcs map[string]FormatFunc
+}{funcs: make(map[string]FormatFunc)}
+
+// RegisterFormat associates the extension `ext` (e.g., `.cms`) with a custom
+// format, which takes precedence over any built-in format for that extension.
+//
+// Files with this extension are split into blocks by `fn`, each of which is
+// then linted as any other scope (e.g., `text.heading.h1.cms`). Alerts are
+// located using the block's line range.
+func RegisterFormat(ext string, fn FormatFunc) {
+	customFormats.Lock()
+	defer customFormats.Unlock()
+	customFormats.funcs["."+strings.Trim(ext, ".")] = fn
+}
+
+// CustomFormat returns the `FormatFunc` registered for `ext`, if any.
+func CustomFormat(ext string) (FormatFunc, bool) {
+	customFormats.RLock()
+	defer customFormats.RUnlock()
+	fn, found := customFormats.funcs[ext]
+	return fn, found
+}
+
 // FormatFromExt takes a file extension and returns its [normExt, format]
 // list, if supported.
 func FormatFromExt(path string, mapping map[string]string) (string, string) {
@@ -96,6 +140,9 @@ func FormatFromExt(path string, mapping map[string]string) (string, string) {
 		ext = format
 	}
 	ext = "." + ext
+	if _, found := CustomFormat(ext); found {
+		return ext, "custom"
+	}
 	for r, f := range FormatByExtension {
 		m, _ := regexp.MatchString(r, ext)
 		if m {
@@ -6,10 +6,12 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/errata-ai/ini"
 	"github.com/gobwas/glob"
+	"gopkg.in/yaml.v2"
 )
 
 var syntaxOpts = map[string]func(string, *ini.Section, *Config) error{
@@ -30,6 +32,16 @@ var syntaxOpts = map[string]func(string, *ini.Section, *Config) error{
 
 		return nil
 	},
+	"MinAlertLevel": func(label string, sec *ini.Section, cfg *Config) error {
+		level := sec.Key("MinAlertLevel").String()
+		if index, found := LevelToInt[level]; found {
+			cfg.SMinAlertLevel[label] = index
+			return nil
+		}
+		return settingError(
+			"MinAlertLevel must be 'suggestion', 'warning', or 'error'.",
+			label, "MinAlertLevel", level, cfg.Flags.Path)
+	},
 	"IgnorePatterns": func(label string, sec *ini.Section, cfg *Config) error {
 		cfg.BlockIgnores[label] = sec.Key("IgnorePatterns").Strings(",")
 		return nil
@@ -42,6 +54,24 @@ var syntaxOpts = map[string]func(string, *ini.Section, *Config) error{
 		cfg.TokenIgnores[label] = sec.Key("TokenIgnores").Strings(",")
 		return nil
 	},
+	"IgnoredScopes": func(label string, sec *ini.Section, cfg *Config) error {
+		cfg.SIgnoredScopes[label] = mergeValues(sec.Key("IgnoredScopes").StringsWithShadows(","))
+		return nil
+	},
+	"SkippedScopes": func(label string, sec *ini.Section, cfg *Config) error {
+		cfg.SSkippedScopes[label] = mergeValues(sec.Key("SkippedScopes").StringsWithShadows(","))
+		return nil
+	},
+	"Command": func(label string, sec *ini.Section, cfg *Config) error {
+		cfg.Commands[label] = sec.Key("Command").String()
+		return nil
+	},
+	"Project": func(label string, sec *ini.Section, cfg *Config) error {
+		return loadSectionVocab(label, vocabsOf(sec.Key("Project"), cfg), cfg)
+	},
+	"Vocab": func(label string, sec *ini.Section, cfg *Config) error {
+		return loadSectionVocab(label, vocabsOf(sec.Key("Vocab"), cfg), cfg)
+	},
 	"Transform": func(label string, sec *ini.Section, cfg *Config) error {
 		canidate := sec.Key("Transform").String()
 
@@ -70,6 +100,12 @@ var globalOpts = map[string]func(*ini.Section, *Config, []string){
 	"TokenIgnores": func(sec *ini.Section, cfg *Config, args []string) {
 		cfg.TokenIgnores["*"] = sec.Key("TokenIgnores").Strings(",")
 	},
+	"IgnoredScopes": func(sec *ini.Section, cfg *Config, args []string) {
+		cfg.IgnoredScopes = mergeValues(sec.Key("IgnoredScopes").StringsWithShadows(","))
+	},
+	"SkippedScopes": func(sec *ini.Section, cfg *Config, args []string) {
+		cfg.SkippedScopes = mergeValues(sec.Key("SkippedScopes").StringsWithShadows(","))
+	},
 }
 
 var coreOpts = map[string]func(*ini.Section, *Config, []string) error{
@@ -84,8 +120,17 @@ var coreOpts = map[string]func(*ini.Section, *Config, []string) error{
 			entry := sec.Key("StylesPath").MustString("")
 			canidate := filepath.FromSlash(entry)
 
-			cfg.StylesPath = determinePath(cfg.Flags.Path, canidate)
-			if !FileExists(cfg.StylesPath) {
+			if IsRemote(entry) {
+				local, err := fetchStyles(cfg, entry)
+				if err != nil {
+					return err
+				}
+				cfg.StylesPath = local
+			} else {
+				cfg.StylesPath = determinePath(cfg.Flags.Path, canidate)
+			}
+
+			if !FileExists(cfg.StylesPath) && cfg.Flags.Rules == "" {
 				return NewE201FromTarget(
 					fmt.Sprintf("The path '%s' does not exist.", cfg.StylesPath),
 					entry,
@@ -93,6 +138,26 @@ var coreOpts = map[string]func(*ini.Section, *Config, []string) error{
 			}
 
 			cfg.Paths = []string{cfg.StylesPath}
+			if len(cfg.chain) > 1 {
+				// The `StylesPath`s of any parent configurations (see
+				// `LoadChain`), which have already been resolved.
+				for _, p := range mergeValues(paths[1:]) {
+					if IsRemote(p) {
+						local, err := fetchStyles(cfg, p)
+						if err != nil {
+							return err
+						}
+						p = local
+					}
+					if p != cfg.StylesPath && IsDir(p) {
+						cfg.Paths = append(cfg.Paths, p)
+					}
+				}
+			}
+			if p := cfg.userStyles; p != "" && !StringInSlice(p, cfg.Paths) && IsDir(p) {
+				// The user's personal `StylesPath` (see `loadUserConfig`).
+				cfg.Paths = append(cfg.Paths, p)
+			}
 		}
 		return nil
 	},
@@ -126,17 +191,64 @@ var coreOpts = map[string]func(*ini.Section, *Config, []string) error{
 		cfg.SkippedScopes = mergeValues(sec.Key("SkippedScopes").StringsWithShadows(","))
 		return nil
 	},
+	"BuiltIn": func(sec *ini.Section, cfg *Config, args []string) error {
+		// `--no-default-rules` has already been applied, and wins.
+		cfg.BuiltIn = cfg.BuiltIn && sec.Key("BuiltIn").MustBool(true)
+		return nil
+	},
+	"SeverityExitCode": func(sec *ini.Section, cfg *Config, args []string) error {
+		if cfg.Flags.SeverityExit != "" {
+			// `--severity-exit-code` has already been applied, and wins.
+			return nil
+		}
+		value := sec.Key("SeverityExitCode").String()
+		codes, err := ParseExitCodes(value)
+		if err != nil {
+			return NewE201FromTarget(
+				fmt.Sprintf("SeverityExitCode: %s.", err),
+				"SeverityExitCode",
+				cfg.Flags.Path)
+		}
+		cfg.ExitCodes = codes
+		return nil
+	},
+	"UseDocutils": func(sec *ini.Section, cfg *Config, args []string) error {
+		cfg.UseDocutils = sec.Key("UseDocutils").MustBool(false)
+		return nil
+	},
+	"MergeDuplicates": func(sec *ini.Section, cfg *Config, args []string) error {
+		cfg.MergeDups = sec.Key("MergeDuplicates").MustBool(false)
+		return nil
+	},
+	"FailIfEmpty": func(sec *ini.Section, cfg *Config, args []string) error {
+		cfg.FailIfEmpty = cfg.Flags.FailIfEmpty || sec.Key("FailIfEmpty").MustBool(false)
+		return nil
+	},
+	"IgnoreFiles": func(sec *ini.Section, cfg *Config, args []string) error {
+		cfg.IgnoreFiles = mergeValues(sec.Key("IgnoreFiles").StringsWithShadows(","))
+		return nil
+	},
 	"IgnoredClasses": func(sec *ini.Section, cfg *Config, args []string) error {
 		cfg.IgnoredClasses = mergeValues(sec.Key("IgnoredClasses").StringsWithShadows(","))
 		return nil
 	},
+	"LintedAttributes": func(sec *ini.Section, cfg *Config, args []string) error {
+		cfg.LintedAttrs = mergeValues(sec.Key("LintedAttributes").StringsWithShadows(","))
+		return nil
+	},
+	"LintKeys": func(sec *ini.Section, cfg *Config, args []string) error {
+		cfg.LintKeys = mergeValues(sec.Key("LintKeys").StringsWithShadows(","))
+		return nil
+	},
+	"Packages": func(sec *ini.Section, cfg *Config, args []string) error {
+		cfg.Packages = sec.Key("Packages").Strings(",")
+		return nil
+	},
 	"Project": func(sec *ini.Section, cfg *Config, args []string) error {
-		cfg.Project = sec.Key("Project").String()
-		return loadVocab(cfg.Project, cfg)
+		return loadVocabs(vocabsOf(sec.Key("Project"), cfg), cfg)
 	},
 	"Vocab": func(sec *ini.Section, cfg *Config, args []string) error {
-		cfg.Project = sec.Key("Vocab").String()
-		return loadVocab(cfg.Project, cfg)
+		return loadVocabs(vocabsOf(sec.Key("Vocab"), cfg), cfg)
 	},
 	"LTPath": func(sec *ini.Section, cfg *Config, args []string) error {
 		cfg.LTPath = sec.Key("LTPath").String()
@@ -151,26 +263,414 @@ var coreOpts = map[string]func(*ini.Section, *Config, []string) error{
 		cfg.SphinxAuto = sec.Key("SphinxAutoBuild").MustString("")
 		return nil
 	},
+	"TaggerModel": func(sec *ini.Section, cfg *Config, args []string) error {
+		entry := sec.Key("TaggerModel").MustString("")
+		canidate := determinePath(cfg.Flags.Path, filepath.FromSlash(entry))
+		if !FileExists(canidate) {
+			return NewE201FromTarget(
+				fmt.Sprintf("The path '%s' does not exist.", canidate),
+				entry,
+				cfg.Flags.Path)
+		}
+		tagger, err := LoadTagger(canidate)
+		if err != nil {
+			return NewE201FromTarget(
+				fmt.Sprintf("'%s' isn't a valid tagger model: %s.", canidate, err),
+				entry,
+				cfg.Flags.Path)
+		}
+		cfg.TaggerModel = canidate
+		cfg.Tagger = tagger
+		return nil
+	},
+	"MaxScopeBytes": func(sec *ini.Section, cfg *Config, args []string) error {
+		size, err := sec.Key("MaxScopeBytes").Int()
+		if err != nil || size < 0 {
+			return NewE201FromTarget(
+				"MaxScopeBytes must be a non-negative integer.",
+				"MaxScopeBytes",
+				cfg.Flags.Path)
+		}
+		cfg.MaxScopeBytes = size
+		return nil
+	},
+	"MaxNonProseRatio": func(sec *ini.Section, cfg *Config, args []string) error {
+		ratio, err := sec.Key("MaxNonProseRatio").Float64()
+		if err != nil || ratio < 0 || ratio > 1 {
+			return NewE201FromTarget(
+				"MaxNonProseRatio must be a number between 0 and 1.",
+				"MaxNonProseRatio",
+				cfg.Flags.Path)
+		}
+		cfg.MaxNonProse = ratio
+		return nil
+	},
 	"ProcessTimeout": func(sec *ini.Section, cfg *Config, args []string) error {
 		cfg.Timeout = sec.Key("ProcessTimeout").MustInt()
 		return nil
 	},
+	"Inherit": func(sec *ini.Section, cfg *Config, args []string) error {
+		// This only affects how the user's personal configuration is
+		// merged (see `loadUserConfig`).
+		return nil
+	},
+	"Root": func(sec *ini.Section, cfg *Config, args []string) error {
+		// This only affects which configuration files we load (see
+		// `ConfigChain`).
+		return nil
+	},
 }
 
+// shadowLoad loads the configuration files (or sources) given, in order of
+// precedence, as a single INI file. YAML files are converted to INI first
+// (see `yamlToINI`).
 func shadowLoad(source interface{}, others ...interface{}) (*ini.File, error) {
+	sources, err := configSources(append([]interface{}{source}, others...))
+	if err != nil {
+		return nil, err
+	}
 	return ini.LoadSources(ini.LoadOptions{
 		AllowShadows:             true,
-		SpaceBeforeInlineComment: true}, source, others...)
+		SpaceBeforeInlineComment: true}, sources[0], sources[1:]...)
 }
 
+// configSources applies `configSource` to each of `sources`.
+func configSources(sources []interface{}) ([]interface{}, error) {
+	converted := make([]interface{}, len(sources))
+	for i, source := range sources {
+		s, err := configSource(source)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %s", source, err)
+		}
+		converted[i] = s
+	}
+	return converted, nil
+}
+
+// configNames are the file names that we recognize as configuration files.
+//
+// If a directory has both, its INI file takes precedence over its YAML one.
+var configNames = []string{
+	".vale", "_vale", "vale.ini", ".vale.ini", "_vale.ini",
+	"vale.yml", ".vale.yml", "_vale.yml"}
+
+// ConfigChain returns the configuration files that apply to `dir`: the one
+// nearest to it (in `dir` itself or its closest ancestor), followed by those
+// of each ancestor after that.
+//
+// The search stops at the root of a repository (that is, a directory with a
+// `.git` entry) or at a configuration file that sets `Root = true`.
+func ConfigChain(dir string) ([]string, error) {
+	chain := []string{}
+	for {
+		if path := configIn(dir); path != "" {
+			chain = append(chain, path)
+
+			uCfg, err := shadowLoad(path)
+			if err != nil {
+				return chain, NewE100(path, err)
+			} else if uCfg.Section("").Key("Root").MustBool(false) {
+				return chain, nil
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir || FileExists(filepath.Join(dir, ".git")) {
+			return chain, nil
+		}
+		dir = parent
+	}
+}
+
+// findConfig returns the configuration file that `--config` refers to when
+// it's given a directory: the first of `configNames` (in that order) in the
+// directory or, if it has none, in its nearest ancestor that does -- up to
+// the root of its repository (that is, a directory with a `.git` entry).
+func findConfig(dir string) (string, error) {
+	start := dir
+	for {
+		if path := configIn(dir); path != "" {
+			return path, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir || FileExists(filepath.Join(dir, ".git")) {
+			return "", NewE100("--config", fmt.Errorf(
+				"no configuration file found in '%s' or its parents", start))
+		}
+		dir = parent
+	}
+}
+
+// IsConfigFile determines if `fp` is named like a configuration file (see
+// `configNames`).
+func IsConfigFile(fp string) bool {
+	return StringInSlice(filepath.Base(fp), configNames)
+}
+
+// configIn returns the path of the configuration file in `dir`, if any.
+func configIn(dir string) string {
+	for _, name := range configNames {
+		loc := filepath.Join(dir, name)
+		if FileExists(loc) && !IsDir(loc) {
+			return loc
+		}
+	}
+	return ""
+}
+
+// LoadChain loads the configuration files in `chain` (see `ConfigChain`) as a
+// single configuration.
+//
+// Each file's values take precedence over those of the files after it, while
+// styles, checks, and vocabularies are combined.
+func LoadChain(cfg *Config, chain []string) error {
+	if len(chain) == 0 {
+		return NewE100("LoadChain", errors.New("no configuration files"))
+	}
+
+	// Relative paths are relative to the file that contains them, so we need
+	// to resolve them before the files are merged.
+	styles := []string{}
+	for _, path := range chain {
+		uCfg, err := shadowLoad(path)
+		if err != nil {
+			return NewE100(path, err)
+		}
+		if entry := uCfg.Section("").Key("StylesPath").String(); IsRemote(entry) {
+			styles = append(styles, entry)
+		} else if entry != "" {
+			styles = append(styles, determinePath(path, filepath.FromSlash(entry)))
+		}
+	}
+
+	others := []interface{}{}
+	for _, path := range chain[1:] {
+		others = append(others, path)
+	}
+
+	uCfg, err := shadowLoad(chain[0], others...)
+	if err != nil {
+		return NewE100(".vale.ini", err)
+	}
+
+	if len(styles) > 0 {
+		sec := uCfg.Section("")
+		sec.DeleteKey("StylesPath")
+
+		key, err := sec.NewKey("StylesPath", styles[0])
+		if err != nil {
+			return NewE100("LoadChain", err)
+		}
+		for _, p := range styles[1:] {
+			if err = key.AddShadow(p); err != nil {
+				return NewE100("LoadChain", err)
+			}
+		}
+	}
+
+	cfg.Flags.Path = chain[0]
+	cfg.chain = chain
+
+	packages, err := loadPackages(uCfg, cfg)
+	if err != nil {
+		return err
+	}
+
+	user, err := loadUserConfig(uCfg, cfg, chain)
+	if err != nil {
+		return err
+	} else if user != "" {
+		packages = append(packages, user)
+	}
+
+	recordSettings(uCfg, cfg, append(append([]string{}, chain...), packages...))
+	if err = applyEnv(uCfg, cfg); err != nil {
+		return err
+	}
+	applyFlags(cfg)
+
+	uCfg.BlockMode = false
+	return processConfig(uCfg, cfg, chain)
+}
+
+// recordSettings records, in `cfg.Settings`, the value of each of uCfg's
+// settings along with which of `files` (given in order of precedence) it came
+// from. Core settings that aren't set anywhere are recorded as defaults.
+func recordSettings(uCfg *ini.File, cfg *Config, files []string) {
+	loaded := []*ini.File{}
+	paths := []string{}
+	for _, fp := range files {
+		var source interface{} = fp
+		if fp == InlineConfig {
+			source = []byte(os.Getenv("VALE_CONFIG"))
+		}
+
+		if fp == "" {
+			continue
+		} else if f, err := shadowLoad(source); err == nil {
+			loaded = append(loaded, f)
+			paths = append(paths, fp)
+		}
+	}
+
+	for k := range coreOpts {
+		cfg.Settings[k] = Setting{Source: "default"}
+	}
+
+	for _, sec := range uCfg.Sections() {
+		for _, key := range sec.Keys() {
+			source := "default"
+			for i, f := range loaded {
+				if s, err := f.GetSection(sec.Name()); err == nil && s.HasKey(key.Name()) {
+					source = paths[i]
+					break
+				}
+			}
+
+			name := key.Name()
+			if sec.Name() != ini.DefaultSection {
+				name = "[" + sec.Name() + "] " + name
+			}
+
+			cfg.Settings[name] = Setting{
+				Value:  strings.Join(mergeValues(key.ValueWithShadows()), ", "),
+				Source: source,
+			}
+		}
+	}
+}
+
+// applyFlags applies the CLI flags that override settings.
+func applyFlags(cfg *Config) {
+	if StringInSlice(cfg.Flags.AlertLevel, AlertLevels) {
+		cfg.MinAlertLevel = LevelToInt[cfg.Flags.AlertLevel]
+		cfg.Settings["MinAlertLevel"] = Setting{
+			Value: cfg.Flags.AlertLevel, Source: "--minAlertLevel"}
+	}
+	if cfg.Flags.FailIfEmpty {
+		cfg.FailIfEmpty = true
+		cfg.Settings["FailIfEmpty"] = Setting{Value: "true", Source: "--fail-if-empty"}
+	}
+	if codes, err := ParseExitCodes(cfg.Flags.SeverityExit); cfg.Flags.SeverityExit != "" && err == nil {
+		// NOTE: The flag's syntax is checked by `validateFlags`.
+		cfg.ExitCodes = codes
+		cfg.Settings["SeverityExitCode"] = Setting{
+			Value: cfg.Flags.SeverityExit, Source: "--severity-exit-code"}
+	}
+	if cfg.Flags.NoGlobal {
+		cfg.BuiltIn = false
+		cfg.Settings["BuiltIn"] = Setting{Value: "false", Source: "--no-default-rules"}
+	}
+}
+
+// envSettings maps the environment variables that may override a core
+// setting to the setting they override.
+//
+// These take precedence over any configuration file, but not over CLI flags.
+// See also `VALE_CONFIG` and `VALE_CONFIG_PATH` (in `loadINI`) and
+// `VALE_OUTPUT` (`--output`).
+var envSettings = map[string]string{
+	"VALE_STYLES_PATH":     "StylesPath",
+	"VALE_MIN_ALERT_LEVEL": "MinAlertLevel",
+}
+
+// applyEnv replaces the values in `uCfg` with those given by `envSettings`,
+// recording each replacement in `cfg.Settings`.
+func applyEnv(uCfg *ini.File, cfg *Config) error {
+	sec := uCfg.Section("")
+	for env, key := range envSettings {
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+
+		switch key {
+		case "StylesPath":
+			if IsRemote(value) {
+				break
+			}
+			abs, err := filepath.Abs(value)
+			if err != nil {
+				return NewE100(env, err)
+			} else if !IsDir(abs) && cfg.Flags.Rules == "" {
+				return NewE100(env, fmt.Errorf("path '%s' does not exist", abs))
+			}
+			value = abs
+		case "MinAlertLevel":
+			if StringInSlice(cfg.Flags.AlertLevel, AlertLevels) {
+				// `--minAlertLevel` wins.
+				continue
+			} else if _, found := LevelToInt[value]; !found {
+				return NewE100(env, fmt.Errorf(
+					"'%s' must be 'suggestion', 'warning', or 'error'", value))
+			}
+		}
+
+		sec.DeleteKey(key)
+		if _, err := sec.NewKey(key, value); err != nil {
+			return NewE100(env, err)
+		}
+		cfg.Settings[key] = Setting{Value: value, Source: "$" + env}
+	}
+
+	if value := os.Getenv("VALE_OUTPUT"); value != "" && value == cfg.Flags.Output {
+		// NOTE: `VALE_OUTPUT` is the default value of `--output`.
+		cfg.Settings["Output"] = Setting{Value: value, Source: "$VALE_OUTPUT"}
+	}
+
+	return nil
+}
+
+// InlineConfig is the source, in `cfg.Settings`, of the settings given by
+// `VALE_CONFIG`.
+const InlineConfig = "$VALE_CONFIG"
+
+// loadINI loads the user's configuration, which comes from (in order of
+// precedence)
+//
+//  1. the `--config` (or `--sources`) flag, where `--config` may also be a
+//     directory (see `findConfig`);
+//  2. the `VALE_CONFIG` environment variable, which holds the content of a
+//     configuration file -- e.g., for a container with nothing mounted but the
+//     files to lint (relative paths, such as `StylesPath`, are then relative
+//     to the current directory);
+//  3. the `VALE_CONFIG_PATH` environment variable, which holds a path; or
+//  4. the nearest configuration file (see `loadConfig`).
 func loadINI(cfg *Config) error {
 	var base string
 	var uCfg *ini.File
 	var err error
 	var sources []string
 
-	names := []string{
-		".vale", "_vale", "vale.ini", ".vale.ini", "_vale.ini", ""}
+	inline := ""
+	names := append(append([]string{}, configNames...), "")
+	if cfg.Flags.Path != "" && IsDir(cfg.Flags.Path) {
+		found, err := findConfig(cfg.Flags.Path)
+		if err != nil {
+			return err
+		} else if cfg.Flags.Debug {
+			fmt.Fprintf(os.Stderr, "--config: using '%s'\n", found)
+		}
+		cfg.Flags.Path = found
+		cfg.Flags.resolved = Setting{Value: found, Source: "--config"}
+	} else if cfg.Flags.Path == "" && cfg.Flags.Sources == "" {
+		if inline = os.Getenv("VALE_CONFIG"); inline != "" {
+			cfg.Settings["ConfigPath"] = Setting{Source: InlineConfig}
+		} else if env := os.Getenv("VALE_CONFIG_PATH"); env != "" {
+			if !FileExists(env) {
+				return NewE100(
+					"VALE_CONFIG_PATH",
+					fmt.Errorf("path '%s' does not exist", env))
+			}
+			cfg.Flags.Path = env
+			cfg.Flags.resolved = Setting{Value: env, Source: "$VALE_CONFIG_PATH"}
+		}
+	}
+	if cfg.Flags.Path != "" && cfg.Flags.Path == cfg.Flags.resolved.Value {
+		cfg.Settings["ConfigPath"] = cfg.Flags.resolved
+	}
+	cfg.Explicit = cfg.Flags.Path != "" || cfg.Flags.Sources != "" || inline != ""
 
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -187,27 +687,72 @@ func loadINI(cfg *Config) error {
 		sources = []string{base, cfg.Flags.Path}
 	}
 
+	// The files we load, in order of precedence.
+	var files []string
+
 	if cfg.Flags.Local && FileExists(base) && FileExists(cfg.Flags.Path) {
 		uCfg, err = shadowLoad(cfg.Flags.Path, base)
+		files = []string{cfg.Flags.Path, base}
 	} else if cfg.Flags.Remote && FileExists(base) && FileExists(cfg.Flags.Path) {
 		uCfg, err = shadowLoad(base, cfg.Flags.Path)
+		files = []string{base, cfg.Flags.Path}
 		cfg.Flags.Path = base
 	} else if cfg.Flags.Sources != "" {
 		uCfg, err = processSources(cfg, sources)
-	} else {
-		base = loadConfig(names, []string{cfg.Flags.Path, "", home})
+		for i := len(sources) - 1; i >= 0; i-- {
+			// Later sources override earlier ones.
+			files = append(files, sources[i])
+		}
+	} else if inline != "" {
+		uCfg, err = shadowLoad([]byte(inline))
+		files = []string{InlineConfig}
+	} else if base = loadConfig(names, []string{cfg.Flags.Path, "", home}); base != "" {
 		uCfg, err = shadowLoad(base)
+		files = []string{base}
 		cfg.Flags.Path = base
+	} else {
+		// There's no configuration file for the current directory, but each
+		// file we lint may still have its own (see `HasConfig`).
+		uCfg, err = shadowLoad([]byte{})
+		cfg.missing = true
 	}
 
 	if err != nil {
 		return NewE100(".vale.ini", err)
-	} else if StringInSlice(cfg.Flags.AlertLevel, AlertLevels) {
-		cfg.MinAlertLevel = LevelToInt[cfg.Flags.AlertLevel]
 	}
 
+	packages, err := loadPackages(uCfg, cfg)
+	if err != nil {
+		return err
+	}
+	files = append(files, packages...)
+
+	user, err := loadUserConfig(uCfg, cfg, files)
+	if err != nil {
+		return err
+	} else if user != "" {
+		files = append(files, user)
+	}
+
+	recordSettings(uCfg, cfg, files)
+	if err = applyEnv(uCfg, cfg); err != nil {
+		return err
+	}
+	applyFlags(cfg)
+
 	uCfg.BlockMode = false
-	return processConfig(uCfg, cfg, sources)
+	if err = processConfig(uCfg, cfg, sources); err != nil {
+		return err
+	}
+
+	if cfg.Flags.Debug {
+		// Report anything that `vale lint-config` would.
+		for _, problem := range cfg.Validate() {
+			fmt.Fprintln(os.Stderr, problem)
+		}
+	}
+
+	return nil
 }
 
 // loadConfig loads the .vale file. It checks the current directory up to the
@@ -252,18 +797,19 @@ func processSources(cfg *Config, sources []string) (*ini.File, error) {
 
 	if len(sources) == 0 {
 		return uCfg, errors.New("no sources provided")
-	} else if len(sources) == 1 {
-		cfg.Flags.Path = sources[0]
-		return ini.Load(cfg.Flags.Path)
 	}
 
-	t := sources[1:]
-	s := make([]interface{}, len(t))
-	for i, v := range t {
+	s := make([]interface{}, len(sources))
+	for i, v := range sources {
 		s[i] = v
 	}
 
-	uCfg, err = ini.Load(sources[0], s...)
+	s, err = configSources(s)
+	if err != nil {
+		return uCfg, err
+	}
+
+	uCfg, err = ini.Load(s[0], s[1:]...)
 	cfg.Flags.Path = sources[len(sources)-1]
 
 	return uCfg, err
@@ -273,9 +819,17 @@ func processConfig(uCfg *ini.File, cfg *Config, paths []string) error {
 	core := uCfg.Section("")
 	global := uCfg.Section("*")
 	formats := uCfg.Section("formats")
+	plugins := uCfg.Section("plugins")
 
 	// Default settings
-	for _, k := range core.KeyStrings() {
+	//
+	// NOTE: Other settings (e.g., `Vocab`) depend on `StylesPath`, so we
+	// always handle it first.
+	keys := core.KeyStrings()
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i] == "StylesPath" && keys[j] != "StylesPath"
+	})
+	for _, k := range keys {
 		if f, found := coreOpts[k]; found {
 			if err := f(core, cfg, paths); err != nil {
 				return err
@@ -288,10 +842,37 @@ func processConfig(uCfg *ini.File, cfg *Config, paths []string) error {
 		cfg.Formats[k] = formats.Key(k).String()
 	}
 
+	// External rules
+	for _, k := range plugins.KeyStrings() {
+		exe := filepath.FromSlash(plugins.Key(k).String())
+		if strings.ContainsRune(exe, filepath.Separator) {
+			// A path (rather than a command on `$PATH`) is relative to the
+			// configuration file that assigned it.
+			source := cfg.Flags.Path
+			if s, ok := cfg.Settings["[plugins] "+k]; ok && FileExists(s.Source) {
+				source = s.Source
+			}
+			exe = determinePath(source, exe)
+		}
+		cfg.Plugins[k] = exe
+	}
+
 	// Global settings
 	for _, k := range global.KeyStrings() {
 		if f, found := globalOpts[k]; found {
 			f(global, cfg, paths)
+		} else if isRuleParam(k) {
+			if err := addRuleParam(k, global.Key(k).String(), cfg); err != nil {
+				return err
+			}
+		} else if isBuiltin(k, "") {
+			// A built-in style (e.g., `Vale = NO`) may be turned off as a
+			// whole, which is the same as `BuiltIn = false`.
+			if global.Key(k).String() == "NO" && cfg.BuiltIn {
+				cfg.BuiltIn = false
+				cfg.Settings["BuiltIn"] = Setting{
+					Value: "false", Source: cfg.Settings["[*] "+k].Source}
+			}
 		} else {
 			cfg.GChecks[k] = validateLevel(k, global.Key(k).String(), cfg)
 			cfg.Checks = append(cfg.Checks, k)
@@ -300,13 +881,21 @@ func processConfig(uCfg *ini.File, cfg *Config, paths []string) error {
 
 	// Syntax-specific settings
 	for _, sec := range uCfg.SectionStrings() {
-		if sec == "*" || sec == "DEFAULT" || sec == "formats" {
+		if sec == "*" || sec == "DEFAULT" || sec == "formats" || sec == "plugins" {
+			continue
+		} else if isInlineSection(sec) {
+			if err := addInlineRule(uCfg.Section(sec), cfg); err != nil {
+				return err
+			}
 			continue
 		}
 
 		pat, err := glob.Compile(sec)
 		if err != nil {
-			return err
+			return NewE201FromTarget(
+				fmt.Sprintf("The glob pattern '%s' could not be compiled.", sec),
+				sec,
+				cfg.Flags.Path)
 		}
 		cfg.SecToPat[sec] = pat
 
@@ -316,6 +905,10 @@ func processConfig(uCfg *ini.File, cfg *Config, paths []string) error {
 				if err = f(sec, uCfg.Section(sec), cfg); err != nil {
 					return err
 				}
+			} else if isRuleParam(k) {
+				if err = addRuleParam(k, uCfg.Section(sec).Key(k).String(), cfg); err != nil {
+					return err
+				}
 			} else {
 				syntaxMap[k] = validateLevel(k, uCfg.Section(sec).Key(k).String(), cfg)
 				cfg.Checks = append(cfg.Checks, k)
@@ -326,3 +919,45 @@ func processConfig(uCfg *ini.File, cfg *Config, paths []string) error {
 
 	return nil
 }
+
+// isRuleParam determines if `key` overrides one of a rule's parameters --
+// e.g., `Readability.Grade.grade`.
+func isRuleParam(key string) bool {
+	return strings.Count(key, ".") == 2
+}
+
+// addRuleParam records the rule parameter `key` (`Style.Rule.param`), whose
+// value is parsed as YAML (so, e.g., `10` is a number and `[a, b]` is a list).
+//
+// NOTE: Like levels, parameters apply to a rule wherever it's used.
+func addRuleParam(key, value string, cfg *Config) error {
+	var parsed interface{}
+	if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+		return NewE201FromTarget(
+			fmt.Sprintf("'%s' is not a valid value: %s", key, err), key, cfg.Flags.Path)
+	}
+
+	i := strings.LastIndex(key, ".")
+	rule, param := key[:i], key[i+1:]
+	if _, found := cfg.RuleParams[rule]; !found {
+		cfg.RuleParams[rule] = make(map[string]interface{})
+	}
+	cfg.RuleParams[rule][param] = parsed
+
+	return nil
+}
+
+// vocabsOf returns the projects listed by `key`.
+//
+// In a chain of configurations (see `LoadChain`), the nearest configuration's
+// values come first, so we reverse them: this ensures that its projects are
+// loaded last and therefore take precedence.
+func vocabsOf(key *ini.Key, cfg *Config) []string {
+	projects := mergeValues(key.StringsWithShadows(","))
+	if len(cfg.chain) > 1 {
+		for i, j := 0, len(projects)-1; i < j; i, j = i+1, j-1 {
+			projects[i], projects[j] = projects[j], projects[i]
+		}
+	}
+	return projects
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/errata-ai/ini"
+	"gopkg.in/yaml.v2"
+)
+
+// InlineStyle is the style of the rules defined in our configuration file
+// (see `InlineRules`) -- e.g., `[inline.TODO]` defines `Ini.TODO`.
+const InlineStyle = "Ini"
+
+// inlineSection is the prefix of a section that defines an inline rule.
+const inlineSection = "inline."
+
+// isInlineSection determines if `sec` defines an inline rule (e.g.,
+// `[inline.TODO]`) rather than matching files.
+func isInlineSection(sec string) bool {
+	return strings.HasPrefix(sec, inlineSection)
+}
+
+// addInlineRule records the rule defined by the section `sec` (e.g.,
+// `[inline.TODO]`), whose keys are those of a YAML definition:
+//
+//	[inline.TODO]
+//	extends = existence
+//	message = "Remove '%s' before publishing."
+//	tokens = TODO, FIXME
+//
+// Each value is parsed as YAML (falling back to the raw string); the manager
+// splits comma-separated lists (see `check.Manager`).
+//
+// Inline rules are on by default: like any other rule, they may be turned
+// off globally or for a section (`Ini.TODO = NO`).
+func addInlineRule(sec *ini.Section, cfg *Config) error {
+	name := strings.TrimPrefix(sec.Name(), inlineSection)
+	if name == "" || strings.ContainsAny(name, ". ") {
+		return NewE201FromTarget(
+			fmt.Sprintf("'%s' is not a valid rule name.", name),
+			sec.Name(),
+			cfg.Flags.Path)
+	}
+
+	def := make(map[string]interface{})
+	for _, k := range sec.KeyStrings() {
+		value := sec.Key(k).String()
+
+		var parsed interface{}
+		if err := yaml.Unmarshal([]byte(value), &parsed); err != nil || parsed == nil {
+			parsed = value
+		}
+		def[k] = parsed
+	}
+	cfg.InlineRules[name] = def
+
+	key := InlineStyle + "." + name
+	if _, found := cfg.GChecks[key]; !found {
+		cfg.GChecks[key] = true
+		cfg.Checks = append(cfg.Checks, key)
+	}
+
+	return nil
+}
+
+// InlineRuleNames returns the names of our inline rules (e.g., `Ini.TODO`),
+// sorted.
+func (c *Config) InlineRuleNames() []string {
+	names := []string{}
+	for name := range c.InlineRules {
+		names = append(names, InlineStyle+"."+name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// InlineSource returns the configuration file that defined the inline rule
+// `name` (e.g., `TODO`), for error messages.
+func (c *Config) InlineSource(name string) string {
+	prefix := "[" + inlineSection + name + "] "
+	for k, s := range c.Settings {
+		if strings.HasPrefix(k, prefix) && FileExists(s.Source) {
+			return s.Source
+		}
+	}
+	return c.Flags.Path
+}
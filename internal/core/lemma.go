@@ -0,0 +1,87 @@
+package core
+
+import "strings"
+
+// irregularLemmas maps the irregular inflections of common verbs and nouns to
+// their lemmas.
+var irregularLemmas = map[string]string{
+	"am": "be", "is": "be", "are": "be", "was": "be", "were": "be", "been": "be", "being": "be",
+	"has": "have", "had": "have", "having": "have",
+	"does": "do", "did": "do", "done": "do", "doing": "do",
+	"goes": "go", "went": "go", "gone": "go",
+	"made": "make", "said": "say", "got": "get", "gotten": "get",
+	"took": "take", "taken": "take", "came": "come", "saw": "see", "seen": "see",
+	"knew": "know", "known": "know", "gave": "give", "given": "give",
+	"found": "find", "thought": "think", "told": "tell", "became": "become",
+	"felt": "feel", "brought": "bring", "began": "begin", "begun": "begin",
+	"kept": "keep", "held": "hold", "wrote": "write", "written": "write",
+	"ran": "run", "ate": "eat", "eaten": "eat", "built": "build", "sent": "send",
+	"children": "child", "men": "man", "women": "woman", "people": "person",
+	"mice": "mouse", "feet": "foot", "teeth": "tooth", "data": "datum",
+}
+
+// Lemmas returns the possible lemmas of `word`, given its part-of-speech
+// `tag` (see `Tag`): the word itself and, if it's an inflected noun or verb,
+// the forms it may have been inflected from -- e.g., `going/VBG` gives `going`
+// and `go`.
+//
+// Since we don't have a dictionary, regular inflections give more than one
+// candidate (e.g., `making/VBG` gives `mak` and `make`); two words share a
+// lemma if any of their candidates are the same (see `SameLemma`).
+func Lemmas(word, tag string) []string {
+	lemmas := []string{word}
+
+	lower := strings.ToLower(word)
+	if lemma, found := irregularLemmas[lower]; found && (strings.HasPrefix(tag, "VB") || strings.HasPrefix(tag, "NN")) {
+		return append(lemmas, lemma)
+	}
+
+	switch tag {
+	case "NNS", "NNPS", "VBZ":
+		if strings.HasSuffix(lower, "ies") && len(word) > 4 {
+			lemmas = append(lemmas, word[:len(word)-3]+"y")
+		} else if strings.HasSuffix(lower, "es") {
+			lemmas = append(lemmas, word[:len(word)-2], word[:len(word)-1])
+		} else if strings.HasSuffix(lower, "s") && !strings.HasSuffix(lower, "ss") {
+			lemmas = append(lemmas, word[:len(word)-1])
+		}
+	case "VBG", "NN":
+		// The tagger often treats gerunds (e.g., "stopping") as nouns.
+		lemmas = append(lemmas, stems(word, "ing")...)
+	case "VBD", "VBN":
+		lemmas = append(lemmas, stems(word, "ed")...)
+	}
+
+	return lemmas
+}
+
+// SameLemma determines if two lists of candidates (see `Lemmas`) share a
+// lemma.
+func SameLemma(a, b []string) bool {
+	for _, lemma := range a {
+		if StringInSlice(lemma, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// stems returns the candidate stems of `word` without its inflectional
+// `suffix` (e.g., `-ing`): `stopping` gives `stopp`, `stoppe`, and `stop`.
+func stems(word, suffix string) []string {
+	n := len(word) - len(suffix)
+	if n < 2 || !strings.HasSuffix(strings.ToLower(word), suffix) {
+		return nil
+	}
+
+	stem := word[:n]
+	candidates := []string{stem, stem + "e"}
+	if stem[n-1] == stem[n-2] && !strings.ContainsRune("aeiouls", rune(stem[n-1])) {
+		// A doubled consonant (e.g., `running`).
+		candidates = append(candidates, stem[:n-1])
+	} else if suffix == "ed" && stem[n-1] == 'i' {
+		// e.g., `studied`.
+		candidates = append(candidates, stem[:n-1]+"y")
+	}
+	return candidates
+}
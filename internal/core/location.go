@@ -13,7 +13,10 @@ var cache = sync.Map{}
 
 // initialPosition calculates the position of a match (given by the location in
 // the reference document, `loc`) in the source document (`ctx`).
-func initialPosition(ctx, txt string, a Alert) (int, string) {
+//
+// `cell` is true if `txt` is (part of) a table, whose rows we don't want to
+// include in a guessed location (see `guessLocation`).
+func initialPosition(ctx, txt string, a Alert, cell bool) (int, string) {
 	var idx int
 	var pat *regexp.Regexp
 
@@ -33,7 +36,9 @@ func initialPosition(ctx, txt string, a Alert) (int, string) {
 		pat = p.(*regexp.Regexp)
 	} else {
 		pat = regexp.MustCompile(`(?:^|\b|_)` + regexp.QuoteMeta(sub) + `(?:_|\b|$)`)
-		cache.Store(sub, pat)
+		if cachesEnabled() {
+			cache.Store(sub, pat)
+		}
 	}
 
 	fsi := pat.FindStringIndex(ctx)
@@ -42,7 +47,7 @@ func initialPosition(ctx, txt string, a Alert) (int, string) {
 		if idx < 0 {
 			// This should only happen if we're in a scope that contains inline
 			// markup (e.g., a sentence with code spans).
-			return guessLocation(ctx, txt, sub)
+			return guessLocation(ctx, txt, sub, cell)
 		}
 	} else {
 		idx = fsi[0]
@@ -55,7 +60,7 @@ func initialPosition(ctx, txt string, a Alert) (int, string) {
 	return utf8.RuneCountInString(ctx[:idx]) + 1, sub
 }
 
-func guessLocation(ctx, sub, match string) (int, string) {
+func guessLocation(ctx, sub, match string, cell bool) (int, string) {
 	target := ""
 	for _, s := range SentenceTokenizer.Tokenize(sub) {
 		if s == match || strings.Index(s, match) > 0 {
@@ -70,7 +75,20 @@ func guessLocation(ctx, sub, match string) (int, string) {
 	tokens := WordTokenizer.Tokenize(target)
 	for _, text := range strings.Split(ctx, "\n") {
 		if allStringsInString(tokens, text) {
-			return strings.Index(ctx, text) + 1, text
+			idx := strings.Index(ctx, text)
+			if cell && len(tokens) > 0 {
+				// We narrow the guess to the tokens themselves, so that it
+				// doesn't include the rest of the row -- e.g., its `|`
+				// separators or the cells before this one.
+				last := tokens[len(tokens)-1]
+				start := strings.Index(text, tokens[0])
+				end := strings.LastIndex(text, last) + len(last)
+				if end > start {
+					idx += start
+					text = text[start:end]
+				}
+			}
+			return idx + 1, text
 		}
 	}
 
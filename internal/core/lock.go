@@ -0,0 +1,223 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// LockTimeout is how long we'll wait to acquire a lock before giving up.
+var LockTimeout = 30 * time.Second
+
+// staleLock is the age after which we assume a lock's owner has died without
+// releasing it.
+var staleLock = 2 * time.Minute
+
+// retryDelay is how long we wait between attempts to acquire a lock or read a
+// file that's being replaced.
+var retryDelay = 10 * time.Millisecond
+
+// A Lock is an advisory, file-based lock on a path within the `StylesPath`.
+//
+// Multiple Vale processes (e.g., an editor plugin, a CI job, and a pre-commit
+// hook) may share the same `StylesPath`, so any writes to it need to be
+// serialized.
+type Lock struct {
+	path string
+	info os.FileInfo
+}
+
+// AcquireLock takes the lock associated with `target`, waiting up to
+// `LockTimeout` for any other owner to release it.
+//
+// Locks older than `staleLock` are assumed to have been abandoned and are
+// broken (see `breakLock`).
+func AcquireLock(target string) (*Lock, error) {
+	path := target + ".lock"
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, NewE100("AcquireLock", err)
+	}
+
+	deadline := time.Now().Add(LockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, err = f.WriteString(strconv.Itoa(os.Getpid()))
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			var info os.FileInfo
+			if err == nil {
+				info, err = os.Stat(path)
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, NewE100("AcquireLock", err)
+			}
+			return &Lock{path: path, info: info}, nil
+		} else if !os.IsExist(err) {
+			return nil, NewE100("AcquireLock", err)
+		}
+
+		if fi, err := os.Stat(path); err == nil && time.Since(fi.ModTime()) > staleLock {
+			// The owner has (presumably) died without releasing the lock.
+			breakLock(path, fi)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, NewE100(
+				"AcquireLock",
+				fmt.Errorf("timed out waiting for lock '%s'", path))
+		}
+		time.Sleep(retryDelay)
+	}
+}
+
+// breakLock removes the abandoned lock at `path`, described by `stale`.
+//
+// Other processes may be trying to break the same lock, and one of them may
+// have already done so and taken a new lock in its place. So, rather than
+// removing whatever is at `path`, we move it aside -- which only one of us
+// can do -- and then make sure that it's the lock we meant to break, putting
+// it back otherwise.
+func breakLock(path string, stale os.FileInfo) {
+	aside := fmt.Sprintf("%s.stale-%d-%d", path, os.Getpid(), time.Now().UnixNano())
+	if err := os.Rename(path, aside); err != nil {
+		// Someone else got there first.
+		return
+	}
+	defer os.Remove(aside)
+
+	if fi, err := os.Stat(aside); err == nil && !sameLock(fi, stale) {
+		// NOTE: Unlike a rename, a link fails if yet another process has
+		// since taken the lock.
+		os.Link(aside, path)
+	}
+}
+
+// sameLock determines if `a` and `b` describe the same lock file. Since a
+// file's inode may be reused once it's removed, we also compare their
+// modification times.
+func sameLock(a, b os.FileInfo) bool {
+	return os.SameFile(a, b) && a.ModTime().Equal(b.ModTime())
+}
+
+// Release gives up the lock, unless it was broken in the meantime (see
+// `breakLock`) -- in which case, it now belongs to someone else.
+func (l *Lock) Release() error {
+	fi, err := os.Stat(l.path)
+	if os.IsNotExist(err) || (err == nil && !sameLock(fi, l.info)) {
+		return nil
+	}
+
+	if err = os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return NewE100("Release", err)
+	}
+	return nil
+}
+
+// WriteFileAtomic writes `data` to `path` such that concurrent readers see
+// either the old or the new content, but never a partial write.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	lock, err := AcquireLock(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return NewE100("WriteFileAtomic", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return NewE100("WriteFileAtomic", err)
+	} else if err = tmp.Close(); err != nil {
+		return NewE100("WriteFileAtomic", err)
+	} else if err = os.Chmod(tmp.Name(), perm); err != nil {
+		return NewE100("WriteFileAtomic", err)
+	}
+
+	return rename(tmp.Name(), path)
+}
+
+// ReplaceDir moves the directory `src` to `dst`, replacing any existing
+// content at `dst`.
+//
+// This is used to install styles: they're first unpacked into a temporary
+// directory, so a failed or partial download never corrupts an existing
+// `StylesPath`.
+func ReplaceDir(src, dst string) error {
+	lock, err := AcquireLock(dst)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	old := ""
+	if IsDir(dst) {
+		old = fmt.Sprintf("%s.old-%d-%d", dst, os.Getpid(), time.Now().UnixNano())
+		if err = rename(dst, old); err != nil {
+			return err
+		}
+	}
+
+	if err = rename(src, dst); err != nil {
+		if old != "" {
+			// Try to restore the previous version.
+			rename(old, dst)
+		}
+		return err
+	}
+
+	if old != "" {
+		return os.RemoveAll(old)
+	}
+	return nil
+}
+
+// ReadFileRetry reads the file at `path`, tolerating brief periods in which
+// it's missing because another process is replacing it.
+//
+// We only retry while the file's lock exists; any other error (e.g., a
+// permission error) is returned immediately.
+func ReadFileRetry(path string) ([]byte, error) {
+	var b []byte
+	var err error
+
+	deadline := time.Now().Add(LockTimeout)
+	for {
+		b, err = ioutil.ReadFile(path)
+		if err == nil || time.Now().After(deadline) || !os.IsNotExist(err) {
+			return b, err
+		} else if !FileExists(path + ".lock") {
+			// Nobody is replacing it -- it's really missing.
+			return b, err
+		}
+		time.Sleep(retryDelay)
+	}
+}
+
+// rename is `os.Rename`, but retried for a short period to account for
+// Windows, where a rename fails if another process has the target open.
+func rename(src, dst string) error {
+	var err error
+
+	for i := 0; i < 50; i++ {
+		if err = os.Rename(src, dst); err == nil {
+			return nil
+		} else if errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		time.Sleep(retryDelay)
+	}
+
+	return NewE100("rename", err)
+}
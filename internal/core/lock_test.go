@@ -0,0 +1,249 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrentInstalls(t *testing.T) {
+	root, err := ioutil.TempDir("", "styles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	style := filepath.Join(root, "MyStyle")
+	rule := filepath.Join(style, "Rule.yml")
+
+	install := func(n int) error {
+		tmp, err := ioutil.TempDir(root, ".install-")
+		if err != nil {
+			return err
+		}
+		content := fmt.Sprintf("extends: existence\nmessage: '%d'\n", n)
+		err = ioutil.WriteFile(filepath.Join(tmp, "Rule.yml"), []byte(content), 0644)
+		if err != nil {
+			return err
+		}
+		return ReplaceDir(tmp, style)
+	}
+
+	if err = install(0); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := 1; i <= 10; i++ {
+		wg.Add(2)
+		go func(n int) {
+			defer wg.Done()
+			if err := install(n); err != nil {
+				errs <- err
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				b, err := ReadFileRetry(rule)
+				if err != nil {
+					errs <- err
+				} else if !strings.HasPrefix(string(b), "extends: existence") {
+					errs <- fmt.Errorf("partial read: %q", b)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	entries, err := ioutil.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != "MyStyle" {
+			t.Errorf("unexpected leftover '%s'", e.Name())
+		}
+	}
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	root, err := ioutil.TempDir("", "vocab")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	target := filepath.Join(root, "accept.txt")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			content := strings.Repeat(fmt.Sprintf("term%d\n", n), 1000)
+			if err := WriteFileAtomic(target, []byte(content), 0644); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	b, err := ReadFileRetry(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 1000 {
+		t.Fatalf("expected 1000 lines, got %d", len(lines))
+	}
+	for _, l := range lines {
+		if l != lines[0] {
+			t.Fatalf("interleaved write: %q != %q", l, lines[0])
+		}
+	}
+}
+
+func TestStaleLock(t *testing.T) {
+	root, err := ioutil.TempDir("", "lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	target := filepath.Join(root, "target")
+	if err = ioutil.WriteFile(target+".lock", []byte("1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	old := staleLock
+	staleLock = 0
+	defer func() { staleLock = old }()
+
+	lock, err := AcquireLock(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock.Release()
+}
+
+func TestBrokenStaleLock(t *testing.T) {
+	root, err := ioutil.TempDir("", "lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	target := filepath.Join(root, "target")
+	path := target + ".lock"
+
+	// age makes the file at `path` look as if it were modified `d` ago.
+	age := func(d time.Duration) os.FileInfo {
+		then := time.Now().Add(-d)
+		if err := os.Chtimes(path, then, then); err != nil {
+			t.Fatal(err)
+		}
+		fi, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fi
+	}
+
+	if err = ioutil.WriteFile(path, []byte("1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stale := age(time.Hour)
+
+	// Another process breaks the stale lock and takes its own ...
+	breakLock(path, stale)
+	lock, err := AcquireLock(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock.info = age(time.Minute)
+
+	// ... before we get to it, so we leave the new lock alone.
+	breakLock(path, stale)
+	if fi, err := os.Stat(path); err != nil || !sameLock(fi, lock.info) {
+		t.Fatalf("expected the new lock to be kept, got %v", err)
+	}
+
+	// If that lock is itself broken, it belongs to someone else.
+	breakLock(path, lock.info)
+	if err = ioutil.WriteFile(path, []byte("2"), 0644); err != nil {
+		t.Fatal(err)
+	} else if err = lock.Release(); err != nil {
+		t.Fatal(err)
+	} else if !FileExists(path) {
+		t.Error("expected another process's lock to be kept")
+	}
+
+	entries, err := ioutil.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != "target.lock" {
+			t.Errorf("unexpected leftover '%s'", e.Name())
+		}
+	}
+}
+
+func TestReadFileRetry(t *testing.T) {
+	root, err := ioutil.TempDir("", "lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	// A file that's missing without a lock is really missing, and any error
+	// other than a missing file (here, reading a directory) isn't retried --
+	// even if the path is locked.
+	dir := filepath.Join(root, "dir")
+	if err = os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	} else if err = ioutil.WriteFile(dir+".lock", []byte("1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	for _, path := range []string{filepath.Join(root, "missing"), dir} {
+		if _, err = ReadFileRetry(path); err == nil {
+			t.Errorf("%s: expected an error", path)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > LockTimeout/2 {
+		t.Errorf("expected to fail immediately, took %s", elapsed)
+	}
+
+	// A locked file that's being replaced is waited for.
+	target := filepath.Join(root, "target")
+	if err = ioutil.WriteFile(target+".lock", []byte("1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(5 * retryDelay)
+		ioutil.WriteFile(target+".tmp", []byte("new"), 0644)
+		os.Rename(target+".tmp", target)
+	}()
+
+	b, err := ReadFileRetry(target)
+	if err != nil {
+		t.Fatal(err)
+	} else if string(b) != "new" {
+		t.Errorf("expected 'new', got %q", b)
+	}
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MergeDuplicates collapses the alerts that different rules made for the
+// same fix -- e.g., two styles that both swap "utilize" for "use" -- into
+// one (see `MergeDuplicates` in our configuration).
+//
+// Alerts are merged if they have the same location and severity, and they
+// suggest the same fix: the same action parameters (in any order) or, for
+// those without any (e.g., a substitution rule without an `action`), the same
+// message. The alert of the rule that sorts first is kept (rules don't run
+// in a fixed order), with `MergedFrom` listing every rule that made it.
+func (f *File) MergeDuplicates() {
+	alerts := []Alert{}
+	seen := map[string]int{}
+	for _, a := range f.Alerts {
+		key := mergeKey(a)
+		if i, found := seen[key]; found {
+			if alerts[i].Check == a.Check {
+				alerts = append(alerts, a)
+				continue
+			}
+			if len(alerts[i].MergedFrom) == 0 {
+				alerts[i].MergedFrom = []string{alerts[i].Check}
+			}
+			if !StringInSlice(a.Check, alerts[i].MergedFrom) {
+				alerts[i].MergedFrom = append(alerts[i].MergedFrom, a.Check)
+				sort.Strings(alerts[i].MergedFrom)
+			}
+			if a.Check < alerts[i].Check {
+				a.MergedFrom = alerts[i].MergedFrom
+				alerts[i] = a
+			}
+			continue
+		}
+
+		seen[key] = len(alerts)
+		alerts = append(alerts, a)
+	}
+	f.Alerts = alerts
+}
+
+// mergeKey identifies the alerts that `MergeDuplicates` considers the same.
+func mergeKey(a Alert) string {
+	params := append([]string{}, a.Action.Params...)
+	sort.Strings(params)
+	if len(params) == 0 {
+		params = []string{a.Message}
+	}
+
+	return strings.Join([]string{
+		strconv.Itoa(a.Line),
+		strconv.Itoa(a.Span[0]),
+		strconv.Itoa(a.Span[1]),
+		a.Severity,
+		a.Action.Name,
+		strings.Join(params, "\x00"),
+	}, "\x01")
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMergeDuplicates(t *testing.T) {
+	use := Action{Name: "replace", Params: []string{"use"}}
+	alert := func(check, severity string, span []int, action Action) Alert {
+		return Alert{
+			Check: check, Severity: severity, Line: 1, Span: span, Action: action,
+			Message: "Use 'use' instead of 'utilize'."}
+	}
+
+	f := File{Alerts: []Alert{
+		alert("A.Utilize", "warning", []int{5, 11}, use),
+		alert("B.Utilize", "warning", []int{5, 11}, use),
+		alert("C.Utilize", "warning", []int{5, 11}, use),
+		// A different replacement ...
+		alert("D.Utilize", "warning", []int{5, 11}, Action{Name: "replace", Params: []string{"employ"}}),
+		// ... severity ...
+		alert("E.Utilize", "error", []int{5, 11}, use),
+		// ... or span isn't merged.
+		alert("F.Utilize", "warning", []int{20, 26}, use),
+		// Without an action's parameters, we compare messages instead.
+		alert("G.Utilize", "warning", []int{5, 11}, Action{}),
+		alert("H.Utilize", "warning", []int{5, 11}, Action{}),
+		alert("I.Utilize", "warning", []int{5, 11}, Action{Name: "replace"}),
+	}}
+	f.Alerts[len(f.Alerts)-1].Message = "Use 'employ' instead of 'utilize'."
+	f.MergeDuplicates()
+
+	observed := []string{}
+	for _, a := range f.Alerts {
+		observed = append(observed, fmt.Sprintf("%s%v", a.Check, a.MergedFrom))
+	}
+
+	expected := []string{
+		"A.Utilize[A.Utilize B.Utilize C.Utilize]",
+		"D.Utilize[]",
+		"E.Utilize[]",
+		"F.Utilize[]",
+		"G.Utilize[G.Utilize H.Utilize]",
+		"I.Utilize[]",
+	}
+	if fmt.Sprint(observed) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, observed)
+	}
+}
+
+func TestMergeDuplicatesOrder(t *testing.T) {
+	use := Action{Name: "replace", Params: []string{"use"}}
+	a := Alert{
+		Check: "A.Utilize", Severity: "warning", Line: 1, Span: []int{5, 11},
+		Action: use, Message: "Prefer 'use'."}
+	b := Alert{
+		Check: "B.Utilize", Severity: "warning", Line: 1, Span: []int{5, 11},
+		Action: use, Message: "Use 'use' instead of 'utilize'."}
+
+	// Rules don't run in a fixed order, so the kept alert can't depend on
+	// which one came first.
+	for _, alerts := range [][]Alert{{a, b}, {b, a}} {
+		f := File{Alerts: alerts}
+		f.MergeDuplicates()
+
+		if len(f.Alerts) != 1 {
+			t.Fatalf("expected 1 alert, got %d", len(f.Alerts))
+		}
+		kept := f.Alerts[0]
+		if kept.Check != "A.Utilize" || kept.Message != a.Message {
+			t.Errorf("expected A.Utilize's alert, got %s: %q", kept.Check, kept.Message)
+		}
+		if fmt.Sprint(kept.MergedFrom) != "[A.Utilize B.Utilize]" {
+			t.Errorf("unexpected MergedFrom: %v", kept.MergedFrom)
+		}
+	}
+}
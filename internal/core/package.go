@@ -0,0 +1,66 @@
+package core
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/errata-ai/ini"
+)
+
+// PackageConfigs is the directory, within `StylesPath`, that holds the
+// configuration files bundled with our `Packages` (see `vale sync`).
+//
+// They're named `<index>-<package>.ini`, so they sort in the order the
+// packages are listed.
+const PackageConfigs = ".vale-config"
+
+// packageOwned are the settings that a package's configuration can't change.
+var packageOwned = []string{"StylesPath", "Packages"}
+
+// loadPackages merges the configuration files in `PackageConfigs` into
+// `uCfg`, returning their paths.
+//
+// They're merged as if they'd been loaded after `uCfg`'s own files: ours
+// take precedence, while lists such as `BasedOnStyles` are combined.
+func loadPackages(uCfg *ini.File, cfg *Config) ([]string, error) {
+	entry := uCfg.Section("").Key("StylesPath").String()
+	if entry == "" || IsRemote(entry) {
+		// NOTE: `vale sync` installs packages into a local `StylesPath`.
+		return nil, nil
+	}
+
+	dir := filepath.Join(determinePath(cfg.Flags.Path, filepath.FromSlash(entry)), PackageConfigs)
+	paths, err := filepath.Glob(filepath.Join(dir, "*.ini"))
+	if err != nil {
+		return nil, NewE100("loadPackages", err)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		pkg, err := shadowLoad(path)
+		if err != nil {
+			return nil, NewE100(path, err)
+		}
+
+		for _, sec := range pkg.Sections() {
+			target := uCfg.Section(sec.Name())
+			for _, key := range sec.Keys() {
+				if sec.Name() == ini.DefaultSection && StringInSlice(key.Name(), packageOwned) {
+					continue
+				}
+				for _, value := range key.ValueWithShadows() {
+					if existing, err := target.GetKey(key.Name()); err == nil {
+						// A shadow only contributes to multi-value settings
+						// (otherwise, our value wins), so there's nothing
+						// to do if they aren't allowed (see `--sources`).
+						_ = existing.AddShadow(value)
+					} else if _, err = target.NewKey(key.Name(), value); err != nil {
+						return nil, NewE100(path, err)
+					}
+				}
+			}
+		}
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Prefixes of Windows' extended-length paths (e.g., `\\?\C:\docs`), which
+// aren't limited to MAX_PATH characters.
+const (
+	longPrefix    = `\\?\`
+	longUNCPrefix = `\\?\UNC\`
+)
+
+// LongPath returns a form of `p` that can be opened even if it's longer than
+// MAX_PATH characters.
+//
+// On Windows, Go extends absolute paths (with the `\\?\` prefix) as needed,
+// but not relative ones -- so we make `p` absolute. Elsewhere, `p` is
+// returned as is.
+func LongPath(p string) string {
+	if runtime.GOOS != "windows" || p == "" || filepath.IsAbs(p) {
+		return p
+	} else if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return p
+}
+
+// NormalizePath returns `p` with slashes (`/`) as its separators, for
+// `--normalize`.
+//
+// An extended-length prefix is removed, so `\\?\C:\docs` becomes `C:/docs`
+// and `\\?\UNC\server\share` becomes `//server/share`.
+func NormalizePath(p string) string {
+	return normalizePath(p, filepath.Separator)
+}
+
+func normalizePath(p string, sep rune) string {
+	if sep == '\\' {
+		if strings.HasPrefix(p, longUNCPrefix) {
+			p = `\\` + p[len(longUNCPrefix):]
+		} else if strings.HasPrefix(p, longPrefix) {
+			p = p[len(longPrefix):]
+		}
+	}
+	return strings.Replace(p, string(sep), "/", -1)
+}
+
+// RelativePath returns `p` relative to the current directory, for
+// `--relative`.
+//
+// `p` is returned as is if it's already relative or it's outside of the
+// current directory (e.g., on another drive or UNC share).
+func RelativePath(p string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return p
+	}
+	return relativePath(p, wd)
+}
+
+func relativePath(p, wd string) string {
+	if !filepath.IsAbs(p) {
+		return p
+	}
+
+	rel, err := filepath.Rel(wd, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return p
+	} else if !strings.ContainsRune(p, filepath.Separator) {
+		// The path has already been normalized (see `NormalizePath`).
+		rel = filepath.ToSlash(rel)
+	}
+
+	return rel
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestNormalizePath(t *testing.T) {
+	cases := []struct {
+		path     string
+		sep      rune
+		expected string
+	}{
+		{`docs/a.md`, '/', `docs/a.md`},
+		{`/srv/docs/a.md`, '/', `/srv/docs/a.md`},
+		{`docs\a.md`, '\\', `docs/a.md`},
+		{`C:\docs\a.md`, '\\', `C:/docs/a.md`},
+		{`\\server\share\docs\a.md`, '\\', `//server/share/docs/a.md`},
+		{`\\?\C:\docs\a.md`, '\\', `C:/docs/a.md`},
+		{`\\?\UNC\server\share\a.md`, '\\', `//server/share/a.md`},
+	}
+
+	for _, c := range cases {
+		if got := normalizePath(c.path, c.sep); got != c.expected {
+			t.Errorf("normalizePath(%q): expected %q, got %q", c.path, c.expected, got)
+		}
+	}
+}
+
+func TestRelativePath(t *testing.T) {
+	wd := filepath.FromSlash("/home/user/project")
+	if runtime.GOOS == "windows" {
+		wd = `\\server\share\project`
+	}
+
+	cases := map[string]string{
+		filepath.Join(wd, "docs", "a.md"):     filepath.Join("docs", "a.md"),
+		filepath.Join(wd, "a.md"):             "a.md",
+		filepath.Join("docs", "a.md"):         filepath.Join("docs", "a.md"),
+		filepath.Join(wd, "..", "other.md"):   filepath.Join(wd, "..", "other.md"),
+		filepath.ToSlash(wd) + "/docs/b.md":   "docs/b.md",
+		filepath.Join(wd+"-other", "docs.md"): filepath.Join(wd+"-other", "docs.md"),
+	}
+
+	for path, expected := range cases {
+		if got := relativePath(path, wd); got != expected {
+			t.Errorf("relativePath(%q): expected %q, got %q", path, expected, got)
+		}
+	}
+}
+
+func TestDeterminePath(t *testing.T) {
+	config := filepath.FromSlash("/home/user/project/.vale.ini")
+	abs := filepath.FromSlash("/srv/styles")
+	if runtime.GOOS == "windows" {
+		config = `\\server\share\project\.vale.ini`
+		abs = `\\server\share\styles`
+	}
+	dir := filepath.Dir(config)
+
+	cases := map[string]string{
+		"styles":                         filepath.Join(dir, "styles"),
+		filepath.FromSlash("a/b/"):       filepath.Join(dir, "a", "b"),
+		filepath.FromSlash("../s"):       filepath.Join(dir, "..", "s"),
+		abs:                              abs,
+		abs + string(filepath.Separator): abs,
+	}
+
+	for path, expected := range cases {
+		if got := determinePath(config, path); got != expected {
+			t.Errorf("determinePath(%q): expected %q, got %q", path, expected, got)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// longDir creates a directory, under a temporary one, whose path is longer
+// than MAX_PATH characters. It returns both.
+func longDir(t *testing.T) (string, string) {
+	root, err := ioutil.TempDir("", "vale")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	segment := strings.Repeat("d", 50)
+	dir := filepath.Join(root, segment, segment, segment, segment, segment, segment)
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		os.RemoveAll(root)
+		t.Fatal(err)
+	}
+
+	return root, dir
+}
+
+func TestLongPath(t *testing.T) {
+	root, dir := longDir(t)
+	defer os.RemoveAll(root)
+
+	fp := filepath.Join(dir, "test.md")
+	if err := ioutil.WriteFile(fp, []byte("Hello, world!\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if err = os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+
+	rel, err := filepath.Rel(root, fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !FileExists(rel) || !IsDir(filepath.Dir(rel)) {
+		t.Fatalf("'%s' wasn't found", rel)
+	}
+
+	cfg, err := NewConfig(&CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := NewFile(rel, cfg)
+	if err != nil {
+		t.Fatal(err)
+	} else if f.Content != "Hello, world!\n" {
+		t.Errorf("Expected the file's content, got %q", f.Content)
+	}
+}
+
+func TestUNCPath(t *testing.T) {
+	root, err := ioutil.TempDir("", "vale")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	// The administrative share of the temporary directory's drive (e.g.,
+	// `\\localhost\C$\...`), which isn't available everywhere.
+	vol := filepath.VolumeName(root)
+	if len(vol) != 2 {
+		t.Skip("the temporary directory isn't on a drive")
+	}
+	unc := `\\localhost\` + vol[:1] + "$" + root[len(vol):]
+	if !IsDir(unc) {
+		t.Skip("administrative shares aren't available")
+	}
+
+	ini := filepath.Join(unc, ".vale.ini")
+	if got := determinePath(ini, "styles"); got != filepath.Join(unc, "styles") {
+		t.Errorf("Expected '%s', got '%s'", filepath.Join(unc, "styles"), got)
+	}
+
+	if got := NormalizePath(filepath.Join(unc, "a.md")); !strings.HasPrefix(got, "//localhost/") {
+		t.Errorf("Expected a normalized UNC path, got '%s'", got)
+	}
+}
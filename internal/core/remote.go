@@ -0,0 +1,159 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mholt/archiver/v3"
+)
+
+var remoteClient = &http.Client{Timeout: time.Minute}
+
+// IsRemote determines if `entry` (e.g., a `StylesPath`) is an HTTP(S) URL
+// rather than a local path.
+func IsRemote(entry string) bool {
+	return strings.HasPrefix(entry, "http://") || strings.HasPrefix(entry, "https://")
+}
+
+// fetchStyles returns the local copy of the styles published (as a zip file
+// or tarball) at `src`, downloading them into our cache if they've changed
+// since we last did so.
+//
+// If the server can't be reached, we fall back to the cached copy (if any).
+func fetchStyles(cfg *Config, src string) (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", NewE100("StylesPath", err)
+	}
+
+	sum := sha256.Sum256([]byte(src))
+	dir := filepath.Join(cache, "vale", "styles", hex.EncodeToString(sum[:8]))
+	etag := dir + ".etag"
+
+	// NOTE: `ReplaceDir` takes the lock on `dir` itself.
+	lock, err := AcquireLock(dir + ".fetch")
+	if err != nil {
+		return "", err
+	}
+	defer lock.Release()
+
+	req, err := http.NewRequest(http.MethodGet, src, nil)
+	if err != nil {
+		return "", NewE100("StylesPath", err)
+	}
+	if b, err := ioutil.ReadFile(etag); err == nil && IsDir(dir) {
+		req.Header.Set("If-None-Match", string(b))
+	}
+
+	resp, err := remoteClient.Do(req)
+	if err == nil {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotModified {
+			return stylesRoot(dir), nil
+		} else if resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("%s", resp.Status)
+		} else {
+			err = unpackStyles(src, resp.Body, dir)
+		}
+	}
+
+	if err != nil {
+		if !IsDir(dir) {
+			return "", NewE100("StylesPath", fmt.Errorf("could not fetch '%s': %s", src, err))
+		} else if cfg.Flags.Debug {
+			fmt.Fprintf(os.Stderr, "StylesPath: using the cached copy of '%s' (%s)\n", src, err)
+		}
+		return stylesRoot(dir), nil
+	}
+
+	if tag := resp.Header.Get("ETag"); tag != "" {
+		err = WriteFileAtomic(etag, []byte(tag), 0644)
+	} else {
+		err = os.Remove(etag)
+		if os.IsNotExist(err) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return "", NewE100("StylesPath", err)
+	}
+
+	return stylesRoot(dir), nil
+}
+
+// unpackStyles extracts the archive in `body`, downloaded from `src`, into
+// `dir`.
+//
+// It's first unpacked into a temporary directory, so a failed download never
+// replaces the cached copy.
+func unpackStyles(src string, body io.Reader, dir string) error {
+	if err := os.MkdirAll(filepath.Dir(dir), os.ModePerm); err != nil {
+		return err
+	}
+
+	name := "styles.zip"
+	if u, err := url.Parse(src); err == nil && path.Ext(u.Path) != "" {
+		name = path.Base(u.Path)
+	}
+
+	unarchiver := archiver.Unarchiver(archiver.NewZip())
+	if format, err := archiver.ByExtension(name); err == nil {
+		if u, ok := format.(archiver.Unarchiver); ok {
+			unarchiver = u
+		}
+	}
+
+	tmp, err := ioutil.TempDir(filepath.Dir(dir), ".fetch-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	archive := filepath.Join(tmp, name)
+	f, err := os.Create(archive)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	unpacked := filepath.Join(tmp, "styles")
+	if err = os.Mkdir(unpacked, os.ModePerm); err != nil {
+		return err
+	} else if err = unarchiver.Unarchive(archive, unpacked); err != nil {
+		return err
+	}
+
+	return ReplaceDir(unpacked, dir)
+}
+
+// stylesRoot returns the directory within the unpacked archive `dir` that
+// holds its styles: `dir` or, if `dir` only has one directory (e.g.,
+// `styles/`) that isn't itself a style, that directory.
+func stylesRoot(dir string) string {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return dir
+	}
+
+	inner := filepath.Join(dir, entries[0].Name())
+	if rules, _ := filepath.Glob(filepath.Join(inner, "*.yml")); len(rules) > 0 {
+		return dir
+	}
+	return inner
+}
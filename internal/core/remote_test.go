@@ -0,0 +1,77 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemoteStylesPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vale")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	old := os.Getenv("XDG_CACHE_HOME")
+	defer os.Setenv("XDG_CACHE_HOME", old)
+	os.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create("styles/Org/Rule.yml")
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err = f.Write([]byte("extends: existence\n")); err != nil {
+		t.Fatal(err)
+	} else if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Write(buf.Bytes())
+	}))
+
+	ini := filepath.Join(dir, ".vale.ini")
+	content := "StylesPath = " + server.URL + "/styles.zip\n[*]\nBasedOnStyles = Org\n"
+	if err = ioutil.WriteFile(ini, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second load is served from our cache, as is the third (when the
+	// server is unavailable).
+	for i := 0; i < 3; i++ {
+		if i == 2 {
+			server.Close()
+		}
+
+		cfg, err := NewConfig(&CLIFlags{Path: ini, InExt: ".txt"})
+		if err != nil {
+			t.Fatal(err)
+		} else if err = From("ini", cfg); err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+
+		if !FileExists(filepath.Join(cfg.StylesPath, "Org", "Rule.yml")) {
+			t.Errorf("%d: expected 'Org' in '%s'", i, cfg.StylesPath)
+		} else if FindAsset(cfg, "Org") != filepath.Join(cfg.StylesPath, "Org") {
+			t.Errorf("%d: expected assets in '%s'", i, cfg.StylesPath)
+		}
+	}
+
+	if downloads != 1 {
+		t.Errorf("expected 1 download, got %d", downloads)
+	}
+}
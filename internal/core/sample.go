@@ -0,0 +1,112 @@
+package core
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// A RuleSample describes the alerts of a rule that was sampled (see
+// `SampleAlerts`).
+type RuleSample struct {
+	Shown  int            // the number of alerts that were kept
+	Total  int            // the number of alerts before sampling
+	Levels map[string]int // the number of alerts of each level before sampling
+}
+
+// sampled is an alert kept by `SampleAlerts`.
+type sampled struct {
+	file  *File
+	index int
+}
+
+// SampleAlerts keeps at most `size` of each rule's alerts across all of
+// `linted` (see `--sample`), chosen uniformly at random, recording the number
+// of alerts dropped from each file in its `Sampled`.
+//
+// We use a reservoir sampler, so only `size` alerts per rule are held
+// regardless of how many there are. The files are visited by path, and their
+// alerts in order, so that the same `seed` always chooses the same alerts.
+func SampleAlerts(linted []*File, size int, seed int64) {
+	rng := rand.New(rand.NewSource(seed))
+
+	files := make([]*File, len(linted))
+	copy(files, linted)
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	seen := map[string]int{}
+	reservoirs := map[string][]sampled{}
+	for _, f := range files {
+		for i, a := range f.SortedAlerts() {
+			seen[a.Check]++
+			if n := seen[a.Check]; n <= size {
+				reservoirs[a.Check] = append(reservoirs[a.Check], sampled{f, i})
+			} else if j := rng.Intn(n); j < size {
+				reservoirs[a.Check][j] = sampled{f, i}
+			}
+		}
+	}
+
+	kept := map[*File]map[int]bool{}
+	for _, reservoir := range reservoirs {
+		for _, s := range reservoir {
+			if kept[s.file] == nil {
+				kept[s.file] = map[int]bool{}
+			}
+			kept[s.file][s.index] = true
+		}
+	}
+
+	for _, f := range files {
+		alerts := []Alert{}
+		for i, a := range f.Alerts {
+			if kept[f][i] {
+				alerts = append(alerts, a)
+				continue
+			}
+			if f.Sampled == nil {
+				f.Sampled = make(map[string]map[string]int)
+			}
+			if f.Sampled[a.Check] == nil {
+				f.Sampled[a.Check] = make(map[string]int)
+			}
+			f.Sampled[a.Check][a.Severity]++
+		}
+		f.Alerts = alerts
+	}
+}
+
+// Samples reports each rule in `linted` whose alerts were sampled (see
+// `SampleAlerts`).
+func Samples(linted []*File) map[string]RuleSample {
+	samples := map[string]RuleSample{}
+
+	add := func(rule, level string, shown, n int) {
+		s, found := samples[rule]
+		if !found {
+			s = RuleSample{Levels: make(map[string]int)}
+		}
+		s.Shown += shown
+		s.Total += n
+		s.Levels[level] += n
+		samples[rule] = s
+	}
+
+	for _, f := range linted {
+		for rule, levels := range f.Sampled {
+			for level, n := range levels {
+				add(rule, level, 0, n)
+			}
+		}
+	}
+	for _, f := range linted {
+		for _, a := range f.Alerts {
+			if _, found := samples[a.Check]; found {
+				add(a.Check, a.Severity, 1, 1)
+			}
+		}
+	}
+
+	return samples
+}
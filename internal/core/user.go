@@ -0,0 +1,114 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/errata-ai/ini"
+)
+
+// UserConfigDir is the directory, within the user's configuration directory
+// (e.g., `$XDG_CONFIG_HOME` or `~/.config` on Linux, `~/Library/Application
+// Support` on macOS, and `%AppData%` on Windows), that holds their personal
+// configuration file.
+const UserConfigDir = "vale"
+
+// userOwned are the settings that only a project's configuration can
+// change.
+var userOwned = []string{"Packages", "Inherit", "Root"}
+
+// userConfig returns the path of the user's personal configuration file, if
+// they have one.
+func userConfig() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return configIn(filepath.Join(dir, UserConfigDir))
+}
+
+// loadUserConfig merges the user's personal configuration file (see
+// `UserConfigDir`) into `uCfg`, returning its path (if it was merged).
+//
+// It's merged underneath everything else, so the project's settings (and our
+// CLI flags) take precedence: by default, a personal setting only applies if
+// the project doesn't have the same one. A project that sets `Inherit = true`
+// instead combines its lists -- e.g., `BasedOnStyles` and `Vocab` -- with
+// ours, as it would with a parent configuration (see `LoadChain`).
+func loadUserConfig(uCfg *ini.File, cfg *Config, loaded []string) (string, error) {
+	path := userConfig()
+	if path == "" || StringInSlice(path, loaded) {
+		return "", nil
+	}
+
+	user, err := shadowLoad(path)
+	if err != nil {
+		return "", NewE100(path, err)
+	}
+	core := uCfg.Section("")
+	inherit := core.HasKey("Inherit") && core.Key("Inherit").MustBool(false)
+
+	for _, sec := range user.Sections() {
+		target := uCfg.Section(sec.Name())
+		for _, key := range sec.Keys() {
+			name, values := key.Name(), key.ValueWithShadows()
+			if sec.Name() == ini.DefaultSection {
+				if StringInSlice(name, userOwned) {
+					continue
+				} else if name == "StylesPath" {
+					// Our `StylesPath` is searched after the project's (see
+					// `Config.Paths`), so that our vocabularies are found.
+					if IsRemote(values[0]) {
+						continue
+					}
+					cfg.userStyles = determinePath(path, filepath.FromSlash(values[0]))
+					values = []string{cfg.userStyles}
+				}
+			}
+
+			existing, err := target.GetKey(name)
+			if err != nil {
+				if err = newKey(target, name, values); err != nil {
+					return "", NewE100(path, err)
+				}
+				continue
+			} else if !inherit || name == "StylesPath" {
+				continue
+			}
+
+			if name == "Vocab" && len(cfg.chain) <= 1 {
+				// A later vocabulary overrides an earlier one, so ours must
+				// come first (see `vocabsOf`).
+				values = append(values, existing.ValueWithShadows()...)
+				target.DeleteKey(name)
+				if err = newKey(target, name, values); err != nil {
+					return "", NewE100(path, err)
+				}
+				continue
+			}
+
+			for _, value := range values {
+				// As with packages, a shadow only contributes to
+				// multi-value settings (see `loadPackages`).
+				_ = existing.AddShadow(value)
+			}
+		}
+	}
+
+	return path, nil
+}
+
+// newKey adds the key `name` to `sec`, with `values` as its value and
+// shadows.
+func newKey(sec *ini.Section, name string, values []string) error {
+	key, err := sec.NewKey(name, values[0])
+	if err != nil {
+		return err
+	}
+	for _, value := range values[1:] {
+		if err = key.AddShadow(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
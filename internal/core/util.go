@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"encoding/gob"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -108,14 +109,50 @@ func InRange(n int, r []int) bool {
 	return len(r) == 2 && (r[0] <= n && n <= r[1])
 }
 
-// Tag assigns part-of-speech tags to `words`.
-func Tag(words []string) []tag.Token {
-	if Tagger == nil {
-		Tagger = tag.NewPerceptronTagger()
+// Tag assigns part-of-speech tags to `words` using `model` or, if it's nil,
+// our default model.
+func Tag(words []string, model *tag.PerceptronTagger) []tag.Token {
+	if model != nil {
+		return model.Tag(words)
 	}
+	taggerOnce.Do(func() {
+		if Tagger == nil {
+			Tagger = tag.NewPerceptronTagger()
+		}
+	})
 	return Tagger.Tag(words)
 }
 
+// LoadTagger reads a custom part-of-speech tagging model (see
+// `Config.TaggerModel`).
+//
+// The model is a single file consisting of three consecutive gob-encoded
+// values -- the model's classes (`[]string`), its tag map
+// (`map[string]string`), and its weights (`map[string]map[string]float64`) --
+// as returned by a trained `tag.PerceptronTagger`'s `Classes`, `TagMap`, and
+// `Weights` methods, respectively.
+func LoadTagger(path string) (*tag.PerceptronTagger, error) {
+	var classes []string
+	var tags map[string]string
+	var weights map[string]map[string]float64
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dec := gob.NewDecoder(f)
+	for _, v := range []interface{}{&classes, &tags, &weights} {
+		if err = dec.Decode(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return tag.NewTrainedPerceptronTagger(
+		tag.NewAveragedPerceptron(weights, tags, classes)), nil
+}
+
 // TextToWords convert raw text into a slice of words.
 func TextToWords(text string, nlp bool) []string {
 	// TODO: Replace with iterTokenizer?
@@ -133,10 +170,27 @@ func TextToWords(text string, nlp bool) []string {
 	return words
 }
 
-// TextToTokens converts a string to a slice of tokens.
-func TextToTokens(text string, needsTagging bool) []tag.Token {
+// TextToTokens converts a string to a slice of tokens, tagging them with
+// `model` (see `Tag`) if `needsTagging` is true.
+func TextToTokens(text string, needsTagging bool, model *tag.PerceptronTagger) []tag.Token {
 	if needsTagging {
-		return Tag(TextToWords(text, true))
+		taggedCache.Lock()
+		if taggedCache.tokens != nil && taggedCache.text == text && taggedCache.model == model {
+			tokens := append([]tag.Token{}, taggedCache.tokens...)
+			taggedCache.Unlock()
+			return tokens
+		}
+		taggedCache.Unlock()
+
+		tokens := Tag(TextToWords(text, true), model)
+
+		if cachesEnabled() {
+			taggedCache.Lock()
+			taggedCache.text, taggedCache.model, taggedCache.tokens = text, model, tokens
+			taggedCache.Unlock()
+		}
+
+		return append([]tag.Token{}, tokens...)
 	}
 	tokens := []tag.Token{}
 	for _, word := range TextToWords(text, true) {
@@ -146,12 +200,12 @@ func TextToTokens(text string, needsTagging bool) []tag.Token {
 }
 
 // CheckPOS determines if a match (as found by an extension point) also matches
-// the expected part-of-speech in text.
-func CheckPOS(loc []int, expected, text string) bool {
+// the expected part-of-speech in text, as tagged by `model` (see `Tag`).
+func CheckPOS(loc []int, expected, text string, model *tag.PerceptronTagger) bool {
 	pos := 1
 
 	observed := []string{}
-	for _, tok := range TextToTokens(text, true) {
+	for _, tok := range TextToTokens(text, true, model) {
 		if InRange(pos, loc) {
 			observed = append(observed, (tok.Text + "/" + tok.Tag))
 		}
@@ -236,13 +290,13 @@ func Indent(text, indent string) string {
 
 // IsDir determines if the path given by `filename` is a directory.
 func IsDir(filename string) bool {
-	fi, err := os.Stat(filename)
+	fi, err := os.Stat(LongPath(filename))
 	return err == nil && fi.IsDir()
 }
 
 // FileExists determines if the path given by `filename` exists.
 func FileExists(filename string) bool {
-	_, err := os.Stat(filename)
+	_, err := os.Stat(LongPath(filename))
 	return err == nil
 }
 
@@ -297,18 +351,17 @@ func SplitLines(data []byte, atEOF bool) (adv int, token []byte, err error) {
 	return 0, nil, nil
 }
 
+// determinePath resolves `keyPath`, a path given in the configuration file
+// `configPath`, which is relative to the file's directory unless it's
+// absolute (including, on Windows, a UNC path like `\\server\share`).
 func determinePath(configPath string, keyPath string) string {
 	if !IsDir(configPath) {
 		configPath = filepath.Dir(configPath)
 	}
-	sep := string(filepath.Separator)
-	abs, _ := filepath.Abs(keyPath)
-	rel := strings.TrimRight(keyPath, sep)
-	if abs != rel || !strings.Contains(keyPath, sep) {
-		// The path was relative
-		return filepath.Join(configPath, keyPath)
+	if filepath.IsAbs(keyPath) {
+		return filepath.Clean(keyPath)
 	}
-	return abs
+	return filepath.Join(configPath, keyPath)
 }
 
 func mergeValues(shadows []string) []string {
@@ -332,6 +385,61 @@ func validateLevel(key, val string, cfg *Config) bool {
 	return true
 }
 
+// loadVocabs loads each of the given projects, in order, so that a later
+// project may override the terms of an earlier one.
+func loadVocabs(projects []string, cfg *Config) error {
+	cfg.Projects = projects
+	if cfg.Flags.Rules != "" {
+		// A frozen rule set includes its own vocabulary.
+		return nil
+	}
+
+	for _, project := range projects {
+		if err := loadVocab(project, cfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadSectionVocab records the projects given by the section `label` and,
+// unless they're the same as the global ones, loads them as their own
+// vocabulary (see `Config.SVocabs`).
+//
+// Each distinct list of projects is only loaded once, however many sections
+// share it.
+func loadSectionVocab(label string, projects []string, cfg *Config) error {
+	cfg.SProjects[label] = projects
+
+	key := VocabKey(projects)
+	if len(projects) == 0 || key == VocabKey(cfg.Projects) || cfg.SVocabs[key] != nil {
+		return nil
+	} else if cfg.Flags.Rules != "" {
+		// A frozen rule set only includes the global vocabulary.
+		return nil
+	}
+
+	// We load the projects into a copy of our configuration, so that we can
+	// reuse `loadVocabs` without affecting the global vocabulary.
+	scratch := *cfg
+	scratch.AcceptedTokens = make(map[string]struct{})
+	scratch.RejectedTokens = make(map[string]struct{})
+	scratch.Rejections = make(map[string]VocabRejection)
+
+	if err := loadVocabs(projects, &scratch); err != nil {
+		return err
+	}
+
+	cfg.SVocabs[key] = &Vocab{
+		Projects:       projects,
+		AcceptedTokens: scratch.AcceptedTokens,
+		RejectedTokens: scratch.RejectedTokens,
+		Rejections:     scratch.Rejections,
+	}
+	return nil
+}
+
 func loadVocab(root string, cfg *Config) error {
 	target := ""
 	for _, p := range cfg.Paths {
@@ -1,7 +1,18 @@
 package core
 
 import (
+	"encoding/gob"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+
+	"github.com/errata-ai/vale/v2/internal/testutil"
+	"github.com/jdkato/prose/tag"
 )
 
 func TestFormatFromExt(t *testing.T) {
@@ -33,3 +44,501 @@ func TestPrepText(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadTagger(t *testing.T) {
+	base := tag.NewPerceptronTagger()
+
+	f, err := ioutil.TempFile("", "model.*.gob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	enc := gob.NewEncoder(f)
+	for _, v := range []interface{}{base.Classes(), base.TagMap(), base.Weights()} {
+		if err = enc.Encode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.Close()
+
+	model, err := LoadTagger(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	words := []string{"The", "cat", "sat", "on", "the", "mat", "."}
+	expected := base.Tag(words)
+	for i, tok := range Tag(words, model) {
+		if tok.Tag != expected[i].Tag {
+			t.Errorf("expected = %v, got = %v", expected[i], tok)
+		}
+	}
+
+	// A model we can't decode is an error, rather than a silent fallback to
+	// the default model.
+	bad, err := ioutil.TempFile("", "model.*.gob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(bad.Name())
+	bad.WriteString("not a model")
+	bad.Close()
+
+	for _, path := range []string{bad.Name(), bad.Name() + ".missing"} {
+		if _, err = LoadTagger(path); err == nil {
+			t.Errorf("%s: expected an error", path)
+		}
+	}
+
+	// The same goes for `TaggerModel`, which is kept on its `Config`.
+	for path, valid := range map[string]bool{f.Name(): true, bad.Name(): false} {
+		ini := path + ".ini"
+		content := "TaggerModel = " + filepath.ToSlash(path) + "\n"
+		if err = ioutil.WriteFile(ini, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(ini)
+
+		cfg, err := NewConfig(&CLIFlags{Path: ini})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = From("ini", cfg)
+		if valid && (err != nil || cfg.Tagger == nil) {
+			t.Errorf("%s: expected a tagger, got %v", path, err)
+		} else if !valid && (err == nil || !strings.Contains(err.Error(), "E201")) {
+			t.Errorf("%s: expected an E201 error, got %v", path, err)
+		}
+	}
+}
+
+func TestTextToTokensConcurrent(t *testing.T) {
+	texts := []string{"Jane Doe went home.", "We like Acme Corp.", "It rains."}
+
+	expected := map[string]string{}
+	for _, text := range texts {
+		expected[text] = fmt.Sprint(Tag(TextToWords(text, true), nil))
+	}
+
+	// The tagged text is cached, so concurrent callers mustn't see each
+	// other's tokens.
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(text string) {
+			defer wg.Done()
+			if got := fmt.Sprint(TextToTokens(text, true, nil)); got != expected[text] {
+				t.Errorf("%q: expected %s, got %s", text, expected[text], got)
+			}
+		}(texts[i%len(texts)])
+	}
+	wg.Wait()
+}
+
+func TestLoadVocabs(t *testing.T) {
+	dir := t.TempDir()
+
+	lists := map[string]string{
+		"Vocab/Base/accept.txt":     "Vale\nbackend\n",
+		"Vocab/Base/reject.txt":     "frontend\nblacklist\n",
+		"Vocab/ProductA/accept.txt": "frontend\n",
+		"Vocab/ProductA/reject.txt": "backend\n",
+	}
+	testutil.WriteFiles(t, dir, lists)
+
+	for _, order := range [][]string{{"Base", "ProductA"}, {"ProductA", "Base"}} {
+		cfg, err := NewConfig(&CLIFlags{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.Paths = []string{dir}
+
+		if err = loadVocabs(order, cfg); err != nil {
+			t.Fatal(err)
+		}
+
+		last := order[1] == "ProductA"
+		for term, accepted := range map[string]bool{
+			"Vale": true, "blacklist": false, "frontend": last, "backend": !last,
+		} {
+			_, a := cfg.AcceptedTokens[term]
+			_, r := cfg.RejectedTokens[term]
+			if a != accepted || r == accepted {
+				t.Errorf("%v: '%s' expected accepted = %v, got = %v (rejected = %v)",
+					order, term, accepted, a, r)
+			}
+		}
+	}
+
+	cfg, err := NewConfig(&CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Paths = []string{dir}
+	if err = loadVocabs([]string{"Base", "Missing"}, cfg); err == nil {
+		t.Error("expected an error for a missing project")
+	}
+}
+
+func TestVocabPatterns(t *testing.T) {
+	cfg, err := NewConfig(&CLIFlags{Quiet: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := "/Kubernetes 1\\.\\d+/\n/bad(/\n/\n"
+	if err = cfg.addWordList(strings.NewReader(list), true, "accept.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The invalid pattern is skipped, while a lone slash is a word.
+	for term, accepted := range map[string]bool{
+		`/Kubernetes 1\.\d+/`: true, "/bad(/": false, "/": true,
+	} {
+		if _, ok := cfg.AcceptedTokens[term]; ok != accepted {
+			t.Errorf("'%s': expected accepted = %v", term, accepted)
+		}
+	}
+
+	if pat, ok := VocabPattern(`/Config\w*/`); !ok || pat != `Config\w*` {
+		t.Errorf("expected a pattern 'Config\\w*', got '%s' (%v)", pat, ok)
+	} else if term, ok := VocabIgnoreCase("!email"); !ok || term != "email" {
+		t.Errorf("expected a case-insensitive 'email', got %q", term)
+	} else if _, ok = VocabIgnoreCase("macOS"); ok {
+		t.Error("expected 'macOS' to be case-sensitive")
+	} else if _, ok = VocabPattern("Config"); ok {
+		t.Error("expected 'Config' not to be a pattern")
+	}
+}
+
+func TestVocabRejections(t *testing.T) {
+	cfg, err := NewConfig(&CLIFlags{Quiet: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := strings.Join([]string{
+		"foo",
+		"whitelist\tsuggestion\tUse 'allowlist' instead of '%s'.",
+		"utilize\twarning",
+		"bar\tsevere\tToo much.",
+		"baz\t",
+	}, "\n")
+	if err = cfg.addWordList(strings.NewReader(list), false, "reject.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]VocabRejection{
+		"whitelist": {Level: "suggestion", Message: "Use 'allowlist' instead of '%s'."},
+		"utilize":   {Level: "warning"},
+		"bar":       {Message: "Too much."},
+	}
+	if !reflect.DeepEqual(cfg.Rejections, expected) {
+		t.Errorf("expected %v, got %v", expected, cfg.Rejections)
+	}
+
+	for _, term := range []string{"foo", "whitelist", "utilize", "bar", "baz"} {
+		if _, ok := cfg.RejectedTokens[term]; !ok {
+			t.Errorf("expected '%s' to be rejected", term)
+		}
+	}
+
+	// Accepting a term forgets its details.
+	if err = cfg.addWordList(strings.NewReader("utilize"), true, "accept.txt"); err != nil {
+		t.Fatal(err)
+	} else if _, ok := cfg.Rejections["utilize"]; ok {
+		t.Error("expected 'utilize' to be accepted")
+	}
+}
+
+func TestEnvOverrides(t *testing.T) {
+	dir := t.TempDir()
+
+	testutil.WriteFiles(t, dir, map[string]string{
+		".vale.ini":    "StylesPath = styles\nMinAlertLevel = suggestion\n",
+		"styles/.keep": "",
+		"shared/.keep": "",
+	})
+
+	ini := filepath.Join(dir, ".vale.ini")
+
+	env := map[string]string{
+		"VALE_CONFIG_PATH":     ini,
+		"VALE_STYLES_PATH":     filepath.Join(dir, "shared"),
+		"VALE_MIN_ALERT_LEVEL": "error",
+	}
+	for k, v := range env {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	cfg, err := NewConfig(&CLIFlags{AlertLevel: "warning"})
+	if err != nil {
+		t.Fatal(err)
+	} else if err = From("ini", cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Flags.Path != ini {
+		t.Errorf("expected config = %s, got = %s", ini, cfg.Flags.Path)
+	}
+	if cfg.StylesPath != env["VALE_STYLES_PATH"] {
+		t.Errorf("expected StylesPath = %s, got = %s",
+			env["VALE_STYLES_PATH"], cfg.StylesPath)
+	}
+	if cfg.MinAlertLevel != LevelToInt["warning"] {
+		// The flag takes precedence over the environment.
+		t.Errorf("expected MinAlertLevel = %d, got = %d",
+			LevelToInt["warning"], cfg.MinAlertLevel)
+	}
+
+	expected := map[string]string{
+		"ConfigPath":    "$VALE_CONFIG_PATH",
+		"StylesPath":    "$VALE_STYLES_PATH",
+		"MinAlertLevel": "--minAlertLevel",
+	}
+	for k, v := range expected {
+		if cfg.Settings[k].Source != v {
+			t.Errorf("expected %s source = %s, got = %v", k, v, cfg.Settings[k])
+		}
+	}
+
+	os.Setenv("VALE_MIN_ALERT_LEVEL", "fatal")
+	cfg, _ = NewConfig(&CLIFlags{})
+	if err = From("ini", cfg); err == nil {
+		t.Error("expected an error for an invalid VALE_MIN_ALERT_LEVEL")
+	}
+}
+
+func TestInlineConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	testutil.WriteFiles(t, dir, map[string]string{
+		".vale.ini":    "StylesPath = styles\nMinAlertLevel = error\n",
+		"styles/.keep": "",
+	})
+
+	ini := filepath.Join(dir, ".vale.ini")
+
+	inline := fmt.Sprintf("StylesPath = %s\nMinAlertLevel = warning\n[*.md]\nBasedOnStyles = Vale\n",
+		filepath.ToSlash(filepath.Join(dir, "styles")))
+	for k, v := range map[string]string{"VALE_CONFIG": inline, "VALE_CONFIG_PATH": ini} {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	// `VALE_CONFIG` takes precedence over `VALE_CONFIG_PATH` ...
+	cfg, err := NewConfig(&CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	} else if err = From("ini", cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.MinAlertLevel != LevelToInt["warning"] || !cfg.Explicit {
+		t.Errorf("expected the inline config, got MinAlertLevel = %d", cfg.MinAlertLevel)
+	}
+	for _, k := range []string{"ConfigPath", "MinAlertLevel", "[*.md] BasedOnStyles"} {
+		if cfg.Settings[k].Source != "$VALE_CONFIG" {
+			t.Errorf("expected %s source = $VALE_CONFIG, got = %v", k, cfg.Settings[k])
+		}
+	}
+
+	// ... but not over `--config`.
+	cfg, _ = NewConfig(&CLIFlags{Path: ini})
+	if err = From("ini", cfg); err != nil {
+		t.Fatal(err)
+	} else if cfg.MinAlertLevel != LevelToInt["error"] {
+		t.Errorf("expected the --config file, got MinAlertLevel = %d", cfg.MinAlertLevel)
+	}
+}
+
+func TestConfigDirectory(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		".git/HEAD":       "",
+		".vale.ini":       "MinAlertLevel = error\n",
+		"_vale.ini":       "MinAlertLevel = warning\n",
+		"docs/api/doc.md": "",
+		"empty/.git/HEAD": "",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	// `.vale.ini` comes before `_vale.ini`, and ancestors are searched.
+	for _, sub := range []string{"", "docs/api"} {
+		cfg, _ := NewConfig(&CLIFlags{Path: filepath.Join(dir, filepath.FromSlash(sub))})
+		if err := From("ini", cfg); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := filepath.Join(dir, ".vale.ini")
+		if cfg.Flags.Path != expected || cfg.MinAlertLevel != LevelToInt["error"] {
+			t.Errorf("%s: expected '%s', got '%s'", sub, expected, cfg.Flags.Path)
+		} else if s := cfg.Settings["ConfigPath"]; s.Value != expected || s.Source != "--config" {
+			t.Errorf("%s: expected ConfigPath = '%s', got %v", sub, expected, s)
+		}
+	}
+
+	// The search stops at the root of a repository.
+	cfg, _ := NewConfig(&CLIFlags{Path: filepath.Join(dir, "empty")})
+	if err := From("ini", cfg); err == nil || !strings.Contains(err.Error(), "no configuration file") {
+		t.Errorf("expected an error for a directory without a config, got %v", err)
+	}
+}
+
+func TestSettings(t *testing.T) {
+	dir := t.TempDir()
+
+	testutil.WriteFiles(t, dir, map[string]string{
+		"base.ini":     "StylesPath = styles\nMinAlertLevel = suggestion\nWordTemplate = \\b(?:%s)\\b\n[*.md]\nBasedOnStyles = Vale\n",
+		"override.ini": "StylesPath = styles\nMinAlertLevel = warning\n[*.md]\nVale.Spelling = NO\n",
+		"styles/.keep": "",
+	})
+
+	base := filepath.Join(dir, "base.ini")
+	override := filepath.Join(dir, "override.ini")
+
+	cfg, err := NewConfig(&CLIFlags{Sources: base + "," + override})
+	if err != nil {
+		t.Fatal(err)
+	} else if err = From("ini", cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]Setting{
+		"MinAlertLevel":        {Value: "warning", Source: override},
+		"WordTemplate":         {Value: `\b(?:%s)\b`, Source: base},
+		"[*.md] BasedOnStyles": {Value: "Vale", Source: base},
+		"[*.md] Vale.Spelling": {Value: "NO", Source: override},
+		"DictionaryPath":       {Source: "default"},
+	}
+	for name, setting := range expected {
+		if cfg.Settings[name] != setting {
+			t.Errorf("%s: expected = %v, got = %v", name, setting, cfg.Settings[name])
+		}
+	}
+}
+
+func TestUserConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	old, set := os.LookupEnv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "home"))
+	defer func() {
+		if set {
+			os.Setenv("XDG_CONFIG_HOME", old)
+		} else {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		}
+	}()
+	if home, _ := os.UserConfigDir(); home != filepath.Join(dir, "home") {
+		t.Skip("the user's configuration directory doesn't follow $XDG_CONFIG_HOME")
+	}
+
+	user := filepath.Join(dir, "home", UserConfigDir, ".vale.ini")
+	files := map[string]string{
+		"home/vale/.vale.ini": strings.Join([]string{
+			"StylesPath = styles",
+			"MinAlertLevel = error",
+			"Vocab = Me",
+			"[*]",
+			"BasedOnStyles = Personal",
+		}, "\n"),
+		"home/vale/styles/Vocab/Me/accept.txt": "Jdoe\n",
+		"home/vale/styles/Personal/.keep":      "",
+		"project/styles/.keep":                 "",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	project := filepath.Join(dir, "project", ".vale.ini")
+	for _, inherit := range []bool{false, true} {
+		content := "StylesPath = styles\nMinAlertLevel = warning\n[*]\nBasedOnStyles = Vale\n"
+		if inherit {
+			content = "Inherit = true\n" + content
+		}
+		if err := ioutil.WriteFile(project, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := NewConfig(&CLIFlags{Path: project})
+		if err != nil {
+			t.Fatal(err)
+		} else if err = From("ini", cfg); err != nil {
+			t.Fatal(err)
+		}
+
+		// The project's settings win, while our own fill in the rest.
+		if cfg.MinAlertLevel != LevelToInt["warning"] {
+			t.Errorf("inherit = %v: expected MinAlertLevel = warning, got %d", inherit, cfg.MinAlertLevel)
+		} else if _, found := cfg.AcceptedTokens["Jdoe"]; !found {
+			t.Errorf("inherit = %v: expected our vocabulary, got %v", inherit, cfg.AcceptedTokens)
+		} else if cfg.Settings["Vocab"].Source != user {
+			t.Errorf("inherit = %v: expected Vocab from %s, got %v", inherit, user, cfg.Settings["Vocab"])
+		}
+
+		// Our styles are only added if the project opts in.
+		expected := []string{"Vale"}
+		if inherit {
+			expected = append(expected, "Personal")
+		}
+		if !reflect.DeepEqual(cfg.GBaseStyles, expected) {
+			t.Errorf("inherit = %v: expected BasedOnStyles = %v, got %v", inherit, expected, cfg.GBaseStyles)
+		}
+	}
+}
+
+func TestSectionMinAlertLevel(t *testing.T) {
+	dir := t.TempDir()
+
+	testutil.WriteFiles(t, dir, map[string]string{
+		".vale.ini": strings.Join([]string{
+			"StylesPath = styles",
+			"MinAlertLevel = suggestion",
+			"[legacy/**]",
+			"MinAlertLevel = error",
+			"[guides/**]",
+			"MinAlertLevel = warning",
+		}, "\n"),
+		"styles/.keep": "",
+	})
+
+	ini := filepath.Join(dir, ".vale.ini")
+
+	for flag, expected := range map[string]map[string]int{
+		"":           {"legacy/a.md": 2, "guides/a.md": 1, "a.md": 0},
+		"suggestion": {"legacy/a.md": 0, "guides/a.md": 0, "a.md": 0},
+	} {
+		cfg, err := NewConfig(&CLIFlags{Path: ini, AlertLevel: flag, InExt: ".txt"})
+		if err != nil {
+			t.Fatal(err)
+		} else if err = From("ini", cfg); err != nil {
+			t.Fatal(err)
+		}
+
+		for fp, level := range expected {
+			if got := cfg.minAlertLevelFor(fp); got != level {
+				t.Errorf("%q, %s: expected = %d, got = %d", flag, fp, level, got)
+			}
+		}
+	}
+}
+
+func TestParseExitCodes(t *testing.T) {
+	codes, err := ParseExitCodes("error=1, warning = 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]int{"suggestion": 0, "warning": 2, "error": 1}
+	if fmt.Sprint(codes) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, codes)
+	}
+
+	for _, bad := range []string{"fatal=1", "error", "error=x", "error=-1", "error=300"} {
+		if _, err = ParseExitCodes(bad); err == nil {
+			t.Errorf("expected '%s' to be rejected", bad)
+		}
+	}
+}
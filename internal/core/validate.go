@@ -0,0 +1,237 @@
+package core
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gobwas/glob"
+)
+
+// builtinRules holds the names of the rules that don't have a YAML file on
+// `StylesPath`, keyed by style -- e.g., `Vale: [Spelling, Terms, ...]`.
+var builtinRules = struct {
+	sync.RWMutex
+	styles map[string][]string
+}{styles: make(map[string][]string)}
+
+// RegisterBuiltinRules records the names (e.g., `Vale.Spelling`) of rules
+// that are built into Vale, so that `Validate` doesn't expect to find them on
+// `StylesPath`.
+func RegisterBuiltinRules(names []string) {
+	builtinRules.Lock()
+	defer builtinRules.Unlock()
+	for _, name := range names {
+		parts := strings.SplitN(name, ".", 2)
+		if len(parts) == 2 {
+			builtinRules.styles[parts[0]] = append(builtinRules.styles[parts[0]], parts[1])
+		}
+	}
+}
+
+func isBuiltin(style, rule string) bool {
+	builtinRules.RLock()
+	defer builtinRules.RUnlock()
+	rules, found := builtinRules.styles[style]
+	return found && (rule == "" || StringInSlice(rule, rules))
+}
+
+// ruleLevels are the values that may be assigned to a rule.
+var ruleLevels = []string{"YES", "NO", "suggestion", "warning", "error"}
+
+// Validate strictly checks the settings that `c` was loaded from (see
+// `Config.Settings`), returning an error (with its location, if possible)
+// for each problem found:
+//
+// - unknown settings;
+// - section globs that don't compile;
+// - styles that don't exist on `StylesPath`;
+// - rules (`Style.Rule = level`) that don't resolve to a YAML file or an
+// inline rule (see `InlineRules`);
+// - rule levels other than YES, NO, suggestion, warning, or error;
+// - parameters (`Style.Rule.param = value`) for rules that don't exist; and
+// - plugins (see `Plugins`) that can't be executed.
+func (c *Config) Validate() []error {
+	var problems []error
+
+	names := []string{}
+	for name := range c.Settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	seen := map[string]bool{}
+	for _, name := range names {
+		setting := c.Settings[name]
+		if !FileExists(setting.Source) {
+			// Defaults, flags, and environment variables are validated as
+			// they're applied.
+			continue
+		}
+
+		section, key := "", name
+		if strings.HasPrefix(name, "[") {
+			parts := strings.SplitN(name[1:], "] ", 2)
+			section, key = parts[0], parts[1]
+		}
+
+		inline := isInlineSection(section)
+		if section != "" && section != "*" && section != "formats" && section != "plugins" && !inline && !seen[section] {
+			seen[section] = true
+			if _, err := glob.Compile(section); err != nil {
+				problems = append(problems, settingError(
+					fmt.Sprintf("The glob pattern '%s' could not be compiled.", section),
+					section, "", section, setting.Source))
+			}
+		}
+
+		var err error
+		switch {
+		case section == "":
+			if _, found := coreOpts[key]; !found {
+				err = unknownSetting(section, key, setting.Source)
+			}
+		case section == "formats":
+			// Any extension may be mapped to another.
+		case section == "plugins":
+			err = c.validatePlugin(key, setting)
+		case inline:
+			// An inline rule's definition is validated as it's loaded (see
+			// `check.NewManager`).
+		case key == "BasedOnStyles":
+			problems = append(problems, c.validateStyles(section, setting)...)
+		case section == "*" && globalOpts[key] != nil:
+		case section == "*" && isBuiltin(key, ""):
+			if setting.Value != "YES" && setting.Value != "NO" {
+				err = settingError(
+					fmt.Sprintf("'%s' must be 'YES' or 'NO'.", key),
+					section, key, setting.Value, setting.Source)
+			}
+		case section != "*" && syntaxOpts[key] != nil:
+		default:
+			err = c.validateRule(section, key, setting)
+		}
+
+		if err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	return problems
+}
+
+func (c *Config) validateStyles(section string, setting Setting) []error {
+	var problems []error
+	for _, style := range strings.Split(setting.Value, ", ") {
+		if style == "" || isBuiltin(style, "") || c.onStylesPath(style) || c.HasPlugin(style) || c.hasInline(style, "") {
+			continue
+		}
+		problems = append(problems, settingError(
+			fmt.Sprintf("The style '%s' does not exist on StylesPath.", style),
+			section, "BasedOnStyles", style, setting.Source))
+	}
+	return problems
+}
+
+func (c *Config) validateRule(section, key string, setting Setting) error {
+	parts := strings.Split(key, ".")
+	if len(parts) == 3 {
+		// A parameter (`Style.Rule.param = value`) may have any value.
+		return c.validateRuleName(section, key, parts[0], parts[1], setting)
+	} else if len(parts) != 2 {
+		return unknownSetting(section, key, setting.Source)
+	} else if !StringInSlice(setting.Value, ruleLevels) {
+		return settingError(
+			fmt.Sprintf("'%s' must be one of %v.", key, ruleLevels),
+			section, key, setting.Value, setting.Source)
+	} else if parts[1] == "*" {
+		// A wildcard (e.g., `Style.* = NO`) only needs its style to exist.
+		if !isBuiltin(parts[0], "") && !c.onStylesPath(parts[0]) && !c.HasPlugin(parts[0]) && !c.hasInline(parts[0], "") {
+			return settingError(
+				fmt.Sprintf("The style '%s' does not exist on StylesPath.", parts[0]),
+				section, key, key, setting.Source)
+		}
+		return nil
+	}
+	return c.validateRuleName(section, key, parts[0], parts[1], setting)
+}
+
+// validateRuleName checks that the rule `style.rule`, assigned by `key`,
+// exists.
+func (c *Config) validateRuleName(section, key, style, rule string, setting Setting) error {
+	name := style + "." + rule
+	if !isBuiltin(style, rule) && !c.onStylesPath(style, rule+".yml") && c.Plugins[name] == "" && !c.hasInline(style, rule) {
+		return settingError(
+			fmt.Sprintf("The rule '%s' does not exist on StylesPath.", name),
+			section, key, name, setting.Source)
+	}
+	return nil
+}
+
+func (c *Config) validatePlugin(key string, setting Setting) error {
+	if strings.Count(key, ".") != 1 {
+		return settingError(
+			fmt.Sprintf("'%s' must be of the form 'Style.Rule'.", key),
+			"plugins", key, key, setting.Source)
+	} else if _, err := exec.LookPath(c.Plugins[key]); err != nil {
+		return settingError(
+			fmt.Sprintf("The plugin '%s' is not executable: %s", setting.Value, err),
+			"plugins", key, setting.Value, setting.Source)
+	}
+	return nil
+}
+
+// HasPlugin determines if `style` has any external rules (see `Plugins`).
+func (c *Config) HasPlugin(style string) bool {
+	for name := range c.Plugins {
+		if strings.HasPrefix(name, style+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasInline determines if `style` is our `InlineStyle` and `rule` (if given)
+// is one of our inline rules.
+func (c *Config) hasInline(style, rule string) bool {
+	if style != InlineStyle || len(c.InlineRules) == 0 {
+		return false
+	}
+	_, found := c.InlineRules[rule]
+	return rule == "" || found
+}
+
+// onStylesPath determines if the path given by `elem` exists in any of our
+// `StylesPath`s.
+func (c *Config) onStylesPath(elem ...string) bool {
+	for _, p := range c.Paths {
+		if p != "" && FileExists(filepath.Join(append([]string{p}, elem...)...)) {
+			return true
+		}
+	}
+	return false
+}
+
+func unknownSetting(section, key, path string) error {
+	return settingError(
+		fmt.Sprintf("'%s' is not a known setting.", key), section, key, key, path)
+}
+
+// settingError creates an E201 error located at `target` on the line that
+// assigns `key` in `section` (or, if `key` is empty, at the section's header)
+// of the file `path`.
+func settingError(msg, section, key, target, path string) error {
+	current := ""
+	return NewE201(msg, target, path, func(position int, line, t string) bool {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			current = trimmed[1 : len(trimmed)-1]
+			return key == "" && current == section
+		}
+		name := strings.TrimSpace(strings.SplitN(trimmed, "=", 2)[0])
+		return key != "" && current == section && name == key && strings.Contains(line, t)
+	})
+}
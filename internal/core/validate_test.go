@@ -0,0 +1,68 @@
+package core
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/errata-ai/vale/v2/internal/testutil"
+	"github.com/jdkato/regexp"
+)
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+
+	RegisterBuiltinRules([]string{"Vale.Spelling"})
+
+	ini := strings.Join([]string{
+		"StylesPath = styles",
+		"StylePath = styles",
+		"Root = true",
+		"",
+		"[*]",
+		"BasedOnStyles = A, Vale, Missing",
+		"BasedOnStyle = A",
+		"A.Foo = warnings",
+		"A.Bar = YES",
+		"Vale.Spelling = NO",
+		"Vale = maybe",
+		"",
+		"[*.md]",
+		"A.Foo = error",
+		"",
+		"[formats]",
+		"mdx = md",
+	}, "\n")
+
+	files := map[string]string{
+		".vale.ini":        ini,
+		"styles/A/Foo.yml": "extends: existence\nmessage: '%s'\ntokens:\n  - foo\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	cfg, err := NewConfig(&CLIFlags{Path: filepath.Join(dir, ".vale.ini")})
+	if err != nil {
+		t.Fatal(err)
+	} else if err = From("ini", cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// The title of an E201 error includes its location.
+	location := regexp.MustCompile(`:(\d+:\d+)\]`)
+
+	observed := []string{}
+	for _, problem := range cfg.Validate() {
+		m := location.FindStringSubmatch(StripANSI(problem.Error()))
+		if m == nil {
+			t.Fatalf("expected a location, got %v", problem)
+		}
+		observed = append(observed, m[1])
+	}
+	sort.Strings(observed)
+
+	expected := []string{"11:8", "2:1", "6:26", "7:1", "8:9", "9:1"}
+	if strings.Join(observed, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected = %v, got = %v", expected, observed)
+	}
+}
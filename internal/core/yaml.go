@@ -0,0 +1,160 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// isYAMLConfig determines if the configuration file at `path` is written in
+// YAML (e.g., `.vale.yml`) rather than INI.
+func isYAMLConfig(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yml" || ext == ".yaml"
+}
+
+// configSource returns the INI source of the configuration file at `path`:
+// `path` itself or, for a YAML file, its content converted to INI (see
+// `yamlToINI`).
+func configSource(source interface{}) (interface{}, error) {
+	path, ok := source.(string)
+	if !ok || !isYAMLConfig(path) {
+		return source, nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return yamlToINI(b)
+}
+
+// yamlToINI converts a YAML configuration into its INI equivalent, so that
+// both formats share a single representation.
+//
+// Top-level scalars and lists are core settings (e.g., `StylesPath`), while
+// top-level mappings are sections (e.g., `"*.md"` or `formats`):
+//
+//	StylesPath: styles
+//	Vocab: [Base]
+//	"*.md":
+//	  BasedOnStyles: [Vale, MyStyle]
+//	  Vale.Spelling: NO
+//	  BlockIgnores: ['(?s) *({< file [^>]* >}.*?{</ ?file >})']
+//
+// Lists are joined by commas, except for rule parameters (e.g.,
+// `MyStyle.Rule.tokens`), whose values are written in YAML's flow style
+// (e.g., `[a, b]`).
+func yamlToINI(src []byte) ([]byte, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, err
+	}
+
+	var core, sections bytes.Buffer
+	for _, item := range doc {
+		name := fmt.Sprint(item.Key)
+		if section, ok := item.Value.(yaml.MapSlice); ok {
+			fmt.Fprintf(&sections, "\n[%s]\n", name)
+			if err := writeINIKeys(&sections, section); err != nil {
+				return nil, fmt.Errorf("%s: %s", name, err)
+			}
+			continue
+		}
+		if err := writeINIKeys(&core, yaml.MapSlice{item}); err != nil {
+			return nil, err
+		}
+	}
+
+	return append(core.Bytes(), sections.Bytes()...), nil
+}
+
+// writeINIKeys writes each of `keys` to `buf` as an INI key.
+func writeINIKeys(buf *bytes.Buffer, keys yaml.MapSlice) error {
+	for _, item := range keys {
+		key := fmt.Sprint(item.Key)
+
+		var value string
+		switch v := item.Value.(type) {
+		case yaml.MapSlice, []interface{}:
+			if !isRuleParam(key) {
+				if _, ok := v.(yaml.MapSlice); ok {
+					return fmt.Errorf("'%s' can't be a mapping", key)
+				}
+				entries := []string{}
+				for _, entry := range v.([]interface{}) {
+					entries = append(entries, iniScalar(key, entry))
+				}
+				value = strings.Join(entries, ", ")
+				break
+			}
+			value = flowYAML(v)
+		default:
+			value = iniScalar(key, v)
+		}
+
+		fmt.Fprintf(buf, "%s = %s\n", key, iniQuote(value))
+	}
+	return nil
+}
+
+// iniScalar returns the INI form of the YAML scalar `v`.
+//
+// NOTE: YAML reads unquoted `YES` and `NO` (as used for rules) as booleans,
+// so we spell them as such; a rule parameter's `true` is left alone.
+func iniScalar(key string, v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case bool:
+		if isRuleParam(key) {
+			return fmt.Sprint(v)
+		} else if v {
+			return "YES"
+		}
+		return "NO"
+	}
+	return fmt.Sprint(v)
+}
+
+// flowYAML writes the YAML value `v` in flow style, as it would be written
+// in an INI file.
+func flowYAML(v interface{}) string {
+	entries := []string{}
+	switch v := v.(type) {
+	case yaml.MapSlice:
+		for _, item := range v {
+			entries = append(entries, flowYAML(item.Key)+": "+flowYAML(item.Value))
+		}
+		return "{" + strings.Join(entries, ", ") + "}"
+	case []interface{}:
+		for _, entry := range v {
+			entries = append(entries, flowYAML(entry))
+		}
+		return "[" + strings.Join(entries, ", ") + "]"
+	case string:
+		var plain interface{}
+		if yaml.Unmarshal([]byte(v), &plain) != nil || plain != v || strings.ContainsAny(v, ",[]{}") {
+			// Quote anything that wouldn't otherwise be read back as the
+			// same string (e.g., `"10"` or `"a, b"`).
+			b, _ := json.Marshal(v)
+			return string(b)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// iniQuote quotes `value`, if necessary, so that INI reads it verbatim.
+func iniQuote(value string) string {
+	if !strings.ContainsAny(value, "#;\"`\n") {
+		return value
+	} else if !strings.Contains(value, "`") && !strings.Contains(value, "\n") {
+		return "`" + value + "`"
+	}
+	return `"""` + value + `"""`
+}
@@ -88,10 +88,12 @@ func (l *Linter) lintADoc(f *core.File) error {
 
 	exe := core.Which([]string{"asciidoctor"})
 	if exe == "" {
-		return core.NewE100("lintAdoc", errors.New("asciidoctor not found"))
+		// Without Asciidoctor, we convert the file ourselves -- which
+		// doesn't support, e.g., `include::` or conditionals.
+		return l.lintADocNative(f)
 	}
 
-	s, err := l.prep(f.Content, "\n----\n$1\n----\n", "`$1`", ".adoc")
+	s, err := l.prep(f, "\n----\n$1\n----\n", "`$1`", ".adoc")
 	if err != nil {
 		return err
 	}
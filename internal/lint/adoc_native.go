@@ -0,0 +1,445 @@
+package lint
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/jdkato/regexp"
+)
+
+var (
+	// reADocHeading matches a section title (e.g., `== Title`), capturing its
+	// level and text.
+	reADocHeading = regexp.MustCompile(`^(={1,6})\s+(.+?)(?:\s+=+)?\s*$`)
+
+	// reADocDelimiter matches the start or end of a delimited block (e.g.,
+	// `----`).
+	reADocDelimiter = regexp.MustCompile(`^(?:-{4,}|\.{4,}|\+{4,}|/{4,}|={4,}|\*{4,}|_{4,}|--|\|===)$`)
+
+	// reADocAttribute matches an attribute entry (e.g., `:toc: left`).
+	reADocAttribute = regexp.MustCompile(`^:!?\w[\w-]*!?:(?:\s|$)`)
+
+	// reADocBlockAttrs matches a line of block attributes or an anchor (e.g.,
+	// `[source,go]` or `[[intro]]`).
+	reADocBlockAttrs = regexp.MustCompile(`^\[.*\]\s*$`)
+
+	// reADocBlockMacro matches a block macro (e.g., `image::logo.png[]`).
+	reADocBlockMacro = regexp.MustCompile(`^[\w-]+::\S*\[.*\]\s*$`)
+
+	// reADocBreak matches a thematic (`'''`) or page (`<<<`) break.
+	reADocBreak = regexp.MustCompile(`^(?:'{3,}|<{3})\s*$`)
+
+	// reADocTitle matches a block title (e.g., `.Example`), capturing its
+	// text.
+	reADocTitle = regexp.MustCompile(`^\.([^\s.].*)$`)
+
+	// reADocComment matches a comment line, capturing its text.
+	reADocComment = regexp.MustCompile(`^//(?:\s*(.*))?$`)
+
+	// reADocAdmonition matches the label of an admonition paragraph (e.g.,
+	// `NOTE: `).
+	reADocAdmonition = regexp.MustCompile(`^(?:NOTE|TIP|IMPORTANT|WARNING|CAUTION):\s+`)
+
+	// reADocItem matches a list item, capturing its text.
+	reADocItem = regexp.MustCompile(`^\s*(?:[*\-]+|\.+|\d+\.|[a-zA-Z]\.|<\d+>)\s+(?:\[[ xX*]\]\s+)?(.*)$`)
+
+	// reADocTerm matches an item of a description list (e.g., `CPU:: The
+	// brain`), capturing its term and description.
+	reADocTerm = regexp.MustCompile(`^(.*?[^:;\s])(?::{2,4}|;;)(?:\s+(.*))?$`)
+
+	// reADocCellSpec matches a table cell's specifier (e.g., `2+` or `a`).
+	reADocCellSpec = regexp.MustCompile(`^[\d.+*<^>a-z]*$`)
+
+	// reADocInline matches the inline markup that isn't prose: code (group
+	// 1), attribute references (2), cross references (3, with their text in
+	// 4), links (5 and 6), inline macros (7 and 8), and bare URLs (9).
+	reADocInline = regexp.MustCompile(strings.Join([]string{
+		"(``[^`]+``|`[^`\\s](?:[^`]*[^`\\s])?`|\\+[^+\\s](?:[^+]*[^+\\s])?\\+)",
+		`(\{[\w-]+\})`,
+		`<<([^,>]+)(?:,\s*([^>]+))?>>`,
+		`((?:link|xref):[^\s\[\]]+|(?:https?|ftp|irc)://[^\s\[\]]+|mailto:[^\s\[\]]+)\[([^\]]*)\]`,
+		`((?:kbd|btn|menu|image|icon|pass|anchor|indexterm2?|footnote|stem|latexmath|asciimath):[^\s\[\]]*)\[([^\]]*)\]`,
+		`((?:https?|ftp|irc)://[^\s\[\]<>]+)`,
+	}, "|"))
+
+	// reADocMarkup matches constrained (`*bold*`) and unconstrained
+	// (`**bold**`) emphasis.
+	reADocMarkup = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__|##([^#]+)##|(^|[^\w*_#])([*_#])([^\s*_#](?:[^*_#]*?[^\s*_#])?)([*_#])`)
+)
+
+// adocTags are the HTML tags used for AsciiDoc's emphasis markers.
+var adocTags = map[string]string{"*": "strong", "_": "em", "#": "mark"}
+
+// lintADocNative lints an AsciiDoc file, which we convert to HTML ourselves
+// when Asciidoctor isn't installed (see `lintADoc`).
+//
+// Headings, paragraphs, lists, tables, admonitions, and quotes are linted as
+// they would be in any other markup format, while listing, literal, and
+// passthrough blocks, attribute entries (e.g., `:toc:`), block attributes
+// (e.g., `[source,go]`), block macros (e.g., `include::...[]`), and inline
+// code are skipped. Comments (`// ...` and `////` blocks) are linted as
+// `text.comment.line.adoc`.
+func (l *Linter) lintADocNative(f *core.File) error {
+	// We blank the `BlockIgnores` (rather than wrapping them in a listing
+	// block), so that the lines we skip are those of the source.
+	s, err := l.prep(f, "", "`$1`", ".adoc")
+	if err != nil {
+		return err
+	}
+
+	body, comments, skipped := adocToHTML(strings.SplitAfter(s, "\n"))
+	l.lintLineComments(f, comments)
+
+	// As with Org files, everything that isn't linted is masked, so that we
+	// don't find matches in it (see `lintOrg`). Each line keeps its length,
+	// so the alerts' locations are unaffected.
+	lines := strings.SplitAfter(f.Content, "\n")
+	for _, i := range skipped {
+		if i < len(lines) {
+			text := strings.TrimRight(lines[i], "\r\n")
+			lines[i] = maskText(text) + lines[i][len(text):]
+		}
+	}
+	for i, line := range lines {
+		if loc := reADocAdmonition.FindStringIndex(line); loc != nil {
+			line = maskText(line[:loc[1]-1]) + line[loc[1]-1:]
+		}
+		lines[i] = adocMask(line)
+	}
+	f.Content = strings.Join(lines, "")
+
+	return l.lintHTMLTokens(f, []byte(body), 0)
+}
+
+// adocToHTML converts the AsciiDoc source `lines` into HTML, also returning
+// its comments and the (0-based) lines that aren't prose -- e.g., blocks and
+// attribute entries.
+func adocToHTML(lines []string) (string, []lineComment, []int) {
+	var buf strings.Builder
+	var comments []lineComment
+	var skipped []int
+
+	// The delimiter of the listing, literal, passthrough, or comment block
+	// that we're in, if any.
+	block := ""
+
+	// Are we in a literal paragraph or the value of an attribute entry
+	// (continued by a trailing ` \`)?
+	literal, value := false, false
+
+	// The number of lines after the document's title that may be its author
+	// and revision lines.
+	header := 0
+
+	para, list, table, quote, attached := []string{}, false, false, false, false
+
+	flush := func() {
+		if len(para) > 0 {
+			fmt.Fprintf(&buf, "<p>%s</p>\n", adocInline(strings.Join(para, "\n")))
+			para = []string{}
+		}
+		if list {
+			buf.WriteString("</li>\n</ul>\n")
+			list = false
+		}
+		attached = false
+	}
+
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r\n")
+		trimmed := strings.TrimSpace(line)
+
+		if block != "" {
+			// We're in a (non-prose) block, which we skip.
+			skipped = append(skipped, i)
+			if strings.TrimRight(line, " \t") == block {
+				block = ""
+			} else if strings.HasPrefix(block, "////") && trimmed != "" {
+				comments = append(comments, lineComment{
+					text: trimmed,
+					line: i,
+					col:  utf8.RuneCountInString(line[:strings.Index(line, trimmed)]) + 1,
+				})
+			}
+			continue
+		} else if value {
+			skipped = append(skipped, i)
+			value = strings.HasSuffix(trimmed, " \\")
+			continue
+		} else if literal && trimmed != "" {
+			skipped = append(skipped, i)
+			continue
+		}
+		literal = false
+
+		if trimmed == "" {
+			header = 0
+		} else if header > 0 && !reADocAttribute.MatchString(line) && !reADocComment.MatchString(line) {
+			// The author and revision lines that follow the document's
+			// title.
+			header--
+			skipped = append(skipped, i)
+			continue
+		}
+
+		delim := strings.TrimRight(line, " \t")
+		if reADocDelimiter.MatchString(delim) || strings.HasPrefix(delim, "```") {
+			flush()
+			skipped = append(skipped, i)
+
+			switch delim[0] {
+			case '|':
+				if table {
+					buf.WriteString("</table>\n")
+				} else {
+					buf.WriteString("<table>\n")
+				}
+				table = !table
+			case '_':
+				if quote {
+					buf.WriteString("</blockquote>\n")
+				} else {
+					buf.WriteString("<blockquote>\n")
+				}
+				quote = !quote
+			case '-', '.', '+', '/':
+				if delim != "--" {
+					block = delim
+				}
+			case '`':
+				block = "```"
+			}
+			continue
+		}
+
+		if table {
+			if trimmed != "" {
+				buf.WriteString("<tr>")
+				for _, cell := range adocCells(trimmed) {
+					fmt.Fprintf(&buf, "<td>%s</td>", adocInline(cell))
+				}
+				buf.WriteString("</tr>\n")
+			}
+			continue
+		}
+
+		if reADocAttribute.MatchString(line) {
+			flush()
+			skipped = append(skipped, i)
+			value = strings.HasSuffix(trimmed, " \\")
+		} else if m := reADocComment.FindStringSubmatchIndex(line); m != nil {
+			flush()
+			skipped = append(skipped, i)
+			if m[2] >= 0 && m[2] < m[3] {
+				comments = append(comments, lineComment{
+					text: line[m[2]:m[3]],
+					line: i,
+					col:  utf8.RuneCountInString(line[:m[2]]) + 1,
+				})
+			}
+		} else if reADocBlockAttrs.MatchString(line) || reADocBlockMacro.MatchString(line) || reADocBreak.MatchString(line) {
+			flush()
+			skipped = append(skipped, i)
+		} else if m := reADocHeading.FindStringSubmatch(line); m != nil {
+			flush()
+			level := len(m[1])
+			if level == 1 {
+				header = 2
+			}
+			fmt.Fprintf(&buf, "<h%d>%s</h%d>\n", level, adocInline(m[2]), level)
+		} else if trimmed == "+" && (list || len(para) > 0) {
+			// A list continuation, which attaches the next block to the
+			// current item.
+			skipped = append(skipped, i)
+			attached = list
+		} else if trimmed == "" {
+			flush()
+		} else if attached {
+			buf.WriteString("\n" + adocInline(adocBreak(trimmed)))
+		} else if m := reADocTitle.FindStringSubmatch(line); m != nil && len(para) == 0 {
+			flush()
+			fmt.Fprintf(&buf, "<p>%s</p>\n", adocInline(m[1]))
+		} else if m := reADocItem.FindStringSubmatch(line); m != nil {
+			if len(para) > 0 {
+				flush()
+			}
+			if list {
+				buf.WriteString("</li>\n")
+			} else {
+				buf.WriteString("<ul>\n")
+				list = true
+			}
+			fmt.Fprintf(&buf, "<li>%s", adocInline(adocBreak(m[1])))
+		} else if len(para) == 0 && !list && (line[0] == ' ' || line[0] == '\t') {
+			// An indented paragraph is a literal block.
+			skipped = append(skipped, i)
+			literal = true
+		} else if m := reADocTerm.FindStringSubmatch(line); m != nil && len(para) == 0 {
+			if list {
+				buf.WriteString("</li>\n")
+			} else {
+				buf.WriteString("<ul>\n")
+				list = true
+			}
+			fmt.Fprintf(&buf, "<li>%s</li>\n<li>%s", adocInline(m[1]), adocInline(m[2]))
+		} else if list {
+			// This continues the current item.
+			buf.WriteString("\n" + adocInline(adocBreak(trimmed)))
+		} else {
+			if loc := reADocAdmonition.FindStringIndex(trimmed); loc != nil && len(para) == 0 {
+				trimmed = trimmed[loc[1]:]
+			}
+			para = append(para, adocBreak(trimmed))
+		}
+	}
+
+	flush()
+	if table {
+		buf.WriteString("</table>\n")
+	}
+	if quote {
+		buf.WriteString("</blockquote>\n")
+	}
+
+	return buf.String(), comments, skipped
+}
+
+// adocBreak removes the hard line break (` +`) that may end `line`.
+func adocBreak(line string) string {
+	return strings.TrimSuffix(line, " +")
+}
+
+// adocCells splits a table row into its cells, dropping their specifiers
+// (e.g., the `2+` of `2+|cell`).
+func adocCells(row string) []string {
+	parts := strings.Split(row, "|")
+	if len(parts) == 1 {
+		// This continues the previous cell.
+		return parts
+	}
+
+	cells := []string{}
+	if !reADocCellSpec.MatchString(strings.TrimSpace(parts[0])) {
+		cells = append(cells, parts[0])
+	}
+	for _, cell := range parts[1:] {
+		cells = append(cells, strings.TrimSpace(cell))
+	}
+	return cells
+}
+
+// adocSpan returns the masked source and the HTML of the `reADocInline`
+// match `m` in `s`.
+func adocSpan(s string, m []int) (string, string) {
+	match := s[m[0]:m[1]]
+	switch {
+	case m[2] >= 0:
+		// Code is always given in backticks (see `codify`), so we use them
+		// whatever its delimiters.
+		code := maskText(match[1 : len(match)-1])
+		return "`" + code + "`", "<code>" + code + "</code>"
+	case m[6] >= 0:
+		return adocLink(s, m[0], m[8], m[9], m[1]-2, -1)
+	case m[10] >= 0:
+		target := m[10]
+		if strings.HasPrefix(match, "link:") || strings.HasPrefix(match, "xref:") {
+			target += len("link:")
+		}
+		return adocLink(s, m[0], m[12], m[13], m[1]-1, target)
+	case m[14] >= 0 && strings.HasPrefix(match, "footnote:"):
+		// A footnote's text is prose.
+		start, end := m[16], m[17]
+		return maskText(s[m[0]:start]) + s[start:end] + maskText(s[end:m[1]]),
+			adocEmphasis(s[start:end])
+	case m[18] >= 0:
+		// A bare URL is its own text, which isn't prose.
+		return match, `<a href="` + html.EscapeString(match) + `">` + maskText(match) + "</a>"
+	}
+	return maskText(match), maskText(match)
+}
+
+// adocLink masks everything but the text, `s[start:end]`, of the link
+// `s[from:to]` (where `to` excludes its closing delimiter).
+//
+// If the link has a `target` (an index of `s`), its URL is left as it is, to
+// be linted as `text.url` (see `lintTags`).
+func adocLink(s string, from, start, end, to, target int) (string, string) {
+	if target >= 0 {
+		// The text starts after the `[` that ends the URL.
+		url := s[target : start-1]
+		masked := maskText(s[from:target]) + url + maskText(s[start-1:start])
+		open := `<a href="` + html.EscapeString(url) + `">`
+		if start == end {
+			// There's no text, so it's shown as its target.
+			return masked + maskText(s[start:to]) + s[to:], open + maskText(s[from:to]) + "</a>"
+		}
+		return masked + s[start:end] + maskText(s[end:to]) + s[to:],
+			open + adocEmphasis(s[start:end]) + "</a>"
+	}
+
+	if start < 0 || start == end {
+		// There's no text, so it's shown as its target.
+		masked := maskText(s[from:to]) + s[to:]
+		return masked, maskText(s[from:to])
+	}
+	return maskText(s[from:start]) + s[start:end] + maskText(s[end:to]) + s[to:],
+		"<a>" + adocEmphasis(s[start:end]) + "</a>"
+}
+
+// adocMask masks the inline markup in `line` that isn't prose (see
+// `reADocInline`).
+func adocMask(line string) string {
+	var buf strings.Builder
+
+	cursor := 0
+	for _, m := range reADocInline.FindAllStringSubmatchIndex(line, -1) {
+		masked, _ := adocSpan(line[:m[1]], m)
+		buf.WriteString(line[cursor:m[0]])
+		buf.WriteString(masked)
+		cursor = m[1]
+	}
+	buf.WriteString(line[cursor:])
+
+	return buf.String()
+}
+
+// adocInline converts the inline markup in `s` into HTML. The content of code
+// spans, URLs, and attribute references is masked, as it is in the source
+// (see `adocMask`).
+func adocInline(s string) string {
+	var buf strings.Builder
+
+	cursor := 0
+	for _, m := range reADocInline.FindAllStringSubmatchIndex(s, -1) {
+		_, converted := adocSpan(s[:m[1]], m)
+		buf.WriteString(adocEmphasis(s[cursor:m[0]]))
+		buf.WriteString(converted)
+		cursor = m[1]
+	}
+	buf.WriteString(adocEmphasis(s[cursor:]))
+
+	return buf.String()
+}
+
+// adocEmphasis escapes `s`, converting its emphasis markers into HTML tags.
+func adocEmphasis(s string) string {
+	s = html.EscapeString(s)
+	return reADocMarkup.ReplaceAllStringFunc(s, func(m string) string {
+		parts := reADocMarkup.FindStringSubmatch(m)
+		switch {
+		case parts[1] != "":
+			return "<strong>" + parts[1] + "</strong>"
+		case parts[2] != "":
+			return "<em>" + parts[2] + "</em>"
+		case parts[3] != "":
+			return "<mark>" + parts[3] + "</mark>"
+		case parts[5] != parts[7]:
+			return m
+		}
+		tag := adocTags[parts[5]]
+		return fmt.Sprintf("%s<%s>%s</%s>", parts[4], tag, parts[6], tag)
+	})
+}
@@ -17,6 +17,13 @@ var skipTags = []string{"script", "style", "pre", "figure"}
 // 	  case, could be things like file-insertion URLs.
 // 	- `pre` is added by rst2html to code spans.
 var skipClasses = []string{"problematic", "pre", "code"}
+// proseAttrs are the attributes that usually hold prose, which we mask (see
+// `lintAttrs`) even if they aren't linted.
+var proseAttrs = []string{"alt", "title", "aria-label", "placeholder"}
+
+// voidTags are the tags that never have any content.
+var voidTags = []string{"area", "br", "hr", "img", "input", "wbr"}
+
 var inlineTags = []string{
 	"b", "big", "i", "small", "abbr", "acronym", "cite", "dfn", "em", "kbd",
 	"strong", "a", "br", "img", "span", "sub", "sup", "code", "tt", "del"}
@@ -27,10 +34,11 @@ var tagToScope = map[string]string{
 	"blockquote": "text.blockquote",
 
 	// NOTE: These shouldn't inherit from `text`
-	// (or else they'll be linted twice.)
+	// (or else they'll be linted twice.) A link's text does, but it's only
+	// linted by the rules that name it (see `core.Selector.Contains`).
 	"strong": "strong",
 	"b":      "strong",
-	"a":      "link",
+	"a":      "text.link",
 	"em":     "emphasis",
 	"i":      "emphasis",
 	"code":   "code",
@@ -42,24 +50,21 @@ func (l Linter) lintHTMLTokens(f *core.File, raw []byte, offset int) error {
 
 	buf := bytes.NewBufferString("")
 
-	// The user has specified a custom list of tags/classes to ignore.
-	if len(l.Manager.Config.SkippedScopes) > 0 {
-		skipTags = l.Manager.Config.SkippedScopes
-	}
-	if len(l.Manager.Config.IgnoredClasses) > 0 {
-		skipClasses = append(skipClasses, l.Manager.Config.IgnoredClasses...)
-	}
+	// pending holds the attributes of the current element, which we lint
+	// once we've seen its text (see `lintAttrs`).
+	var pending []html.Attribute
 
-	skipped := []string{"tt", "code"}
-	if len(l.Manager.Config.IgnoredScopes) > 0 {
-		skipped = l.Manager.Config.IgnoredScopes
-	}
+	// The user has specified a custom list of tags/classes to ignore.
+	skipped, skipTags := l.scopesFor(f)
+	skipClasses := append(
+		append([]string{}, skipClasses...), l.Manager.Config.IgnoredClasses...)
 
 	walker := newWalker(f, raw, offset)
 	for {
 		tokt, tok, txt := walker.walk()
 		skipClass = checkClasses(attr, skipClasses)
 		if tokt == html.ErrorToken {
+			l.lintAttrs(f, &walker, pending, "")
 			break
 		} else if tokt == html.StartTagToken && core.StringInSlice(txt, skipTags) {
 			inBlock = true
@@ -74,20 +79,23 @@ func (l Linter) lintHTMLTokens(f *core.File, raw []byte, offset int) error {
 		} else if tokt == html.CommentToken {
 			f.UpdateComments(txt)
 		} else if tokt == html.TextToken {
+			pending = l.lintAttrs(f, &walker, pending, txt)
 			skip = skip || shouldBeSkipped(walker.tagHistory, f.NormedExt)
 			if scope, match := tagToScope[walker.activeTag]; match {
 				if core.StringInSlice(walker.activeTag, inlineTags) {
-					// NOTE: We need to create a "temporary" context because
-					// this text is actually linted twice: once as a 'link' and
-					// once as part of the overall paragraph. See issue #105
-					// for more info.
-					tempCtx := updateContext(walker.context, walker.queue)
-					l.lintBlock(
-						f,
-						core.NewBlock(tempCtx, txt, scope),
-						walker.lines,
-						0,
-						true)
+					if l.needsScope(scope) {
+						// NOTE: We need to create a "temporary" context
+						// because this text is actually linted twice: once
+						// as a 'link' and once as part of the overall
+						// paragraph. See issue #105 for more info.
+						tempCtx := updateContext(walker.context, walker.queue)
+						l.lintBlock(
+							f,
+							core.NewBlock(tempCtx, txt, scope),
+							walker.lines,
+							0,
+							true)
+					}
 					walker.activeTag = ""
 				}
 			}
@@ -109,14 +117,39 @@ func (l Linter) lintHTMLTokens(f *core.File, raw []byte, offset int) error {
 
 		attr = getAttribute(tok, "class")
 
+		if tokt == html.StartTagToken || tokt == html.EndTagToken {
+			pending = l.lintAttrs(f, &walker, pending, "")
+		}
+
+		// A link's target is linted (see `lintTags`) before it's masked.
+		pending = append(pending, l.lintTags(f, &walker, tok)...)
 		walker.replaceToks(tok)
-		l.lintTags(f, walker, tok)
 	}
 
-	l.lintSizedScopes(f)
+	l.lintSizedScopes(f, *walker.summary)
 	return nil
 }
 
+// scopesFor returns the inline (`IgnoredScopes`) and block-level
+// (`SkippedScopes`) tags to ignore in f.
+//
+// The settings of f's most specific section (see `core.NewFile`) take
+// precedence over the global ones, which in turn take precedence over our
+// defaults.
+func (l Linter) scopesFor(f *core.File) ([]string, []string) {
+	ignored := []string{"tt", "code"}
+	if len(f.IgnoredScopes) > 0 {
+		ignored = f.IgnoredScopes
+	}
+
+	skipped := skipTags
+	if len(f.SkippedScopes) > 0 {
+		skipped = f.SkippedScopes
+	}
+
+	return ignored, skipped
+}
+
 func (l Linter) lintScope(f *core.File, state walker, txt string) {
 	for _, tag := range state.tagHistory {
 		scope, match := tagToScope[tag]
@@ -126,56 +159,92 @@ func (l Linter) lintScope(f *core.File, state walker, txt string) {
 			} else {
 				scope = "text.heading." + tag + f.RealExt
 			}
-			txt = strings.TrimLeft(txt, " ")
-			b := state.block(txt, scope)
-			l.lintBlock(f, b, state.lines, 0, false)
+			if l.needsScope(scope) {
+				txt = strings.TrimLeft(txt, " ")
+				b := state.block(txt, scope)
+				l.lintBlock(f, b, state.lines, 0, false)
+			}
+			if l.needsScope("summary" + f.RealExt) {
+				// We don't want the summary's alerts to be located here.
+				*state.summary = updateContext(*state.summary, state.queue)
+			}
 			return
 		}
 	}
 
 	// NOTE: We don't include headings, list items, or table cells (which are
 	// processed above) in our Summary content.
-	f.Summary.WriteString(txt + " ")
+	if l.needsScope("summary" + f.RealExt) {
+		f.Summary.WriteString(txt + " ")
+	}
 
 	b := state.block(txt, "txt")
-	l.lintProse(f, b, state.lines)
+	l.lintProse(f, b, state.lines, f.RealExt)
 }
 
-func (l Linter) lintSizedScopes(f *core.File) {
+func (l *Linter) lintSizedScopes(f *core.File, ctx string) {
 	f.ResetComments()
 
 	// Run all rules with `scope: summary`
 	l.lintBlock(
 		f,
-		core.NewBlock(f.Content, f.Summary.String(), "summary."+f.RealExt),
+		core.NewBlock(ctx, f.Summary.String(), "summary."+f.RealExt),
 		len(f.Lines),
 		0,
 		true)
 
-	// Run all rules with `scope: raw`
-	//
-	// NOTE: We need to use `f.Lines` (instead of `f.Content`) to ensure that
-	// we don't include any markup preprocessing.
-	//
-	// See #248, #306.
-	l.lintBlock(
-		f,
-		core.NewBlock("", strings.Join(f.Lines, ""), "raw."+f.RealExt),
-		len(f.Lines),
-		0,
-		true)
+	l.lintRaw(f)
 }
 
-func (l Linter) lintTags(f *core.File, state walker, tok html.Token) {
-	if tok.Data == "img" {
-		for _, a := range tok.Attr {
-			if a.Key == "alt" {
-				l.lintBlock(
-					f,
-					state.block(a.Val, "text.attr."+a.Key), state.lines, 0, false)
-			}
+// lintTags lints the target of a link (as `text.url`) and returns the
+// attributes of `tok` that are linted as prose (see `LintedAttributes`).
+//
+// An element that can't have any text (e.g., `img`) has its attributes linted
+// right away; the others wait for their element's text (see `lintAttrs`).
+func (l Linter) lintTags(f *core.File, state *walker, tok html.Token) []html.Attribute {
+	if tok.Type != html.StartTagToken && tok.Type != html.SelfClosingTagToken {
+		return nil
+	}
+
+	if tok.Data == "a" && l.needsScope("text.url") {
+		if href := getAttribute(tok, "href"); href != "" {
+			l.lintBlock(f, state.block(href, "text.url"), state.lines, 0, false)
 		}
 	}
+
+	attrs := []html.Attribute{}
+	for _, a := range tok.Attr {
+		linted := core.StringInSlice(a.Key, l.Manager.Config.LintedAttrs)
+		if strings.TrimSpace(a.Val) == "" {
+			continue
+		} else if linted && l.needsScope("text.attr."+a.Key) {
+			attrs = append(attrs, a)
+		} else if linted || core.StringInSlice(a.Key, proseAttrs) {
+			state.context = updateCtx(state.context, a.Val, html.TextToken)
+		}
+	}
+
+	if tok.Type == html.SelfClosingTagToken || core.StringInSlice(tok.Data, voidTags) {
+		return l.lintAttrs(f, state, attrs, "")
+	}
+	return attrs
+}
+
+// lintAttrs lints each of `attrs` as `text.attr.NAME`, except for those that
+// repeat the `text` of their element (e.g., a link whose `title` is its
+// text), which is linted anyway.
+//
+// Each is then masked, so that the alerts of the surrounding text aren't
+// located in it.
+func (l Linter) lintAttrs(f *core.File, state *walker, attrs []html.Attribute, text string) []html.Attribute {
+	for _, a := range attrs {
+		if text == "" || !strings.EqualFold(strings.TrimSpace(a.Val), text) {
+			scope := "text.attr." + a.Key
+			l.lintBlock(f, state.block(a.Val, scope), state.lines, 0, false)
+		}
+		state.context = updateCtx(state.context, a.Val, html.TextToken)
+	}
+	return nil
 }
 
 func checkClasses(attr string, ignore []string) bool {
@@ -204,10 +273,12 @@ func shouldBeSkipped(tagHistory []string, ext string) bool {
 }
 
 func codify(ext, text string) string {
-	if ext == ".md" || ext == ".adoc" {
+	if ext == ".md" || ext == ".mdx" || ext == ".adoc" {
 		return "`" + text + "`"
 	} else if ext == ".rst" {
 		return "``" + text + "``"
+	} else if ext == ".org" {
+		return "=" + text + "="
 	}
 	return text
 }
@@ -0,0 +1,110 @@
+package lint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+// A Document is one entry of the input read by `--input=json`: a JSON array
+// of objects such as
+//
+//	[{"path": "docs/index.md", "content": "# Hello ..."}]
+//
+// `path` identifies the document in our output and matches it against the
+// configuration's sections; it needn't exist. `ext` (e.g., `.md`) gives its
+// format and defaults to that of `path`.
+type Document struct {
+	Path    string `json:"path"`
+	Ext     string `json:"ext"`
+	Content string `json:"content"`
+}
+
+// document is a `Document` as it's decoded, so that we can tell a missing
+// field from an empty one.
+type document struct {
+	Path    *string `json:"path"`
+	Ext     *string `json:"ext"`
+	Content *string `json:"content"`
+}
+
+// ReadDocuments reads and validates the documents given to `--input=json`.
+//
+// Each document must have a unique, non-empty `path` and a `content` (which
+// may be empty); `ext` is required only if `path` doesn't have an extension.
+// Unknown fields are an error.
+func ReadDocuments(r io.Reader) ([]Document, error) {
+	var raw []document
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&raw); err != nil {
+		return nil, core.NewE100("--input", fmt.Errorf(
+			"expected a JSON array of {path, ext, content} objects: %s", err))
+	} else if dec.More() {
+		return nil, core.NewE100("--input", errors.New(
+			"unexpected data after the JSON array"))
+	}
+
+	docs := make([]Document, 0, len(raw))
+	seen := map[string]bool{}
+	for i, doc := range raw {
+		if doc.Path == nil || *doc.Path == "" {
+			return nil, core.NewE100("--input", fmt.Errorf(
+				"document %d: missing 'path'", i))
+		} else if seen[*doc.Path] {
+			return nil, core.NewE100("--input", fmt.Errorf(
+				"document %d: duplicate path '%s'", i, *doc.Path))
+		} else if doc.Content == nil {
+			return nil, core.NewE100("--input", fmt.Errorf(
+				"document %d ('%s'): missing 'content'", i, *doc.Path))
+		}
+		seen[*doc.Path] = true
+
+		ext := filepath.Ext(*doc.Path)
+		if doc.Ext != nil && *doc.Ext != "" {
+			ext = *doc.Ext
+		} else if ext == "" {
+			return nil, core.NewE100("--input", fmt.Errorf(
+				"document %d ('%s'): missing 'ext' and the path has no extension", i, *doc.Path))
+		}
+
+		docs = append(docs, Document{Path: *doc.Path, Ext: ext, Content: *doc.Content})
+	}
+
+	return docs, nil
+}
+
+// LintDocuments lints each of `docs` according to its extension, without
+// reading from or writing to the filesystem (see `LintBytes`).
+//
+// As with `Lint`, a document that can't be linted is recorded as a
+// `Failure` (see `Failures`) rather than ending the run.
+func (l *Linter) LintDocuments(docs []Document) ([]*core.File, error) {
+	linted := []*core.File{}
+
+	l.failures = []Failure{}
+	for _, doc := range docs {
+		file, err := core.NewNamedFile(doc.Path, []byte(doc.Content), doc.Ext, l.Manager.Config)
+		if err != nil {
+			return linted, err
+		}
+
+		result := l.lintContent(file)
+
+		var failure *Failure
+		if errors.As(result.err, &failure) {
+			l.failures = append(l.failures, *failure)
+			continue
+		} else if result.err != nil {
+			return linted, result.err
+		}
+		linted = append(linted, result.file)
+	}
+
+	return linted, nil
+}
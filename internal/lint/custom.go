@@ -0,0 +1,37 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+// lintCustom lints a file whose format was added by `core.RegisterFormat`.
+func (l *Linter) lintCustom(f *core.File) error {
+	fn, found := core.CustomFormat(f.NormedExt)
+	if !found {
+		return core.NewE100(f.Path, fmt.Errorf("no format for '%s'", f.NormedExt))
+	}
+
+	blocks, err := fn(f.Content)
+	if err != nil {
+		return core.NewE100(f.Path, err)
+	}
+
+	for _, b := range blocks {
+		if b.Start < 1 || b.End < b.Start || b.End > len(f.Lines) {
+			return core.NewE100(f.Path, fmt.Errorf(
+				"block '%s' has an invalid line range (%d-%d)", b.Scope, b.Start, b.End))
+		}
+
+		// The block's source lines are its context, which allows us to
+		// locate alerts within them.
+		ctx := strings.TrimSuffix(strings.Join(f.Lines[b.Start-1:b.End], ""), "\n")
+		blk := core.NewBlock(ctx, b.Text, b.Scope+f.RealExt)
+		l.lintBlock(f, blk, b.End, 0, true)
+	}
+
+	l.lintRaw(f)
+	return nil
+}
@@ -0,0 +1,115 @@
+package lint
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/gobwas/glob"
+)
+
+// IsGlob determines if the positional argument `arg` looks like a glob
+// pattern (e.g., a quoted `docs/**/*.md`) rather than a path or a string of
+// text to lint.
+func IsGlob(arg string) bool {
+	if strings.ContainsAny(arg, " \t\n") || core.FileExists(arg) {
+		return false
+	}
+	return strings.Contains(arg, "*") ||
+		(strings.ContainsAny(filepath.ToSlash(arg), "/") && strings.ContainsAny(arg, "?[{"))
+}
+
+// ExpandGlob returns the files (in sorted order) that match the glob pattern
+// `pattern`, as a shell would.
+//
+// Unlike `--glob`, a `*` doesn't match across directories; `**` does and may
+// also match no directories at all (so `docs/**/*.md` includes `docs/a.md`).
+func ExpandGlob(pattern string) ([]string, error) {
+	// The volume (e.g., `C:` or `//server/share`) is kept out of `path.Clean`,
+	// which would otherwise collapse a UNC path's leading slashes.
+	vol := filepath.ToSlash(filepath.VolumeName(pattern))
+	pattern = vol + path.Clean(filepath.ToSlash(pattern)[len(vol):])
+
+	variants := []string{pattern}
+	if strings.Contains(pattern, "**/") {
+		variants = append(variants, strings.Replace(pattern, "**/", "", -1))
+	}
+
+	globs := []glob.Glob{}
+	for _, v := range variants {
+		g, err := glob.Compile(v, '/')
+		if err != nil {
+			return nil, core.NewE100("glob", err)
+		}
+		globs = append(globs, g)
+	}
+
+	matches := []string{}
+	err := walkPath(globRoot(pattern), func(fp string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		} else if fi.IsDir() && core.ShouldIgnoreDirectory(fi.Name()) {
+			return filepath.SkipDir
+		} else if fi.IsDir() {
+			return nil
+		}
+
+		vol := filepath.ToSlash(filepath.VolumeName(fp))
+		candidate := vol + path.Clean(filepath.ToSlash(fp)[len(vol):])
+		for _, g := range globs {
+			if g.Match(candidate) {
+				matches = append(matches, fp)
+				break
+			}
+		}
+		return nil
+	})
+
+	sort.Strings(matches)
+	return matches, err
+}
+
+// globRoot returns the directory that all of `pattern`'s matches must be in:
+// the segments that precede the first one with a wildcard.
+func globRoot(pattern string) string {
+	vol := filepath.ToSlash(filepath.VolumeName(filepath.FromSlash(pattern)))
+	pattern = pattern[len(vol):]
+
+	static := []string{}
+	for _, segment := range strings.Split(pattern, "/") {
+		if strings.ContainsAny(segment, "*?[{") {
+			break
+		}
+		static = append(static, segment)
+	}
+
+	root := strings.Join(static, "/")
+	if root == "" && strings.HasPrefix(pattern, "/") {
+		root = "/"
+	} else if root == "" && vol == "" {
+		root = "."
+	}
+	return filepath.FromSlash(vol + root)
+}
+
+// walkPath is `filepath.Walk`, but able to descend into directories whose
+// paths are longer than MAX_PATH characters on Windows (see `core.LongPath`).
+//
+// The paths passed to `fn` are still based on `root`.
+func walkPath(root string, fn filepath.WalkFunc) error {
+	long := core.LongPath(root)
+	if long == root {
+		return filepath.Walk(root, fn)
+	}
+	return filepath.Walk(long, func(fp string, fi os.FileInfo, err error) error {
+		if fp == long {
+			fp = root
+		} else {
+			fp = filepath.Join(root, fp[len(long):])
+		}
+		return fn(fp, fi, err)
+	})
+}
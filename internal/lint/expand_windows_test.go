@@ -0,0 +1,77 @@
+package lint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGlobRootVolume(t *testing.T) {
+	cases := map[string]string{
+		"C:/docs/**/*.md":          `C:\docs`,
+		"C:/*.md":                  `C:\`,
+		"//server/share/docs/*.md": `\\server\share\docs`,
+		"//server/share/*.md":      `\\server\share\`,
+		"docs/*.md":                `docs`,
+		"*.md":                     `.`,
+	}
+
+	for pattern, expected := range cases {
+		if got := globRoot(pattern); got != expected {
+			t.Errorf("globRoot(%q): expected %q, got %q", pattern, expected, got)
+		}
+	}
+}
+
+func TestWalkLongPath(t *testing.T) {
+	root, err := ioutil.TempDir("", "vale")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	segment := strings.Repeat("d", 50)
+	dir := filepath.Join(segment, segment, segment, segment, segment, segment)
+	if err = os.MkdirAll(filepath.Join(root, dir), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	fp := filepath.Join(dir, "test.md")
+	if err = ioutil.WriteFile(filepath.Join(root, fp), []byte("Hello!"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if err = os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+
+	found := []string{}
+	err = walkPath(segment, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		} else if !fi.IsDir() {
+			found = append(found, p)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(found) != 1 || found[0] != fp {
+		t.Errorf("Expected ['%s'], got %v", fp, found)
+	}
+
+	matches, err := ExpandGlob(segment + "/**/*.md")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(matches) != 1 || matches[0] != fp {
+		t.Errorf("Expected ['%s'], got %v", fp, matches)
+	}
+}
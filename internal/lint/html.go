@@ -7,11 +7,11 @@ import (
 	"io/ioutil"
 	"net"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/errata-ai/vale/v2/internal/core"
-	"github.com/gobwas/glob"
 	"github.com/jdkato/regexp"
 )
 
@@ -27,55 +27,116 @@ func (l *Linter) lintHTML(f *core.File) error {
 	return l.lintHTMLTokens(f, []byte(f.Content), 0)
 }
 
-func (l *Linter) prep(content, block, inline, ext string) (string, error) {
-	s := reFrontMatter.ReplaceAllString(content, block)
+// prep replaces the front matter and the `BlockIgnores` of `f` with `block`
+// and its `TokenIgnores` with `inline`.
+//
+// An empty `block` blanks them instead, keeping the number of each line for
+// our own converters (see `lintRSTNative`). Since their text is then gone
+// from the markup we lint, we also mask it in the content we locate alerts
+// in. Otherwise, we leave that content as-is: the text of a code block or
+// span is consumed from it as we walk the markup, so masking it would make us
+// consume the next occurrence of that text instead.
+func (l *Linter) prep(f *core.File, block, inline, ext string) (string, error) {
+	s := f.Content
+	if block == "" {
+		s = reFrontMatter.ReplaceAllStringFunc(s, blankLines)
+	} else {
+		s = reFrontMatter.ReplaceAllString(s, block)
+	}
 
-	for syntax, regexes := range l.Manager.Config.TokenIgnores {
-		sec, err := glob.Compile(syntax)
-		if err != nil {
-			return s, err
-		} else if sec.Match(ext) {
-			for _, r := range regexes {
-				pat, err := regexp.Compile(r)
-				if err != nil {
-					return s, core.NewE201FromTarget(
-						err.Error(),
-						r,
-						l.Manager.Config.Flags.Path,
-					)
-				}
-				s = pat.ReplaceAllString(s, inline)
+	tokens, err := l.ignorePatterns(f.TokenIgnores)
+	if err != nil {
+		return s, err
+	}
+	for _, pat := range tokens {
+		s = pat.ReplaceAllString(s, inline)
+	}
+
+	blocks, err := l.ignorePatterns(f.BlockIgnores)
+	if err != nil {
+		return s, err
+	}
+	for _, pat := range blocks {
+		if block == "" {
+			s = pat.ReplaceAllStringFunc(s, blankLines)
+		} else if ext == ".rst" {
+			// HACK: We need to add padding for the literal block.
+			for _, c := range pat.FindAllStringSubmatch(s, -1) {
+				new := fmt.Sprintf(block, core.Indent(c[0], "    "))
+				s = strings.Replace(s, c[0], new, 1)
 			}
+		} else {
+			s = pat.ReplaceAllString(s, block)
 		}
 	}
 
-	for syntax, regexes := range l.Manager.Config.BlockIgnores {
-		sec, err := glob.Compile(syntax)
+	if block == "" {
+		f.Content = mask(f.Content, blocks)
+	}
+	return s, nil
+}
+
+// blankLines replaces `s` with its line breaks.
+func blankLines(s string) string {
+	return strings.Repeat("\n", strings.Count(s, "\n"))
+}
+
+// ignorePatterns compiles a file's `TokenIgnores` or `BlockIgnores`
+// patterns.
+func (l *Linter) ignorePatterns(regexes []string) ([]*regexp.Regexp, error) {
+	patterns := []*regexp.Regexp{}
+	for _, r := range regexes {
+		pat, err := regexp.Compile(r)
 		if err != nil {
-			return s, err
-		} else if sec.Match(ext) {
-			for _, r := range regexes {
-				pat, err := regexp.Compile(r)
-				if err != nil {
-					return s, core.NewE201FromTarget(
-						err.Error(),
-						r,
-						l.Manager.Config.Flags.Path,
-					)
-				} else if ext == ".rst" {
-					// HACK: We need to add padding for the literal block.
-					for _, c := range pat.FindAllStringSubmatch(s, -1) {
-						new := fmt.Sprintf(block, core.Indent(c[0], "    "))
-						s = strings.Replace(s, c[0], new, 1)
-					}
-				} else {
-					s = pat.ReplaceAllString(s, block)
-				}
-			}
+			return patterns, core.NewE201FromTarget(
+				err.Error(),
+				r,
+				l.Manager.Config.Flags.Path,
+			)
 		}
+		patterns = append(patterns, pat)
 	}
+	return patterns, nil
+}
 
-	return s, nil
+// mask replaces every match of `patterns` in `content` with placeholder text
+// of the same length.
+//
+// Newlines are kept as-is, so the line and column of any content surrounding
+// an ignored region (as calculated by `File.FindLoc`) are unaffected.
+func mask(content string, patterns []*regexp.Regexp) string {
+	locs := [][]int{}
+	for _, pat := range patterns {
+		locs = append(locs, pat.FindAllStringIndex(content, -1)...)
+	}
+	if len(locs) == 0 {
+		return content
+	}
+
+	sort.Slice(locs, func(i, j int) bool { return locs[i][0] < locs[j][0] })
+
+	var buf strings.Builder
+
+	cursor := 0
+	for _, loc := range locs {
+		if loc[1] <= cursor {
+			// This match is entirely within one we've already masked.
+			continue
+		} else if loc[0] < cursor {
+			loc = []int{cursor, loc[1]}
+		}
+		buf.WriteString(content[cursor:loc[0]])
+		buf.WriteString(strings.Map(func(r rune) rune {
+			if r == '\n' {
+				return r
+			}
+			return '*'
+		}, content[loc[0]:loc[1]]))
+		cursor = loc[1]
+	}
+	buf.WriteString(content[cursor:])
+
+	return buf.String()
 }
 
 func (l *Linter) post(f *core.File, text, url string) (string, error) {
@@ -2,12 +2,14 @@ package lint
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
+	"unicode"
 
 	"github.com/errata-ai/vale/v2/internal/check"
 	"github.com/errata-ai/vale/v2/internal/core"
@@ -19,14 +21,41 @@ import (
 type Linter struct {
 	Manager *check.Manager
 
-	seen map[string]bool
-	glob *glob.Glob
+	seen    map[string]bool
+	glob    *glob.Glob
+	ignores []glob.Glob
+	changes core.ChangedLines
 
 	client *http.Client
 	pids   []int
 	temps  []*os.File
 
 	nonGlobal bool
+	nearest   *nearest
+
+	// skipped counts the files that `Lint` found but didn't lint, by reason.
+	skipped map[string]int
+
+	// failures holds the files that `Lint` couldn't finish linting.
+	failures []Failure
+
+	// panics holds the rules that have panicked (and are now disabled).
+	panics *ruleErrors
+
+	// memory, if set, tells `lintFiles` when to stop linting in parallel.
+	memory *memoryWatchdog
+
+	// escalate holds the `escalate` thresholds of our rules (see
+	// `escalations`).
+	escalate *ruleEscalations
+
+	// loadErr holds the first error in loading a rule's resources (see
+	// `load`).
+	loadErr *loadError
+
+	// onBlock, if set, receives every block extracted from a file *instead*
+	// of it being linted (see `Scopes`).
+	onBlock func(blk core.Block)
 }
 
 type lintResult struct {
@@ -34,6 +63,18 @@ type lintResult struct {
 	err  error
 }
 
+// A Failure records a file that couldn't be linted -- e.g., because it was
+// deleted mid-run or caused an unexpected error -- which, unlike other
+// errors, doesn't stop the rest of the run.
+type Failure struct {
+	Path string
+	Err  error
+}
+
+func (f *Failure) Error() string {
+	return fmt.Sprintf("%s: %s", f.Path, f.Err)
+}
+
 // NewLinter initializes a Linter.
 func NewLinter(cfg *core.Config) (*Linter, error) {
 	mgr, err := check.NewManager(cfg)
@@ -45,15 +86,54 @@ func NewLinter(cfg *core.Config) (*Linter, error) {
 		Manager: mgr,
 
 		client:    http.DefaultClient,
+		nearest:   newNearest(),
+		panics:    newRuleErrors(),
+		memory:    newMemoryWatchdog(cfg),
+		escalate:  &ruleEscalations{},
+		loadErr:   &loadError{},
 		nonGlobal: globalStyles+globalChecks == 0}, err
 }
 
-// LintString src according to its format.
+// LintString lints `src`, which is either the path of a file or, if there's
+// no such file, text with the extension given by `--ext` (see `LintBytes`).
 func (l *Linter) LintString(src string) ([]*core.File, error) {
+	if !core.FileExists(src) {
+		return l.LintBytes([]byte(src), l.Manager.Config.Flags.InExt)
+	}
 	linted := l.lintFile(src)
 	return []*core.File{linted.file}, linted.err
 }
 
+// LintBytes lints `content` according to the format of the extension `ext`
+// (e.g., `.md`), without reading from or writing to the filesystem -- for
+// example, a document received over HTTP.
+//
+// The linted file's path is `stdin` followed by `ext` (see
+// `core.NewFileFromBytes`).
+func (l *Linter) LintBytes(content []byte, ext string) ([]*core.File, error) {
+	file, err := core.NewFileFromBytes(content, ext, l.Manager.Config)
+	if err != nil {
+		return nil, err
+	}
+	linted := l.lintContent(file)
+	return []*core.File{linted.file}, linted.err
+}
+
+// Scopes runs `src` through the same format-specific processing as
+// `LintString`, but returns the extracted blocks (that is, the text of each
+// scope) rather than linting them.
+func (l *Linter) Scopes(src string) ([]core.Block, error) {
+	var blocks []core.Block
+
+	l.onBlock = func(blk core.Block) {
+		blocks = append(blocks, blk)
+	}
+	defer func() { l.onBlock = nil }()
+
+	linted := l.lintFile(src)
+	return blocks, linted.err
+}
+
 // Lint src according to its format.
 func (l *Linter) Lint(input []string, pat string) ([]*core.File, error) {
 	var linted []*core.File
@@ -70,16 +150,37 @@ func (l *Linter) Lint(input []string, pat string) ([]*core.File, error) {
 		return linted, err
 	}
 
+	l.ignores, err = l.ignoreGlobs()
+	if err != nil {
+		return linted, err
+	}
+
+	if ref := l.Manager.Config.Flags.Diff; ref != "" {
+		l.changes, err = core.GitDiff(ref)
+		if err != nil {
+			return linted, err
+		}
+	}
+
 	l.glob = &gp
+	l.skipped = make(map[string]int)
+	l.failures = []Failure{}
 	for _, src := range input {
 		filesChan, errChan := l.lintFiles(done, src)
 
 		for result := range filesChan {
-			if result.err != nil {
+			var failure *Failure
+			if errors.As(result.err, &failure) {
+				l.failures = append(l.failures, *failure)
+				continue
+			} else if result.err != nil {
 				l.teardown()
 				return linted, result.err
-			} else if l.Manager.Config.Flags.Normalize {
-				result.file.Path = filepath.ToSlash(result.file.Path)
+			} else if l.changes != nil {
+				l.onlyChanged(result.file)
+			}
+			if l.Manager.Config.Flags.Normalize {
+				result.file.Path = core.NormalizePath(result.file.Path)
 			}
 			linted = append(linted, result.file)
 		}
@@ -103,21 +204,48 @@ func (l *Linter) lintFiles(done <-chan core.File, root string) (<-chan lintResul
 	go func() {
 		wg := sizedwaitgroup.New(5)
 
-		err := filepath.Walk(root, func(fp string, fi os.FileInfo, err error) error {
-			if fi.IsDir() && core.ShouldIgnoreDirectory(fi.Name()) {
+		err := walkPath(root, func(fp string, fi os.FileInfo, err error) error {
+			if err == nil && l.ignored(root, fp) {
+				if fi.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			} else if fi.IsDir() && core.ShouldIgnoreDirectory(fi.Name()) {
 				return filepath.SkipDir
-			} else if err != nil || fi.IsDir() || l.skip(fp) {
+			} else if err != nil || fi.IsDir() {
+				return nil
+			}
+
+			linter, err := l.linterFor(fp)
+			if err != nil {
+				return err
+			} else if reason := linter.skip(fp); reason != "" {
+				if reason == "unknown format" && !core.IsConfigFile(fp) {
+					// Files that are excluded on purpose (e.g., by
+					// `--glob`) aren't worth reporting.
+					l.skipped[reason]++
+				}
 				return nil
 			}
 
-			wg.Add()
-			go func(fp string) {
+			if l.memory.nearLimit() {
+				// We're low on memory, so we lint this file on its own
+				// (once any others have finished).
+				wg.Wait()
 				select {
-				case filesChan <- l.lintFile(fp):
+				case filesChan <- linter.lintPath(fp):
 				case <-done:
 				}
-				wg.Done()
-			}(fp)
+			} else {
+				wg.Add()
+				go func(fp string) {
+					select {
+					case filesChan <- linter.lintPath(fp):
+					case <-done:
+					}
+					wg.Done()
+				}(fp)
+			}
 
 			// Abort the walk if done is closed.
 			select {
@@ -140,46 +268,182 @@ func (l *Linter) lintFiles(done <-chan core.File, root string) (<-chan lintResul
 	return filesChan, errChan
 }
 
+// lintPath lints the file at `fp`, which `lintFiles` found on disk, unless it
+// has since been removed.
+func (l *Linter) lintPath(fp string) lintResult {
+	if fi, err := os.Stat(core.LongPath(fp)); err != nil || fi.IsDir() {
+		// Otherwise, `NewFile` would lint `fp` as a string of text.
+		return lintResult{err: &Failure{Path: fp, Err: errors.New("no longer exists")}}
+	}
+
+	result := l.lintFile(fp)
+	if result.err != nil && !core.FileExists(fp) {
+		// It was removed while we were reading it.
+		result.err = &Failure{Path: fp, Err: errors.New("no longer exists")}
+	}
+	return result
+}
+
 // lintFile creates a new `File` from the path `src` and selects a linter based
 // on its format.
+//
+// All of the file's positions are computed from the content read by
+// `NewFile`, so later changes to the file on disk don't affect them; an
+// unexpected panic is returned as a `Failure`.
 func (l *Linter) lintFile(src string) lintResult {
-	var err error
-
 	file, err := core.NewFile(src, l.Manager.Config)
 	if err != nil {
 		return lintResult{err: err}
-	} else if len(file.Checks) == 0 && len(file.BaseStyles) == 0 {
+	}
+	return l.lintContent(file)
+}
+
+// lintContent lints `file` according to its format.
+func (l *Linter) lintContent(file *core.File) (result lintResult) {
+	var err error
+
+	defer func() {
+		if r := recover(); r != nil {
+			result = lintResult{err: &Failure{Path: file.Path, Err: fmt.Errorf("%v", r)}}
+		}
+	}()
+
+	if len(file.Checks) == 0 && len(file.BaseStyles) == 0 && l.onBlock == nil {
 		if len(l.Manager.Config.GBaseStyles) == 0 && len(l.Manager.Config.GChecks) == 0 {
 			// There's nothing to do; bail early.
 			return lintResult{file: file}
 		}
 	}
 
-	if file.Format == "markup" && !l.Manager.Config.Flags.Simple {
+	if file.Command != "" && !l.Manager.Config.Flags.Simple {
+		err = l.lintTransform(file, strings.Fields(file.Command))
+	} else if isCommand(file.Transform) && !l.Manager.Config.Flags.Simple {
+		err = l.lintTransform(file, []string{file.Transform})
+	} else if file.Format == "custom" && !l.Manager.Config.Flags.Simple {
+		err = l.lintCustom(file)
+	} else if file.Format == "markup" && !l.Manager.Config.Flags.Simple {
 		switch file.NormedExt {
 		case ".adoc":
 			err = l.lintADoc(file)
 		case ".md":
 			err = l.lintMarkdown(file)
+		case ".mdx":
+			err = l.lintMDX(file)
+		case ".org":
+			err = l.lintOrg(file)
+		case ".po":
+			err = l.lintPO(file)
 		case ".rst":
 			err = l.lintRST(file)
 		case ".xml":
 			err = l.lintXML(file)
 		case ".dita":
 			err = l.lintDITA(file)
+		case ".yml":
+			err = l.lintYAML(file)
 		case ".html":
 			err = l.lintHTML(file)
 		}
 	} else if file.Format == "code" && !l.Manager.Config.Flags.Simple {
 		l.lintCode(file)
+		l.lintRaw(file)
 	} else {
-		l.lintLines(file)
+		err = l.lintLines(file)
+	}
+
+	if err == nil {
+		err = l.loadErr.get()
+	}
+
+	// Now that we know how many alerts each rule has, we can escalate them.
+	file.Escalate(l.escalations())
+	if l.Manager.Config.MergeDups {
+		file.MergeDuplicates()
 	}
 
 	return lintResult{file, err}
 }
 
-func (l *Linter) lintProse(f *core.File, parent core.Block, lines int) {
+// ruleEscalations holds the `escalate` thresholds of each of our rules that
+// has them, which are collected the first time they're needed.
+type ruleEscalations struct {
+	once       sync.Once
+	thresholds map[string]map[int]string
+}
+
+// escalations returns the `escalate` thresholds of each of our rules that has
+// them, keyed by the name its alerts are reported under.
+func (l *Linter) escalations() map[string]map[int]string {
+	if l.escalate == nil {
+		return nil
+	}
+
+	l.escalate.once.Do(func() {
+		l.escalate.thresholds = map[string]map[int]string{}
+		for _, chk := range l.Manager.Rules() {
+			if details := chk.Fields(); len(details.Escalate) > 0 {
+				l.escalate.thresholds[details.Name] = details.Escalate
+			}
+		}
+	})
+	return l.escalate.thresholds
+}
+
+// loadError holds the first error encountered in loading a rule's resources.
+type loadError struct {
+	sync.Mutex
+	err error
+}
+
+func (le *loadError) set(err error) {
+	le.Lock()
+	defer le.Unlock()
+	if le.err == nil {
+		le.err = err
+	}
+}
+
+func (le *loadError) get() error {
+	if le == nil {
+		return nil
+	}
+	le.Lock()
+	defer le.Unlock()
+	return le.err
+}
+
+// load builds any resources that `chk` loads on first use (i.e., a spelling
+// rule's dictionaries), returning `false` if it can't run. The error is
+// reported once the file has been linted (see `lintContent`).
+func (l *Linter) load(chk check.Rule) bool {
+	s, ok := chk.(check.Spelling)
+	if !ok {
+		return true
+	}
+
+	_, err := s.Load()
+	if err != nil && l.loadErr != nil {
+		l.loadErr.set(err)
+	}
+	return err == nil
+}
+
+// highestLevel returns the highest level that the alerts of a rule with the
+// definition `details` can have: its own or, if it has `escalate`
+// thresholds, the highest of those.
+func highestLevel(details check.Definition) int {
+	highest := core.LevelToInt[details.Level]
+	for _, level := range details.Escalate {
+		if core.LevelToInt[level] > highest {
+			highest = core.LevelToInt[level]
+		}
+	}
+	return highest
+}
+
+// lintProse lints `parent` as text, along with its paragraphs and sentences
+// (if any rules need them), using the extension `ext` for their scopes.
+func (l *Linter) lintProse(f *core.File, parent core.Block, lines int, ext string) {
 	var b core.Block
 
 	// FIXME: This is required for paragraphs that lack a newline delimiter:
@@ -191,53 +455,116 @@ func (l *Linter) lintProse(f *core.File, parent core.Block, lines int) {
 	needsLookup := strings.Count(parent.Text, "\n") > 0
 
 	text := core.Sanitize(parent.Text)
-	if l.Manager.HasScope("paragraph") || l.Manager.HasScope("sentence") {
+	if l.hasScope("paragraph") || l.hasScope("sentence") {
 		for _, p := range strings.SplitAfter(text, "\n\n") {
 			for _, s := range core.SentenceTokenizer.Tokenize(p) {
 				b = core.NewLinedBlock(
 					parent.Context,
 					strings.TrimSpace(s),
-					"sentence"+f.RealExt,
+					"sentence"+ext,
 					parent.Line)
 				l.lintBlock(f, b, lines, 0, needsLookup)
 			}
 			b = core.NewLinedBlock(
 				parent.Context,
 				p,
-				"paragraph"+f.RealExt,
+				"paragraph"+ext,
 				parent.Line)
 			l.lintBlock(f, b, lines, 0, needsLookup)
 		}
 	}
 
-	b = core.NewLinedBlock(parent.Context, text, "text"+f.RealExt, parent.Line)
+	b = core.NewLinedBlock(parent.Context, text, "text"+ext, parent.Line)
 	l.lintBlock(f, b, lines, 0, needsLookup)
 }
 
-func (l *Linter) lintLines(f *core.File) {
+func (l *Linter) lintLines(f *core.File) error {
+	// Plain-text formats don't have a code scope to redirect ignored content
+	// to, so we just mask it.
+	ignored, err := l.ignorePatterns(f.TokenIgnores)
+	if err != nil {
+		return err
+	}
+
+	blocks, err := l.ignorePatterns(f.BlockIgnores)
+	if err != nil {
+		return err
+	}
+
+	ignored = append(ignored, blocks...)
+	f.Content = mask(f.Content, ignored)
+
 	block := core.NewBlock("", f.Content, "text"+f.RealExt)
 	l.lintBlock(f, block, len(f.Lines), 0, true)
+
+	l.lintRaw(f)
+	return nil
+}
+
+// lintRaw runs all rules with `scope: raw` against each line of the file's
+// source and those with `scope: raw.file` against all of it.
+//
+// NOTE: We need to use `f.Lines` (instead of `f.Content`) to ensure that we
+// don't include any markup preprocessing.
+//
+// See #248, #306.
+func (l *Linter) lintRaw(f *core.File) {
+	src := strings.Join(f.Lines, "")
+	l.lintBlock(f, core.NewBlock("", src, "raw.file"+f.RealExt), len(f.Lines), 0, true)
+
+	if !l.hasScope("raw") {
+		return
+	}
+	for i, line := range f.Lines {
+		if line != "" {
+			blk := core.NewLinedBlock(src, line, "raw"+f.RealExt, i)
+			l.lintBlock(f, blk, len(f.Lines), 0, false)
+		}
+	}
 }
 
 func (l *Linter) lintBlock(f *core.File, blk core.Block, lines, pad int, lookup bool) {
 	var wg sync.WaitGroup
 
+	if l.onBlock != nil {
+		l.onBlock(blk)
+		return
+	} else if !l.needsScope(blk.Scope.Value) {
+		// No rule would run on it.
+		return
+	} else if reason := l.unlintable(blk); reason != "" {
+		f.Skipped++
+		if l.Manager.Config.Flags.Debug {
+			fmt.Fprintf(os.Stderr, "%s: skipped '%s' scope on line %d (%s)\n",
+				f.Path, blk.Scope.Value, blk.Line+1, reason)
+		}
+		return
+	}
+
 	f.ChkToCtx = make(map[string]string)
 
 	results := make(chan core.Alert)
 	for name, chk := range l.Manager.Rules() {
-		if !l.shouldRun(name, f, chk, blk) {
+		if !l.shouldRun(name, f, chk, blk) || !l.load(chk) {
 			continue
 		}
 
 		wg.Add(1)
 		go func(txt, name string, f *core.File, chk check.Rule) {
+			defer wg.Done()
+			defer func() {
+				// A panicking rule shouldn't take the rest of the run with
+				// it, so we disable it instead.
+				if r := recover(); r != nil {
+					l.panics.add(name, f, blk, r)
+				}
+			}()
+
 			info := chk.Fields()
 			for _, a := range chk.Run(txt, f) {
 				core.FormatAlert(&a, info.Limit, info.Level, name)
 				results <- a
 			}
-			wg.Done()
 		}(blk.Text, name, f, chk)
 	}
 
@@ -246,17 +573,70 @@ func (l *Linter) lintBlock(f *core.File, blk core.Block, lines, pad int, lookup
 		close(results)
 	}()
 
+	escalate := l.escalations()
 	for a := range results {
-		f.AddAlert(a, blk, lines, pad, lookup)
+		if _, ok := escalate[a.Check]; ok || core.LevelToInt[a.Severity] >= f.MinAlertLevel {
+			// NOTE: An alert's severity may differ from its rule's level
+			// (e.g., a plugin's alerts), and an alert below the minimum may
+			// yet be escalated (see `core.File.Escalate`).
+			f.AddAlert(a, blk, lines, pad, lookup)
+		}
+	}
+}
+
+// minRatioBytes is the size below which we don't apply the non-prose ratio
+// guard: short scopes, such as table cells, are often entirely non-alphabetic
+// (e.g., "1.0.0") but are still cheap to lint.
+const minRatioBytes = 256
+
+// unlintable explains why `blk` shouldn't be linted -- either because it's
+// larger than `MaxScopeBytes` or because it's not prose (e.g., minified JSON)
+// -- or returns an empty string if it should.
+func (l *Linter) unlintable(blk core.Block) string {
+	cfg := l.Manager.Config
+
+	size := len(blk.Text)
+	if cfg.MaxScopeBytes > 0 && size > cfg.MaxScopeBytes {
+		return fmt.Sprintf("%d bytes exceeds MaxScopeBytes", size)
+	} else if cfg.MaxNonProse <= 0 || size < minRatioBytes {
+		return ""
+	} else if blk.Scope.Has("summary") || blk.Scope.Has("raw") {
+		// These represent the whole file, so a single non-prose section
+		// shouldn't disable them.
+		return ""
+	}
+
+	total, other := 0, 0
+	for _, r := range blk.Text {
+		if unicode.IsSpace(r) {
+			continue
+		} else if !unicode.IsLetter(r) {
+			other++
+		}
+		total++
 	}
+
+	if total == 0 {
+		return ""
+	} else if ratio := float64(other) / float64(total); ratio > cfg.MaxNonProse {
+		return fmt.Sprintf("%.2f non-alphabetic exceeds MaxNonProseRatio", ratio)
+	}
+
+	return ""
 }
 
 func (l *Linter) shouldRun(name string, f *core.File, chk check.Rule, blk core.Block) bool {
-	min := l.Manager.Config.MinAlertLevel
+	min := f.MinAlertLevel
 	run := false
 
+	key := name
+
 	details := chk.Fields()
-	if strings.Count(name, ".") > 1 {
+	if details.Instance != "" || details.Vocab != "" {
+		// This is one of several rules generated from a single definition
+		// (e.g., `Vale.Terms`), so we use the definition's name.
+		name = details.Name
+	} else if strings.Count(name, ".") > 1 {
 		// NOTE: This fixes the loading issue with consistency checks.
 		//
 		// See #129.
@@ -264,13 +644,24 @@ func (l *Linter) shouldRun(name string, f *core.File, chk check.Rule, blk core.B
 		name = strings.Join([]string{list[0], list[1]}, ".")
 	}
 
+	if l.panics.isDisabled(key) {
+		// It has panicked earlier in the run.
+		return false
+	}
+
 	// It has been disabled via an in-text comment.
 	if f.QueryComments(name) {
 		return false
-	} else if core.LevelToInt[details.Level] < min {
+	} else if details.Vocab != "" && details.Vocab != f.Vocab {
+		// It was built for another vocabulary (see `core.Config.SVocabs`).
+		return false
+	} else if highestLevel(details) < min {
 		return false
 	} else if !blk.Scope.ContainsString(details.Scope) {
 		return false
+	} else if blk.Scope.Has("file") && !strings.HasPrefix(details.Scope, "raw.file") {
+		// `raw` rules are run line-by-line.
+		return false
 	}
 
 	// Has the check been disabled for this extension?
@@ -297,6 +688,18 @@ func (l *Linter) shouldRun(name string, f *core.File, chk check.Rule, blk core.B
 	return true
 }
 
+// hasScope reports whether or not we need to extract the given scope.
+func (l *Linter) hasScope(scope string) bool {
+	return l.onBlock != nil || l.Manager.HasScope(scope)
+}
+
+// needsScope reports whether or not any rule would run on a block with the
+// selector `sel` (see `check.Manager.NeedsScope`), which we can otherwise
+// skip extracting.
+func (l *Linter) needsScope(sel string) bool {
+	return l.onBlock != nil || l.Manager.NeedsScope(sel)
+}
+
 // setup handles any necessary building, compiling, or pre-processing.
 func (l *Linter) setup() error {
 	if l.Manager.Config.SphinxAuto != "" {
@@ -307,6 +710,14 @@ func (l *Linter) setup() error {
 }
 
 func (l *Linter) teardown() error {
+	if l.nearest != nil {
+		for _, linter := range l.nearest.linters {
+			if err := linter.teardown(); err != nil {
+				return err
+			}
+		}
+	}
+
 	for _, pid := range l.pids {
 		if p, err := os.FindProcess(pid); err == nil {
 			if p.Kill() != nil {
@@ -331,7 +742,78 @@ func (l *Linter) match(s string) bool {
 	return l.glob.Match(s)
 }
 
-func (l *Linter) skip(fp string) bool {
+// ignoreGlobs compiles the patterns given by `--ignore` and `IgnoreFiles`.
+// onlyChanged removes any of f's alerts that aren't on a line changed in the
+// diff given by `--diff`.
+func (l *Linter) onlyChanged(f *core.File) {
+	alerts := []core.Alert{}
+	for _, a := range f.Alerts {
+		if l.changes.Contains(f.Path, a.Line) {
+			alerts = append(alerts, a)
+		}
+	}
+	f.Alerts = alerts
+}
+
+func (l *Linter) ignoreGlobs() ([]glob.Glob, error) {
+	patterns := append([]string{}, l.Manager.Config.IgnoreFiles...)
+	if l.Manager.Config.Flags.Ignore != "" {
+		patterns = append(patterns, strings.Split(l.Manager.Config.Flags.Ignore, ",")...)
+	}
+
+	globs := []glob.Glob{}
+	for _, pat := range patterns {
+		pat = strings.TrimSpace(pat)
+		if pat == "" {
+			continue
+		}
+		g, err := glob.NewGlob(pat)
+		if err != nil {
+			return globs, core.NewE100("--ignore", err)
+		}
+		globs = append(globs, g)
+	}
+
+	return globs, nil
+}
+
+// ignored determines if `fp` matches any of our ignore patterns.
+//
+// Patterns are matched against the path relative to `root` (that is, the
+// directory being linted) or, if `root` is itself `fp`, its base name.
+func (l *Linter) ignored(root, fp string) bool {
+	if len(l.ignores) == 0 {
+		return false
+	}
+
+	rel, err := filepath.Rel(root, fp)
+	if err != nil || rel == "." {
+		rel = filepath.Base(fp)
+	}
+	rel = filepath.ToSlash(rel)
+
+	for _, g := range l.ignores {
+		if g.Match(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// Failures returns the files that the last call to `Lint` couldn't lint.
+func (l *Linter) Failures() []Failure {
+	return l.failures
+}
+
+// Skipped returns the number of files that the last call to `Lint` didn't
+// lint, keyed by the reason they were skipped.
+func (l *Linter) Skipped() map[string]int {
+	return l.skipped
+}
+
+// skip explains why the file `fp` shouldn't be linted, or returns an empty
+// string if it should.
+func (l *Linter) skip(fp string) string {
 	var ext string
 
 	old := filepath.Ext(fp)
@@ -342,15 +824,15 @@ func (l *Linter) skip(fp string) bool {
 
 	fp = filepath.ToSlash(fp)
 	if !l.match(fp) {
-		return true
+		return "excluded by --glob"
 	} else if l.nonGlobal {
 		for _, pat := range l.Manager.Config.SecToPat {
 			if pat.Match(fp) {
-				return false
+				return ""
 			}
 		}
-		return true
+		return "unknown format"
 	}
 
-	return false
+	return ""
 }
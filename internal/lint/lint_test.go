@@ -1,11 +1,21 @@
 package lint
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/errata-ai/vale/v2/internal/check"
 	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/errata-ai/vale/v2/internal/testutil"
 	"github.com/jdkato/regexp"
 )
 
@@ -47,12 +57,21 @@ func TestGenderBias(t *testing.T) {
 	}
 }
 
-func benchmarkLint(path string, b *testing.B) {
-	cfg, err := core.NewConfig(&core.CLIFlags{})
+func benchmarkLint(path string, b *testing.B, checks ...string) {
+	// NOTE: `.txt` is `--ext`'s default, which means "use the file's own
+	// extension".
+	cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".txt"})
 	if err != nil {
 		panic(err)
 	}
-	cfg.GBaseStyles = []string{"Vale"}
+
+	if len(checks) == 0 {
+		cfg.GBaseStyles = []string{"Vale"}
+	}
+	for _, chk := range checks {
+		cfg.GChecks[chk] = true
+		cfg.Checks = append(cfg.Checks, chk)
+	}
 
 	path, err = filepath.Abs(path)
 	if err != nil {
@@ -64,7 +83,7 @@ func benchmarkLint(path string, b *testing.B) {
 		panic(err)
 	}
 
-	linter := Linter{Manager: mgr}
+	linter := Linter{Manager: mgr, panics: newRuleErrors()}
 	for n := 0; n < b.N; n++ {
 		_, _ = linter.Lint([]string{path}, "*")
 	}
@@ -77,3 +96,2167 @@ func BenchmarkLintRST(b *testing.B) {
 func BenchmarkLintMD(b *testing.B) {
 	benchmarkLint("../../fixtures/benchmarks/bench.md", b)
 }
+
+// BenchmarkLintMDSpelling only loads `Vale.Spelling`, so the scopes that it
+// doesn't target (e.g., `summary` and `link`) aren't extracted.
+func BenchmarkLintMDSpelling(b *testing.B) {
+	benchmarkLint("../../fixtures/benchmarks/bench.md", b, "Vale.Spelling")
+}
+
+func TestMask(t *testing.T) {
+	patterns := []*regexp.Regexp{
+		regexp.MustCompile(`{{<[^>]*>}}`),
+		regexp.MustCompile(`(?s){% raw %}.+?{% endraw %}`),
+	}
+
+	content := "A {{< note >}} here.\n{% raw %}\nbad wrod\n{% endraw %}\nEnd."
+	expected := "A ************ here.\n*********\n********\n************\nEnd."
+
+	if masked := mask(content, patterns); masked != expected {
+		t.Errorf("expected = %q, got = %q", expected, masked)
+	}
+}
+
+// TestIgnoredLocations checks that ignoring a block doesn't move the alerts
+// for text that's repeated outside of it.
+func TestIgnoredLocations(t *testing.T) {
+	root, err := filepath.Abs("../../fixtures/patterns")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := core.NewConfig(&core.CLIFlags{Path: filepath.Join(root, "_vale"), InExt: ".txt"})
+	if err != nil {
+		t.Fatal(err)
+	} else if err = core.From("ini", cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"test.md", "test.rst", "test.adoc"} {
+		linted, err := linter.Lint([]string{filepath.Join(root, name)}, "*")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		observed := []string{}
+		for _, a := range linted[0].SortedAlerts() {
+			if a.Check == "vale.Redundancy" {
+				observed = append(observed, fmt.Sprintf("%d:%d", a.Line, a.Span[0]))
+			}
+		}
+
+		expected := []string{"1:19", "7:19", "19:1"}
+		if fmt.Sprint(observed) != fmt.Sprint(expected) {
+			t.Errorf("%s: expected %v, got %v", name, expected, observed)
+		}
+	}
+}
+
+func TestUnlintable(t *testing.T) {
+	cfg, err := core.NewConfig(&core.CLIFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.MaxScopeBytes = 1024
+	cfg.MaxNonProse = 0.6
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prose := strings.Repeat("This is a sentence. ", 20)
+	data := strings.Repeat(`{"a":[1,2,3]},`, 40)
+
+	blocks := []struct {
+		blk  core.Block
+		skip bool
+	}{
+		{core.NewBlock("", prose, "text.md"), false},
+		{core.NewBlock("", "1.0.0", "text.md"), false},
+		{core.NewBlock("", data, "text.md"), true},
+		{core.NewBlock("", data, "summary.md"), false},
+		{core.NewBlock("", strings.Repeat(prose, 3), "text.md"), true},
+	}
+
+	for _, b := range blocks {
+		if reason := linter.unlintable(b.blk); (reason != "") != b.skip {
+			t.Errorf("%s: expected skip = %v, got '%s'", b.blk.Scope.Value, b.skip, reason)
+		}
+	}
+}
+
+func lintFixture(t *testing.T, cfg *core.Config, path string) []byte {
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	linted, err := linter.Lint([]string{path}, "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alerts := []core.Alert{}
+	for _, f := range linted {
+		alerts = append(alerts, f.Alerts...)
+	}
+
+	// NOTE: Rules are run in an arbitrary order, so we need a total order
+	// to compare results.
+	sort.Slice(alerts, func(i, j int) bool {
+		a, b := alerts[i], alerts[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		} else if a.Span[0] != b.Span[0] {
+			return a.Span[0] < b.Span[0]
+		}
+		return a.Check < b.Check
+	})
+
+	b, err := json.Marshal(alerts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestFrozenRules(t *testing.T) {
+	styles, err := filepath.Abs("../../styles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture, err := filepath.Abs("../../fixtures/styles/demo/test.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := core.NewConfig(&core.CLIFlags{SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.StylesPath = styles
+	cfg.Paths = []string{styles}
+	cfg.GBaseStyles = []string{"demo", "Vale"}
+	cfg.Styles = cfg.GBaseStyles
+	if err = cfg.AddWordListFile(filepath.Join(styles, "Vocab", "Cap", "accept.txt"), true); err != nil {
+		t.Fatal(err)
+	}
+	cfg.RejectedTokens["FOOOOOO"] = struct{}{}
+
+	// The exported rules keep the levels assigned by our configuration.
+	cfg.RuleToLevel["demo.Contractions"] = "warning"
+	cfg.RuleToLevel["Vale.Avoid"] = "warning"
+
+	expected := lintFixture(t, cfg, fixture)
+
+	alerts := []core.Alert{}
+	if err = json.Unmarshal(expected, &alerts); err != nil {
+		t.Fatal(err)
+	}
+	overridden := map[string]int{}
+	for _, a := range alerts {
+		if a.Check == "demo.Contractions" || a.Check == "Vale.Avoid" {
+			if a.Severity != "warning" {
+				t.Errorf("expected '%s' to be a warning, got '%s'", a.Check, a.Severity)
+			}
+			overridden[a.Check]++
+		}
+	}
+	if len(overridden) != 2 {
+		t.Fatalf("expected alerts from both overridden rules, got %v", overridden)
+	}
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frozen, err := linter.Manager.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmp, err := ioutil.TempFile("", "frozen*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(frozen); err != nil {
+		t.Fatal(err)
+	}
+	tmp.Close()
+
+	cfg, err = core.NewConfig(&core.CLIFlags{Rules: tmp.Name(), SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.GBaseStyles = []string{"demo", "Vale"}
+	cfg.Styles = cfg.GBaseStyles
+
+	if observed := lintFixture(t, cfg, fixture); string(observed) != string(expected) {
+		t.Errorf("expected = %s,\ngot = %s", expected, observed)
+	}
+}
+
+func TestIgnoreFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	testutil.WriteFiles(t, dir, map[string]string{
+		"README.md":    "Hello.",
+		"CHANGELOG.md": "Hello.",
+		"vendor/a.md":  "Hello.",
+		"docs/b.md":    "Hello.",
+	})
+
+	cfg, err := core.NewConfig(&core.CLIFlags{Ignore: "CHANGELOG.md, vendor"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.IgnoreFiles = []string{"docs/*.md"}
+	cfg.GBaseStyles = []string{"Vale"}
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	linted, err := linter.Lint([]string{dir}, "*")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(linted) != 1 || filepath.Base(linted[0].Path) != "README.md" {
+		for _, f := range linted {
+			t.Errorf("unexpected file: %s", f.Path)
+		}
+	}
+}
+
+func TestNearestConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		// This is outside of the repository, so it should never be loaded.
+		".vale.ini": "[*]\nBasedOnStyles = Missing\n",
+
+		"repo/.git/HEAD":              "",
+		"repo/.vale.ini":              "StylesPath = styles\n[*]\nBasedOnStyles = A\nA.Foo = YES\n",
+		"repo/sub/.vale.ini":          "StylesPath = ../other\n[*]\nBasedOnStyles = B\n",
+		"repo/sub/nested/.vale.ini":   "[*]\nA.Foo = NO\n",
+		"repo/iso/.vale.ini":          "Root = true\nStylesPath = ../other\n[*]\nBasedOnStyles = B\n",
+		"repo/styles/A/Foo.yml":       "extends: existence\nmessage: '%s'\ntokens:\n  - foo\n",
+		"repo/other/B/Bar.yml":        "extends: existence\nmessage: '%s'\ntokens:\n  - bar\n",
+		"repo/a.md":                   "foo bar\n",
+		"repo/sub/b.md":               "foo bar\n",
+		"repo/sub/nested/deep.md":     "foo bar\n",
+		"repo/sub/nested/more/end.md": "foo bar\n",
+		"repo/iso/c.md":               "foo bar\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	expected := map[string]string{
+		"a.md":                   "A.Foo",
+		"sub/b.md":               "A.Foo B.Bar",
+		"sub/nested/deep.md":     "B.Bar",
+		"sub/nested/more/end.md": "B.Bar",
+		"iso/c.md":               "B.Bar",
+	}
+
+	root := filepath.Join(dir, "repo")
+	for _, single := range []bool{false, true} {
+		cfg, err := core.NewConfig(&core.CLIFlags{
+			Path: filepath.Join(root, ".vale.ini"), SingleConfig: single})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.StylesPath = filepath.Join(root, "styles")
+		cfg.Paths = []string{cfg.StylesPath}
+		cfg.GBaseStyles = []string{"A"}
+		cfg.Styles = cfg.GBaseStyles
+
+		linter, err := NewLinter(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		linted, err := linter.Lint([]string{root}, "*.md")
+		if err != nil {
+			t.Fatal(err)
+		} else if len(linted) != len(expected) {
+			t.Fatalf("expected %d files, got %d", len(expected), len(linted))
+		}
+
+		for _, f := range linted {
+			rel, _ := filepath.Rel(root, f.Path)
+
+			checks := []string{}
+			for _, a := range f.Alerts {
+				checks = append(checks, a.Check)
+			}
+			sort.Strings(checks)
+
+			want := expected[filepath.ToSlash(rel)]
+			if single {
+				want = "A.Foo"
+			}
+			if got := strings.Join(checks, " "); got != want {
+				t.Errorf("%s (single = %v): expected = '%s', got = '%s'", rel, single, want, got)
+			}
+		}
+	}
+}
+
+// cmsBlocks is a reference `core.FormatFunc` for a simple block-based format,
+// in which each line is a JSON object describing a single block -- e.g.,
+// `{"type": "heading", "text": "..."}`. Code blocks aren't linted.
+func cmsBlocks(content string) ([]core.FormatBlock, error) {
+	var blocks []core.FormatBlock
+
+	for i, line := range strings.Split(content, "\n") {
+		var block struct {
+			Type string
+			Text string
+		}
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		} else if err := json.Unmarshal([]byte(line), &block); err != nil {
+			return nil, err
+		}
+
+		scope := "text"
+		switch block.Type {
+		case "code":
+			continue
+		case "heading":
+			scope = "text.heading.h1"
+		}
+
+		blocks = append(blocks, core.FormatBlock{
+			Scope: scope, Text: block.Text, Start: i + 1, End: i + 1})
+	}
+
+	return blocks, nil
+}
+
+func TestCustomFormat(t *testing.T) {
+	dir := t.TempDir()
+
+	lines := []string{
+		`{"type": "heading", "text": "A foo heading"}`,
+		`{"type": "paragraph", "text": "Some foo text."}`,
+		`{"type": "code", "text": "foo()"}`,
+	}
+
+	files := map[string]string{
+		"styles/A/Foo.yml":  "extends: existence\nmessage: '%s'\ntokens:\n  - foo\n",
+		"styles/A/Head.yml": "extends: existence\nmessage: '%s'\nscope: heading\ntokens:\n  - heading\n",
+		"test.cms":          strings.Join(lines, "\n") + "\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	core.RegisterFormat(".cms", cmsBlocks)
+
+	cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".txt", SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.StylesPath = filepath.Join(dir, "styles")
+	cfg.Paths = []string{cfg.StylesPath}
+	cfg.GBaseStyles = []string{"A"}
+	cfg.Styles = cfg.GBaseStyles
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	linted, err := linter.Lint([]string{filepath.Join(dir, "test.cms")}, "*")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(linted) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(linted))
+	}
+
+	expected := []struct {
+		check string
+		line  int
+		col   int
+	}{
+		{"A.Foo", 1, strings.Index(lines[0], "foo") + 1},
+		{"A.Head", 1, strings.LastIndex(lines[0], "heading") + 1},
+		{"A.Foo", 2, strings.Index(lines[1], "foo") + 1},
+	}
+
+	alerts := linted[0].SortedAlerts()
+	if len(alerts) != len(expected) {
+		t.Fatalf("expected %d alerts, got %v", len(expected), alerts)
+	}
+	for i, a := range alerts {
+		e := expected[i]
+		if a.Check != e.check || a.Line != e.line || a.Span[0] != e.col {
+			t.Errorf("expected = %v, got = %s (%d:%d)", e, a.Check, a.Line, a.Span[0])
+		}
+	}
+}
+
+func TestRawScope(t *testing.T) {
+	dir := t.TempDir()
+
+	lines := []string{
+		"# A <b>bold</b> heading",
+		"",
+		"```html",
+		"日本語 <b>code</b>",
+		"```",
+		"",
+		strings.Repeat("é", 121),
+	}
+
+	files := map[string]string{
+		"styles/A/Bold.yml":  "extends: existence\nmessage: '%s'\nscope: raw\nnonword: true\ntokens:\n  - '<b>'\n",
+		"styles/A/Start.yml": "extends: existence\nmessage: '%s'\nscope: raw.file\nnonword: true\ntokens:\n  - '\\A#'\n",
+		"test.md":            strings.Join(lines, "\n") + "\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".md", SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.StylesPath = filepath.Join(dir, "styles")
+	cfg.Paths = []string{cfg.StylesPath}
+	cfg.GBaseStyles = []string{"A"}
+	cfg.Styles = cfg.GBaseStyles
+	cfg.MinAlertLevel = 0
+	cfg.Checks = []string{"Vale.LineLength"}
+	cfg.GChecks["Vale.LineLength"] = true
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	linted, err := linter.Lint([]string{filepath.Join(dir, "test.md")}, "*")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(linted) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(linted))
+	}
+
+	expected := []struct {
+		check string
+		line  int
+		span  []int
+	}{
+		{"A.Start", 1, []int{1, 1}},
+		{"A.Bold", 1, []int{5, 7}},
+		{"A.Bold", 4, []int{5, 7}},
+		{"Vale.LineLength", 7, []int{1, 121}},
+	}
+
+	alerts := linted[0].SortedAlerts()
+	if len(alerts) != len(expected) {
+		t.Fatalf("expected %d alerts, got %v", len(expected), alerts)
+	}
+	for i, a := range alerts {
+		e := expected[i]
+		if a.Check != e.check || a.Line != e.line || a.Span[0] != e.span[0] || a.Span[1] != e.span[1] {
+			t.Errorf("expected = %v, got = %s (%d:%v)", e, a.Check, a.Line, a.Span)
+		}
+	}
+}
+
+func TestExpandGlob(t *testing.T) {
+	dir := t.TempDir()
+
+	testutil.WriteFiles(t, dir, map[string]string{
+		"a.md":              "text",
+		"b.txt":             "text",
+		"sub/c.md":          "text",
+		"sub/deep/d.md":     "text",
+		"node_modules/e.md": "text",
+	})
+
+	root := filepath.ToSlash(dir)
+	cases := map[string][]string{
+		root + "/*.md":        {"a.md"},
+		root + "/**/*.md":     {"a.md", "sub/c.md", "sub/deep/d.md"},
+		root + "/sub/*/*.md":  {"sub/deep/d.md"},
+		root + "/*.{md,txt}":  {"a.md", "b.txt"},
+		root + "/missing/*.*": {},
+	}
+	for pattern, expected := range cases {
+		if !IsGlob(pattern) {
+			t.Errorf("expected '%s' to be a glob", pattern)
+		}
+
+		matches, err := ExpandGlob(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rel := []string{}
+		for _, m := range matches {
+			r, _ := filepath.Rel(dir, m)
+			rel = append(rel, filepath.ToSlash(r))
+		}
+		if strings.Join(rel, ",") != strings.Join(expected, ",") {
+			t.Errorf("%s: expected %v, got %v", pattern, expected, rel)
+		}
+	}
+
+	for _, arg := range []string{"Is this OK?", "what?", filepath.Join(dir, "a.md")} {
+		if IsGlob(arg) {
+			t.Errorf("expected '%s' not to be a glob", arg)
+		}
+	}
+}
+
+func TestStripJSX(t *testing.T) {
+	src := strings.Join([]string{
+		"import { Note } from './note'",
+		"export const meta = {",
+		"  title: 'A',",
+		"}",
+		"",
+		"<Note title=\"Hi\">",
+		"Text in {props.name} a note.",
+		"</Note>",
+		"",
+		"<Image",
+		"  src=\"a.png\"",
+		"/>",
+		"",
+		"Use `{code}` and <Badge>this</Badge>.",
+		"",
+		"```jsx",
+		"<Note>{x}</Note>",
+		"```",
+	}, "\n")
+
+	expected := strings.Join([]string{
+		"",
+		"",
+		"",
+		"",
+		"",
+		"",
+		"Text in  a note.",
+		"",
+		"",
+		"",
+		"",
+		"",
+		"",
+		"Use `{code}` and this.",
+		"",
+		"```jsx",
+		"<Note>{x}</Note>",
+		"```",
+	}, "\n")
+
+	if got := stripJSX(src); got != expected {
+		t.Errorf("expected = %q, got = %q", expected, got)
+	}
+
+	// The masked source keeps each line's text in the same columns.
+	masked := strings.Split(maskJSX(src), "\n")
+	for i, line := range strings.Split(src, "\n") {
+		if utf8.RuneCountInString(masked[i]) != utf8.RuneCountInString(line) {
+			t.Errorf("line %d: expected %q to be as long as %q", i+1, masked[i], line)
+		}
+	}
+	if masked[6] != "Text in ************ a note." {
+		t.Errorf("unexpected masked line: %q", masked[6])
+	}
+}
+
+func TestMDXPositions(t *testing.T) {
+	dir := t.TempDir()
+
+	lines := []string{
+		"import Tabs from '@theme/Tabs';",
+		"",
+		"<Tabs groupId=\"os\">",
+		"  <TabItem value=\"a\">This is TODO prose.</TabItem>",
+		"</Tabs>",
+		"",
+		"Some <Badge label=\"TODO\">TODO</Badge> with {props.TODO} and TODO.",
+	}
+
+	files := map[string]string{
+		"styles/A/Word.yml": "extends: existence\nmessage: '%s'\ntokens:\n  - TODO\n",
+		"test.mdx":          strings.Join(lines, "\n") + "\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".txt", SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.StylesPath = filepath.Join(dir, "styles")
+	cfg.Paths = []string{cfg.StylesPath}
+	cfg.GBaseStyles = []string{"A"}
+	cfg.Styles = cfg.GBaseStyles
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	linted, err := linter.Lint([]string{filepath.Join(dir, "test.mdx")}, "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	observed := []string{}
+	for _, a := range linted[0].SortedAlerts() {
+		observed = append(observed, fmt.Sprintf("%d:%d", a.Line, a.Span[0]))
+	}
+
+	// The `TODO`s in JSX attributes and expressions aren't prose.
+	expected := []string{
+		fmt.Sprintf("4:%d", strings.Index(lines[3], "TODO")+1),
+		fmt.Sprintf("7:%d", strings.Index(lines[6], ">TODO")+2),
+		fmt.Sprintf("7:%d", strings.LastIndex(lines[6], "TODO")+1),
+	}
+	if fmt.Sprint(observed) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, observed)
+	}
+}
+
+func TestChangedDuringLint(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"styles/A/Foo.yml": "extends: existence\nmessage: '%s'\ntokens:\n  - foo\n",
+		"docs/a.md":        "# One\n\nTwo foo.\n",
+		"docs/b.md":        "# Three\n\nFour foo.\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".md", SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.StylesPath = filepath.Join(dir, "styles")
+	cfg.Paths = []string{cfg.StylesPath}
+	cfg.GBaseStyles = []string{"A"}
+	cfg.Styles = cfg.GBaseStyles
+	cfg.MinAlertLevel = 0
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A file truncated after it's read is linted as it was.
+	a := filepath.Join(dir, "docs", "a.md")
+	f, err := core.NewFile(a, cfg)
+	if err != nil {
+		t.Fatal(err)
+	} else if err = ioutil.WriteFile(a, []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	} else if err = linter.lintMarkdown(f); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Alerts) != 1 || f.Alerts[0].Line != 3 || f.Alerts[0].Span[0] != 5 {
+		t.Errorf("expected 'foo' at 3:5, got %v", f.Alerts)
+	}
+
+	// A file removed after it's found is a failure.
+	result := linter.lintPath(filepath.Join(dir, "docs", "c.md"))
+	var failure *Failure
+	if !errors.As(result.err, &failure) {
+		t.Errorf("expected a failure, got %v", result.err)
+	}
+
+	// So is an unexpected panic, which doesn't stop the rest of the run.
+	linter.onBlock = func(blk core.Block) { panic("boom") }
+	linted, err := linter.Lint([]string{filepath.Join(dir, "docs")}, "*")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(linted) != 0 || len(linter.Failures()) != 2 {
+		t.Errorf("expected 2 failures, got %v (and %d files)", linter.Failures(), len(linted))
+	}
+}
+
+func TestMemoryWatchdog(t *testing.T) {
+	limits := map[string]uint64{"off": 0, "1024": 1024, "512MiB": 512 << 20, "2GiB": 2 << 30}
+	for s, expected := range limits {
+		if limit, err := parseMemLimit(s); err != nil || limit != expected {
+			t.Errorf("parseMemLimit(%q): expected %d, got %d (%v)", s, expected, limit, err)
+		}
+	}
+	if _, err := parseMemLimit("lots"); err == nil {
+		t.Error("expected an error for an invalid limit")
+	}
+
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"styles/A/Foo.yml": "extends: existence\nmessage: '%s'\ntokens:\n  - foo\n",
+		"docs/a.md":        "One foo.\n",
+		"docs/b.md":        "Two foo.\n",
+		"docs/c.md":        "Three foo.\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".md", SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.StylesPath = filepath.Join(dir, "styles")
+	cfg.Paths = []string{cfg.StylesPath}
+	cfg.GBaseStyles = []string{"A"}
+	cfg.Styles = cfg.GBaseStyles
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Near the limit, every file is still linted (one at a time), and we
+	// warn only once.
+	warnings := 0
+	linter.memory = &memoryWatchdog{
+		limit: 100,
+		inUse: func() uint64 { return 90 },
+		warn:  func(used, limit uint64) { warnings++ },
+	}
+
+	linted, err := linter.Lint([]string{filepath.Join(dir, "docs")}, "*")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(linted) != 3 {
+		t.Fatalf("expected 3 files, got %d", len(linted))
+	}
+	for _, f := range linted {
+		if len(f.Alerts) != 1 {
+			t.Errorf("%s: expected 1 alert, got %v", f.Path, f.Alerts)
+		}
+	}
+	if warnings != 1 || !linter.memory.nearLimit() {
+		t.Errorf("expected 1 warning, got %d", warnings)
+	}
+}
+
+// panicRule is a rule that panics on any text containing "boom".
+type panicRule struct{}
+
+func (r panicRule) Run(txt string, f *core.File) []core.Alert {
+	if strings.Contains(txt, "boom") {
+		var m map[string]int
+		m["boom"]++
+	}
+	return []core.Alert{}
+}
+
+func (r panicRule) Fields() check.Definition {
+	return check.Definition{Name: "A.Panic", Level: "error", Scope: "text"}
+}
+
+func (r panicRule) Pattern() string { return "" }
+
+func TestRulePanic(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"styles/A/Foo.yml": "extends: existence\nmessage: '%s'\ntokens:\n  - foo\n",
+		"docs/a.md":        "# Title\n\nThis foo goes boom.\n",
+		"docs/b.md":        "# Title\n\nAnother foo, and another boom.\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".txt", SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.StylesPath = filepath.Join(dir, "styles")
+	cfg.Paths = []string{cfg.StylesPath}
+	cfg.GBaseStyles = []string{"A"}
+	cfg.Styles = cfg.GBaseStyles
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	} else if err = linter.Manager.AddRule("A.Panic", panicRule{}); err != nil {
+		t.Fatal(err)
+	}
+
+	linted, err := linter.Lint([]string{filepath.Join(dir, "docs")}, "*")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(linted) != 2 || len(linter.Failures()) != 0 {
+		t.Fatalf("expected 2 files, got %d (and %v)", len(linted), linter.Failures())
+	}
+
+	// The other rules still ran ...
+	for _, f := range linted {
+		if len(f.Alerts) != 1 || f.Alerts[0].Check != "A.Foo" {
+			t.Errorf("%s: expected 'A.Foo', got %v", f.Path, f.Alerts)
+		}
+	}
+
+	// ... while the panic was recorded once, after which the rule was
+	// disabled.
+	errs := linter.RuleErrors()
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 rule error, got %v", errs)
+	}
+
+	e := errs[0]
+	if e.Rule != "A.Panic" || !strings.Contains(e.Excerpt, "boom") || !strings.HasPrefix(e.Scope, "text") {
+		t.Errorf("unexpected rule error: %s", e.Error())
+	} else if !strings.Contains(e.Error(), "assignment to entry in nil map") {
+		t.Errorf("expected the panic's value, got %s", e.Error())
+	}
+}
+
+func TestPO(t *testing.T) {
+	dir := t.TempDir()
+
+	lines := []string{
+		`# translator: foo`,
+		`#, fuzzy`,
+		`msgid "foo"`,
+		`msgstr "Un foo."`,
+		``,
+		`msgctxt "UI|Button"`,
+		`msgid "Save foo"`,
+		`msgstr ""`,
+		`"Enregistrer "`,
+		`"le foo"`,
+		``,
+		`msgid "One foo"`,
+		`msgid_plural "Many foos"`,
+		`msgstr[0] "Un foo"`,
+		`msgstr[1] "Des bar"`,
+	}
+
+	files := map[string]string{
+		"styles/A/Foo.yml": "extends: existence\nmessage: '%s'\ntokens:\n  - foo\n",
+		"styles/A/UI.yml":  "extends: existence\nmessage: '%s'\nscope: text.ui_button\ntokens:\n  - le\n",
+		"test.po":          strings.Join(lines, "\n") + "\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".txt", SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.StylesPath = filepath.Join(dir, "styles")
+	cfg.Paths = []string{cfg.StylesPath}
+	cfg.GBaseStyles = []string{"A"}
+	cfg.Styles = cfg.GBaseStyles
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	linted, err := linter.Lint([]string{filepath.Join(dir, "test.po")}, "*")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(linted) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(linted))
+	}
+
+	// Alerts in a value split across lines are reported on its first line,
+	// relative to the start of its text.
+	expected := []struct {
+		check string
+		line  int
+		col   int
+	}{
+		{"A.Foo", 4, strings.Index(lines[3], "foo") + 1},
+		{"A.UI", 9, len(`"Enregistrer `) + 1},
+		{"A.Foo", 9, len(`"Enregistrer le `) + 1},
+		{"A.Foo", 14, strings.Index(lines[13], "foo") + 1},
+	}
+
+	alerts := linted[0].SortedAlerts()
+	if len(alerts) != len(expected) {
+		t.Fatalf("expected %d alerts, got %v", len(expected), alerts)
+	}
+	for i, a := range alerts {
+		e := expected[i]
+		if a.Check != e.check || a.Line != e.line || a.Span[0] != e.col {
+			t.Errorf("expected = %v, got = %s (%d:%d)", e, a.Check, a.Line, a.Span[0])
+		}
+	}
+}
+
+func TestOrg(t *testing.T) {
+	dir := t.TempDir()
+
+	lines := []string{
+		`#+TITLE: A foo title`,
+		``,
+		`* TODO A foo heading :foo:`,
+		`Some foo with =foo= and ~foo~ code,`,
+		`and another foo.`,
+		``,
+		`# A comment about foo.`,
+		``,
+		`#+BEGIN_SRC go`,
+		`foo := 1`,
+		`#+END_SRC`,
+		`:PROPERTIES:`,
+		`:ID: foo`,
+		`:END:`,
+		``,
+		`- [ ] An item.`,
+		`- A *foo* item.`,
+		``,
+		`| bar | foo |`,
+		`|-----+-----|`,
+		``,
+		`See [[https://foo.com][the foo site]].`,
+		``,
+		`** A bar heading`,
+		`: foo := 1`,
+		``,
+		`* COMMENT A foo subtree`,
+		`Some foo.`,
+		`** A foo child`,
+		`* Done`,
+	}
+
+	files := map[string]string{
+		"styles/A/Foo.yml":     "extends: existence\nmessage: '%s'\ntokens:\n  - foo\n",
+		"styles/A/Comment.yml": "extends: existence\nmessage: '%s'\nscope: text.comment\ntokens:\n  - comment\n",
+		"styles/A/H2.yml":      "extends: existence\nmessage: '%s'\nscope: heading.h2\ntokens:\n  - bar\n",
+		"test.org":             strings.Join(lines, "\n") + "\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".txt", SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.StylesPath = filepath.Join(dir, "styles")
+	cfg.Paths = []string{cfg.StylesPath}
+	cfg.GBaseStyles = []string{"A"}
+	cfg.Styles = cfg.GBaseStyles
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	linted, err := linter.Lint([]string{filepath.Join(dir, "test.org")}, "*")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(linted) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(linted))
+	}
+
+	// Keywords, blocks, drawers, tags, code, fixed-width lines, and
+	// commented subtrees are skipped.
+	expected := []struct {
+		check string
+		line  int
+		col   int
+	}{
+		{"A.Foo", 3, strings.Index(lines[2], "foo") + 1},
+		{"A.Foo", 4, strings.Index(lines[3], "foo") + 1},
+		{"A.Foo", 5, strings.Index(lines[4], "foo") + 1},
+		{"A.Comment", 7, strings.Index(lines[6], "comment") + 1},
+		{"A.Foo", 7, strings.Index(lines[6], "foo") + 1},
+		{"A.Foo", 17, strings.Index(lines[16], "foo") + 1},
+		{"A.Foo", 19, strings.Index(lines[18], "foo") + 1},
+		{"A.Foo", 22, strings.LastIndex(lines[21], "foo") + 1},
+		{"A.H2", 24, strings.Index(lines[23], "bar") + 1},
+	}
+
+	alerts := linted[0].SortedAlerts()
+	if len(alerts) != len(expected) {
+		t.Fatalf("expected %d alerts, got %v", len(expected), alerts)
+	}
+	for i, a := range alerts {
+		e := expected[i]
+		if a.Check != e.check || a.Line != e.line || a.Span[0] != e.col {
+			t.Errorf("expected = %v, got = %s (%d:%d)", e, a.Check, a.Line, a.Span[0])
+		}
+	}
+}
+
+func TestRSTNative(t *testing.T) {
+	dir := t.TempDir()
+
+	lines := []string{
+		`=============`,
+		`A foo title`,
+		`=============`,
+		``,
+		`:Author: A foo author`,
+		``,
+		`.. note:: A foo note`,
+		`   that continues.`,
+		``,
+		`.. _foo-target: https://foo.com`,
+		``,
+		`.. toctree::`,
+		`   :maxdepth: 2`,
+		``,
+		`   foo/index`,
+		``,
+		`.. vale off`,
+		``,
+		`A foo that's off.`,
+		``,
+		`.. vale on`,
+		``,
+		`=====  =====`,
+		`A      B`,
+		`=====  =====`,
+		`bar    foo`,
+		`=====  =====`,
+		``,
+		"See ``foo``, :ref:`foo <bar>`, and `the foo <https://foo.com>`_ [#]_.",
+		``,
+		`.. [#] A foo footnote.`,
+		``,
+		`Term`,
+		`   A foo definition.`,
+		``,
+		`- An item::`,
+		``,
+		`     foo = 1`,
+		``,
+		`Some foo.`,
+	}
+
+	files := map[string]string{
+		"styles/A/Foo.yml": "extends: existence\nmessage: '%s'\ntokens:\n  - foo\n",
+		"test.rst":         strings.Join(lines, "\n") + "\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".txt", SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.StylesPath = filepath.Join(dir, "styles")
+	cfg.Paths = []string{cfg.StylesPath}
+	cfg.GBaseStyles = []string{"A"}
+	cfg.Styles = cfg.GBaseStyles
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	linted, err := linter.Lint([]string{filepath.Join(dir, "test.rst")}, "*")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(linted) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(linted))
+	}
+
+	// Targets, other directives, literals, roles, URLs, and the text turned
+	// off by comments are skipped.
+	expected := []struct {
+		line int
+		col  int
+	}{
+		{2, strings.Index(lines[1], "foo") + 1},
+		{5, strings.Index(lines[4], "foo") + 1},
+		{7, strings.Index(lines[6], "foo") + 1},
+		{26, strings.Index(lines[25], "foo") + 1},
+		{29, strings.Index(lines[28], "the foo") + 5},
+		{31, strings.Index(lines[30], "foo") + 1},
+		{34, strings.Index(lines[33], "foo") + 1},
+		{40, strings.Index(lines[39], "foo") + 1},
+	}
+
+	alerts := linted[0].SortedAlerts()
+	if len(alerts) != len(expected) {
+		t.Fatalf("expected %d alerts, got %v", len(expected), alerts)
+	}
+	for i, a := range alerts {
+		if a.Line != expected[i].line || a.Span[0] != expected[i].col {
+			t.Errorf("expected foo at %d:%d, got %d:%d", expected[i].line, expected[i].col, a.Line, a.Span[0])
+		}
+	}
+}
+
+func TestADocNative(t *testing.T) {
+	dir := t.TempDir()
+
+	// We only convert AsciiDoc ourselves if Asciidoctor isn't installed.
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	os.Setenv("PATH", dir)
+
+	lines := []string{
+		`= A foo title`,
+		`Foo Author <foo@example.com>`,
+		`:toc: foo`,
+		`:description: A foo \`,
+		`  that continues.`,
+		``,
+		`== A foo heading`,
+		`Some foo with ` + "`foo`" + ` code and {foo} here, +`,
+		`and another *foo*.`,
+		``,
+		`// A comment about foo.`,
+		``,
+		`[source,go]`,
+		`----`,
+		`foo := 1`,
+		`----`,
+		`image::foo.png[]`,
+		``,
+		`* An item.`,
+		`* A _bar_ item.`,
+		`+`,
+		`More about foo.`,
+		``,
+		`NOTE: Remember the foo.`,
+		``,
+		`|===`,
+		`| bar | foo`,
+		`|===`,
+		``,
+		`See https://foo.com[the foo site].`,
+	}
+
+	files := map[string]string{
+		"styles/A/Foo.yml":     "extends: existence\nmessage: '%s'\ntokens:\n  - foo\n",
+		"styles/A/Comment.yml": "extends: existence\nmessage: '%s'\nscope: text.comment\ntokens:\n  - comment\n",
+		"test.adoc":            strings.Join(lines, "\n") + "\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".txt", SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.StylesPath = filepath.Join(dir, "styles")
+	cfg.Paths = []string{cfg.StylesPath}
+	cfg.GBaseStyles = []string{"A"}
+	cfg.Styles = cfg.GBaseStyles
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	linted, err := linter.Lint([]string{filepath.Join(dir, "test.adoc")}, "*")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(linted) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(linted))
+	}
+
+	// The header, attributes, blocks, macros, code, and URLs are skipped.
+	expected := []struct {
+		check string
+		line  int
+		col   int
+	}{
+		{"A.Foo", 1, strings.Index(lines[0], "foo") + 1},
+		{"A.Foo", 7, strings.Index(lines[6], "foo") + 1},
+		{"A.Foo", 8, strings.Index(lines[7], "foo") + 1},
+		{"A.Foo", 9, strings.Index(lines[8], "foo") + 1},
+		{"A.Comment", 11, strings.Index(lines[10], "comment") + 1},
+		{"A.Foo", 11, strings.Index(lines[10], "foo") + 1},
+		{"A.Foo", 22, strings.Index(lines[21], "foo") + 1},
+		{"A.Foo", 24, strings.Index(lines[23], "foo") + 1},
+		{"A.Foo", 27, strings.Index(lines[26], "foo") + 1},
+		{"A.Foo", 30, strings.LastIndex(lines[29], "foo") + 1},
+	}
+
+	alerts := linted[0].SortedAlerts()
+	if len(alerts) != len(expected) {
+		t.Fatalf("expected %d alerts, got %v", len(expected), alerts)
+	}
+	for i, a := range alerts {
+		e := expected[i]
+		if a.Check != e.check || a.Line != e.line || a.Span[0] != e.col {
+			t.Errorf("expected = %v, got = %s (%d:%d)", e, a.Check, a.Line, a.Span[0])
+		}
+	}
+}
+
+func TestPDF(t *testing.T) {
+	dir := t.TempDir()
+
+	pages := []string{
+		`BT /F1 12 Tf 72 700 Td (A page about foo.) Tj ET`,
+		`BT /F1 12 Tf 72 700 Td (Nothing to see here.) Tj ET`,
+		`BT /F1 12 Tf 72 700 Td (More) Tj 0 -14 Td [(f) 10 (oo) -250 (and foo.)] TJ ET`,
+	}
+
+	objects := []string{
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents 6 0 R >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents 7 0 R >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents 8 0 R >>`,
+	}
+	for _, page := range pages {
+		objects = append(objects, fmt.Sprintf(
+			"<< /Length %d >>\nstream\n%s\nendstream", len(page), page))
+	}
+
+	pdf := "%PDF-1.4\n"
+	for i, obj := range objects {
+		pdf += fmt.Sprintf("%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	pdf += "trailer\n<< /Root 1 0 R >>\n%%EOF\n"
+
+	files := map[string]string{
+		"styles/A/Foo.yml": "extends: existence\nmessage: '%s'\ntokens:\n  - foo\n",
+		"test.pdf":         pdf,
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".txt", SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.StylesPath = filepath.Join(dir, "styles")
+	cfg.Paths = []string{cfg.StylesPath}
+	cfg.GBaseStyles = []string{"A"}
+	cfg.Styles = cfg.GBaseStyles
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	linted, err := linter.Lint([]string{filepath.Join(dir, "test.pdf")}, "*")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(linted) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(linted))
+	}
+
+	// Each page is a line, with its text joined by spaces.
+	expected := [][]int{{1, 14}, {3, 6}, {3, 14}}
+
+	alerts := linted[0].SortedAlerts()
+	if len(alerts) != len(expected) {
+		t.Fatalf("expected %d alerts, got %v", len(expected), alerts)
+	}
+	for i, a := range alerts {
+		if a.Line != expected[i][0] || a.Span[0] != expected[i][1] {
+			t.Errorf("expected = %v, got = %d:%d", expected[i], a.Line, a.Span[0])
+		}
+	}
+}
+
+func TestTransform(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	dir := t.TempDir()
+
+	// `to_html.sh` converts each line starting with "! " into a paragraph,
+	// mapping it back to its source line.
+	files := map[string]string{
+		"styles/A/Foo.yml": "extends: existence\nmessage: '%s'\ntokens:\n  - foo\n",
+		"to_html.sh": `#!/bin/sh
+n=0; out=0
+while IFS= read -r line; do
+  n=$((n+1))
+  case "$line" in
+    "! "*) out=$((out+1)); echo "<p>${line#! }</p>"; echo "$out $n" >&3 ;;
+  esac
+done
+`,
+		"fail.sh":  "#!/bin/sh\necho 'bad input' >&2\nexit 3\n",
+		"test.foo": "# A foo heading\n! This is foo.\nskip\n! Another foo and foo.\n",
+		"test.bar": "foo\n",
+		"test.baz": "foo\n! A foo.\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+	for _, script := range []string{"to_html.sh", "fail.sh"} {
+		if err := os.Chmod(filepath.Join(dir, script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".txt", SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.StylesPath = filepath.Join(dir, "styles")
+	cfg.Paths = []string{cfg.StylesPath}
+	cfg.GBaseStyles = []string{"A"}
+	cfg.Styles = cfg.GBaseStyles
+	cfg.Formats["foo"] = "html"
+	cfg.Stylesheets["*.foo"] = filepath.Join(dir, "to_html.sh")
+	cfg.Stylesheets["*.bar"] = filepath.Join(dir, "fail.sh")
+	cfg.Commands["*.baz"] = "sh " + filepath.Join(dir, "to_html.sh")
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	linted, err := linter.Lint([]string{
+		filepath.Join(dir, "test.foo"), filepath.Join(dir, "test.bar"),
+		filepath.Join(dir, "test.baz")}, "*")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(linted) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(linted))
+	}
+	sort.Slice(linted, func(i, j int) bool { return linted[i].Path < linted[j].Path })
+
+	// `test.baz` is converted by its `Command`, which is given arguments.
+	alerts := linted[0].SortedAlerts()
+	if len(alerts) != 1 || alerts[0].Line != 2 || alerts[0].Span[0] != 5 {
+		t.Errorf("expected an alert at 2:5 in test.baz, got %v", alerts)
+	}
+
+	expected := [][]int{{2, 11}, {4, 11}, {4, 19}}
+
+	alerts = linted[1].SortedAlerts()
+	if len(alerts) != len(expected) {
+		t.Fatalf("expected %d alerts, got %v", len(expected), alerts)
+	}
+	for i, a := range alerts {
+		if a.Line != expected[i][0] || a.Span[0] != expected[i][1] {
+			t.Errorf("expected = %v, got = %d:%d", expected[i], a.Line, a.Span[0])
+		}
+	}
+
+	failures := linter.Failures()
+	if len(failures) != 1 || !strings.Contains(failures[0].Error(), "bad input") {
+		t.Errorf("expected a failure for test.bar, got %v", failures)
+	}
+}
+
+// TestScopesGolden checks that our alerts for each of the scope fixtures
+// (which cover every markup scope) match `fixtures/scopes/golden.txt`, both
+// for the full rule set and for each rule on its own (which only requires
+// some scopes to be extracted).
+func TestDuplicateAlerts(t *testing.T) {
+	root, err := filepath.Abs("../../fixtures/dedupe")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := core.NewConfig(&core.CLIFlags{
+		InExt: ".txt", Rules: filepath.Join(root, "rules.yml"), SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.GBaseStyles = []string{"Dupe"}
+	cfg.Styles = cfg.GBaseStyles
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	linted, err := linter.Lint([]string{filepath.Join(root, "test.md")}, "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The heading and list item aren't part of the summary, so only the
+	// paragraphs' matches are reported -- each of them once.
+	got := []string{}
+	for _, a := range linted[0].SortedAlerts() {
+		got = append(got, fmt.Sprintf("%d:%d %s", a.Line, a.Span[0], a.Check))
+	}
+
+	expected := []string{"3:16 Dupe.Foo", "7:6 Dupe.Foo", "8:1 Dupe.Foo"}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestScopesGolden(t *testing.T) {
+	root, err := filepath.Abs("../../fixtures/scopes")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixtures, err := filepath.Glob(filepath.Join(root, "*", "test.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := filepath.Glob(filepath.Join(root, "rules", "*.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lint := func(checks []string) []string {
+		cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".txt", SingleConfig: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.StylesPath = root
+		cfg.Paths = []string{root}
+		cfg.MinAlertLevel = 0
+		for _, chk := range checks {
+			cfg.GChecks[chk] = true
+			cfg.Checks = append(cfg.Checks, chk)
+		}
+
+		linter, err := NewLinter(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		linted, err := linter.Lint(fixtures, "*")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		results := []string{}
+		for _, f := range linted {
+			rel, _ := filepath.Rel(root, f.Path)
+			for _, a := range f.SortedAlerts() {
+				results = append(results, fmt.Sprintf(
+					"%s:%d:%d %s", filepath.ToSlash(rel), a.Line, a.Span[0], a.Check))
+			}
+		}
+		sort.Strings(results)
+		return results
+	}
+
+	all := []string{"Vale.Spelling", "Vale.Repetition"}
+	for _, rule := range rules {
+		all = append(all, "rules."+strings.TrimSuffix(filepath.Base(rule), ".yml"))
+	}
+
+	golden, err := ioutil.ReadFile(filepath.Join(root, "golden.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Split(strings.TrimSpace(string(golden)), "\n")
+	if got := lint(all); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected:\n%s\n\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+
+	for _, chk := range all {
+		want := []string{}
+		for _, line := range expected {
+			if strings.HasSuffix(line, " "+chk) {
+				want = append(want, line)
+			}
+		}
+		if got := lint([]string{chk}); strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("%s: expected %v, got %v", chk, want, got)
+		}
+	}
+}
+
+func TestLintBytes(t *testing.T) {
+	root, err := filepath.Abs("../../fixtures/dedupe")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(root, "test.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := core.NewConfig(&core.CLIFlags{
+		InExt: ".txt", Rules: filepath.Join(root, "rules.yml"), SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.GBaseStyles = []string{"Dupe"}
+	cfg.Styles = cfg.GBaseStyles
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The extension, rather than `--ext`, decides the format: as Markdown, the
+	// results are the same as linting `test.md` itself.
+	linted, err := linter.LintBytes(content, "md")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(linted) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(linted))
+	} else if linted[0].Path != "stdin.md" {
+		t.Errorf("expected the path 'stdin.md', got '%s'", linted[0].Path)
+	}
+
+	got := []string{}
+	for _, a := range linted[0].SortedAlerts() {
+		got = append(got, fmt.Sprintf("%d:%d %s", a.Line, a.Span[0], a.Check))
+	}
+
+	expected := []string{"3:16 Dupe.Foo", "7:6 Dupe.Foo", "8:1 Dupe.Foo"}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestReadDocuments(t *testing.T) {
+	docs, err := ReadDocuments(strings.NewReader(
+		`[{"path": "a.md", "content": "foo"}, {"path": "b", "ext": "rst", "content": ""}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Document{
+		{Path: "a.md", Ext: ".md", Content: "foo"},
+		{Path: "b", Ext: "rst", Content: ""},
+	}
+	if fmt.Sprint(docs) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, docs)
+	}
+
+	invalid := map[string]string{
+		`{"path": "a.md", "content": "foo"}`:                                 "expected a JSON array",
+		`[{"path": "a.md", "content": "foo", "extra": 1}]`:                   "unknown field",
+		`[{"content": "foo"}]`:                                               "document 0: missing 'path'",
+		`[{"path": "a.md"}]`:                                                 "missing 'content'",
+		`[{"path": "a", "content": "foo"}]`:                                  "missing 'ext'",
+		`[{"path": "a.md", "content": ""}, {"path": "a.md", "content": ""}]`: "document 1: duplicate path",
+		`[] []`: "unexpected data",
+	}
+	for input, msg := range invalid {
+		if _, err := ReadDocuments(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: expected an error", input)
+		} else if !strings.Contains(err.Error(), msg) {
+			t.Errorf("%s: expected '%s', got '%s'", input, msg, err)
+		}
+	}
+}
+
+func TestLintDocuments(t *testing.T) {
+	root, err := filepath.Abs("../../fixtures/dedupe")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(root, "test.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := core.NewConfig(&core.CLIFlags{
+		InExt: ".txt", Rules: filepath.Join(root, "rules.yml"), SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.GBaseStyles = []string{"Dupe"}
+	cfg.Styles = cfg.GBaseStyles
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Neither path exists: each document is linted from its content, in the
+	// format given by its extension.
+	linted, err := linter.LintDocuments([]Document{
+		{Path: "docs/missing.md", Ext: ".md", Content: string(content)},
+		{Path: "notes", Ext: "md", Content: "A foo note."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, f := range linted {
+		for _, a := range f.SortedAlerts() {
+			got = append(got, fmt.Sprintf("%s %d:%d", f.Path, a.Line, a.Span[0]))
+		}
+	}
+
+	expected := []string{
+		"docs/missing.md 3:16", "docs/missing.md 7:6", "docs/missing.md 8:1", "notes 1:3"}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestEscalate(t *testing.T) {
+	dir := t.TempDir()
+
+	rule := strings.Join([]string{
+		"extends: existence",
+		"message: '%s'",
+		"level: suggestion",
+		"escalate:",
+		"  3: warning",
+		"  5: error",
+		"tokens:",
+		"  - very",
+	}, "\n")
+
+	files := map[string]string{
+		"styles/A/Very.yml": rule + "\n",
+		"two.txt":           "very very\n",
+		"three.txt":         "very very very\n",
+		"five.txt":          "very very very\nvery very\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".txt", SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.StylesPath = filepath.Join(dir, "styles")
+	cfg.Paths = []string{cfg.StylesPath}
+	cfg.GBaseStyles = []string{"A"}
+	cfg.Styles = cfg.GBaseStyles
+	// The rule's own level is below the minimum, so only escalated alerts
+	// are shown.
+	cfg.MinAlertLevel = 1
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"two.txt":   "",
+		"three.txt": "warning warning warning",
+		"five.txt":  "error error error error error",
+	}
+	for name, want := range expected {
+		linted, err := linter.Lint([]string{filepath.Join(dir, name)}, "*")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		levels := []string{}
+		for _, a := range linted[0].Alerts {
+			levels = append(levels, a.Severity)
+		}
+		if got := strings.Join(levels, " "); got != want {
+			t.Errorf("%s: expected '%s', got '%s'", name, want, got)
+		} else if n := linted[0].Escalated["A.Very"]; n != len(levels) {
+			t.Errorf("%s: expected %d escalated, got %d", name, len(levels), n)
+		}
+	}
+
+	bad := filepath.Join(dir, "styles", "A", "Bad.yml")
+	if err = ioutil.WriteFile(bad, []byte(strings.Replace(rule, "5: error", "5: fatal", 1)), 0644); err != nil {
+		t.Fatal(err)
+	} else if _, err = NewLinter(cfg); err == nil {
+		t.Error("expected an error for an invalid 'escalate' level")
+	}
+}
+
+func TestLimit(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"styles/A/Very.yml": "extends: existence\nmessage: '%s'\nlimit: 2\ntokens:\n  - very\n",
+		"one.txt":           "very\n",
+		"five.txt":          "very very very\nvery very\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".txt", SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.StylesPath = filepath.Join(dir, "styles")
+	cfg.Paths = []string{cfg.StylesPath}
+	cfg.GBaseStyles = []string{"A"}
+	cfg.Styles = cfg.GBaseStyles
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The limit applies to each file separately.
+	expected := map[string][]int{"one.txt": {1, 0}, "five.txt": {2, 3}}
+	for name, counts := range expected {
+		linted, err := linter.Lint([]string{filepath.Join(dir, name)}, "*")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		f := linted[0]
+		if len(f.Alerts) != counts[0] {
+			t.Errorf("%s: expected %d alerts, got %d", name, counts[0], len(f.Alerts))
+		} else if f.Limited["A.Very"] != counts[1] {
+			t.Errorf("%s: expected %d limited, got %d", name, counts[1], f.Limited["A.Very"])
+		}
+	}
+
+	// The first alerts are the ones that are kept.
+	linted, err := linter.Lint([]string{filepath.Join(dir, "five.txt")}, "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, a := range linted[0].Alerts {
+		if a.Line != 1 || a.Span[0] != 1+5*i {
+			t.Errorf("expected alert %d at 1:%d, got %d:%d", i, 1+5*i, a.Line, a.Span[0])
+		}
+	}
+}
+
+func TestMergeDuplicates(t *testing.T) {
+	dir := t.TempDir()
+
+	rule := "extends: substitution\nmessage: \"Use '%%s' instead of '%%s'.\"\nlevel: error\nswap:\n  utilize: %s\n"
+	files := map[string]string{
+		"styles/A/Use.yml": fmt.Sprintf(rule, "use"),
+		"styles/B/Use.yml": fmt.Sprintf(rule, "use"),
+		"styles/C/Use.yml": fmt.Sprintf(rule, "employ"),
+		"test.txt":         "We utilize it.\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	for _, merge := range []bool{false, true} {
+		cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".txt", SingleConfig: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.StylesPath = filepath.Join(dir, "styles")
+		cfg.Paths = []string{cfg.StylesPath}
+		cfg.GBaseStyles = []string{"A", "B", "C"}
+		cfg.Styles = cfg.GBaseStyles
+		cfg.MergeDups = merge
+
+		linter, err := NewLinter(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		linted, err := linter.Lint([]string{filepath.Join(dir, "test.txt")}, "*")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		observed := []string{}
+		for _, a := range linted[0].SortedAlerts() {
+			observed = append(observed, fmt.Sprintf("%s%v", a.Check, a.MergedFrom))
+		}
+		sort.Strings(observed)
+
+		// `C.Use` suggests something else, so it's never merged.
+		expected := []string{"A.Use[]", "B.Use[]", "C.Use[]"}
+		if merge {
+			expected = []string{"A.Use[A.Use B.Use]", "C.Use[]"}
+		}
+		if fmt.Sprint(observed) != fmt.Sprint(expected) {
+			t.Errorf("merge = %v: expected %v, got %v", merge, expected, observed)
+		}
+	}
+}
+
+func TestInlineRules(t *testing.T) {
+	dir := t.TempDir()
+
+	ini := strings.Join([]string{
+		"[inline.TODO]",
+		"extends = existence",
+		`message = "Remove '%s' before publishing."`,
+		"ignorecase = true",
+		"tokens = TODO, FIXME",
+		"[*.txt]",
+		"Ini.TODO = NO",
+	}, "\n")
+	files := map[string]string{
+		".vale.ini": ini,
+		"test.md":   "A TODO here and a fixme there.\n",
+		"test.txt":  "A TODO here and a fixme there.\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	cfg, err := core.NewConfig(&core.CLIFlags{Path: filepath.Join(dir, ".vale.ini")})
+	if err != nil {
+		t.Fatal(err)
+	} else if err = core.From("ini", cfg); err != nil {
+		t.Fatal(err)
+	} else if problems := cfg.Validate(); len(problems) > 0 {
+		t.Fatalf("expected no problems, got %v", problems)
+	}
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expected := range map[string][]string{
+		"test.md":  {"Remove 'TODO' before publishing.", "Remove 'fixme' before publishing."},
+		"test.txt": {},
+	} {
+		linted, err := linter.Lint([]string{filepath.Join(dir, name)}, "*")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		observed := []string{}
+		for _, a := range linted[0].SortedAlerts() {
+			if a.Check == "Ini.TODO" {
+				observed = append(observed, a.Message)
+			}
+		}
+		if fmt.Sprint(observed) != fmt.Sprint(expected) {
+			t.Errorf("%s: expected %v, got %v", name, expected, observed)
+		}
+	}
+}
+
+func TestLinkScopes(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"styles/A/Here.yml":  "extends: existence\nmessage: '%s'\nscope: text.link\ntokens:\n  - here\n",
+		"styles/A/HTTPS.yml": "extends: existence\nmessage: '%s'\nscope: text.url\nraw:\n  - '^http://'\n",
+		"styles/A/Word.yml":  "extends: existence\nmessage: '%s'\ntokens:\n  - here\n  - example\n",
+		"test.md":            "Click [here](http://example.com) or [there](https://example.org).\n",
+		"test.rst":           "Click `here <http://example.com>`_ or `there <https://example.org>`_.\n",
+		"test.adoc":          "Click http://example.com[here] or https://example.org[there].\n",
+		"test.html":          "<p>Click <a href=\"http://example.com\">here</a> or <a href=\"https://example.org\">there</a>.</p>\n",
+		"test.org":           "Click [[http://example.com][here]] or [[https://example.org][there]].\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".txt", SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.StylesPath = filepath.Join(dir, "styles")
+	cfg.Paths = []string{cfg.StylesPath}
+	cfg.GBaseStyles = []string{"A"}
+	cfg.Styles = cfg.GBaseStyles
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The link's text is linted once by `text` rules and once by those that
+	// name it; its URL, only by those that name it.
+	for _, name := range []string{"test.md", "test.rst", "test.adoc", "test.html", "test.org"} {
+		linted, err := linter.Lint([]string{filepath.Join(dir, name)}, "*")
+		if err != nil {
+			t.Fatal(err)
+		}
+		content := files[name]
+
+		observed := []string{}
+		for _, a := range linted[0].SortedAlerts() {
+			observed = append(observed, fmt.Sprintf("%s:%d", a.Check, a.Span[0]))
+		}
+
+		text := strings.Index(content, "here") + 1
+		url := strings.Index(content, "http://") + 1
+		expected := []string{
+			fmt.Sprintf("A.HTTPS:%d", url),
+			fmt.Sprintf("A.Here:%d", text),
+			fmt.Sprintf("A.Word:%d", text),
+		}
+		sort.Strings(observed)
+		if fmt.Sprint(observed) != fmt.Sprint(expected) {
+			t.Errorf("%s: expected %v, got %v", name, expected, observed)
+		}
+	}
+}
+
+func TestLintedAttributes(t *testing.T) {
+	dir := t.TempDir()
+
+	lines := []string{
+		`<p><img src="x.png" alt="teh image"> and <a href="x" title="teh link">teh link</a>.</p>`,
+		`<p><input placeholder="Type teh name"> <span aria-label="Close teh box">X</span></p>`,
+		`<p><abbr title="teh thing" data-note="teh note">TT</abbr></p>`,
+	}
+
+	files := map[string]string{
+		"styles/A/Word.yml": "extends: existence\nmessage: '%s'\ntokens:\n  - teh\n",
+		"styles/A/Attr.yml": "extends: existence\nmessage: '%s'\nscope: attr\ntokens:\n  - teh\n",
+		"test.html":         strings.Join(lines, "\n") + "\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	// The title of the link repeats its text, so it isn't linted twice.
+	at := func(check string, line int, s string) string {
+		return fmt.Sprintf("%s:%d:%d", check, line, strings.Index(lines[line-1], s)+1)
+	}
+	text := at("A.Word", 1, "teh link</a>")
+	for _, tt := range []struct {
+		attrs    []string
+		expected []string
+	}{
+		{nil, []string{
+			at("A.Attr", 1, "teh image"), at("A.Word", 1, "teh image"), text,
+			at("A.Attr", 2, "teh name"), at("A.Word", 2, "teh name"),
+			at("A.Attr", 2, "teh box"), at("A.Word", 2, "teh box"),
+			at("A.Attr", 3, "teh thing"), at("A.Word", 3, "teh thing"),
+		}},
+		{[]string{"alt"}, []string{
+			at("A.Attr", 1, "teh image"), at("A.Word", 1, "teh image"), text,
+		}},
+	} {
+		cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".txt", SingleConfig: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.StylesPath = filepath.Join(dir, "styles")
+		cfg.Paths = []string{cfg.StylesPath}
+		cfg.GBaseStyles = []string{"A"}
+		cfg.Styles = cfg.GBaseStyles
+		if tt.attrs != nil {
+			cfg.LintedAttrs = tt.attrs
+		}
+
+		linter, err := NewLinter(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		linted, err := linter.Lint([]string{filepath.Join(dir, "test.html")}, "*")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		observed := []string{}
+		for _, a := range linted[0].SortedAlerts() {
+			observed = append(observed, fmt.Sprintf("%s:%d:%d", a.Check, a.Line, a.Span[0]))
+		}
+		if fmt.Sprint(observed) != fmt.Sprint(tt.expected) {
+			t.Errorf("%v: expected %v, got %v", tt.attrs, tt.expected, observed)
+		}
+	}
+}
+
+func TestMarkdownTables(t *testing.T) {
+	dir := t.TempDir()
+
+	lines := []string{
+		"A TODO before the table.",
+		"",
+		"| TODO header | Other |",
+		"|:-----------|------:|",
+		"| one        | TODO  |",
+		"| a \\| TODO  | b     |",
+		"| x<br>TODO y | z    |",
+	}
+
+	files := map[string]string{
+		"styles/A/Header.yml": "extends: existence\nmessage: '%s'\nscope: text.table.header\ntokens:\n  - TODO\n",
+		"styles/A/Cell.yml":   "extends: existence\nmessage: '%s'\nscope: text.table.cell\ntokens:\n  - TODO\n",
+		"styles/A/Whole.yml":  "extends: existence\nmessage: '%s'\nscope: table.cell\nraw:\n  - 'x TODO y'\n",
+		"styles/A/Pipe.yml":   "extends: existence\nmessage: '%s'\nscope: table\nnonword: true\ntokens:\n  - '\\|'\n",
+		"test.md":             strings.Join(lines, "\n") + "\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	at := func(check string, line int, s string) string {
+		return fmt.Sprintf("%s:%d:%d", check, line, strings.Index(lines[line-1], s)+1)
+	}
+	for _, tt := range []struct {
+		skipped  []string
+		expected []string
+	}{
+		// The escaped `|` is the only one that's part of a cell, and a cell
+		// with inline markup is located at its text rather than at the
+		// start of its row.
+		{nil, []string{
+			at("A.Header", 3, "TODO"),
+			at("A.Cell", 5, "TODO"),
+			at("A.Pipe", 6, "| TODO"), at("A.Cell", 6, "TODO"),
+			at("A.Whole", 7, "x<br>"), at("A.Cell", 7, "TODO"),
+		}},
+		{[]string{"table"}, []string{}},
+	} {
+		cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".txt", SingleConfig: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.StylesPath = filepath.Join(dir, "styles")
+		cfg.Paths = []string{cfg.StylesPath}
+		cfg.GBaseStyles = []string{"A"}
+		cfg.Styles = cfg.GBaseStyles
+		cfg.SkippedScopes = tt.skipped
+
+		linter, err := NewLinter(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		linted, err := linter.Lint([]string{filepath.Join(dir, "test.md")}, "*")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		observed := []string{}
+		for _, a := range linted[0].SortedAlerts() {
+			observed = append(observed, fmt.Sprintf("%s:%d:%d", a.Check, a.Line, a.Span[0]))
+		}
+		if fmt.Sprint(observed) != fmt.Sprint(tt.expected) {
+			t.Errorf("%v: expected %v, got %v", tt.skipped, tt.expected, observed)
+		}
+	}
+}
+
+func TestYAML(t *testing.T) {
+	dir := t.TempDir()
+
+	lines := []string{
+		"# A teh comment.",
+		"info: &teh",
+		"  title: teh title",
+		"  description: |",
+		"    The first teh paragraph.",
+		"",
+		"    The second teh paragraph.",
+		"  version: 1.0 # teh version",
+		"paths:",
+		"  /teh:",
+		"    get:",
+		"      summary: \"Get teh thing\"",
+		"      tags:",
+		"        - teh tag",
+		"      x-teh: teh value",
+		"  description:",
+		"    A plain teh",
+		"    scalar.",
+	}
+
+	files := map[string]string{
+		"styles/A/Word.yml":    "extends: existence\nmessage: '%s'\ntokens:\n  - teh\n",
+		"styles/A/Comment.yml": "extends: existence\nmessage: '%s'\nscope: comment\ntokens:\n  - teh\n",
+		"test.yml":             strings.Join(lines, "\n") + "\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	at := func(check string, line int, s string) string {
+		return fmt.Sprintf("%s:%d:%d", check, line, strings.Index(lines[line-1], s)+1)
+	}
+	comments := []string{
+		at("A.Comment", 1, "teh"), at("A.Word", 1, "teh"),
+		at("A.Comment", 8, "teh"), at("A.Word", 8, "teh"),
+	}
+	for _, tt := range []struct {
+		keys     []string
+		expected []string
+	}{
+		{nil, comments},
+		{[]string{"description", "$.info.title"}, []string{
+			comments[0], comments[1],
+			at("A.Word", 3, "teh"),
+			at("A.Word", 5, "teh"),
+			at("A.Word", 7, "teh"),
+			comments[2], comments[3],
+			at("A.Word", 17, "teh"),
+		}},
+		{[]string{"paths.*.get.summary", "$..tags"}, []string{
+			comments[0], comments[1], comments[2], comments[3],
+			at("A.Word", 12, "teh"),
+			at("A.Word", 14, "teh"),
+		}},
+	} {
+		cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".txt", SingleConfig: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.StylesPath = filepath.Join(dir, "styles")
+		cfg.Paths = []string{cfg.StylesPath}
+		cfg.GBaseStyles = []string{"A"}
+		cfg.Styles = cfg.GBaseStyles
+		cfg.LintKeys = tt.keys
+
+		linter, err := NewLinter(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		linted, err := linter.Lint([]string{filepath.Join(dir, "test.yml")}, "*")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		observed := []string{}
+		for _, a := range linted[0].SortedAlerts() {
+			observed = append(observed, fmt.Sprintf("%s:%d:%d", a.Check, a.Line, a.Span[0]))
+		}
+		if fmt.Sprint(observed) != fmt.Sprint(tt.expected) {
+			t.Errorf("%v: expected %v, got %v", tt.keys, tt.expected, observed)
+		}
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	dir := t.TempDir()
+
+	lines := []string{
+		"openapi: 3.0.0",
+		"info:",
+		"  title: teh title",
+		"  description: teh API.",
+		"paths:",
+		"  /pets:",
+		"    get:",
+		"      summary: teh pets",
+		"      parameters:",
+		"        - name: limit",
+		"          description: teh limit.",
+		"components:",
+		"  parameters:",
+		"    offset:",
+		"      description: teh offset.",
+	}
+
+	files := map[string]string{
+		"styles/A/Summary.yml":     "extends: existence\nmessage: '%s'\nscope: text.openapi.summary\ntokens:\n  - teh\n",
+		"styles/A/Description.yml": "extends: existence\nmessage: '%s'\nscope: text.openapi.description\ntokens:\n  - teh\n",
+		"styles/A/Parameter.yml":   "extends: existence\nmessage: '%s'\nscope: text.openapi.parameter\ntokens:\n  - teh\n",
+		"openapi.yml":              strings.Join(lines, "\n") + "\n",
+		// Without an `openapi` (or `swagger`) key, these are just keys.
+		"other.yml": strings.Join(lines[1:], "\n") + "\n",
+	}
+	testutil.WriteFiles(t, dir, files)
+
+	cfg, err := core.NewConfig(&core.CLIFlags{InExt: ".txt", SingleConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.StylesPath = filepath.Join(dir, "styles")
+	cfg.Paths = []string{cfg.StylesPath}
+	cfg.GBaseStyles = []string{"A"}
+	cfg.Styles = cfg.GBaseStyles
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	linted, err := linter.Lint([]string{dir}, "*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	observed := []string{}
+	for _, f := range linted {
+		for _, a := range f.SortedAlerts() {
+			observed = append(observed, fmt.Sprintf("%s:%s:%d", filepath.Base(f.Path), a.Check, a.Line))
+		}
+	}
+
+	expected := []string{
+		"openapi.yml:A.Description:4",
+		"openapi.yml:A.Summary:8",
+		"openapi.yml:A.Description:11",
+		"openapi.yml:A.Parameter:11",
+		"openapi.yml:A.Description:15",
+		"openapi.yml:A.Parameter:15",
+	}
+	if fmt.Sprint(observed) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, observed)
+	}
+}
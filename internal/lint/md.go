@@ -25,12 +25,65 @@ var goldMd = goldmark.New(
 // might confuse Blackfriday into normal "```".
 var reExInfo = regexp.MustCompile("`{3,}" + `.+`)
 
+// reTableDelim matches the delimiter row of a GFM table -- e.g.,
+// `|:---|---:|`, possibly nested in a list or blockquote.
+var reTableDelim = regexp.MustCompile(`^[\s>]*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+
+// maskTables hides the markup of s's tables -- their delimiter rows and the
+// unescaped `|` that separate their cells -- so that it isn't mistaken for
+// the text of a cell (`text.table.header` or `text.table.cell`).
+func maskTables(s string) string {
+	lines := strings.Split(s, "\n")
+	for i := 1; i < len(lines); i++ {
+		if !strings.Contains(lines[i-1], "|") || !reTableDelim.MatchString(lines[i]) {
+			continue
+		}
+
+		lines[i-1] = maskPipes(lines[i-1])
+		lines[i] = strings.Map(func(r rune) rune {
+			if r == '|' || r == '-' || r == ':' {
+				return '*'
+			}
+			return r
+		}, lines[i])
+
+		for i+1 < len(lines) && strings.Contains(lines[i+1], "|") {
+			i++
+			lines[i] = maskPipes(lines[i])
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// maskPipes hides the unescaped `|` of a table row.
+func maskPipes(row string) string {
+	b := []byte(row)
+	for i := range b {
+		if b[i] == '|' && (i == 0 || b[i-1] != '\\') {
+			b[i] = '*'
+		}
+	}
+	return string(b)
+}
+
 func (l Linter) lintMarkdown(f *core.File) error {
+	return l.lintGoldmark(f, false)
+}
+
+// lintGoldmark lints f as Markdown or, if `jsx` is set, as MDX (see
+// `stripJSX`).
+func (l Linter) lintGoldmark(f *core.File, jsx bool) error {
 	var buf bytes.Buffer
 
-	s, err := l.prep(f.Content, "\n```\n$1\n```\n", "`$1`", ".md")
+	s, err := l.prep(f, "\n```\n$1\n```\n", "`$1`", ".md")
 	if err != nil {
 		return err
+	} else if jsx {
+		// We convert the source without its JSX, but locate our alerts in
+		// one in which it's masked -- so that the text between component
+		// tags stays in the same line *and* column.
+		s = stripJSX(s)
+		f.Content = maskJSX(f.Content)
 	}
 
 	if err := goldMd.Convert([]byte(s), &buf); err != nil {
@@ -55,6 +108,6 @@ func (l Linter) lintMarkdown(f *core.File) error {
 		return tags + span
 	})
 
-	f.Content = body
+	f.Content = maskTables(body)
 	return l.lintHTMLTokens(f, buf.Bytes(), 0)
 }
@@ -0,0 +1,113 @@
+package lint
+
+import (
+	"strings"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/jdkato/regexp"
+)
+
+// reESM matches the first line of an MDX `import` or `export` statement, which
+// continues until the next blank line.
+var reESM = regexp.MustCompile(`^(?:import|export)\b`)
+
+// reJSX matches (in order of precedence) inline code, which we leave alone;
+// self-closing components (e.g., `<Image src="..." />`); opening and closing
+// component tags (e.g., `<Note title="...">` and `</Note>`); and `{...}`
+// expressions.
+var reJSX = regexp.MustCompile(
+	"(`+[^`]*`+)" +
+		`|(<[A-Z][\w.]*(?:\s[^<>]*)?/>)` +
+		`|(</?[A-Z][\w.]*(?:\s[^<>]*)?>)` +
+		`|(\{[^{}]*\})`)
+
+// lintMDX lints an MDX file as Markdown, after removing its JSX syntax.
+func (l Linter) lintMDX(f *core.File) error {
+	return l.lintGoldmark(f, true)
+}
+
+// stripJSX removes the `import`/`export` statements, component tags, and
+// `{expression}`s from the MDX source `src`, while leaving the text between
+// component tags (and everything in code blocks) in place.
+//
+// Removed content is replaced by its newlines, so that the remaining text
+// stays on the same lines.
+func stripJSX(src string) string {
+	return replaceJSX(src, newlines)
+}
+
+// maskJSX is like `stripJSX`, except that it replaces the JSX syntax with
+// asterisks (see `maskLines`), so that the remaining text also stays in the
+// same columns.
+func maskJSX(src string) string {
+	return replaceJSX(src, maskLines)
+}
+
+// replaceJSX replaces the JSX syntax of `src` with `repl` of it.
+func replaceJSX(src string, repl func(string) string) string {
+	var out, prose strings.Builder
+
+	// Tags and expressions may span lines (e.g., a component with one
+	// attribute per line), so we strip them from each run of lines between
+	// fenced code blocks as a whole.
+	flush := func() {
+		out.WriteString(replaceTags(prose.String(), repl))
+		prose.Reset()
+	}
+
+	inFence, inESM := false, false
+	for _, line := range strings.SplitAfter(src, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			if !inFence {
+				flush()
+			}
+			inFence = !inFence
+			out.WriteString(line)
+			continue
+		} else if inFence {
+			out.WriteString(line)
+			continue
+		} else if inESM || reESM.MatchString(line) {
+			// An ESM block ends at the next blank line.
+			inESM = trimmed != ""
+			line = repl(line)
+		}
+		prose.WriteString(line)
+	}
+	flush()
+
+	return out.String()
+}
+
+// replaceTags replaces any component tags and expressions in `text` with
+// `repl` of them.
+func replaceTags(text string, repl func(string) string) string {
+	for prev := ""; prev != text; {
+		// Expressions may be nested, so we repeat until nothing changes.
+		prev = text
+		text = reJSX.ReplaceAllStringFunc(text, func(m string) string {
+			if strings.HasPrefix(m, "`") {
+				return m
+			}
+			return repl(m)
+		})
+	}
+	return text
+}
+
+// newlines returns only the newlines in `s`.
+func newlines(s string) string {
+	return strings.Repeat("\n", strings.Count(s, "\n"))
+}
+
+// maskLines replaces each character of `s`, other than its newlines, with an
+// asterisk.
+func maskLines(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' {
+			return r
+		}
+		return '*'
+	}, s)
+}
@@ -0,0 +1,145 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+// memoryThreshold is the fraction of the soft memory limit at which we start
+// to conserve memory.
+const memoryThreshold = 0.8
+
+// memorySampleInterval is how often we read the runtime's memory statistics,
+// which briefly stops the world.
+const memorySampleInterval = 100 * time.Millisecond
+
+// memoryUnits are the suffixes accepted by `GOMEMLIMIT`.
+var memoryUnits = []struct {
+	suffix string
+	size   uint64
+}{
+	{"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}, {"B", 1},
+}
+
+// parseMemLimit parses a soft memory limit written as it would be for
+// `GOMEMLIMIT` -- e.g., `512MiB` or `1073741824` -- returning 0 for `off`.
+func parseMemLimit(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	if s == "off" {
+		return 0, nil
+	}
+
+	size := uint64(1)
+	for _, unit := range memoryUnits {
+		if strings.HasSuffix(s, unit.suffix) {
+			s, size = strings.TrimSuffix(s, unit.suffix), unit.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory limit '%s'", s)
+	}
+	return n * size, nil
+}
+
+// formatBytes returns `n` in MiB, for warnings.
+func formatBytes(n uint64) string {
+	return fmt.Sprintf("%.1fMiB", float64(n)/(1<<20))
+}
+
+// A memoryWatchdog tracks our memory use against a soft limit (see
+// `newMemoryWatchdog`), which `lintFiles` consults before starting each file.
+//
+// Near the limit, files are linted one at a time (rather than in parallel)
+// and our in-memory caches are disabled (see `core.DisableCaches`), which is
+// slower but less likely to be killed by the OOM killer. A warning is printed
+// the first time this happens; the exit status is unaffected.
+type memoryWatchdog struct {
+	sync.Mutex
+
+	limit uint64
+	inUse func() uint64
+	warn  func(used, limit uint64)
+
+	sampled time.Time
+	tight   bool
+	tripped bool
+}
+
+// newMemoryWatchdog returns a watchdog for the limit given by `GOMEMLIMIT`,
+// or nil if there isn't one.
+//
+// NOTE: The runtime itself doesn't enforce `GOMEMLIMIT` until Go 1.19, but
+// container runtimes and CI services commonly set it to (a fraction of) the
+// container's memory limit.
+func newMemoryWatchdog(cfg *core.Config) *memoryWatchdog {
+	value := os.Getenv("GOMEMLIMIT")
+	if value == "" {
+		return nil
+	}
+
+	limit, err := parseMemLimit(value)
+	if err != nil {
+		cfg.Warnf("GOMEMLIMIT: %s; ignoring it.", err)
+		return nil
+	} else if limit == 0 {
+		return nil
+	}
+
+	return &memoryWatchdog{
+		limit: limit,
+		inUse: memoryInUse,
+		warn: func(used, limit uint64) {
+			cfg.Warnf(
+				"memory use (%s) is near GOMEMLIMIT (%s): linting one file at a time, which is slower. The exit status is unaffected.",
+				formatBytes(used), formatBytes(limit))
+		},
+	}
+}
+
+// memoryInUse returns the memory that the runtime has obtained from the OS
+// and not yet returned to it.
+func memoryInUse() uint64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.Sys - stats.HeapReleased
+}
+
+// nearLimit determines if our memory use is close to the limit. Once it
+// first is, the caches are disabled for the rest of the run.
+func (w *memoryWatchdog) nearLimit() bool {
+	if w == nil {
+		return false
+	}
+
+	w.Lock()
+	defer w.Unlock()
+
+	if !w.sampled.IsZero() && time.Since(w.sampled) < memorySampleInterval {
+		return w.tight
+	}
+	w.sampled = time.Now()
+
+	used := w.inUse()
+	w.tight = float64(used) >= memoryThreshold*float64(w.limit)
+	if w.tight && !w.tripped {
+		w.tripped = true
+
+		core.DisableCaches()
+		debug.FreeOSMemory()
+
+		w.warn(used, w.limit)
+	}
+
+	return w.tight
+}
@@ -0,0 +1,130 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+// nearest tracks the linters created for the configuration files found
+// alongside the files being linted (see `Linter.linterFor`).
+type nearest struct {
+	sync.Mutex
+
+	dirs    map[string]*Linter // directory -> linter
+	linters map[string]*Linter // config chain -> linter
+}
+
+func newNearest() *nearest {
+	return &nearest{
+		dirs:    make(map[string]*Linter),
+		linters: make(map[string]*Linter),
+	}
+}
+
+// linterFor returns the linter that should be used for `fp`: the one built
+// from the configuration files that apply to it (see `core.ConfigChain`) or,
+// if that's only our own configuration (or there isn't one), l itself.
+//
+// This only applies if our configuration was discovered rather than given
+// (e.g., by `--config`) and `--single-config` isn't set. Since the files'
+// configurations take precedence over ours, it's also why we may start
+// without one (see `core.Config.HasConfig`): e.g., an editor that runs us
+// outside of the project that holds `fp`.
+//
+// Linters are cached per directory and per chain of configuration files, so
+// each chain is only loaded once.
+func (l *Linter) linterFor(fp string) (*Linter, error) {
+	cfg := l.Manager.Config
+	if l.nearest == nil || cfg.Explicit || cfg.Flags.SingleConfig {
+		if !cfg.HasConfig() {
+			return l, noConfigFor(fp)
+		}
+		return l, nil
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(fp))
+	if err != nil {
+		return l, core.NewE100("linterFor", err)
+	}
+
+	l.nearest.Lock()
+	defer l.nearest.Unlock()
+
+	linter, found := l.nearest.dirs[dir]
+	if !found {
+		linter = l
+
+		chain, err := core.ConfigChain(dir)
+		if err != nil {
+			return l, err
+		} else if len(chain) == 0 && !cfg.HasConfig() {
+			return l, noConfigFor(fp)
+		}
+
+		if len(chain) > 1 || (len(chain) == 1 && !samePath(chain[0], cfg.Flags.Path)) {
+			key := strings.Join(chain, string(filepath.ListSeparator))
+			if linter, found = l.nearest.linters[key]; !found {
+				if linter, err = l.linterFrom(chain); err != nil {
+					return l, err
+				}
+				l.nearest.linters[key] = linter
+			}
+		}
+
+		l.nearest.dirs[dir] = linter
+	}
+
+	if cfg.Flags.Debug {
+		fmt.Fprintf(os.Stderr, "%s: using '%s'\n", fp, linter.Manager.Config.Flags.Path)
+	}
+
+	// NOTE: `--glob` always applies, regardless of the configuration.
+	linter.glob = l.glob
+	return linter, nil
+}
+
+// linterFrom creates a new Linter using the configuration files in `chain`,
+// along with our CLI flags.
+func (l *Linter) linterFrom(chain []string) (*Linter, error) {
+	flags := *l.Manager.Config.Flags
+	flags.Sources = ""
+	flags.Local = false
+	flags.Remote = false
+
+	cfg, err := core.NewConfig(&flags)
+	if err != nil {
+		return nil, err
+	} else if err = core.LoadChain(cfg, chain); err != nil {
+		return nil, err
+	}
+
+	linter, err := NewLinter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// A rule that panics is disabled for the whole run.
+	linter.panics = l.panics
+
+	return linter, nil
+}
+
+// noConfigFor is the error for a file, `fp`, that has no configuration file
+// (see `linterFor`).
+func noConfigFor(fp string) error {
+	return core.NewE100(".vale.ini", fmt.Errorf(
+		"no configuration file found for '%s' in its directory, the current directory, or their parents", fp))
+}
+
+func samePath(a, b string) bool {
+	if b == "" {
+		return false
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
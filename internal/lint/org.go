@@ -0,0 +1,329 @@
+package lint
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/jdkato/regexp"
+)
+
+var (
+	// reOrgHeading matches a headline -- e.g., `** TODO [#A] Title :tag:` --
+	// capturing its stars, its title, and its tags.
+	reOrgHeading = regexp.MustCompile(`^(\*+)\s+(?:(?:TODO|DONE)\s+)?(?:\[#[A-Z]\]\s+)?(.*?)(?:\s+(:[\w@#%:]+:))?\s*$`)
+
+	// reOrgItem matches a list item, capturing its text.
+	reOrgItem = regexp.MustCompile(`^\s*(?:[-+]|\s\*|\d+[.)])\s+(?:\[[ X-]\]\s+)?(.*)$`)
+
+	// reOrgBlock matches the start or end of a block (e.g., `#+BEGIN_SRC go`),
+	// capturing which it is and the block's type.
+	reOrgBlock = regexp.MustCompile(`(?i)^\s*#\+(BEGIN|END)_(\w+)`)
+
+	// reOrgKeyword matches a keyword line (e.g., `#+TITLE: ...`).
+	reOrgKeyword = regexp.MustCompile(`^\s*#\+\w*(?:\[.*\])?:`)
+
+	// reOrgComment matches a comment line, capturing its text.
+	reOrgComment = regexp.MustCompile(`^\s*#(?:\s+(.*))?$`)
+
+	// reOrgFixed matches a line of a fixed-width area (e.g., `: foo := 1`).
+	reOrgFixed = regexp.MustCompile(`^\s*:(?:\s|$)`)
+
+	// reOrgDrawer matches the start of a drawer (e.g., `:PROPERTIES:`).
+	reOrgDrawer = regexp.MustCompile(`^\s*:[\w-]+:\s*$`)
+
+	// reOrgCode matches inline `=verbatim=` and `~code~`, which must be
+	// preceded by the start of the line, whitespace or an opening character.
+	reOrgCode = regexp.MustCompile(`(^|[\s\-({'"])(?:=([^\s=](?:[^=]*?[^\s=])?)=|~([^\s~](?:[^~]*?[^\s~])?)~)`)
+
+	// reOrgLink matches a link (`[[target]]` or `[[target][description]]`).
+	reOrgLink = regexp.MustCompile(`\[\[([^\]]+)\](?:\[([^\]]+)\])?\]`)
+
+	// reOrgMarkup matches `*bold*`, `/italic/`, `_underline_` and
+	// `+strike-through+` text.
+	reOrgMarkup = regexp.MustCompile(`(^|[\s\-({'"])([*/_+])([^\s*/_+](?:[^*/_+]*?[^\s*/_+])?)([*/_+])`)
+)
+
+// orgTags are the HTML tags used for Org's emphasis markers.
+var orgTags = map[string]string{"*": "strong", "/": "em", "_": "u", "+": "del"}
+
+// lintOrg lints an Org-mode file, which we convert to HTML ourselves.
+//
+// Headlines, paragraphs, lists, tables, and quotes are linted as they would be
+// in any other markup format, while blocks (e.g., `#+BEGIN_SRC`), keyword
+// lines (e.g., `#+TITLE:`), drawers, fixed-width lines (`: ...`), commented
+// subtrees (`* COMMENT ...`), and inline `=verbatim=` and `~code~` are
+// skipped. Comments (`# ...`) are linted as `text.comment.line.org`.
+func (l *Linter) lintOrg(f *core.File) error {
+	s, err := l.prep(f, "\n#+BEGIN_EXAMPLE\n$1\n#+END_EXAMPLE\n", "=$1=", ".org")
+	if err != nil {
+		return err
+	}
+
+	body, comments, skipped := orgToHTML(strings.SplitAfter(s, "\n"))
+	l.lintLineComments(f, comments)
+
+	// Everything that isn't linted is masked, so that we don't find matches
+	// in it -- including code, which is also masked in our HTML (see
+	// `orgInline`) and is always written as `=...=` (see `codify`).
+	lines := strings.SplitAfter(f.Content, "\n")
+	for _, i := range skipped {
+		if i < len(lines) {
+			text := strings.TrimRight(lines[i], "\r\n")
+			lines[i] = maskText(text) + lines[i][len(text):]
+		}
+	}
+	for i, line := range lines {
+		if m := reOrgHeading.FindStringSubmatchIndex(line); m != nil && m[6] >= 0 {
+			lines[i] = line[:m[6]] + maskText(line[m[6]:m[7]]) + line[m[7]:]
+		}
+	}
+	f.Content = reOrgCode.ReplaceAllStringFunc(strings.Join(lines, ""), func(m string) string {
+		prefix, code := orgCode(m, reOrgCode.FindStringSubmatchIndex(m))
+		return prefix + "=" + maskText(code) + "="
+	})
+
+	return l.lintHTMLTokens(f, []byte(body), 0)
+}
+
+// A lineComment is the text of a comment line in an Org or AsciiDoc file.
+type lineComment struct {
+	text string
+	line int // 0-based
+	col  int // 1-based
+}
+
+// lintLineComments lints `comments` as `text.comment.line`.
+func (l *Linter) lintLineComments(f *core.File, comments []lineComment) {
+	for _, c := range comments {
+		// As with PO files, each comment is linted within an otherwise-empty
+		// context (see `lintPO`).
+		ctx := strings.Repeat("\n", c.line) + strings.Repeat(" ", c.col-1) + c.text
+		b := core.NewLinedBlock(ctx, c.text, "text.comment.line"+f.RealExt, c.line)
+		l.lintBlock(f, b, c.line+1, 0, false)
+	}
+}
+
+// orgToHTML converts the Org-mode source `lines` into HTML, also returning
+// its comments and the (0-based) lines that aren't prose -- e.g., blocks and
+// keywords.
+func orgToHTML(lines []string) (string, []lineComment, []int) {
+	var buf strings.Builder
+	var comments []lineComment
+	var skipped []int
+
+	block, drawer, commented := "", false, 0
+	para, list, table := []string{}, false, false
+
+	flush := func() {
+		if len(para) > 0 {
+			fmt.Fprintf(&buf, "<p>%s</p>\n", orgInline(strings.Join(para, "\n")))
+			para = []string{}
+		}
+		if list {
+			buf.WriteString("</li>\n</ul>\n")
+			list = false
+		}
+		if table {
+			buf.WriteString("</table>\n")
+			table = false
+		}
+	}
+
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r\n")
+		trimmed := strings.TrimSpace(line)
+
+		if commented > 0 {
+			// A commented subtree ends at the next headline of the same or
+			// a higher level.
+			if m := reOrgHeading.FindStringSubmatch(line); m == nil || len(m[1]) > commented {
+				skipped = append(skipped, i)
+				continue
+			}
+			commented = 0
+		}
+
+		if block != "" || drawer {
+			skipped = append(skipped, i)
+		}
+
+		if block != "" {
+			// We're in a (non-quote) block, which we skip.
+			if m := reOrgBlock.FindStringSubmatch(line); m != nil &&
+				strings.EqualFold(m[1], "END") && strings.EqualFold(m[2], block) {
+				block = ""
+			}
+			continue
+		} else if drawer {
+			drawer = !strings.EqualFold(trimmed, ":END:")
+			continue
+		}
+
+		if m := reOrgBlock.FindStringSubmatch(line); m != nil {
+			flush()
+			skipped = append(skipped, i)
+
+			name := strings.ToUpper(m[2])
+			begin := strings.EqualFold(m[1], "BEGIN")
+			if name == "QUOTE" || name == "VERSE" {
+				if begin {
+					buf.WriteString("<blockquote>\n")
+				} else {
+					buf.WriteString("</blockquote>\n")
+				}
+			} else if begin {
+				block = name
+			}
+		} else if reOrgKeyword.MatchString(line) || reOrgFixed.MatchString(line) {
+			flush()
+			skipped = append(skipped, i)
+		} else if m := reOrgComment.FindStringSubmatchIndex(line); m != nil {
+			flush()
+			skipped = append(skipped, i)
+			if m[2] >= 0 {
+				comments = append(comments, lineComment{
+					text: line[m[2]:m[3]],
+					line: i,
+					col:  utf8.RuneCountInString(line[:m[2]]) + 1,
+				})
+			}
+		} else if reOrgDrawer.MatchString(line) && !strings.EqualFold(trimmed, ":END:") {
+			flush()
+			skipped = append(skipped, i)
+			drawer = true
+		} else if m := reOrgHeading.FindStringSubmatch(line); m != nil &&
+			(m[2] == "COMMENT" || strings.HasPrefix(m[2], "COMMENT ")) {
+			flush()
+			skipped = append(skipped, i)
+			commented = len(m[1])
+		} else if m := reOrgHeading.FindStringSubmatch(line); m != nil {
+			flush()
+			level := len(m[1])
+			if level > 6 {
+				level = 6
+			}
+			fmt.Fprintf(&buf, "<h%d>%s</h%d>\n", level, orgInline(m[2]), level)
+		} else if trimmed == "" {
+			flush()
+		} else if strings.HasPrefix(trimmed, "|") {
+			if len(para) > 0 || list {
+				flush()
+			}
+			if !table {
+				buf.WriteString("<table>\n")
+				table = true
+			}
+			if !strings.HasPrefix(trimmed, "|-") {
+				buf.WriteString("<tr>")
+				for _, cell := range strings.Split(strings.Trim(trimmed, "|"), "|") {
+					fmt.Fprintf(&buf, "<td>%s</td>", orgInline(strings.TrimSpace(cell)))
+				}
+				buf.WriteString("</tr>\n")
+			}
+		} else if m := reOrgItem.FindStringSubmatch(line); m != nil {
+			if len(para) > 0 || table {
+				flush()
+			}
+			if list {
+				buf.WriteString("</li>\n")
+			} else {
+				buf.WriteString("<ul>\n")
+				list = true
+			}
+			fmt.Fprintf(&buf, "<li>%s", orgInline(m[1]))
+		} else if list {
+			// This continues the current item.
+			buf.WriteString("\n" + orgInline(trimmed))
+		} else {
+			if table {
+				flush()
+			}
+			para = append(para, trimmed)
+		}
+	}
+
+	flush()
+
+	return buf.String(), comments, skipped
+}
+
+// orgInline converts the inline markup in `s` -- code, links, and emphasis --
+// into HTML. The content of code spans is masked, so that it isn't mistaken
+// for the text around it when we locate alerts.
+func orgInline(s string) string {
+	var buf strings.Builder
+
+	cursor := 0
+	for _, m := range reOrgCode.FindAllStringSubmatchIndex(s, -1) {
+		prefix, code := orgCode(s[cursor:m[1]], shift(m, -cursor))
+		buf.WriteString(orgText(prefix))
+		buf.WriteString("<code>" + maskText(code) + "</code>")
+		cursor = m[1]
+	}
+	buf.WriteString(orgText(s[cursor:]))
+
+	return buf.String()
+}
+
+// maskText replaces each character of `s` with an asterisk.
+func maskText(s string) string {
+	return strings.Repeat("*", utf8.RuneCountInString(s))
+}
+
+// orgCode splits the `reOrgCode` match `loc` in `s` into the text before the
+// code and the code itself.
+func orgCode(s string, loc []int) (string, string) {
+	if loc[4] >= 0 {
+		return s[:loc[4]-1], s[loc[4]:loc[5]]
+	}
+	return s[:loc[6]-1], s[loc[6]:loc[7]]
+}
+
+// shift adds `n` to each of the (non-negative) indices in `loc`.
+func shift(loc []int, n int) []int {
+	shifted := make([]int, len(loc))
+	for i, idx := range loc {
+		shifted[i] = idx
+		if idx >= 0 {
+			shifted[i] = idx + n
+		}
+	}
+	return shifted
+}
+
+// orgText converts the links and emphasis in `s`, which has no code.
+func orgText(s string) string {
+	var buf strings.Builder
+
+	cursor := 0
+	for _, m := range reOrgLink.FindAllStringSubmatchIndex(s, -1) {
+		buf.WriteString(orgEmphasis(s[cursor:m[0]]))
+
+		target, text := s[m[2]:m[3]], s[m[2]:m[3]]
+		if m[4] >= 0 {
+			text = s[m[4]:m[5]]
+		}
+		fmt.Fprintf(&buf, `<a href="%s">%s</a>`, html.EscapeString(target), orgEmphasis(text))
+
+		cursor = m[1]
+	}
+	buf.WriteString(orgEmphasis(s[cursor:]))
+
+	return buf.String()
+}
+
+// orgEmphasis escapes `s`, converting its emphasis markers into HTML tags.
+func orgEmphasis(s string) string {
+	s = html.EscapeString(s)
+	return reOrgMarkup.ReplaceAllStringFunc(s, func(m string) string {
+		parts := reOrgMarkup.FindStringSubmatch(m)
+		if parts[2] != parts[4] {
+			return m
+		}
+		tag := orgTags[parts[2]]
+		return fmt.Sprintf("%s<%s>%s</%s>", parts[1], tag, parts[3], tag)
+	})
+}
@@ -0,0 +1,142 @@
+package lint
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/jdkato/regexp"
+)
+
+// rePOKeyword matches the keyword (e.g., `msgstr[1]`) that starts a PO
+// entry's field, along with the first quoted segment of its value.
+var rePOKeyword = regexp.MustCompile(`^(msgctxt|msgid_plural|msgid|msgstr(?:\[\d+\])?)\s+(".*")\s*$`)
+
+// A poValue is a (translated) string from a PO file.
+type poValue struct {
+	text string // the value, with its quoted segments joined
+	line int    // the (0-based) line of its first non-empty segment
+	col  int    // the (0-based) column at which that segment's text starts
+}
+
+// lintPO lints the translations (`msgstr`, including plural forms) in a
+// gettext PO file as prose. Everything else -- `msgid`s, comments, and flags
+// -- is skipped.
+//
+// An entry's `msgctxt`, if any, qualifies its scope: for example, `msgctxt
+// "ui"` results in `text.ui.po`, which a rule can target with `scope:
+// text.ui`.
+func (l *Linter) lintPO(f *core.File) error {
+	for _, entry := range parsePO(f.Lines) {
+		if entry.value.text == "" {
+			// The entry hasn't been translated.
+			continue
+		}
+
+		ext := f.RealExt
+		if entry.context != "" {
+			ext = "." + entry.context + ext
+		}
+
+		// Each value is linted on its own, within an otherwise-empty
+		// context, so alerts can't be located in any other part of the file.
+		v := entry.value
+		ctx := strings.Repeat("\n", v.line) + strings.Repeat(" ", v.col) + v.text
+		l.lintProse(f, core.NewLinedBlock(ctx, v.text, "text"+ext, v.line), v.line+1, ext)
+	}
+
+	l.lintRaw(f)
+	return nil
+}
+
+// A poEntry is a translation to be linted.
+type poEntry struct {
+	context string // the entry's `msgctxt`, as a scope section
+	value   poValue
+}
+
+// parsePO returns the `msgstr` values in the PO source `lines`.
+//
+// A value may be split across several quoted lines (e.g., `msgstr ""`
+// followed by `"Some "` and `"text."`); these are joined, and the result is
+// reported on the first line that contains any of its text.
+func parsePO(lines []string) []poEntry {
+	var entries []poEntry
+	var keyword, context string
+
+	value := poValue{line: -1}
+
+	flush := func() {
+		if strings.HasPrefix(keyword, "msgstr") {
+			entries = append(entries, poEntry{context: context, value: value})
+		}
+		keyword, value = "", poValue{line: -1}
+	}
+
+	add := func(segment string, line, col int) {
+		text := unquotePO(segment)
+		if value.line < 0 && text != "" {
+			value.line, value.col = line, col
+		}
+		value.text += text
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, `"`) && keyword != "" {
+			// This continues the current field's value.
+			col := strings.Index(line, `"`) + 1
+			add(trimmed, i, utf8.RuneCountInString(line[:col]))
+			continue
+		}
+
+		last := keyword
+		flush()
+
+		m := rePOKeyword.FindStringSubmatch(trimmed)
+		if m == nil {
+			// A comment, flag, or blank line ends the entry.
+			context = ""
+			continue
+		} else if m[1] == "msgid" && last != "msgctxt" {
+			context = ""
+		}
+
+		keyword = m[1]
+		if keyword == "msgctxt" {
+			context = poScope(unquotePO(m[2]))
+		}
+
+		col := strings.Index(line, m[2]) + 1
+		add(m[2], i, utf8.RuneCountInString(line[:col]))
+	}
+	flush()
+
+	return entries
+}
+
+// unquotePO returns the text of the quoted segment `s`, with its escape
+// sequences replaced.
+//
+// Newlines and tabs become spaces, so that the joined value stays on a single
+// line.
+func unquotePO(s string) string {
+	text, err := strconv.Unquote(s)
+	if err != nil {
+		text = strings.TrimSuffix(strings.TrimPrefix(s, `"`), `"`)
+	}
+	return strings.NewReplacer("\n", " ", "\t", " ").Replace(text)
+}
+
+// poScope converts a `msgctxt` into a scope section: it's lowercased and any
+// other characters (e.g., `|` or `.`) are replaced by underscores.
+func poScope(ctx string) string {
+	return strings.Trim(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '_'
+	}, ctx), "_")
+}
@@ -0,0 +1,85 @@
+package lint
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+// excerptLength is the number of characters of a scope's text to include in
+// a `RuleError`.
+const excerptLength = 40
+
+// A RuleError records a rule that panicked while linting a scope. The rule
+// is disabled for the rest of the run, while everything else continues.
+type RuleError struct {
+	Rule    string // the rule's name (e.g., `MyStyle.Rule`)
+	Path    string // the file being linted
+	Line    int    // the (1-based) line of the scope, if known
+	Scope   string // the scope being linted (e.g., `text.md`)
+	Excerpt string // the start of the scope's text
+	Err     string // the panic's value
+}
+
+func (e *RuleError) Error() string {
+	loc := e.Path
+	if e.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", e.Path, e.Line)
+	}
+	return fmt.Sprintf("%s: rule '%s' panicked on '%s' scope (%q) and was disabled: %s",
+		loc, e.Rule, e.Scope, e.Excerpt, e.Err)
+}
+
+// ruleErrors holds the rules that have panicked, which are shared by all of a
+// run's linters (see `linterFor`).
+type ruleErrors struct {
+	sync.Mutex
+	disabled map[string]bool
+	errors   []RuleError
+}
+
+func newRuleErrors() *ruleErrors {
+	return &ruleErrors{disabled: make(map[string]bool)}
+}
+
+// add records the panic `r` of the rule `name` and disables it. Only its
+// first panic is recorded, since it won't run again.
+func (re *ruleErrors) add(name string, f *core.File, blk core.Block, r interface{}) {
+	re.Lock()
+	defer re.Unlock()
+
+	if re.disabled[name] {
+		return
+	}
+	re.disabled[name] = true
+
+	excerpt := []rune(core.WhitespaceToSpace(blk.Text))
+	if len(excerpt) > excerptLength {
+		excerpt = append(excerpt[:excerptLength], '…')
+	}
+
+	re.errors = append(re.errors, RuleError{
+		Rule:    name,
+		Path:    f.Path,
+		Line:    blk.Line + 1,
+		Scope:   blk.Scope.Value,
+		Excerpt: string(excerpt),
+		Err:     fmt.Sprint(r),
+	})
+}
+
+// isDisabled determines if the rule `name` has been disabled by a panic.
+func (re *ruleErrors) isDisabled(name string) bool {
+	re.Lock()
+	defer re.Unlock()
+	return re.disabled[name]
+}
+
+// RuleErrors returns the rules that panicked during the run, which were then
+// disabled.
+func (l *Linter) RuleErrors() []RuleError {
+	l.panics.Lock()
+	defer l.panics.Unlock()
+	return append([]RuleError{}, l.panics.errors...)
+}
@@ -139,9 +139,18 @@ func (l *Linter) lintSphinx(f *core.File) error {
 	return l.lintHTMLTokens(f, html, 0)
 }
 
+// lintRST lints a reStructuredText file, which is converted to HTML by our
+// own lexer (see `lintRSTNative`) unless `UseDocutils` is set -- in which
+// case, docutils' `rst2html` must be installed.
 func (l *Linter) lintRST(f *core.File) error {
 	var html string
 
+	if l.Manager.Config.SphinxBuild != "" {
+		return l.lintSphinx(f)
+	} else if !l.Manager.Config.UseDocutils {
+		return l.lintRSTNative(f)
+	}
+
 	rst2html := core.Which([]string{
 		"rst2html", "rst2html.py", "rst2html-3", "rst2html-3.py"})
 	python := core.Which([]string{
@@ -149,11 +158,9 @@ func (l *Linter) lintRST(f *core.File) error {
 
 	if rst2html == "" || python == "" {
 		return core.NewE100("lintRST", errors.New("rst2html not found"))
-	} else if l.Manager.Config.SphinxBuild != "" {
-		return l.lintSphinx(f)
 	}
 
-	s, err := l.prep(f.Content, "\n::\n\n%s\n", "``$1``", ".rst")
+	s, err := l.prep(f, "\n::\n\n%s\n", "``$1``", ".rst")
 	if err != nil {
 		return err
 	}
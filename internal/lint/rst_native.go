@@ -0,0 +1,600 @@
+package lint
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/jdkato/regexp"
+)
+
+var (
+	// reRSTDirective matches a directive (e.g., `.. note::`), capturing its
+	// name and argument.
+	reRSTDirective = regexp.MustCompile(`^\s*\.\.\s+([\w:.+-]+?)::(?:\s+(.*))?$`)
+
+	// reRSTSubstitution matches a substitution definition (e.g., `.. |logo|
+	// image:: logo.png`).
+	reRSTSubstitution = regexp.MustCompile(`^\s*\.\.\s+\|[^|]+\|\s+[\w:.+-]+::`)
+
+	// reRSTTarget matches a hyperlink target (e.g., `.. _intro:` or `__
+	// https://example.com`).
+	reRSTTarget = regexp.MustCompile(`^\s*(?:\.\.\s+_[^:]*:|__(?:\s|$))`)
+
+	// reRSTFootnote matches a footnote or citation (e.g., `.. [1] Text`),
+	// capturing its text.
+	reRSTFootnote = regexp.MustCompile(`^\s*\.\.\s+\[[^\]]+\](?:\s+(.*))?$`)
+
+	// reRSTComment matches a comment, capturing its text.
+	reRSTComment = regexp.MustCompile(`^\s*\.\.(?:\s+(.*))?$`)
+
+	// reRSTOption matches a directive's option (e.g., `:alt: Text`),
+	// capturing its name and value.
+	reRSTOption = regexp.MustCompile(`^\s+:([\w-]+):(?:\s+(.*))?$`)
+
+	// reRSTField matches the field of a field list (e.g., `:param x: Text`),
+	// capturing its name and body.
+	reRSTField = regexp.MustCompile(`^\s*:([^:\s][^:]*):(?:\s+(.*))?$`)
+
+	// reRSTItem matches a bullet or enumerated list item, capturing its text.
+	reRSTItem = regexp.MustCompile(`^\s*(?:[-*+\x{2022}\x{2023}\x{2043}]|(?:\d+|#|[a-zA-Z]|[ivxlcdmIVXLCDM]+)[.)]|\((?:\d+|#|[a-zA-Z])\))\s+(.*)$`)
+
+	// reRSTGridBorder matches a grid table's border (e.g., `+---+---+`).
+	reRSTGridBorder = regexp.MustCompile(`^\s*\+(?:[-=]+\+)+\s*$`)
+
+	// reRSTGridRule matches the runs of a border within a grid table's row
+	// (e.g., a row that spans cells).
+	reRSTGridRule = regexp.MustCompile(`\+?[-=]{3,}\+?`)
+
+	// reRSTSimpleBorder matches a simple table's border (e.g., `===  ===`).
+	reRSTSimpleBorder = regexp.MustCompile(`^\s*=+(?:\s+=+)+\s*$`)
+
+	// reRSTColumns matches the space between the columns of a simple table.
+	reRSTColumns = regexp.MustCompile(`\s{2,}`)
+
+	// reRSTInline matches the inline markup that isn't prose: literals (group
+	// 1), interpreted text with a role (2), links with an embedded URI (3,
+	// with their text in 4 and URI in 5), references (6, with their text in
+	// 7), interpreted text without a role (8, with its text in 9),
+	// substitution and footnote references (10), and bare URLs (11).
+	reRSTInline = regexp.MustCompile(strings.Join([]string{
+		"(``[^`]+``)",
+		"(:[\\w:.+-]+:`[^`]+`|`[^`]+`:[\\w:.+-]+:)",
+		"(`([^`<]*?)\\s*<([^`>]+)>`__?)",
+		"(`([^`]+)`__?)",
+		"(`([^`]+)`)",
+		`(\|[^|\s][^|]*\|_{0,2}|\[(?:#[\w-]*|\*|\d+|[\w.-]+)\]_)`,
+		`((?:https?|ftp)://[^\s<>]+[^\s<>.,;:!?)])`,
+	}, "|"))
+
+	// reRSTMarkup matches strong (`**text**`) and emphasized (`*text*`) text.
+	reRSTMarkup = regexp.MustCompile(`\*\*([^*]+)\*\*|(^|[^\w*])\*([^\s*](?:[^*]*[^\s*])?)\*`)
+)
+
+// rstAdmonitions are the directives whose argument begins their content
+// (e.g., `.. note:: Text`).
+var rstAdmonitions = []string{
+	"attention", "caution", "danger", "error", "hint", "important", "note",
+	"tip", "warning",
+}
+
+// rstTitled are the directives whose argument is a title.
+var rstTitled = []string{"admonition", "topic", "sidebar", "rubric", "table", "list-table"}
+
+// rstBodies are the other directives whose content is prose. Like docutils
+// (see `rstServer`), we skip all of the others -- e.g., `code-block`,
+// `toctree`, or a Sphinx extension's.
+var rstBodies = []string{
+	"figure", "epigraph", "highlights", "pull-quote", "compound", "container",
+	"class", "seealso", "versionadded", "versionchanged", "deprecated",
+}
+
+// lintRSTNative lints a reStructuredText file, which we convert to HTML
+// ourselves unless `UseDocutils` is set (see `lintRST`).
+//
+// Section titles, paragraphs, lists, tables, block quotes, footnotes, and
+// the content of admonitions (e.g., `.. note::`) are linted as they would be
+// by rst2html, while literal blocks (`::`), other directives (e.g.,
+// `.. code-block::`), targets, and inline literals are skipped. Comments are
+// kept as HTML comments, so that they may turn rules on or off (e.g., `..
+// vale off`); an image's `:alt:` text is linted as `text.attr.alt`.
+func (l *Linter) lintRSTNative(f *core.File) error {
+	s, err := l.prep(f, "", "``$1``", ".rst")
+	if err != nil {
+		return err
+	}
+
+	body, skipped := rstToHTML(strings.SplitAfter(s, "\n"))
+
+	// As with AsciiDoc, everything that isn't linted is masked (see
+	// `lintADocNative`).
+	lines := strings.SplitAfter(f.Content, "\n")
+	for _, i := range skipped {
+		if i < len(lines) {
+			text := strings.TrimRight(lines[i], "\r\n")
+			lines[i] = maskText(text) + lines[i][len(text):]
+		}
+	}
+	for i, line := range lines {
+		lines[i] = rstMask(line)
+	}
+	f.Content = strings.Join(lines, "")
+
+	return l.lintHTMLTokens(f, []byte(body), 0)
+}
+
+// rstToHTML converts the reStructuredText source `lines` into HTML, also
+// returning the (0-based) lines that aren't prose -- e.g., directives and
+// literal blocks.
+func rstToHTML(lines []string) (string, []int) {
+	var buf strings.Builder
+	var skipped []int
+
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, "\r\n")
+	}
+
+	// The lines indented by more than `skip` (and any blank lines between
+	// them) aren't prose -- e.g., the content of a literal block. A comment
+	// is also collected, so that it's kept.
+	skip, comment := -1, []string(nil)
+
+	// Are we reading a directive's options, and where does the content of a
+	// prose directive (or a definition) end?
+	options, directive, content := false, -1, -1
+
+	// The section title styles we've seen (e.g., `=` underlined), in order,
+	// which give their levels.
+	styles := []string{}
+
+	para, paraIndent := []string{}, 0
+	list, listIndent := false, 0
+	quote, quoteIndent := false, 0
+	grid, simple, header := false, false, false
+
+	flush := func() {
+		if len(para) > 0 {
+			text := rstLiteral(strings.Join(para, "\n"))
+			if text != "" {
+				fmt.Fprintf(&buf, "<p>%s</p>\n", rstInline(text))
+			}
+			para = []string{}
+		}
+	}
+	closeList := func() {
+		flush()
+		if quote && quoteIndent > listIndent {
+			// The quote is part of the list's last item.
+			buf.WriteString("</blockquote>\n")
+			quote = false
+		}
+		if list {
+			buf.WriteString("</li>\n</ul>\n")
+			list = false
+		}
+	}
+	closeQuote := func() {
+		flush()
+		if quote {
+			buf.WriteString("</blockquote>\n")
+			quote = false
+		}
+	}
+	endComment := func() {
+		if comment != nil {
+			text := strings.Replace(strings.Join(comment, "\n"), "--", "- -", -1)
+			fmt.Fprintf(&buf, "<!-- %s -->\n", text)
+			comment = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+
+		if trimmed == "" {
+			flush()
+			options = false
+			if grid {
+				buf.WriteString("</table>\n")
+				grid = false
+			}
+			continue
+		}
+
+		if options {
+			if m := reRSTOption.FindStringSubmatch(line); m != nil && indent > directive {
+				if m[1] == "alt" && m[2] != "" {
+					// The text is located in our source, so it isn't masked.
+					fmt.Fprintf(&buf, "<p><img alt=\"%s\"></p>\n", html.EscapeString(m[2]))
+				} else {
+					skipped = append(skipped, i)
+				}
+				continue
+			}
+			options = false
+		}
+
+		if skip >= 0 {
+			if indent > skip {
+				skipped = append(skipped, i)
+				if comment != nil {
+					comment = append(comment, trimmed)
+				}
+				continue
+			}
+			skip = -1
+			endComment()
+		}
+
+		if content >= 0 && indent <= content {
+			content = -1
+		}
+		if list && indent < listIndent && !reRSTItem.MatchString(line) {
+			closeList()
+		}
+		if quote && indent < quoteIndent {
+			closeQuote()
+		}
+
+		if grid || simple {
+			skipped, header = rstRow(&buf, line, i, skipped, header)
+			if simple && reRSTSimpleBorder.MatchString(line) && (i+1 >= len(lines) || strings.TrimSpace(lines[i+1]) == "") {
+				// The table's bottom border.
+				buf.WriteString("</table>\n")
+				simple = false
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, ".."):
+			closeList()
+
+			// The lines with an argument or footnote that's prose aren't
+			// masked, so that its alerts can be located.
+			m := reRSTDirective.FindStringSubmatch(line)
+			switch {
+			case reRSTSubstitution.MatchString(line) || reRSTTarget.MatchString(line):
+				skipped = append(skipped, i)
+				skip = indent
+			case m != nil && core.StringInSlice(strings.ToLower(m[1]), rstAdmonitions):
+				options, directive, content = true, indent, indent
+				if m[2] != "" {
+					para, paraIndent = []string{m[2]}, -1
+				}
+			case m != nil && core.StringInSlice(strings.ToLower(m[1]), rstTitled):
+				options, directive, content = true, indent, indent
+				if m[2] != "" {
+					fmt.Fprintf(&buf, "<p>%s</p>\n", rstInline(m[2]))
+				}
+			case m != nil && core.StringInSlice(strings.ToLower(m[1]), rstBodies):
+				skipped = append(skipped, i)
+				options, directive, content = true, indent, indent
+			case m != nil:
+				// We still read its options, for an image's `:alt:`.
+				skipped = append(skipped, i)
+				options, directive, skip = true, indent, indent
+			case reRSTFootnote.MatchString(line):
+				content = indent
+				if text := reRSTFootnote.FindStringSubmatch(line)[1]; text != "" {
+					para, paraIndent = []string{text}, -1
+				}
+			default:
+				skipped = append(skipped, i)
+				skip, comment = indent, []string{}
+				if text := reRSTComment.FindStringSubmatch(line)[1]; text != "" {
+					comment = append(comment, text)
+				}
+			}
+		case reRSTTarget.MatchString(line):
+			closeList()
+			skipped = append(skipped, i)
+			skip = indent
+		case len(para) == 0 && indent == 0 && i+2 < len(lines) && rstAdornment(line) != "" &&
+			rstAdornment(lines[i+2]) == rstAdornment(line) && strings.TrimSpace(lines[i+1]) != "":
+			// A section title with an overline.
+			closeList()
+			closeQuote()
+			skipped = append(skipped, i, i+2)
+			level := rstLevel(&styles, "o"+rstAdornment(line))
+			fmt.Fprintf(&buf, "<h%d>%s</h%d>\n", level, rstInline(strings.TrimSpace(lines[i+1])), level)
+			i += 2
+		case len(para) == 0 && indent == 0 && i+1 < len(lines) && rstTitle(line, lines[i+1]):
+			closeList()
+			closeQuote()
+			skipped = append(skipped, i+1)
+			level := rstLevel(&styles, "u"+rstAdornment(lines[i+1]))
+			fmt.Fprintf(&buf, "<h%d>%s</h%d>\n", level, rstInline(trimmed), level)
+			i++
+		case len(para) == 0 && rstAdornment(line) != "" && utf8.RuneCountInString(trimmed) >= 4:
+			// A transition.
+			closeList()
+			skipped = append(skipped, i)
+		case reRSTGridBorder.MatchString(line) || (len(para) == 0 && reRSTSimpleBorder.MatchString(line)):
+			closeList()
+			buf.WriteString("<table>\n")
+			skipped = append(skipped, i)
+			grid = line[indent] == '+'
+			simple = !grid
+			header = rstHeader(lines[i+1:], grid)
+		case reRSTItem.MatchString(line) && len(para) == 0:
+			flush()
+			if list {
+				buf.WriteString("</li>\n")
+			} else {
+				buf.WriteString("<ul>\n")
+				list = true
+			}
+			m := reRSTItem.FindStringSubmatchIndex(line)
+			listIndent = m[2]
+			para, paraIndent = []string{line[m[2]:m[3]]}, -1
+			buf.WriteString("<li>")
+		case reRSTField.MatchString(line) && len(para) == 0:
+			m := reRSTField.FindStringSubmatch(line)
+			fmt.Fprintf(&buf, "<table>\n<tr><th>%s:</th><td>%s</td></tr>\n</table>\n",
+				rstInline(m[1]), rstInline(m[2]))
+		default:
+			if len(para) == 1 && paraIndent >= 0 && indent > paraIndent {
+				// A definition (of the term before it), which is read as a
+				// directive's content would be.
+				flush()
+				content = paraIndent
+			}
+
+			if len(para) == 0 {
+				context := 0
+				if list {
+					context = listIndent
+				}
+
+				// An indented paragraph is a block quote, unless it's the
+				// content of a directive.
+				if indent > context && !quote && content < 0 {
+					buf.WriteString("<blockquote>\n")
+					quote, quoteIndent = true, indent
+				}
+				paraIndent = indent
+			}
+
+			if strings.HasPrefix(trimmed, "| ") || trimmed == "|" {
+				// A line block.
+				trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, "|"))
+			}
+			para = append(para, trimmed)
+		}
+
+		if n := len(para); n > 0 && strings.HasSuffix(para[n-1], "::") {
+			// The paragraph introduces a literal block.
+			flush()
+			skip = indent
+			if list {
+				skip = listIndent
+			}
+		}
+		paraIndent = rstIndent(paraIndent, para)
+	}
+
+	closeList()
+	closeQuote()
+	endComment()
+	if grid || simple {
+		buf.WriteString("</table>\n")
+	}
+
+	return buf.String(), skipped
+}
+
+// rstIndent resets the indentation of an empty paragraph.
+func rstIndent(indent int, para []string) int {
+	if len(para) == 0 {
+		return 0
+	}
+	return indent
+}
+
+// rstAdornment returns the character that `line` repeats, if it could
+// underline (or overline) a section title.
+func rstAdornment(line string) string {
+	line = strings.TrimRight(line, " \t")
+	if len(line) < 2 {
+		return ""
+	}
+
+	r := rune(line[0])
+	if r > unicode.MaxASCII || !unicode.IsPunct(r) && !unicode.IsSymbol(r) {
+		return ""
+	}
+	for _, c := range line {
+		if c != r {
+			return ""
+		}
+	}
+	return string(r)
+}
+
+// rstTitle determines if `line` is a section title underlined by `next`.
+func rstTitle(line, next string) bool {
+	if rstAdornment(line) != "" || rstAdornment(next) == "" {
+		return false
+	}
+	// An underline shorter than its title is an error, which docutils
+	// forgives if it's at least four characters long.
+	n := utf8.RuneCountInString(strings.TrimRight(next, " \t"))
+	return n >= 4 || n >= utf8.RuneCountInString(strings.TrimSpace(line))
+}
+
+// rstLevel returns the level of a section title in the `style` (e.g., `u=`
+// for underlined with `=`), which is given by the order in which we've seen
+// each style.
+func rstLevel(styles *[]string, style string) int {
+	level := 0
+	for i, s := range *styles {
+		if s == style {
+			level = i + 1
+		}
+	}
+	if level == 0 {
+		*styles = append(*styles, style)
+		level = len(*styles)
+	}
+	if level > 6 {
+		return 6
+	}
+	return level
+}
+
+// rstHeader determines if the table that `lines` begin has a header, which
+// is separated from its body by `=`.
+func rstHeader(lines []string, grid bool) bool {
+	borders := 0
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			break
+		} else if grid && strings.Contains(line, "+=") && reRSTGridBorder.MatchString(line) {
+			return true
+		} else if !grid && reRSTSimpleBorder.MatchString(line) {
+			borders++
+		}
+	}
+	// A simple table's header is followed by a border, as is its body.
+	return borders > 1
+}
+
+// rstRow converts a row of a table (or one of its borders) into HTML,
+// returning the updated skipped lines and whether we're still in its header.
+func rstRow(buf *strings.Builder, line string, i int, skipped []int, header bool) ([]int, bool) {
+	if reRSTGridBorder.MatchString(line) || reRSTSimpleBorder.MatchString(line) {
+		if strings.Contains(line, "=") {
+			header = false
+		}
+		return append(skipped, i), header
+	}
+
+	var cells []string
+	if strings.HasPrefix(strings.TrimSpace(line), "+") || strings.HasPrefix(strings.TrimSpace(line), "|") {
+		cells = strings.Split(line, "|")
+	} else {
+		cells = reRSTColumns.Split(strings.TrimSpace(line), -1)
+	}
+
+	tag := "td"
+	if header {
+		tag = "th"
+	}
+
+	buf.WriteString("<tr>")
+	for _, cell := range cells {
+		cell = strings.TrimSpace(reRSTGridRule.ReplaceAllString(cell, ""))
+		if cell != "" {
+			fmt.Fprintf(buf, "<%s>%s</%s>", tag, rstInline(cell), tag)
+		}
+	}
+	buf.WriteString("</tr>\n")
+
+	return skipped, header
+}
+
+// rstLiteral removes the `::` that introduces a literal block from the end
+// of a paragraph, as docutils does: `Text::` becomes `Text:`, while `Text ::`
+// and `::` become `Text` and nothing.
+func rstLiteral(text string) string {
+	if !strings.HasSuffix(text, "::") {
+		return text
+	}
+	text = strings.TrimSuffix(text, "::")
+	if trimmed := strings.TrimRight(text, " \t\n"); trimmed != text || text == "" {
+		return trimmed
+	}
+	return text + ":"
+}
+
+// rstSpan returns the masked source and the HTML of the `reRSTInline` match
+// `m` in `s`.
+func rstSpan(s string, m []int) (string, string) {
+	match := s[m[0]:m[1]]
+	switch {
+	case m[2] >= 0:
+		// Literals are always given in double backticks (see `codify`).
+		code := maskText(match[2 : len(match)-2])
+		return "``" + code + "``", "<code>" + code + "</code>"
+	case m[6] >= 0 && m[8] < m[9]:
+		return rstLink(s, m[0], m[8], m[9], m[10], m[11], m[1])
+	case m[12] >= 0:
+		return rstText(s, m[0], m[14], m[15], m[1], "a")
+	case m[16] >= 0:
+		return rstText(s, m[0], m[18], m[19], m[1], "cite")
+	case m[22] >= 0:
+		// A bare URL is its own text, which isn't prose.
+		return match, `<a href="` + html.EscapeString(match) + `">` + maskText(match) + "</a>"
+	}
+	return maskText(match), maskText(match)
+}
+
+// rstLink masks everything but the text, `s[start:end]`, and the URI,
+// `s[uri:uriEnd]`, of the link `s[from:to]`. The URI is linted as `text.url`
+// (see `lintTags`), which masks it in turn.
+func rstLink(s string, from, start, end, uri, uriEnd, to int) (string, string) {
+	masked := maskText(s[from:start]) + s[start:end] + maskText(s[end:uri]) +
+		s[uri:uriEnd] + maskText(s[uriEnd:to])
+	return masked, `<a href="` + html.EscapeString(s[uri:uriEnd]) + `">` +
+		rstEmphasis(s[start:end]) + "</a>"
+}
+
+// rstText masks everything but the text, `s[start:end]`, of the markup
+// `s[from:to]`, which is converted into the HTML `tag`.
+func rstText(s string, from, start, end, to int, tag string) (string, string) {
+	return maskText(s[from:start]) + s[start:end] + maskText(s[end:to]),
+		"<" + tag + ">" + rstEmphasis(s[start:end]) + "</" + tag + ">"
+}
+
+// rstMask masks the inline markup in `line` that isn't prose (see
+// `reRSTInline`).
+func rstMask(line string) string {
+	var buf strings.Builder
+
+	cursor := 0
+	for _, m := range reRSTInline.FindAllStringSubmatchIndex(line, -1) {
+		masked, _ := rstSpan(line, m)
+		buf.WriteString(line[cursor:m[0]])
+		buf.WriteString(masked)
+		cursor = m[1]
+	}
+	buf.WriteString(line[cursor:])
+
+	return buf.String()
+}
+
+// rstInline converts the inline markup in `s` into HTML. Literals, roles,
+// URLs, and references to substitutions and footnotes are masked, as they
+// are in the source (see `rstMask`).
+func rstInline(s string) string {
+	var buf strings.Builder
+
+	cursor := 0
+	for _, m := range reRSTInline.FindAllStringSubmatchIndex(s, -1) {
+		_, converted := rstSpan(s, m)
+		buf.WriteString(rstEmphasis(s[cursor:m[0]]))
+		buf.WriteString(converted)
+		cursor = m[1]
+	}
+	buf.WriteString(rstEmphasis(s[cursor:]))
+
+	return buf.String()
+}
+
+// rstEmphasis escapes `s`, converting its emphasis markers into HTML tags.
+func rstEmphasis(s string) string {
+	s = html.EscapeString(s)
+	return reRSTMarkup.ReplaceAllStringFunc(s, func(m string) string {
+		parts := reRSTMarkup.FindStringSubmatch(m)
+		if parts[1] != "" {
+			return "<strong>" + parts[1] + "</strong>"
+		}
+		return parts[2] + "<em>" + parts[3] + "</em>"
+	})
+}
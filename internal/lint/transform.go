@@ -0,0 +1,231 @@
+package lint
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+)
+
+// isCommand determines if `transform` is an external command (e.g.,
+// `./to_html.sh`) rather than an XSLT stylesheet.
+func isCommand(transform string) bool {
+	ext := strings.ToLower(filepath.Ext(transform))
+	return transform != "" && ext != ".xsl" && ext != ".xslt"
+}
+
+// A lineAnchor states that the output's line `out` (and those after it, until
+// the next anchor) comes from the source's line `src`.
+type lineAnchor struct {
+	out, src int
+}
+
+// lintTransform lints the HTML produced by running `command` (a file's
+// `Command` or `Transform`, followed by any arguments) with the file's content
+// on stdin.
+//
+// The command may also write a line map to file descriptor 3: each line is a
+// pair of (1-based) line numbers, `<output> <source>`, stating where the
+// given line of output came from (following lines are assumed to advance
+// along with it, as with a `#line` directive). Alerts are then reported on
+// the source lines the map gives. Without a map, each alert is located by
+// finding its text in the source.
+//
+// A command that fails is reported as a `Failure`.
+func (l *Linter) lintTransform(f *core.File, command []string) error {
+	out, anchors, err := runTransform(command, f.Content)
+	if err != nil {
+		return &Failure{Path: f.Path, Err: err}
+	}
+
+	// The file is linted as HTML, whatever its own format.
+	f.Format = "markup"
+	if len(anchors) == 0 {
+		return l.lintHTMLTokens(f, out, 0)
+	}
+
+	// Our positions are calculated in the output, which we then map back to
+	// the source.
+	content, lines := f.Content, f.Lines
+
+	f.Content = string(out)
+	f.Lines = strings.SplitAfter(f.Content, "\n")
+	err = l.lintHTMLTokens(f, out, 0)
+
+	for i := range f.Alerts {
+		mapAlert(&f.Alerts[i], anchors, f.Lines, lines)
+	}
+	f.Content, f.Lines = content, lines
+
+	return err
+}
+
+// runTransform runs `command` with `content` on stdin, returning its output
+// and the line map (if any) it wrote to file descriptor 3.
+func runTransform(command []string, content string) ([]byte, []lineAnchor, error) {
+	var out, stderr bytes.Buffer
+
+	if len(command) == 0 {
+		return nil, nil, errors.New("empty command")
+	}
+	transform := strings.Join(command, " ")
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+
+	var lineMap []byte
+	var mapErr error
+
+	done := make(chan bool)
+	if runtime.GOOS != "windows" {
+		// NOTE: Windows doesn't support passing extra file descriptors.
+		r, w, err := os.Pipe()
+		if err != nil {
+			return nil, nil, err
+		}
+		defer r.Close()
+
+		cmd.ExtraFiles = []*os.File{w}
+		go func() {
+			lineMap, mapErr = ioutil.ReadAll(r)
+			done <- true
+		}()
+
+		if err = cmd.Start(); err != nil {
+			w.Close()
+			<-done
+			return nil, nil, fmt.Errorf("transform '%s': %s", transform, err)
+		}
+
+		// Our copy of the write end must be closed for the reader to see
+		// the end of the map.
+		w.Close()
+	} else if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("transform '%s': %s", transform, err)
+	} else {
+		close(done)
+	}
+
+	err := cmd.Wait()
+	<-done
+
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, nil, fmt.Errorf("transform '%s' failed: %s", transform, msg)
+	} else if mapErr != nil {
+		return nil, nil, fmt.Errorf("transform '%s': %s", transform, mapErr)
+	}
+
+	anchors, err := parseLineMap(lineMap)
+	if err != nil {
+		return nil, nil, fmt.Errorf("transform '%s': %s", transform, err)
+	}
+
+	return out.Bytes(), anchors, nil
+}
+
+// parseLineMap reads the `<output> <source>` pairs written by a transform,
+// sorted by their output line.
+func parseLineMap(data []byte) ([]lineAnchor, error) {
+	anchors := []lineAnchor{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		} else if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid line map entry '%s'", scanner.Text())
+		}
+
+		out, err1 := strconv.Atoi(fields[0])
+		src, err2 := strconv.Atoi(fields[1])
+		if err1 != nil || err2 != nil || out < 1 || src < 1 {
+			return nil, fmt.Errorf("invalid line map entry '%s'", scanner.Text())
+		}
+		anchors = append(anchors, lineAnchor{out: out, src: src})
+	}
+
+	sort.SliceStable(anchors, func(i, j int) bool {
+		return anchors[i].out < anchors[j].out
+	})
+
+	return anchors, scanner.Err()
+}
+
+// mapAlert moves `a` from its line in a transform's `output` to the
+// corresponding line in `source`.
+//
+// Its span is that of the same occurrence (e.g., the second) of its match on
+// the source line, if there is one; otherwise, it's left as is.
+func mapAlert(a *core.Alert, anchors []lineAnchor, output, source []string) {
+	nth := -1
+	if a.Line >= 1 && a.Line <= len(output) {
+		nth = occurrence(output[a.Line-1], a.Match, a.Span[0])
+	}
+
+	line := a.Line
+	for _, anchor := range anchors {
+		if anchor.out > a.Line {
+			break
+		}
+		line = anchor.src + (a.Line - anchor.out)
+	}
+
+	if line > len(source) {
+		line = len(source)
+	}
+	if line < 1 {
+		line = 1
+	}
+	a.Line = line
+
+	text, idx := source[line-1], -1
+	for i := 0; i <= nth; i++ {
+		next := strings.Index(text[idx+1:], a.Match)
+		if next < 0 {
+			return
+		}
+		idx += next + 1
+	}
+
+	if idx >= 0 {
+		start := utf8.RuneCountInString(text[:idx]) + 1
+		a.Span = []int{start, start + utf8.RuneCountInString(a.Match) - 1}
+	}
+}
+
+// occurrence returns which occurrence (0-based) of `match` in `line` starts
+// at the (1-based) column `col`, or -1 if none does.
+func occurrence(line, match string, col int) int {
+	if match == "" {
+		return -1
+	}
+
+	n, offset := 0, 0
+	for {
+		idx := strings.Index(line[offset:], match)
+		if idx < 0 {
+			return -1
+		} else if utf8.RuneCountInString(line[:offset+idx])+1 == col {
+			return n
+		}
+		n, offset = n+1, offset+idx+1
+	}
+}
@@ -26,12 +26,18 @@ type walker struct {
 	// if we see <ul>, <li>, <p>, we'd get tagHistory = [ul li p]. It's reset
 	// on every non-inline end tag.
 	tagHistory []string
+
+	// summary is the context of the `summary` scope: the file's content,
+	// without the blocks that aren't part of its summary (see `lintScope`).
+	summary *string
 }
 
 func newWalker(f *core.File, raw []byte, offset int) walker {
+	summary := f.Content
 	return walker{
 		lines:   len(f.Lines) + offset,
 		context: f.Content,
+		summary: &summary,
 		z:       html.NewTokenizer(bytes.NewReader(raw))}
 }
 
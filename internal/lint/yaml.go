@@ -0,0 +1,447 @@
+package lint
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/errata-ai/vale/v2/internal/core"
+	"github.com/jdkato/regexp"
+)
+
+// reYAMLKey matches a mapping key (plain or quoted) along with the rest of
+// its line, which holds its value.
+var reYAMLKey = regexp.MustCompile(
+	`^("(?:[^"\\]|\\.)*"|'(?:[^']|'')*'|[^\s#'"{}\[\],&*!|>%@` + "`" + `][^#]*?)\s*:(?:\s+(.*))?$`)
+
+// reYAMLBlock matches the header of a block scalar (e.g., `|`, `>-`, or `|2`),
+// along with any comment that follows it.
+var reYAMLBlock = regexp.MustCompile(`^[|>][-+0-9]*(?:\s+(#.*))?$`)
+
+// reYAMLProps matches the anchor (`&name`) and tag (`!tag`) that may precede
+// a value.
+var reYAMLProps = regexp.MustCompile(`^(?:[&!]\S*\s+)+`)
+
+// reYAMLNonString matches the plain scalars that aren't strings.
+var reYAMLNonString = regexp.MustCompile(
+	`(?i)^(?:~|null|true|false|yes|no|on|off|[-+]?[0-9][0-9_.:eE+-]*|[-+]?\.inf|\.nan)$`)
+
+// A yamlSpan is the part of a line that holds (some of) a value's text.
+type yamlSpan struct {
+	line       int // 0-based
+	start, end int // byte offsets within the line
+}
+
+// A yamlValue is a comment or string value from a YAML file.
+type yamlValue struct {
+	comment bool
+	path    []string // the path of a string value's key
+	spans   []yamlSpan
+}
+
+// A yamlKey is a mapping key along with its indentation.
+type yamlKey struct {
+	name   string
+	indent int
+}
+
+// lintYAML lints the comments (as `text.comment.line`) and, if they're
+// selected by `LintKeys`, the string values (as `text`) of a YAML file.
+// Everything else -- keys, anchors, tags, and all other values -- is skipped.
+//
+// The `summary` and `description` fields of an OpenAPI document are always
+// linted, as `text.openapi.summary`, `text.openapi.description`, and (for
+// parameters) `text.openapi.parameter.description`.
+//
+// A block scalar (`|` or `>`) is linted as a whole, so its paragraphs and
+// sentences are linted as they would be in a Markdown file.
+func (l *Linter) lintYAML(f *core.File) error {
+	lines := make([]string, len(f.Lines))
+	for i, line := range f.Lines {
+		lines[i] = strings.TrimRight(line, "\r\n")
+	}
+
+	keys := l.Manager.Config.LintKeys
+	openapi := isOpenAPI(lines)
+	for _, v := range parseYAML(lines, func(path []string) bool {
+		return yamlKeySelected(path, keys) || (openapi && openAPIScope(path) != "")
+	}) {
+		text := yamlText(lines, v.spans)
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		// Each value is linted within an otherwise-masked copy of the file,
+		// so alerts can't be located in any other part of it.
+		ctx := yamlContext(f.Lines, lines, v.spans)
+		first := v.spans[0].line
+
+		if v.comment {
+			blk := core.NewLinedBlock(ctx, text, "text.comment.line"+f.RealExt, first)
+			l.lintBlock(f, blk, len(f.Lines), 0, false)
+		} else {
+			ext := f.RealExt
+			if openapi {
+				ext = openAPIScope(v.path) + ext
+			}
+			l.lintProse(f, core.NewLinedBlock(ctx, text, "text"+ext, first), len(f.Lines), ext)
+		}
+	}
+
+	l.lintRaw(f)
+	return nil
+}
+
+// parseYAML returns the comments in the YAML source `lines`, along with the
+// string values of the keys whose path (e.g., `[info, description]`) is
+// `selected`.
+//
+// Items of a sequence are part of the sequence's key -- e.g., each string in
+// `tags: [...]` (in block style) has the path `[tags]`.
+func parseYAML(lines []string, selected func([]string) bool) []yamlValue {
+	var values []yamlValue
+	var stack []yamlKey
+
+	// open is set after a key whose value isn't on its line.
+	open := false
+
+	path := func() []string {
+		names := make([]string, len(stack))
+		for i, k := range stack {
+			names[i] = k.name
+		}
+		return names
+	}
+
+	pop := func(indent int, inclusive bool) {
+		for len(stack) > 0 {
+			top := stack[len(stack)-1].indent
+			if top < indent || (top == indent && !inclusive) {
+				break
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	comment := func(i, at int) {
+		// We skip the `#` and any whitespace after it.
+		line := lines[i]
+		start := at + 1
+		for start < len(line) && (line[start] == ' ' || line[start] == '\t' || line[start] == '#') {
+			start++
+		}
+		if start < len(line) {
+			values = append(values, yamlValue{comment: true, spans: []yamlSpan{{i, start, len(line)}}})
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+
+		if trimmed == "" {
+			continue
+		} else if strings.HasPrefix(trimmed, "#") {
+			comment(i, indent)
+			continue
+		} else if trimmed == "---" || trimmed == "..." || strings.HasPrefix(trimmed, "--- ") {
+			// A new document.
+			if idx := yamlCommentStart(line, indent); idx >= 0 {
+				comment(i, idx)
+			}
+			stack, open = nil, false
+			continue
+		} else if strings.HasPrefix(line, "%") {
+			// A directive.
+			continue
+		}
+
+		// We skip any sequence indicators (e.g., `- - key: value`), whose
+		// items are part of the enclosing key.
+		at, item := indent, false
+		for strings.HasPrefix(line[at:], "- ") || line[at:] == "-" {
+			if !item {
+				pop(at, false)
+			}
+			at, item = at+1, true
+			for at < len(line) && line[at] == ' ' {
+				at++
+			}
+		}
+
+		parent := indent
+		rest := line[at:]
+		if m := reYAMLKey.FindStringSubmatchIndex(rest); m != nil {
+			pop(at, true)
+			stack = append(stack, yamlKey{name: yamlKeyName(rest[m[2]:m[3]]), indent: at})
+			if m[4] < 0 || rest[m[4]] == '#' {
+				// The value (if any) is on the following lines.
+				if m[4] >= 0 {
+					comment(i, at+m[4])
+				}
+				open = true
+				continue
+			}
+			parent, at = at, at+m[4]
+		} else if open && !item && len(stack) > 0 && indent > stack[len(stack)-1].indent {
+			// A plain scalar that starts on the line after its key.
+			parent = stack[len(stack)-1].indent
+		} else if !item {
+			// A continuation of a flow collection or multi-line scalar that
+			// isn't ours.
+			if idx := yamlCommentStart(line, at); idx >= 0 {
+				comment(i, idx)
+			}
+			continue
+		}
+		open = false
+
+		wanted := len(stack) > 0 && selected(path())
+		v, next, trailing := scanYAMLValue(lines, i, at, parent)
+		if trailing >= 0 {
+			comment(i, trailing)
+		}
+		if wanted && len(v.spans) > 0 {
+			v.path = path()
+			values = append(values, v)
+		}
+		i = next
+	}
+
+	return values
+}
+
+// scanYAMLValue scans the value that starts at byte `at` of line `i`, whose
+// key (or sequence indicator) is at column `indent`, returning it along with
+// the last line it spans and the start of its line's comment (or -1).
+func scanYAMLValue(lines []string, i, at, indent int) (yamlValue, int, int) {
+	v := yamlValue{}
+	line := lines[i]
+
+	if m := reYAMLProps.FindStringIndex(line[at:]); m != nil {
+		at += m[1]
+	}
+	rest := line[at:]
+
+	switch {
+	case rest == "":
+		return v, i, -1
+	case reYAMLBlock.MatchString(rest):
+		trailing := -1
+		if m := reYAMLBlock.FindStringSubmatchIndex(rest); m[2] >= 0 {
+			trailing = at + m[2]
+		}
+
+		// The block's content is every following line that's indented
+		// more than its key (or blank), stripped of the indentation of its
+		// first line.
+		block, last := -1, i
+		for j := i + 1; j < len(lines); j++ {
+			content := strings.TrimLeft(lines[j], " ")
+			n := len(lines[j]) - len(content)
+			if strings.TrimSpace(content) == "" {
+				continue
+			} else if n <= indent {
+				break
+			} else if block < 0 {
+				block = n
+			}
+			last = j
+		}
+		for j := i + 1; j <= last && block >= 0; j++ {
+			if len(lines[j]) > block {
+				v.spans = append(v.spans, yamlSpan{j, block, len(lines[j])})
+			} else {
+				v.spans = append(v.spans, yamlSpan{j, len(lines[j]), len(lines[j])})
+			}
+		}
+		return v, last, trailing
+	case rest[0] == '"' || rest[0] == '\'':
+		end := yamlQuoteEnd(rest)
+		if end < 0 {
+			// A multi-line quoted scalar, which we don't support.
+			return v, i, -1
+		}
+		if end > 1 {
+			v.spans = []yamlSpan{{i, at + 1, at + end - 1}}
+		}
+		return v, i, yamlCommentStart(line, at+end)
+	case strings.ContainsAny(rest[:1], "{[*"):
+		// A flow collection or an alias.
+		return v, i, -1
+	}
+
+	// A plain scalar, which may continue on the following (more indented)
+	// lines.
+	trailing := yamlCommentStart(line, at)
+	end := len(line)
+	if trailing >= 0 {
+		end = trailing
+	}
+	text := strings.TrimRight(line[at:end], " \t")
+	if text == "" || reYAMLNonString.MatchString(text) {
+		return v, i, trailing
+	}
+	v.spans = []yamlSpan{{i, at, at + len(text)}}
+
+	last := i
+	for j := i + 1; j < len(lines) && trailing < 0; j++ {
+		content := strings.TrimLeft(lines[j], " \t")
+		n := len(lines[j]) - len(content)
+		if n <= indent || content == "" || strings.HasPrefix(content, "#") {
+			break
+		} else if reYAMLKey.MatchString(content) || strings.HasPrefix(content, "- ") {
+			break
+		}
+
+		end = len(lines[j])
+		if trailing = yamlCommentStart(lines[j], n); trailing >= 0 {
+			end = trailing
+		}
+		v.spans = append(v.spans, yamlSpan{j, n, n + len(strings.TrimRight(lines[j][n:end], " \t"))})
+		last = j
+	}
+
+	return v, last, -1
+}
+
+// yamlQuoteEnd returns the index just after the closing quote of the quoted
+// scalar at the start of `s`, or -1 if it isn't closed.
+func yamlQuoteEnd(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
+		} else if s[i] == quote {
+			if quote == '\'' && i+1 < len(s) && s[i+1] == '\'' {
+				// An escaped single quote.
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// yamlCommentStart returns the index of the `#` that starts a comment in
+// `line` at or after `from`, or -1 if there isn't one.
+func yamlCommentStart(line string, from int) int {
+	for i := from; i < len(line); i++ {
+		if line[i] == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			return i
+		}
+	}
+	return -1
+}
+
+// yamlKeyName returns the name of the (possibly quoted) key `s`.
+func yamlKeyName(s string) string {
+	if len(s) > 1 && (s[0] == '"' || s[0] == '\'') {
+		return s[1 : len(s)-1]
+	}
+	return strings.TrimSpace(s)
+}
+
+// yamlKeySelected determines if a key with the given path is selected by
+// one of `selectors` (see `LintKeys`), which may be:
+//
+//   - a key's name (e.g., `description`), which matches it at any depth;
+//   - a path from the root (e.g., `info.description` or
+//     `$.info.description`), in which `*` matches any one key; or
+//   - a path with a leading `..` (e.g., `$..summary`), which matches the
+//     end of a key's path.
+func yamlKeySelected(path, selectors []string) bool {
+	for _, sel := range selectors {
+		anywhere := false
+		sel = strings.TrimPrefix(sel, "$")
+		if strings.HasPrefix(sel, "..") {
+			sel, anywhere = sel[2:], true
+		} else if !strings.Contains(strings.TrimPrefix(sel, "."), ".") {
+			anywhere = true
+		}
+
+		parts := strings.Split(strings.TrimPrefix(sel, "."), ".")
+		if len(parts) > len(path) || (!anywhere && len(parts) != len(path)) {
+			continue
+		}
+
+		matched := true
+		for i, part := range parts {
+			if name := path[len(path)-len(parts)+i]; part != "*" && part != name {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isOpenAPI determines if the YAML source `lines` is an OpenAPI (or Swagger)
+// document, which has an `openapi` (or `swagger`) key at its root.
+func isOpenAPI(lines []string) bool {
+	for _, line := range lines {
+		if m := reYAMLKey.FindStringSubmatch(line); m != nil {
+			if name := yamlKeyName(m[1]); name == "openapi" || name == "swagger" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// openAPIScope returns the sections added to the scope of the OpenAPI field
+// with the given path (e.g., `.openapi.summary`), or an empty string if it's
+// not one that we lint.
+func openAPIScope(path []string) string {
+	n := len(path)
+	switch path[n-1] {
+	case "summary":
+		return ".openapi.summary"
+	case "description":
+		// A parameter is an item of an operation's (or path's) `parameters`
+		// or one of the named parameters that they may refer to.
+		if n > 1 && path[n-2] == "parameters" ||
+			n == 4 && path[0] == "components" && path[1] == "parameters" ||
+			n == 3 && path[0] == "parameters" {
+			return ".openapi.parameter.description"
+		}
+		return ".openapi.description"
+	}
+	return ""
+}
+
+// yamlText returns the text of `spans`, one line per span.
+func yamlText(lines []string, spans []yamlSpan) string {
+	parts := make([]string, len(spans))
+	for i, s := range spans {
+		parts[i] = lines[s.line][s.start:s.end]
+	}
+	return strings.Join(parts, "\n")
+}
+
+// yamlContext returns a copy of the source `raw` (with line endings) in which
+// everything other than `spans` is masked.
+func yamlContext(raw, lines []string, spans []yamlSpan) string {
+	var ctx strings.Builder
+
+	j := 0
+	for i, line := range raw {
+		ending := line[len(lines[i]):]
+		if j < len(spans) && spans[j].line == i {
+			s := spans[j]
+			ctx.WriteString(strings.Repeat(" ", utf8.RuneCountInString(lines[i][:s.start])))
+			ctx.WriteString(lines[i][s.start:s.end])
+			ctx.WriteString(strings.Repeat(" ", utf8.RuneCountInString(lines[i][s.end:])))
+			j++
+		} else {
+			ctx.WriteString(strings.Repeat(" ", utf8.RuneCountInString(lines[i])))
+		}
+		ctx.WriteString(ending)
+	}
+
+	return ctx.String()
+}
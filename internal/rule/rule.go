@@ -2,6 +2,10 @@
 // sources:
 // rule/Vale/Repetition.yml
 // rule/Vale/Spelling.yml
+// rule/api/Descriptions.yml
+// rule/api/ParameterStart.yml
+// rule/api/SummaryCase.yml
+// rule/api/SummaryPunctuation.yml
 // DO NOT EDIT!
 
 package rule
@@ -109,6 +113,86 @@ func ruleValeSpellingYml() (*asset, error) {
 	return a, nil
 }
 
+var _ruleApiDescriptionsYml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x4c\xcc\x31\x8a\xc3\x30\x10\x46\xe1\x5e\xa7\x98\x75\x63\xd8\xc5\x3a\x80\x9a\x25\xa7\x48\x61\x39\x20\xe4\x1f\x23\x62\xcf\x08\xcd\x24\x36\x3e\x7d\x20\x69\x52\xbe\xe2\x7d\x38\x0c\x3c\x6b\x20\x1c\x45\x0d\x9c\xe1\x36\xa8\xa6\x05\x81\xba\x6b\x2b\x06\x9a\xa1\xb9\x95\x6a\x45\x58\x29\x29\x65\xd9\xea\x0a\x03\x29\xf8\x7d\xa8\xef\xdc\x8a\x27\xd6\x40\x7b\x6a\x5c\x78\x71\x9a\xa5\x22\x90\xe1\x30\x2f\x15\x9c\x6a\xf1\x5f\x8e\x63\xe1\x5d\xda\x1c\xc8\xda\x03\xce\xe4\x0e\xd6\xe0\x88\x06\xea\xe3\x65\x4c\xc3\x39\xf5\x9f\x1a\x6f\xfe\xff\x27\xea\xf4\x17\xf5\x37\x9e\xbd\x7b\x0d\x00\x64\x20\xff\xf8\xb0\x00\x00\x00")
+
+func ruleApiDescriptionsYmlBytes() ([]byte, error) {
+	return bindataRead(
+		_ruleApiDescriptionsYml,
+		"rule/api/Descriptions.yml",
+	)
+}
+
+func ruleApiDescriptionsYml() (*asset, error) {
+	bytes, err := ruleApiDescriptionsYmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "rule/api/Descriptions.yml", size: 176, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _ruleApiParameterstartYml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x4c\xcc\xb1\x0d\xc2\x30\x10\x05\xd0\xde\x53\x7c\x45\x42\xae\xf0\x00\xee\x90\x18\x81\x32\x8d\x49\xbe\x12\x8b\xe4\x6c\xdd\x1d\x24\xe3\xd3\x21\x16\x78\x3c\x9d\x32\x5b\x06\xcf\x6a\x4e\x99\x18\x76\x9a\x95\x85\x19\xc3\xbd\x49\x74\x98\x17\x75\x14\xf4\xa2\x65\xa7\x53\xa3\x61\xa6\x4d\x5a\xbb\xd7\x26\x38\xaa\xaf\x88\x17\x8b\x69\x08\x1b\x3f\xdc\x32\x8e\xa2\x52\x65\x09\x36\xb5\xce\x0c\xe7\xe9\xa9\x75\x4a\xe9\x35\xfd\x98\xf4\x87\x04\x69\x72\x34\x9d\x33\x5c\xdf\x0c\xde\x5e\x14\xcb\x01\xb8\x22\x8e\xb7\xc7\xca\xf1\x19\xc3\x77\x00\x65\xa5\xc2\x2a\xac\x00\x00\x00")
+
+func ruleApiParameterstartYmlBytes() ([]byte, error) {
+	return bindataRead(
+		_ruleApiParameterstartYml,
+		"rule/api/ParameterStart.yml",
+	)
+}
+
+func ruleApiParameterstartYml() (*asset, error) {
+	bytes, err := ruleApiParameterstartYmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "rule/api/ParameterStart.yml", size: 172, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _ruleApiSummarycaseYml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x80\x00\x7f\xff\x65\x78\x74\x65\x6e\x64\x73\x3a\x20\x63\x61\x70\x69\x74\x61\x6c\x69\x7a\x61\x74\x69\x6f\x6e\x0a\x6d\x65\x73\x73\x61\x67\x65\x3a\x20\x22\x27\x25\x73\x27\x20\x73\x68\x6f\x75\x6c\x64\x20\x62\x65\x20\x69\x6e\x20\x73\x65\x6e\x74\x65\x6e\x63\x65\x20\x63\x61\x73\x65\x2e\x22\x0a\x6c\x65\x76\x65\x6c\x3a\x20\x77\x61\x72\x6e\x69\x6e\x67\x0a\x73\x63\x6f\x70\x65\x3a\x20\x74\x65\x78\x74\x2e\x6f\x70\x65\x6e\x61\x70\x69\x2e\x73\x75\x6d\x6d\x61\x72\x79\x0a\x6d\x61\x74\x63\x68\x3a\x20\x24\x73\x65\x6e\x74\x65\x6e\x63\x65\x0a\x03\x00\x4c\xb5\x0b\x07\x80\x00\x00\x00")
+
+func ruleApiSummarycaseYmlBytes() ([]byte, error) {
+	return bindataRead(
+		_ruleApiSummarycaseYml,
+		"rule/api/SummaryCase.yml",
+	)
+}
+
+func ruleApiSummarycaseYml() (*asset, error) {
+	bytes, err := ruleApiSummarycaseYmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "rule/api/SummaryCase.yml", size: 128, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _ruleApiSummarypunctuationYml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x8d\x00\x72\xff\x65\x78\x74\x65\x6e\x64\x73\x3a\x20\x65\x78\x69\x73\x74\x65\x6e\x63\x65\x0a\x6d\x65\x73\x73\x61\x67\x65\x3a\x20\x22\x44\x6f\x6e\x27\x74\x20\x65\x6e\x64\x20\x61\x20\x73\x75\x6d\x6d\x61\x72\x79\x20\x77\x69\x74\x68\x20\x61\x20\x70\x65\x72\x69\x6f\x64\x2e\x22\x0a\x6c\x65\x76\x65\x6c\x3a\x20\x77\x61\x72\x6e\x69\x6e\x67\x0a\x73\x63\x6f\x70\x65\x3a\x20\x74\x65\x78\x74\x2e\x6f\x70\x65\x6e\x61\x70\x69\x2e\x73\x75\x6d\x6d\x61\x72\x79\x0a\x6e\x6f\x6e\x77\x6f\x72\x64\x3a\x20\x74\x72\x75\x65\x0a\x74\x6f\x6b\x65\x6e\x73\x3a\x0a\x20\x20\x2d\x20\x27\x5c\x2e\x5c\x7a\x27\x0a\x03\x00\x76\x18\xe3\xc7\x8d\x00\x00\x00")
+
+func ruleApiSummarypunctuationYmlBytes() ([]byte, error) {
+	return bindataRead(
+		_ruleApiSummarypunctuationYml,
+		"rule/api/SummaryPunctuation.yml",
+	)
+}
+
+func ruleApiSummarypunctuationYml() (*asset, error) {
+	bytes, err := ruleApiSummarypunctuationYmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "rule/api/SummaryPunctuation.yml", size: 141, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -163,6 +247,10 @@ func AssetNames() []string {
 var _bindata = map[string]func() (*asset, error){
 	"rule/Vale/Repetition.yml": ruleValeRepetitionYml,
 	"rule/Vale/Spelling.yml": ruleValeSpellingYml,
+	"rule/api/Descriptions.yml": ruleApiDescriptionsYml,
+	"rule/api/ParameterStart.yml": ruleApiParameterstartYml,
+	"rule/api/SummaryCase.yml": ruleApiSummarycaseYml,
+	"rule/api/SummaryPunctuation.yml": ruleApiSummarypunctuationYml,
 }
 
 // AssetDir returns the file names below a certain
@@ -210,6 +298,12 @@ var _bintree = &bintree{nil, map[string]*bintree{
 			"Repetition.yml": &bintree{ruleValeRepetitionYml, map[string]*bintree{}},
 			"Spelling.yml": &bintree{ruleValeSpellingYml, map[string]*bintree{}},
 		}},
+		"api": &bintree{nil, map[string]*bintree{
+			"Descriptions.yml": &bintree{ruleApiDescriptionsYml, map[string]*bintree{}},
+			"ParameterStart.yml": &bintree{ruleApiParameterstartYml, map[string]*bintree{}},
+			"SummaryCase.yml": &bintree{ruleApiSummarycaseYml, map[string]*bintree{}},
+			"SummaryPunctuation.yml": &bintree{ruleApiSummarypunctuationYml, map[string]*bintree{}},
+		}},
 	}},
 }}
 
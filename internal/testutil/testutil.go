@@ -0,0 +1,24 @@
+// Package testutil provides helpers shared by Vale's tests.
+package testutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// WriteFiles creates each of `files` -- a map of slash-separated paths,
+// relative to `dir`, to their content -- along with any missing parent
+// directories.
+func WriteFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		fp := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+			t.Fatal(err)
+		} else if err = ioutil.WriteFile(fp, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package pdf
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// A lexer reads PDF objects (and content-stream operators) from its input.
+type lexer struct {
+	data []byte
+	pos  int
+}
+
+func newLexer(data []byte) *lexer {
+	return &lexer{data: data}
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f' || b == 0
+}
+
+func isDelim(b byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), b) >= 0
+}
+
+// skip advances past any whitespace and comments.
+func (l *lexer) skip() {
+	for l.pos < len(l.data) {
+		if isSpace(l.data[l.pos]) {
+			l.pos++
+		} else if l.data[l.pos] == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		} else {
+			return
+		}
+	}
+}
+
+// next returns the next token: a delimiter (e.g., `<<` or `[`), a name, a
+// string, or a word (a number, keyword, or operator). It returns nil at the
+// end of the input.
+func (l *lexer) next() interface{} {
+	l.skip()
+	if l.pos >= len(l.data) {
+		return nil
+	}
+
+	b := l.data[l.pos]
+	switch {
+	case b == '(':
+		return l.literal()
+	case b == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		return opcode("<<")
+	case b == '>' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '>':
+		l.pos += 2
+		return opcode(">>")
+	case b == '<':
+		return l.hexString()
+	case b == '/':
+		l.pos++
+		return name(l.word())
+	case isDelim(b):
+		l.pos++
+		return opcode(string(b))
+	}
+
+	w := l.word()
+	if n, err := strconv.ParseFloat(w, 64); err == nil {
+		return n
+	}
+	return opcode(w)
+}
+
+// word reads up to the next whitespace or delimiter.
+func (l *lexer) word() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isSpace(l.data[l.pos]) && !isDelim(l.data[l.pos]) {
+		l.pos++
+	}
+	if l.pos == start && l.pos < len(l.data) {
+		// An unexpected delimiter (e.g., `)`), which we skip.
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+// literal reads a `(string)`, including its escape sequences.
+func (l *lexer) literal() str {
+	var out []byte
+
+	depth := 0
+	l.pos++
+	for l.pos < len(l.data) {
+		b := l.data[l.pos]
+		l.pos++
+		switch b {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return out
+			}
+			depth--
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				b = '\n'
+			case 'r':
+				b = '\r'
+			case 't':
+				b = '\t'
+			case 'b':
+				b = '\b'
+			case 'f':
+				b = '\f'
+			case '\r', '\n':
+				// A line continuation.
+				if e == '\r' && l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					n := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data); i++ {
+						d := l.data[l.pos]
+						if d < '0' || d > '7' {
+							break
+						}
+						n = n*8 + int(d-'0')
+						l.pos++
+					}
+					b = byte(n)
+				} else {
+					b = e
+				}
+			}
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// hexString reads a `<hex string>`.
+func (l *lexer) hexString() str {
+	var digits []byte
+
+	l.pos++
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if !isSpace(l.data[l.pos]) {
+			digits = append(digits, l.data[l.pos])
+		}
+		l.pos++
+	}
+	l.pos++
+
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		n, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		out[i] = byte(n)
+	}
+	return out
+}
+
+// object reads the next object, combining its tokens: arrays, dictionaries,
+// and references (`12 0 R`). It returns nil at the end of the input.
+func (l *lexer) object() interface{} {
+	tok := l.next()
+	switch tok {
+	case opcode("["):
+		a := array{}
+		for {
+			obj := l.object()
+			if obj == nil || obj == opcode("]") {
+				return a
+			}
+			a = append(a, obj)
+		}
+	case opcode("<<"):
+		d := dict{}
+		for {
+			key := l.object()
+			if key == nil || key == opcode(">>") {
+				return d
+			} else if k, ok := key.(name); ok {
+				d[k] = l.object()
+			}
+		}
+	}
+
+	if n, ok := tok.(float64); ok {
+		// This may be the start of a reference.
+		save := l.pos
+		if gen, ok := l.next().(float64); ok && l.peekOp("R") {
+			l.next()
+			return ref{num: int(n), gen: int(gen)}
+		}
+		l.pos = save
+	}
+
+	return tok
+}
+
+// peekOp determines if the next token is the keyword `op`.
+func (l *lexer) peekOp(op string) bool {
+	save := l.pos
+	defer func() { l.pos = save }()
+	return l.next() == opcode(op)
+}
+
+// streamData returns the data of the stream whose dictionary, `d`, we've just
+// read (along with its `stream` keyword).
+func (l *lexer) streamData(d dict) []byte {
+	if l.pos < len(l.data) && l.data[l.pos] == '\r' {
+		l.pos++
+	}
+	if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+		l.pos++
+	}
+	start := l.pos
+
+	if n, ok := d["Length"].(float64); ok {
+		end := start + int(n)
+		if end <= len(l.data) && bytes.HasPrefix(bytes.TrimLeft(l.data[end:], " \r\n"), []byte("endstream")) {
+			l.pos = end
+			return l.data[start:end]
+		}
+	}
+
+	// The length is indirect (or wrong), so we look for the end instead.
+	end := bytes.Index(l.data[start:], []byte("endstream"))
+	if end < 0 {
+		l.pos = len(l.data)
+		return l.data[start:]
+	}
+	l.pos = start + end
+	return bytes.TrimRight(l.data[start:start+end], "\r\n")
+}
+
+// skipInlineImage advances past an inline image's data (that is, from `ID`
+// to `EI`), which isn't made up of tokens.
+func (l *lexer) skipInlineImage() {
+	for {
+		tok := l.next()
+		if tok == nil {
+			return
+		} else if tok == opcode("ID") {
+			break
+		}
+	}
+
+	for l.pos < len(l.data) {
+		i := bytes.Index(l.data[l.pos:], []byte("EI"))
+		if i < 0 {
+			l.pos = len(l.data)
+			return
+		}
+		l.pos += i + 2
+		if l.pos >= len(l.data) || isSpace(l.data[l.pos]) {
+			return
+		}
+	}
+}
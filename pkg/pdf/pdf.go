@@ -0,0 +1,523 @@
+// Package pdf extracts the text of a PDF document's pages.
+//
+// It's intended for linting, not rendering: we only need the words on each
+// page (in content-stream order), so fonts are only consulted for their
+// `ToUnicode` maps and positioning is reduced to word and line breaks.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Types of PDF objects.
+type (
+	name   string
+	dict   map[name]interface{}
+	array  []interface{}
+	ref    struct{ num, gen int }
+	str    []byte
+	opcode string
+	stream struct {
+		hdr  dict
+		data []byte
+	}
+)
+
+// maxDepth limits the nesting of page trees and form XObjects, which protects
+// us from (malformed) cyclic references.
+const maxDepth = 32
+
+var reObject = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+var reTrailer = regexp.MustCompile(`trailer\s*<<`)
+
+// A document holds a PDF's objects, keyed by object number.
+type document struct {
+	objects map[int]interface{}
+	root    dict
+
+	// cmaps caches the `ToUnicode` map of each (indirect) font.
+	cmaps map[ref]*cmap
+}
+
+// Pages returns the text of each page in the PDF document `data`, in order.
+//
+// Each page's text is returned on a single line, with runs of whitespace
+// replaced by a space.
+func Pages(data []byte) ([]string, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("%PDF")) {
+		return nil, errors.New("not a PDF document")
+	}
+
+	doc := document{objects: make(map[int]interface{}), cmaps: make(map[ref]*cmap)}
+	doc.load(data)
+
+	pages, ok := doc.resolve(doc.root["Pages"]).(dict)
+	if !ok {
+		return nil, errors.New("no page tree found")
+	}
+
+	texts := []string{}
+	doc.walk(pages, nil, 0, func(page, resources dict) {
+		var buf bytes.Buffer
+		for _, content := range doc.contents(page["Contents"]) {
+			doc.extract(&buf, content, resources, 0)
+		}
+		texts = append(texts, strings.Join(strings.Fields(buf.String()), " "))
+	})
+
+	return texts, nil
+}
+
+// load reads every object in `data`, including those in object streams, and
+// finds the document's catalog.
+func (doc *document) load(data []byte) {
+	var trailers []dict
+
+	for _, m := range reObject.FindAllSubmatchIndex(data, -1) {
+		num, _ := strconv.Atoi(string(data[m[2]:m[3]]))
+
+		lex := newLexer(data[m[1]:])
+		obj := lex.object()
+		if d, ok := obj.(dict); ok && lex.peekOp("stream") {
+			lex.next()
+			obj = stream{hdr: d, data: lex.streamData(d)}
+		}
+		// Later definitions (e.g., from incremental updates) win.
+		doc.objects[num] = obj
+	}
+
+	for _, m := range reTrailer.FindAllIndex(data, -1) {
+		if d, ok := newLexer(data[m[0]+len("trailer"):]).object().(dict); ok {
+			trailers = append(trailers, d)
+		}
+	}
+
+	for _, obj := range doc.objects {
+		s, ok := obj.(stream)
+		if !ok {
+			continue
+		} else if s.hdr["Type"] == name("ObjStm") {
+			doc.loadObjectStream(s)
+		} else if s.hdr["Type"] == name("XRef") {
+			trailers = append(trailers, s.hdr)
+		}
+	}
+
+	for _, t := range trailers {
+		if root, ok := doc.resolve(t["Root"]).(dict); ok {
+			doc.root = root
+		}
+	}
+
+	if doc.root == nil {
+		// The trailer is missing or damaged, so we look for the catalog
+		// itself.
+		for _, obj := range doc.objects {
+			if d, ok := obj.(dict); ok && d["Type"] == name("Catalog") {
+				doc.root = d
+			}
+		}
+	}
+}
+
+// loadObjectStream reads the objects compressed into the object stream `s`.
+func (doc *document) loadObjectStream(s stream) {
+	data, err := doc.decode(s)
+	if err != nil {
+		return
+	}
+
+	n, _ := s.hdr["N"].(float64)
+	first, _ := s.hdr["First"].(float64)
+	if int(first) > len(data) {
+		return
+	}
+
+	header := newLexer(data[:int(first)])
+	for i := 0; i < int(n); i++ {
+		num, ok1 := header.object().(float64)
+		offset, ok2 := header.object().(float64)
+		start := int(first) + int(offset)
+		if !ok1 || !ok2 || start > len(data) {
+			return
+		} else if _, found := doc.objects[int(num)]; !found {
+			doc.objects[int(num)] = newLexer(data[start:]).object()
+		}
+	}
+}
+
+// resolve follows `obj` if it's a reference.
+func (doc *document) resolve(obj interface{}) interface{} {
+	for i := 0; i < maxDepth; i++ {
+		r, ok := obj.(ref)
+		if !ok {
+			return obj
+		}
+		obj = doc.objects[r.num]
+	}
+	return nil
+}
+
+// walk calls `fn` for each page in the page tree `node`, in order, along with
+// the page's (possibly inherited) resources.
+func (doc *document) walk(node, resources dict, depth int, fn func(page, resources dict)) {
+	if depth > maxDepth {
+		return
+	}
+
+	if r, ok := doc.resolve(node["Resources"]).(dict); ok {
+		resources = r
+	}
+
+	kids, ok := doc.resolve(node["Kids"]).(array)
+	if !ok {
+		fn(node, resources)
+		return
+	}
+
+	for _, kid := range kids {
+		if d, ok := doc.resolve(kid).(dict); ok {
+			doc.walk(d, resources, depth+1, fn)
+		}
+	}
+}
+
+// contents returns the decoded content streams in `obj` (a page's
+// `Contents`).
+func (doc *document) contents(obj interface{}) [][]byte {
+	streams := [][]byte{}
+
+	obj = doc.resolve(obj)
+	if a, ok := obj.(array); ok {
+		for _, entry := range a {
+			streams = append(streams, doc.contents(entry)...)
+		}
+	} else if s, ok := obj.(stream); ok {
+		if data, err := doc.decode(s); err == nil {
+			streams = append(streams, data)
+		}
+	}
+
+	return streams
+}
+
+// decode applies the filters of the stream `s`.
+func (doc *document) decode(s stream) ([]byte, error) {
+	filters := array{}
+	switch f := doc.resolve(s.hdr["Filter"]).(type) {
+	case name:
+		filters = append(filters, f)
+	case array:
+		filters = f
+	}
+
+	data := s.data
+	for _, f := range filters {
+		switch doc.resolve(f) {
+		case name("FlateDecode"):
+			r, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			// NOTE: Many streams are missing their checksum (or are
+			// otherwise slightly truncated), so we keep what we could read.
+			out, err := ioutil.ReadAll(r)
+			if err != nil && len(out) == 0 {
+				return nil, err
+			}
+			data = out
+		case name("ASCIIHexDecode"):
+			text := strings.Map(func(r rune) rune {
+				if isSpace(byte(r)) {
+					return -1
+				}
+				return r
+			}, strings.TrimSuffix(strings.TrimSpace(string(data)), ">"))
+			if len(text)%2 == 1 {
+				text += "0"
+			}
+			out, err := hex.DecodeString(text)
+			if err != nil {
+				return nil, err
+			}
+			data = out
+		default:
+			return nil, fmt.Errorf("unsupported filter '%v'", f)
+		}
+	}
+
+	return data, nil
+}
+
+// extract writes the text shown by the content stream `data` to `buf`.
+func (doc *document) extract(buf *bytes.Buffer, data []byte, resources dict, depth int) {
+	if depth > maxDepth {
+		return
+	}
+
+	fonts, _ := doc.resolve(resources["Font"]).(dict)
+	xobjects, _ := doc.resolve(resources["XObject"]).(dict)
+
+	var font *cmap
+	var size float64
+
+	space := func() {
+		if buf.Len() > 0 && !isSpace(buf.Bytes()[buf.Len()-1]) {
+			buf.WriteByte(' ')
+		}
+	}
+
+	operands := []interface{}{}
+	lex := newLexer(data)
+	for {
+		obj := lex.object()
+		if obj == nil {
+			break
+		}
+
+		op, ok := obj.(opcode)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+
+		switch op {
+		case "Tf":
+			if len(operands) > 0 {
+				if n, ok := operands[0].(name); ok {
+					font = doc.fontMap(fonts[n])
+				}
+			}
+			if len(operands) > 1 {
+				size, _ = operands[1].(float64)
+			}
+		case "Tj", "'", "\"":
+			if op != "Tj" {
+				space()
+			}
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].(str); ok {
+					buf.WriteString(font.decode(s))
+				}
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				a, _ := operands[0].(array)
+				for _, entry := range a {
+					switch v := entry.(type) {
+					case str:
+						buf.WriteString(font.decode(v))
+					case float64:
+						// A large negative adjustment is a gap between
+						// words.
+						if v < -200 {
+							space()
+						}
+					}
+				}
+			}
+		case "Td", "TD":
+			if len(operands) == 2 {
+				// A new line, or a move of more than an em along the
+				// current one (e.g., between words set in different
+				// fonts), is also a gap.
+				tx, _ := operands[0].(float64)
+				if operands[1] != float64(0) || (size > 0 && tx > size) {
+					space()
+				}
+			}
+		case "T*", "Tm", "ET":
+			space()
+		case "Do":
+			if len(operands) > 0 {
+				if n, ok := operands[0].(name); ok {
+					doc.extractForm(buf, xobjects[n], resources, depth)
+				}
+			}
+		case "BI":
+			lex.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+}
+
+// extractForm writes the text of the form XObject `obj` to `buf`.
+func (doc *document) extractForm(buf *bytes.Buffer, obj interface{}, resources dict, depth int) {
+	s, ok := doc.resolve(obj).(stream)
+	if !ok || s.hdr["Subtype"] != name("Form") {
+		return
+	}
+
+	if r, ok := doc.resolve(s.hdr["Resources"]).(dict); ok {
+		resources = r
+	}
+
+	if data, err := doc.decode(s); err == nil {
+		doc.extract(buf, data, resources, depth+1)
+	}
+}
+
+// fontMap returns the `ToUnicode` map of the font `obj`, if it has one.
+func (doc *document) fontMap(obj interface{}) *cmap {
+	r, indirect := obj.(ref)
+	if m, found := doc.cmaps[r]; indirect && found {
+		return m
+	}
+
+	m := doc.parseFontMap(obj)
+	if indirect {
+		doc.cmaps[r] = m
+	}
+	return m
+}
+
+func (doc *document) parseFontMap(obj interface{}) *cmap {
+	font, ok := doc.resolve(obj).(dict)
+	if !ok {
+		return nil
+	}
+
+	s, ok := doc.resolve(font["ToUnicode"]).(stream)
+	if !ok {
+		return nil
+	}
+
+	data, err := doc.decode(s)
+	if err != nil {
+		return nil
+	}
+	return parseCMap(data)
+}
+
+// A cmap maps character codes to their Unicode text.
+type cmap struct {
+	width int // the length, in bytes, of each code
+	codes map[int]string
+}
+
+// parseCMap reads the `bfchar` and `bfrange` mappings of the CMap `data`.
+func parseCMap(data []byte) *cmap {
+	m := cmap{width: 1, codes: make(map[int]string)}
+
+	var operands []interface{}
+	lex := newLexer(data)
+	for {
+		obj := lex.object()
+		if obj == nil {
+			break
+		}
+
+		op, ok := obj.(opcode)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+
+		switch op {
+		case "endcodespacerange":
+			if len(operands) > 0 {
+				if s, ok := operands[0].(str); ok && len(s) > 0 {
+					m.width = len(s)
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(str)
+				dst, ok2 := operands[i+1].(str)
+				if ok1 && ok2 {
+					m.codes[code(src)] = utf16BE(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].(str)
+				hi, ok2 := operands[i+1].(str)
+				if !ok1 || !ok2 || code(hi)-code(lo) > 0xFFFF {
+					continue
+				}
+				switch dst := operands[i+2].(type) {
+				case str:
+					units := utf16.Decode(toUnits(dst))
+					for c := code(lo); c <= code(hi) && len(units) > 0; c++ {
+						m.codes[c] = string(units)
+						units[len(units)-1]++
+					}
+				case array:
+					for j, entry := range dst {
+						if s, ok := entry.(str); ok {
+							m.codes[code(lo)+j] = utf16BE(s)
+						}
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+
+	return &m
+}
+
+// decode converts the string `s`, shown using the font with the map `m`, to
+// text.
+//
+// Without a map, we assume a single-byte encoding (WinAnsi, which matches
+// Latin-1 other than in its `0x80-0x9F` range).
+func (m *cmap) decode(s str) string {
+	var sb strings.Builder
+	if m == nil {
+		for _, b := range s {
+			if r, ok := winAnsi[b]; ok {
+				sb.WriteRune(r)
+			} else {
+				sb.WriteRune(rune(b))
+			}
+		}
+		return sb.String()
+	}
+
+	for i := 0; i+m.width <= len(s); i += m.width {
+		if text, ok := m.codes[code(s[i:i+m.width])]; ok {
+			sb.WriteString(text)
+		}
+	}
+	return sb.String()
+}
+
+// winAnsi holds the characters in WinAnsiEncoding's `0x80-0x9F` range.
+var winAnsi = map[byte]rune{
+	0x80: '€', 0x82: '‚', 0x83: 'ƒ', 0x84: '„', 0x85: '…', 0x86: '†',
+	0x87: '‡', 0x88: 'ˆ', 0x89: '‰', 0x8A: 'Š', 0x8B: '‹', 0x8C: 'Œ',
+	0x8E: 'Ž', 0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”', 0x95: '•',
+	0x96: '–', 0x97: '—', 0x98: '˜', 0x99: '™', 0x9A: 'š', 0x9B: '›',
+	0x9C: 'œ', 0x9E: 'ž', 0x9F: 'Ÿ',
+}
+
+// code returns the big-endian character code in `s`.
+func code(s str) int {
+	c := 0
+	for _, b := range s {
+		c = c<<8 | int(b)
+	}
+	return c
+}
+
+func toUnits(s str) []uint16 {
+	units := []uint16{}
+	for i := 0; i+1 < len(s); i += 2 {
+		units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+	}
+	return units
+}
+
+func utf16BE(s str) string {
+	return string(utf16.Decode(toUnits(s)))
+}
@@ -0,0 +1,79 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+)
+
+// buildPDF returns a PDF document made up of `objects`, numbered from 1.
+func buildPDF(objects ...string) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString("%PDF-1.4\n")
+	for i, obj := range objects {
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+
+	return buf.Bytes()
+}
+
+// streamObj returns a stream object holding `data`, compressed if `flate` is
+// true.
+func streamObj(data string, flate bool) string {
+	if !flate {
+		return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(data), data)
+	}
+
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write([]byte(data))
+	w.Close()
+
+	return fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream",
+		buf.Len(), buf.String())
+}
+
+const toUnicode = `/CIDInit /ProcSet findresource begin
+begincmap
+1 begincodespacerange <0000> <FFFF> endcodespacerange
+2 beginbfchar <0001> <0048> <0002> <0069> endbfchar
+1 beginbfrange <0003> <0004> <0020> endbfrange
+endcmap`
+
+func TestPages(t *testing.T) {
+	data := buildPDF(
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R 5 0 R] /Count 2 /Resources << /Font << /F1 7 0 R >> >> >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>`,
+		streamObj(`BT /F1 12 Tf 72 700 Td (This is a \(simple\)) Tj 0 -14 Td [(p) 20 (age) -400 (one.)] TJ 60 0 Td (Ne) Tj 5 0 Td (xt.) Tj ET`, false),
+		`<< /Type /Page /Parent 2 0 R /Contents [6 0 R] /Resources << /Font << /F2 8 0 R >> >> >>`,
+		streamObj(`BT /F2 12 Tf 72 700 Td <0001000200030001> Tj ET`, true),
+		`<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>`,
+		`<< /Type /Font /Subtype /Type0 /ToUnicode 9 0 R >>`,
+		streamObj(toUnicode, true),
+	)
+
+	pages, err := Pages(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"This is a (simple) page one. Next.", "Hi H"}
+	if len(pages) != len(expected) {
+		t.Fatalf("Expected %d pages, got %d: %q", len(expected), len(pages), pages)
+	}
+	for i, page := range pages {
+		if page != expected[i] {
+			t.Errorf("Page %d: expected %q, got %q", i+1, expected[i], page)
+		}
+	}
+}
+
+func TestNotPDF(t *testing.T) {
+	if _, err := Pages([]byte("Hello, world!")); err == nil {
+		t.Error("Expected an error")
+	}
+}
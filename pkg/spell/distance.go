@@ -0,0 +1,113 @@
+package spell
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// editDistance returns the optimal-string-alignment distance between `a` and
+// `b` -- the number of insertions, deletions, substitutions, and
+// transpositions of adjacent characters needed to turn one into the other --
+// or `max`+1 if it's more than `max`.
+func editDistance(a, b []rune, max int) int {
+	return newDistancer(len(b)).distance(a, b, max)
+}
+
+// A distancer calculates edit distances, reusing its rows of the matrix
+// between calls.
+type distancer struct {
+	prev2, prev, curr []int
+}
+
+func newDistancer(n int) *distancer {
+	return &distancer{
+		prev2: make([]int, n+1), prev: make([]int, n+1), curr: make([]int, n+1)}
+}
+
+func (m *distancer) distance(a, b []rune, max int) int {
+	if d := len(a) - len(b); d > max || -d > max {
+		return max + 1
+	} else if len(m.curr) < len(b)+1 {
+		*m = *newDistancer(len(b))
+	}
+
+	// We only need the last two rows (and the current one) of the matrix.
+	prev2, prev, curr := m.prev2, m.prev, m.curr
+	for j := 0; j <= len(b); j++ {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		best := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			d := min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && prev2[j-2]+1 < d {
+				d = prev2[j-2] + 1
+			}
+			curr[j] = d
+
+			if d < best {
+				best = d
+			}
+		}
+
+		if best > max {
+			// Every remaining path is already too long.
+			return max + 1
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	if prev[len(b)] > max {
+		return max + 1
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// distance returns the edit distance (see `editDistance`) between `word` and
+// the nearest word in the dictionary, ignoring case, or -1 if there isn't one
+// within `max`.
+func (s *goSpell) distance(word string, max int) int {
+	target := []rune(strings.ToLower(word))
+	m := newDistancer(len(target) + max)
+
+	nearest, buf := -1, []rune{}
+	for entry := range s.dict {
+		if n := utf8.RuneCountInString(entry) - len(target); n > max || -n > max {
+			// This is much cheaper than the comparison itself.
+			continue
+		}
+
+		buf = buf[:0]
+		for _, r := range entry {
+			buf = append(buf, unicode.ToLower(r))
+		}
+
+		if d := m.distance(target, buf, max); d <= max {
+			if d == 0 {
+				return 0
+			}
+			// From now on, we're only interested in closer words.
+			nearest, max = d, d-1
+		}
+	}
+
+	return nearest
+}
@@ -0,0 +1,21 @@
+package spell
+
+import "testing"
+
+func TestEditDistance(t *testing.T) {
+	for _, tt := range []struct {
+		a, b     string
+		max, out int
+	}{
+		{"recieve", "receive", 2, 1},
+		{"kitten", "sitting", 3, 3},
+		{"kitten", "sitting", 2, 3},
+		{"café", "cafe", 1, 1},
+		{"a", "abcd", 2, 3},
+		{"same", "same", 0, 0},
+	} {
+		if d := editDistance([]rune(tt.a), []rune(tt.b), tt.max); d != tt.out {
+			t.Errorf("(%q, %q, %d) => %d != %d", tt.a, tt.b, tt.max, d, tt.out)
+		}
+	}
+}
@@ -104,6 +104,21 @@ func (m *Checker) Spell(word string) bool {
 	return false
 }
 
+// Distance returns the edit distance between `word` and the nearest word in
+// any of the dictionaries, or -1 if there isn't one within `max`.
+//
+// Every entry of each dictionary is compared against `word`, so this is much
+// slower than `Spell` and is meant for words that it has rejected.
+func (m *Checker) Distance(word string, max int) int {
+	nearest := -1
+	for _, checker := range m.checkers {
+		if d := checker.distance(word, max); d >= 0 && (nearest < 0 || d < nearest) {
+			nearest = d
+		}
+	}
+	return nearest
+}
+
 // Convert performs character substitutions (ICONV).
 func (m *Checker) Convert(s string) string {
 	for _, checker := range m.checkers {